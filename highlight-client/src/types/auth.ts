@@ -19,6 +19,8 @@ export interface AuthResponse {
   user: AuthUser;
   access_token: string;
   token_type: string;
+  expires_in: number;
+  refresh_token: string;
 }
 
 export type AuthErrorMap = Record<string, string>;
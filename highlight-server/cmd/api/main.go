@@ -2,34 +2,83 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	chimw "github.com/go-chi/chi/v5/middleware"
 
 	"highlightiq-server/internal/config"
 	"highlightiq-server/internal/db"
+	accounthandlers "highlightiq-server/internal/http/handlers/account"
+	analyticshandlers "highlightiq-server/internal/http/handlers/analytics"
+	apikeyshandlers "highlightiq-server/internal/http/handlers/apikeys"
 	authhandlers "highlightiq-server/internal/http/handlers/auth"
 	clipcandhandlers "highlightiq-server/internal/http/handlers/clipcandidates"
 	clipshandlers "highlightiq-server/internal/http/handlers/clips"
+	collectionshandlers "highlightiq-server/internal/http/handlers/collections"
+	outboxhandlers "highlightiq-server/internal/http/handlers/outbox"
+	pubhandlers "highlightiq-server/internal/http/handlers/publications"
 	recordinghandlers "highlightiq-server/internal/http/handlers/recordings"
-	yphandlers "highlightiq-server/internal/http/handlers/youtubepublishes"
+	tagshandlers "highlightiq-server/internal/http/handlers/tags"
+	trashhandlers "highlightiq-server/internal/http/handlers/trash"
+	usagehandlers "highlightiq-server/internal/http/handlers/usage"
+	webhookshandlers "highlightiq-server/internal/http/handlers/webhooks"
+	workspaceshandlers "highlightiq-server/internal/http/handlers/workspaces"
+	ytaccounthandlers "highlightiq-server/internal/http/handlers/youtubeaccount"
 	"highlightiq-server/internal/http/middleware"
 	"highlightiq-server/internal/http/router"
 
 	"highlightiq-server/internal/integrations/clipper"
 	"highlightiq-server/internal/integrations/n8n"
+	"highlightiq-server/internal/integrations/oidc"
+	"highlightiq-server/internal/integrations/webhook"
+	"highlightiq-server/internal/integrations/youtube"
+	"highlightiq-server/internal/mail"
 
+	analyticsrepo "highlightiq-server/internal/repos/analytics"
+	apikeysrepo "highlightiq-server/internal/repos/apikeys"
 	clipcandidatesrepo "highlightiq-server/internal/repos/clipcandidates"
 	clipsrepo "highlightiq-server/internal/repos/clips"
+	collectionsrepo "highlightiq-server/internal/repos/collections"
+	identitiesrepo "highlightiq-server/internal/repos/identities"
+	loginattemptsrepo "highlightiq-server/internal/repos/loginattempts"
+	mfarepo "highlightiq-server/internal/repos/mfa"
+	outboxrepo "highlightiq-server/internal/repos/outbox"
+	pubrepo "highlightiq-server/internal/repos/publications"
 	recordingrepo "highlightiq-server/internal/repos/recordings"
+	sessionsrepo "highlightiq-server/internal/repos/sessions"
+	tagsrepo "highlightiq-server/internal/repos/tags"
+	usagerepo "highlightiq-server/internal/repos/usage"
 	"highlightiq-server/internal/repos/users"
-	youtubePublishesRepo "highlightiq-server/internal/repos/youtubepublishes"
+	usertokensrepo "highlightiq-server/internal/repos/usertokens"
+	webhooksrepo "highlightiq-server/internal/repos/webhooks"
+	workspacesrepo "highlightiq-server/internal/repos/workspaces"
+	youtubeaccountsrepo "highlightiq-server/internal/repos/youtubeaccounts"
 
+	"highlightiq-server/internal/secretbox"
+	accountsvc "highlightiq-server/internal/services/account"
+	analyticssvc "highlightiq-server/internal/services/analytics"
+	apikeyssvc "highlightiq-server/internal/services/apikeys"
 	authsvc "highlightiq-server/internal/services/auth"
 	clipcandidatessvc "highlightiq-server/internal/services/clipcandidates"
 	clipssvc "highlightiq-server/internal/services/clips"
+	collectionssvc "highlightiq-server/internal/services/collections"
+	outboxsvc "highlightiq-server/internal/services/outbox"
+	pubsvc "highlightiq-server/internal/services/publications"
 	recordingsvc "highlightiq-server/internal/services/recordings"
-	ypsvc "highlightiq-server/internal/services/youtubepublishes"
+	tagssvc "highlightiq-server/internal/services/tags"
+	trashsvc "highlightiq-server/internal/services/trash"
+	usagesvc "highlightiq-server/internal/services/usage"
+	webhookssvc "highlightiq-server/internal/services/webhooks"
+	workspacessvc "highlightiq-server/internal/services/workspaces"
+	ytpublishersvc "highlightiq-server/internal/services/youtubepublisher"
+	"highlightiq-server/internal/signedurl"
+	"highlightiq-server/internal/storage"
 )
 
 func main() {
@@ -43,46 +92,283 @@ func main() {
 
 	// repos
 	usersRepo := users.New(conn)
+	sessionsRepo := sessionsrepo.New(conn)
 	recRepo := recordingrepo.New(conn)
 	clipCandidatesRepo := clipcandidatesrepo.New(conn)
 	clipsRepo := clipsrepo.New(conn)
-	ypRepo := youtubePublishesRepo.New(conn)
+	pubRepo := pubrepo.New(conn)
+	outboxRepo := outboxrepo.New(conn)
+	webhooksRepo := webhooksrepo.New(conn)
+	analyticsRepo := analyticsrepo.New(conn)
+	tagRepo := tagsrepo.New(conn)
+	collectionRepo := collectionsrepo.New(conn)
+	usageRepo := usagerepo.New(conn)
+	apiKeysRepo := apikeysrepo.New(conn)
+	workspacesRepo := workspacesrepo.New(conn)
+	userTokensRepo := usertokensrepo.New(conn)
+	loginAttemptsRepo := loginattemptsrepo.New(conn)
+	mfaRepo := mfarepo.New(conn)
+	identitiesRepo := identitiesrepo.New(conn)
+	youtubeAccountsRepo := youtubeaccountsrepo.New(conn)
+
+	clipsDir := os.Getenv("CLIPS_DIR")
+	if clipsDir == "" {
+		clipsDir = "/var/lib/highlightiq/clips"
+	}
+
+	// storage
+	recordingStore, clipStore := newStores(cfg, clipsDir)
+	recordingFiles := storage.NewCache(recordingStore, filepath.Join(cfg.Storage.CacheDir, "recordings"), cfg.Storage.CacheMaxBytes)
+	clipFiles := storage.NewCache(clipStore, filepath.Join(cfg.Storage.CacheDir, "clips"), cfg.Storage.CacheMaxBytes)
+
+	// TOTP secrets and YouTube tokens are encrypted at rest; changing the key invalidates every
+	// 2FA enrollment and channel connection. Without TOTP_ENCRYPTION_KEY the key is derived from
+	// JWT_SECRET, so the signing secret itself never doubles as an encryption key.
+	totpKey := cfg.TOTPEncryptionKey
+	if totpKey == "" {
+		totpKey = config.DeriveSecret(cfg.JWTSecret, "totp encryption")
+	}
+	secrets, err := secretbox.New(totpKey)
+	if err != nil {
+		log.Fatalf("secretbox init failed: %v", err)
+	}
 
 	// services
-	authService := authsvc.New(usersRepo, cfg.JWTSecret)
-	recService := recordingsvc.New(recRepo, cfg.RecordingsDir)
+	authService := authsvc.New(usersRepo, sessionsRepo, userTokensRepo, loginAttemptsRepo, mfaRepo, identitiesRepo, newOAuthProviders(cfg.OAuth), secrets, newMailer(cfg.Mail), cfg.AppBaseURL, cfg.JWTSecret)
+	apiKeysService := apikeyssvc.New(apiKeysRepo)
+	workspacesService := workspacessvc.New(workspacesRepo, usersRepo)
+	usageService := usagesvc.New(usageRepo, usagesvc.Limits{
+		RecordingBytes:   cfg.Quota.RecordingBytes,
+		ExportBytes:      cfg.Quota.ExportBytes,
+		DetectionMinutes: cfg.Quota.DetectionMinutes,
+	})
+
+	// Webhook events go through the outbox; each topic has one sender.
+	var playlistSender outboxsvc.Sender
+	if cfg.N8NPlaylistWebhookURL != "" {
+		playlistSender = n8n.New(cfg.N8NPlaylistWebhookURL, cfg.N8NPublishWebhookAuth)
+	}
+
+	webhookClient := webhook.New()
+	if cfg.WebhooksAllowPrivate {
+		log.Printf("webhooks: WEBHOOKS_ALLOW_PRIVATE is set; endpoints may use http and private addresses")
+		webhookClient = webhook.NewUnrestricted()
+	}
+
+	senders := map[string]outboxsvc.Sender{
+		outboxsvc.TopicPlaylistPublish: playlistSender,
+		outboxsvc.TopicWebhookDelivery: webhookssvc.NewSender(webhooksRepo, secrets, webhookClient),
+	}
+	for platform, url := range cfg.N8NPlatformWebhookURLs {
+		senders[outboxsvc.PublishTopic(platform)] = n8n.New(url, cfg.N8NPublishWebhookAuth)
+	}
+	outboxService := outboxsvc.New(outboxRepo, senders)
+	webhooksService := webhookssvc.New(webhooksRepo, outboxService, secrets, workspacesService, webhookClient)
+
+	recService := recordingsvc.New(recRepo, tagRepo, recordingStore, usageService, workspacesService, webhooksService)
 
 	clipperClient := clipper.New("http://127.0.0.1:8090")
-	clipCandidatesService := clipcandidatessvc.New(recRepo, clipCandidatesRepo, clipperClient)
+	clipCandidatesService := clipcandidatessvc.New(recRepo, clipCandidatesRepo, clipperClient, recordingFiles, usageService, workspacesService, webhooksService)
 
-	clipsDir := os.Getenv("CLIPS_DIR")
-	if clipsDir == "" {
-		clipsDir = "/var/lib/highlightiq/clips"
+	// YouTube uploads go natively or through n8n; the native publisher reports to webhooks, so it
+	// is registered with the outbox once both exist.
+	var publishSender outboxsvc.Sender
+	var youtubePublisher *ytpublishersvc.Service
+	switch cfg.YouTube.Publisher {
+	case "native":
+		if cfg.YouTube.ClientID == "" {
+			log.Fatalf("YOUTUBE_PUBLISHER=native needs YOUTUBE_CLIENT_ID")
+		}
+		youtubeAPI := youtube.New(youtube.Config{
+			ClientID:     cfg.YouTube.ClientID,
+			ClientSecret: cfg.YouTube.ClientSecret,
+			RedirectURL:  cfg.YouTube.RedirectURL,
+			AuthURL:      cfg.YouTube.AuthURL,
+			TokenURL:     cfg.YouTube.TokenURL,
+			APIURL:       cfg.YouTube.APIURL,
+		})
+		youtubePublisher = ytpublishersvc.New(youtubeAccountsRepo, pubRepo, clipsRepo, identitiesRepo, youtubeAPI, secrets, clipFiles, webhooksService, cfg.YouTube.Privacy)
+		publishSender = youtubePublisher
+	case "n8n":
+		if cfg.N8NPublishWebhookURL != "" {
+			publishSender = n8n.New(cfg.N8NPublishWebhookURL, cfg.N8NPublishWebhookAuth)
+		}
+	default:
+		log.Fatalf("unknown YOUTUBE_PUBLISHER %q (want n8n or native)", cfg.YouTube.Publisher)
 	}
 
-	var publishNotifier clipssvc.PublishNotifier
-	if cfg.N8NPublishWebhookURL != "" {
-		publishNotifier = n8n.New(cfg.N8NPublishWebhookURL, cfg.N8NPublishWebhookAuth)
+	outboxService.Handle(outboxsvc.PublishTopic(pubrepo.PlatformYouTube), publishSender)
+
+	// Signed links let n8n fetch exports without a user token. Without PUBLIC_URL_SECRET the key
+	// is derived from JWT_SECRET under its own label, so it cannot sign tokens or vice versa.
+	urlSecret := cfg.PublicURLSecret
+	if urlSecret == "" {
+		urlSecret = config.DeriveSecret(cfg.JWTSecret, "public url signing")
 	}
+	publicURLs := signedurl.New(urlSecret, cfg.PublicBaseURL, time.Duration(cfg.PublicURLTTLMinutes)*time.Minute)
 
-	clipsService := clipssvc.New(clipsRepo, recRepo, clipsDir, cfg.ClipsBaseURL, publishNotifier)
-	youtubePublishesService := ypsvc.New(clipsRepo, ypRepo)
+	clipsService := clipssvc.New(clipsRepo, recRepo, tagRepo, recordingFiles, clipFiles, clipsDir, publicURLs, usageService, workspacesService, webhooksService)
+	analyticsService := analyticssvc.New(analyticsRepo)
+	publicationsService := pubsvc.New(clipsRepo, tagRepo, pubRepo, workspacesService, outboxService, webhooksService, clipsService)
+	tagsService := tagssvc.New(tagRepo, clipsRepo, recRepo, workspacesService)
+	collectionsService := collectionssvc.New(collectionRepo, clipsRepo, clipFiles, clipsDir, outboxService)
+	trashService := trashsvc.New(recRepo, clipsRepo, recordingFiles, clipFiles, cfg.TrashRetentionDays)
+	accountService := accountsvc.New(usersRepo, authService, workspacesRepo, recRepo, clipCandidatesRepo, clipsRepo, pubRepo, tagRepo, collectionRepo, apiKeysRepo, usageService, recordingFiles, clipFiles)
 
 	// handlers
 	authHandler := authhandlers.New(authService)
 	recHandler := recordinghandlers.New(recService)
 	clipHandler := clipcandhandlers.New(clipCandidatesService)
-	clipsHandler := clipshandlers.New(clipsService)
-	youtubePublishesHandler := yphandlers.New(youtubePublishesService, cfg.N8NWebhookSecret)
+	clipsHandler := clipshandlers.New(clipsService, clipStore)
+	publicationsHandler := pubhandlers.New(publicationsService)
+	tagsHandler := tagshandlers.New(tagsService)
+	collectionsHandler := collectionshandlers.New(collectionsService, clipStore)
+	trashHandler := trashhandlers.New(trashService)
+	usageHandler := usagehandlers.New(usageService)
+	apiKeysHandler := apikeyshandlers.New(apiKeysService)
+	workspacesHandler := workspaceshandlers.New(workspacesService)
+	accountHandler := accounthandlers.New(accountService)
+	outboxHandler := outboxhandlers.New(outboxService)
+	webhooksHandler := webhookshandlers.New(webhooksService)
+	analyticsHandler := analyticshandlers.New(analyticsService)
+	var youtubeAccountHandler *ytaccounthandlers.Handler
+	if youtubePublisher != nil {
+		youtubeAccountHandler = ytaccounthandlers.New(youtubePublisher)
+	}
 
 	// middleware
-	jwtAuth := middleware.NewJWTAuth(usersRepo, cfg.JWTSecret)
+	jwtAuth := middleware.NewJWTAuth(usersRepo, sessionsRepo, apiKeysService, cfg.JWTSecret)
+
+	internalClients := make([]middleware.InternalClient, 0, len(cfg.InternalClients))
+	for _, c := range cfg.InternalClients {
+		internalClients = append(internalClients, middleware.InternalClient{Name: c.Name, Secret: c.Secret, Routes: c.Routes})
+	}
+	internalAuth := middleware.NewInternalAuth(internalClients, time.Duration(cfg.InternalSignWindowSec)*time.Second)
 
 	// router
-	r := router.New(authHandler, recHandler, clipHandler, clipsHandler, youtubePublishesHandler, jwtAuth.Middleware)
+	r := router.New(router.Handlers{
+		Auth:           authHandler,
+		Recordings:     recHandler,
+		ClipCandidates: clipHandler,
+		Clips:          clipsHandler,
+		Publications:   publicationsHandler,
+		Tags:           tagsHandler,
+		Collections:    collectionsHandler,
+		Trash:          trashHandler,
+		Usage:          usageHandler,
+		APIKeys:        apiKeysHandler,
+		Workspaces:     workspacesHandler,
+		Account:        accountHandler,
+		YouTubeAccount: youtubeAccountHandler,
+		Outbox:         outboxHandler,
+		Webhooks:       webhooksHandler,
+		Analytics:      analyticsHandler,
+	}, jwtAuth.Middleware, internalAuth.Middleware)
+
+	// Client IPs (login throttling, audit) come from RemoteAddr unless a trusted proxy sets them.
+	var handler http.Handler = r
+	if cfg.TrustProxyHeaders {
+		handler = chimw.RealIP(r)
+	}
+
+	// background jobs
+	go trashService.Run(context.Background(), time.Hour)
+	go authService.Run(context.Background(), time.Hour)
+	go publicationsService.Run(context.Background(), 30*time.Second)
+	go publicationsService.RunMetricsRetention(context.Background(), time.Hour)
+	go outboxService.Run(context.Background(), 15*time.Second)
+	if youtubePublisher != nil {
+		go youtubePublisher.Run(context.Background(), 15*time.Minute)
+	}
 
 	log.Println("API listening on :8080")
-	if err := http.ListenAndServe(":8080", r); err != nil {
+	if err := http.ListenAndServe(":8080", handler); err != nil {
 		log.Fatalf("server failed: %v", err)
 	}
 }
+
+// newStores builds the recording and clip stores. With S3 both share one bucket under
+// different prefixes; locally they map to RECORDINGS_DIR and CLIPS_DIR.
+func newStores(cfg config.Config, clipsDir string) (storage.BlobStore, storage.BlobStore) {
+	if cfg.Storage.Backend != "s3" {
+		return storage.NewLocal(cfg.RecordingsDir), storage.NewLocal(clipsDir)
+	}
+
+	s3 := storage.S3Config{
+		Endpoint:  cfg.Storage.S3Endpoint,
+		Region:    cfg.Storage.S3Region,
+		Bucket:    cfg.Storage.S3Bucket,
+		AccessKey: cfg.Storage.S3AccessKey,
+		SecretKey: cfg.Storage.S3SecretKey,
+		PathStyle: cfg.Storage.S3PathStyle,
+		Presign:   cfg.Storage.S3Presign,
+	}
+
+	s3.Prefix = "recordings"
+	recordings, err := storage.NewS3(s3)
+	if err != nil {
+		log.Fatalf("storage init failed: %v", err)
+	}
+
+	s3.Prefix = "clips"
+	clips, err := storage.NewS3(s3)
+	if err != nil {
+		log.Fatalf("storage init failed: %v", err)
+	}
+
+	return recordings, clips
+}
+
+// newOAuthProviders builds the identity providers that have credentials configured.
+func newOAuthProviders(cfg config.OAuthConfig) []*oidc.Provider {
+	base := strings.TrimRight(cfg.RedirectBaseURL, "/")
+
+	var out []*oidc.Provider
+	if cfg.GoogleClientID != "" {
+		out = append(out, oidc.New(oidc.Google(cfg.GoogleClientID, cfg.GoogleClientSecret, base+"/google")))
+	}
+	if cfg.DiscordClientID != "" {
+		out = append(out, oidc.New(oidc.Discord(cfg.DiscordClientID, cfg.DiscordClientSecret, base+"/discord")))
+	}
+	if cfg.TwitchClientID != "" {
+		out = append(out, oidc.New(oidc.Twitch(cfg.TwitchClientID, cfg.TwitchClientSecret, base+"/twitch")))
+	}
+	if cfg.OIDCIssuer != "" {
+		if cfg.OIDCClientID == "" {
+			log.Fatalf("OIDC_ISSUER needs OIDC_CLIENT_ID")
+		}
+		out = append(out, oidc.New(oidc.Config{
+			Name:         "oidc",
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  base + "/oidc",
+			Scopes:       []string{"openid", "email", "profile"},
+			Issuer:       cfg.OIDCIssuer,
+		}))
+	}
+	return out
+}
+
+// newMailer picks the email transport. "log" and "file" are for local setups without SMTP.
+func newMailer(cfg config.MailConfig) mail.Mailer {
+	switch cfg.Driver {
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			log.Fatalf("MAIL_DRIVER=smtp needs SMTP_HOST")
+		}
+		return mail.NewSMTP(mail.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.From,
+		})
+	case "file":
+		return mail.NewFile(cfg.Dir, cfg.From)
+	case "log":
+		return mail.Log{}
+	default:
+		log.Fatalf("unknown MAIL_DRIVER %q (want smtp, log or file)", cfg.Driver)
+		return nil
+	}
+}
@@ -1,6 +1,12 @@
 package config
 
-import "os"
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
 
 // MySQLConfig holds DB connection settings.
 type MySQLConfig struct {
@@ -11,14 +17,109 @@ type MySQLConfig struct {
 	Pass string
 }
 
+// StorageConfig selects where recordings and exported clips are kept.
+type StorageConfig struct {
+	Backend       string // "local" or "s3"
+	CacheDir      string
+	CacheMaxBytes int64
+
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3PathStyle bool
+	S3Presign   bool
+}
+
+// QuotaConfig holds the default per-user limits. 0 disables a limit.
+type QuotaConfig struct {
+	RecordingBytes   int64
+	ExportBytes      int64
+	DetectionMinutes int64
+}
+
+// MailConfig selects how transactional email is delivered: "smtp", "log" (print to the server
+// log) or "file" (write .eml files into Dir).
+type MailConfig struct {
+	Driver string
+	From   string
+	Dir    string
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// OAuthConfig enables "sign in with" providers; a provider is on when its client id is set.
+// OIDC is any OpenID Connect issuer with discovery (e.g. a local mock provider); it shows up as
+// provider "oidc". The provider sends the browser back to RedirectBaseURL + "/" + provider.
+type OAuthConfig struct {
+	RedirectBaseURL string
+
+	GoogleClientID      string
+	GoogleClientSecret  string
+	DiscordClientID     string
+	DiscordClientSecret string
+	TwitchClientID      string
+	TwitchClientSecret  string
+
+	OIDCIssuer       string
+	OIDCClientID     string
+	OIDCClientSecret string
+}
+
+// YouTubeConfig selects how exported clips reach YouTube: "n8n" hands them to the publish
+// webhook, "native" uploads them to each user's connected channel. The URLs only need setting to
+// use a fake YouTube API.
+type YouTubeConfig struct {
+	Publisher    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Privacy      string
+
+	AuthURL  string
+	TokenURL string
+	APIURL   string
+}
+
+// InternalClient is a named caller of the /internal routes. Requests must be signed with
+// Secret (see package reqsign) and may only hit Routes.
+type InternalClient struct {
+	Name   string   `json:"name"`
+	Secret string   `json:"secret"`
+	Routes []string `json:"routes"`
+}
+
 type Config struct {
 	MySQL                 MySQLConfig
 	JWTSecret             string
+	TOTPEncryptionKey     string
 	RecordingsDir         string
-	N8NWebhookSecret      string
-	ClipsBaseURL          string
+	InternalClients       []InternalClient
+	InternalSignWindowSec int
+	PublicBaseURL         string
+	AppBaseURL            string
+	TrustProxyHeaders     bool
+	PublicURLSecret       string
+	PublicURLTTLMinutes   int
 	N8NPublishWebhookURL  string
 	N8NPublishWebhookAuth string
+	N8NPlaylistWebhookURL string
+	// N8NPlatformWebhookURLs holds the n8n publish workflow of each platform other than
+	// YouTube, keyed by platform. Platforms without one cannot be published to.
+	N8NPlatformWebhookURLs map[string]string
+	TrashRetentionDays     int
+	// WebhooksAllowPrivate lets user webhooks call http URLs and private addresses, for local
+	// development against a receiver on the same machine.
+	WebhooksAllowPrivate bool
+	Storage              StorageConfig
+	Quota                QuotaConfig
+	Mail                 MailConfig
+	OAuth                OAuthConfig
+	YouTube              YouTubeConfig
 }
 
 // Load reads configuration from environment variables with sane defaults.
@@ -31,13 +132,92 @@ func Load() Config {
 			User: getenv("DB_USER", "highlightiq"),
 			Pass: getenv("DB_PASS", "highlightiq_pass"),
 		},
-		JWTSecret:             getenv("JWT_SECRET", "dev-secret-change-me"),
-		RecordingsDir:         getenv("RECORDINGS_DIR", "D:\\recordings"),
-		N8NWebhookSecret:      getenv("N8N_WEBHOOK_SECRET", ""),
-		ClipsBaseURL:          getenv("CLIPS_BASE_URL", ""),
-		N8NPublishWebhookURL:  getenv("N8N_PUBLISH_WEBHOOK_URL", ""),
-		N8NPublishWebhookAuth: getenv("N8N_PUBLISH_WEBHOOK_AUTH", ""),
+		JWTSecret:              getenv("JWT_SECRET", "dev-secret-change-me"),
+		TOTPEncryptionKey:      getenv("TOTP_ENCRYPTION_KEY", ""),
+		RecordingsDir:          getenv("RECORDINGS_DIR", "D:\\recordings"),
+		InternalClients:        internalClients(),
+		InternalSignWindowSec:  getenvInt("INTERNAL_SIGN_WINDOW_SECONDS", 300),
+		PublicBaseURL:          getenv("PUBLIC_BASE_URL", "http://localhost:8080"),
+		AppBaseURL:             getenv("APP_BASE_URL", "http://localhost:5173"),
+		TrustProxyHeaders:      getenvBool("TRUST_PROXY_HEADERS", false),
+		PublicURLSecret:        getenv("PUBLIC_URL_SECRET", ""),
+		PublicURLTTLMinutes:    getenvInt("PUBLIC_URL_TTL_MINUTES", 360),
+		N8NPublishWebhookURL:   getenv("N8N_PUBLISH_WEBHOOK_URL", ""),
+		N8NPublishWebhookAuth:  getenv("N8N_PUBLISH_WEBHOOK_AUTH", ""),
+		N8NPlaylistWebhookURL:  getenv("N8N_PLAYLIST_WEBHOOK_URL", ""),
+		N8NPlatformWebhookURLs: platformWebhookURLs(),
+		TrashRetentionDays:     getenvInt("TRASH_RETENTION_DAYS", 30),
+		WebhooksAllowPrivate:   getenvBool("WEBHOOKS_ALLOW_PRIVATE", false),
+		Storage: StorageConfig{
+			Backend:       getenv("STORAGE_BACKEND", "local"),
+			CacheDir:      getenv("STORAGE_CACHE_DIR", os.TempDir()+"/highlightiq-cache"),
+			CacheMaxBytes: int64(getenvInt("STORAGE_CACHE_MAX_MB", 20_000)) << 20,
+			S3Endpoint:    getenv("S3_ENDPOINT", ""),
+			S3Region:      getenv("S3_REGION", "us-east-1"),
+			S3Bucket:      getenv("S3_BUCKET", ""),
+			S3AccessKey:   getenv("S3_ACCESS_KEY", ""),
+			S3SecretKey:   getenv("S3_SECRET_KEY", ""),
+			S3PathStyle:   getenvBool("S3_PATH_STYLE", true),
+			S3Presign:     getenvBool("S3_PRESIGN", true),
+		},
+		Quota: QuotaConfig{
+			RecordingBytes:   int64(getenvInt("QUOTA_RECORDING_MB", 50_000)) << 20,
+			ExportBytes:      int64(getenvInt("QUOTA_EXPORT_MB", 10_000)) << 20,
+			DetectionMinutes: int64(getenvInt("QUOTA_DETECTION_MINUTES", 600)),
+		},
+		Mail: MailConfig{
+			Driver:       getenv("MAIL_DRIVER", "log"),
+			From:         getenv("MAIL_FROM", "HighlightIQ <no-reply@highlightiq.local>"),
+			Dir:          getenv("MAIL_DIR", os.TempDir()+"/highlightiq-mail"),
+			SMTPHost:     getenv("SMTP_HOST", ""),
+			SMTPPort:     getenvInt("SMTP_PORT", 587),
+			SMTPUsername: getenv("SMTP_USERNAME", ""),
+			SMTPPassword: getenv("SMTP_PASSWORD", ""),
+		},
+		OAuth: OAuthConfig{
+			RedirectBaseURL:     getenv("OAUTH_REDIRECT_BASE_URL", getenv("APP_BASE_URL", "http://localhost:5173")+"/oauth/callback"),
+			GoogleClientID:      getenv("OAUTH_GOOGLE_CLIENT_ID", ""),
+			GoogleClientSecret:  getenv("OAUTH_GOOGLE_CLIENT_SECRET", ""),
+			DiscordClientID:     getenv("OAUTH_DISCORD_CLIENT_ID", ""),
+			DiscordClientSecret: getenv("OAUTH_DISCORD_CLIENT_SECRET", ""),
+			TwitchClientID:      getenv("OAUTH_TWITCH_CLIENT_ID", ""),
+			TwitchClientSecret:  getenv("OAUTH_TWITCH_CLIENT_SECRET", ""),
+			OIDCIssuer:          getenv("OIDC_ISSUER", ""),
+			OIDCClientID:        getenv("OIDC_CLIENT_ID", ""),
+			OIDCClientSecret:    getenv("OIDC_CLIENT_SECRET", ""),
+		},
+		YouTube: YouTubeConfig{
+			Publisher:    getenv("YOUTUBE_PUBLISHER", "n8n"),
+			ClientID:     getenv("YOUTUBE_CLIENT_ID", ""),
+			ClientSecret: getenv("YOUTUBE_CLIENT_SECRET", ""),
+			RedirectURL:  getenv("YOUTUBE_REDIRECT_URL", getenv("APP_BASE_URL", "http://localhost:5173")+"/youtube/callback"),
+			Privacy:      getenv("YOUTUBE_PRIVACY", "private"),
+			AuthURL:      getenv("YOUTUBE_AUTH_URL", ""),
+			TokenURL:     getenv("YOUTUBE_TOKEN_URL", ""),
+			APIURL:       getenv("YOUTUBE_API_URL", ""),
+		},
+	}
+}
+
+// internalClients reads INTERNAL_CLIENTS, a JSON array such as
+//
+//	[{"name":"n8n","secret":"...","routes":["/internal/*"]}]
+//
+// For existing deployments N8N_WEBHOOK_SECRET alone still yields an "n8n" client allowed on every
+// internal route; it now has to sign its requests instead of sending the secret.
+func internalClients() []InternalClient {
+	var out []InternalClient
+	if raw := os.Getenv("INTERNAL_CLIENTS"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &out); err != nil {
+			log.Fatalf("invalid INTERNAL_CLIENTS: %v", err)
+		}
+		return out
+	}
+
+	if secret := os.Getenv("N8N_WEBHOOK_SECRET"); secret != "" {
+		out = append(out, InternalClient{Name: "n8n", Secret: secret, Routes: []string{"/internal/*"}})
 	}
+	return out
 }
 
 func getenv(key, fallback string) string {
@@ -47,3 +227,38 @@ func getenv(key, fallback string) string {
 	}
 	return v
 }
+
+func getenvInt(key string, fallback int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+func getenvBool(key string, fallback bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fallback
+	}
+	return b
+}
+
+// platformWebhookURLs reads N8N_<PLATFORM>_PUBLISH_WEBHOOK_URL for tiktok, instagram, x and twitch.
+func platformWebhookURLs() map[string]string {
+	out := map[string]string{}
+	for _, platform := range []string{"tiktok", "instagram", "x", "twitch"} {
+		if url := os.Getenv("N8N_" + strings.ToUpper(platform) + "_PUBLISH_WEBHOOK_URL"); url != "" {
+			out[platform] = url
+		}
+	}
+	return out
+}
@@ -0,0 +1,19 @@
+package config
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// DeriveSecret derives an independent secret for one purpose (label) from a master secret with
+// HKDF-SHA256. Secrets derived under different labels reveal nothing about each other or the
+// master, so a leaked one cannot be used to forge anything else.
+func DeriveSecret(master string, label string) string {
+	key, err := hkdf.Key(sha256.New, []byte(master), nil, "highlightiq "+label, 32)
+	if err != nil {
+		// Only possible for lengths HKDF-SHA256 cannot produce.
+		panic(err)
+	}
+	return hex.EncodeToString(key)
+}
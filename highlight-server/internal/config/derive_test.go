@@ -0,0 +1,18 @@
+package config
+
+import "testing"
+
+func TestDeriveSecret(t *testing.T) {
+	totp := DeriveSecret("jwt-secret", "totp encryption")
+	urls := DeriveSecret("jwt-secret", "public url signing")
+
+	if totp == urls || totp == "jwt-secret" || urls == "jwt-secret" {
+		t.Fatalf("expected independent secrets, got %q and %q", totp, urls)
+	}
+	if again := DeriveSecret("jwt-secret", "totp encryption"); again != totp {
+		t.Fatalf("expected the same secret for the same label, got %q and %q", totp, again)
+	}
+	if other := DeriveSecret("other-secret", "totp encryption"); other == totp {
+		t.Fatal("expected a different master secret to give a different secret")
+	}
+}
@@ -0,0 +1,109 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"highlightiq-server/internal/http/middleware"
+	"highlightiq-server/internal/http/response"
+	reqs "highlightiq-server/internal/requests/account"
+	svc "highlightiq-server/internal/services/account"
+	authsvc "highlightiq-server/internal/services/auth"
+)
+
+type AccountService interface {
+	Delete(ctx context.Context, userID int64, password string, confirmation string) error
+	Export(ctx context.Context, userID int64) (svc.Export, error)
+	WriteArchive(ctx context.Context, w io.Writer, e svc.Export, includeMedia bool) error
+}
+
+type Handler struct {
+	svc AccountService
+}
+
+func New(s AccountService) *Handler {
+	return &Handler{svc: s}
+}
+
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+// DELETE /me
+// Permanently deletes the account, its content and its files.
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	var req reqs.DeleteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), u.ID, req.Password, strings.TrimSpace(req.ConfirmationCode)); err != nil {
+		switch {
+		case errors.Is(err, svc.ErrInvalidPassword):
+			response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid password"})
+		case errors.Is(err, authsvc.ErrTooManyAttempts):
+			response.JSON(w, http.StatusTooManyRequests, messageResponse{Message: "too many attempts, try again later"})
+		case errors.Is(err, svc.ErrSharedWorkspaces):
+			response.JSON(w, http.StatusConflict, messageResponse{Message: "remove the other members of your workspaces first"})
+		default:
+			log.Printf("Delete account failed: %v", err)
+			response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to delete account"})
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /me/export?include_media=true
+// Streams a zip of the user's data; media files are only included when asked for.
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	includeMedia := false
+	if raw := r.URL.Query().Get("include_media"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid include_media"})
+			return
+		}
+		includeMedia = v
+	}
+
+	e, err := h.svc.Export(r.Context(), u.ID)
+	if err != nil {
+		log.Printf("Export account failed: %v", err)
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to export account"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"highlightiq-export-"+e.ExportedAt.Format("20060102")+".zip\"")
+	w.WriteHeader(http.StatusOK)
+
+	// The status is already sent; a failure here can only cut the download short.
+	if err := h.svc.WriteArchive(r.Context(), w, e, includeMedia); err != nil {
+		log.Printf("Export account %d interrupted: %v", u.ID, err)
+	}
+}
@@ -0,0 +1,107 @@
+package analytics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"highlightiq-server/internal/http/middleware"
+	"highlightiq-server/internal/http/response"
+	pubrepo "highlightiq-server/internal/repos/publications"
+	svc "highlightiq-server/internal/services/analytics"
+)
+
+type AnalyticsService interface {
+	Overview(ctx context.Context, userID int64, in svc.OverviewInput) (svc.Overview, error)
+}
+
+type Handler struct {
+	svc AnalyticsService
+}
+
+func New(s AnalyticsService) *Handler {
+	return &Handler{svc: s}
+}
+
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+// GET /analytics/overview?from=YYYY-MM-DD&to=YYYY-MM-DD&platform=&limit=&tz_offset=
+// tz_offset is the viewer's offset from UTC in minutes, for the weekday and hour comparisons.
+func (h *Handler) Overview(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	q := r.URL.Query()
+
+	from, err := parseDay(q.Get("from"))
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid from date"})
+		return
+	}
+	to, err := parseDay(q.Get("to"))
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid to date"})
+		return
+	}
+	start, end, err := svc.Range(from, to, time.Now())
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid range"})
+		return
+	}
+
+	in := svc.OverviewInput{From: start, To: end, Platform: q.Get("platform")}
+	if in.Platform != "" && !validPlatform(in.Platform) {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid platform"})
+		return
+	}
+	if raw := q.Get("limit"); raw != "" {
+		in.TopClips, err = strconv.Atoi(raw)
+		if err != nil || in.TopClips < 1 || in.TopClips > svc.MaxTopClips {
+			response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid limit"})
+			return
+		}
+	}
+	if raw := q.Get("tz_offset"); raw != "" {
+		in.UTCOffsetMinutes, err = strconv.Atoi(raw)
+		// UTC-12:00 to UTC+14:00
+		if err != nil || in.UTCOffsetMinutes < -12*60 || in.UTCOffsetMinutes > 14*60 {
+			response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid tz_offset"})
+			return
+		}
+	}
+
+	out, err := h.svc.Overview(r.Context(), u.ID, in)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to load analytics"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, out)
+}
+
+// parseDay parses a YYYY-MM-DD query value; empty means not given.
+func parseDay(raw string) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func validPlatform(platform string) bool {
+	for _, p := range pubrepo.Platforms {
+		if p == platform {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package apikeys
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"highlightiq-server/internal/http/middleware"
+	"highlightiq-server/internal/http/response"
+	apikeysrepo "highlightiq-server/internal/repos/apikeys"
+	reqs "highlightiq-server/internal/requests/apikeys"
+	svc "highlightiq-server/internal/services/apikeys"
+)
+
+type APIKeyService interface {
+	Create(ctx context.Context, userID int64, name string, scopes []string) (svc.Created, error)
+	List(ctx context.Context, userID int64) ([]apikeysrepo.APIKey, error)
+	Revoke(ctx context.Context, userID int64, id int64) error
+}
+
+type Handler struct {
+	svc APIKeyService
+}
+
+func New(s APIKeyService) *Handler {
+	return &Handler{svc: s}
+}
+
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+// POST /api-keys
+// The full key is only in this response; afterwards only its prefix is shown.
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	var req reqs.CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	out, err := h.svc.Create(r.Context(), u.ID, req.Name, req.Scopes)
+	if err != nil {
+		if errors.Is(err, svc.ErrInvalidScope) {
+			response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid scope"})
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to create api key"})
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, out)
+}
+
+// GET /api-keys
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	items, err := h.svc.List(r.Context(), u.ID)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to list api keys"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]any{"data": items})
+}
+
+// DELETE /api-keys/{id}
+func (h *Handler) Revoke(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid id"})
+		return
+	}
+
+	if err := h.svc.Revoke(r.Context(), u.ID, id); err != nil {
+		if errors.Is(err, svc.ErrNotFound) {
+			response.JSON(w, http.StatusNotFound, messageResponse{Message: "not found"})
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to revoke api key"})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
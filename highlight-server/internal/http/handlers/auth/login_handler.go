@@ -3,8 +3,11 @@ package auth
 import (
 	"encoding/json"
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
+	"highlightiq-server/internal/http/middleware"
 	resp "highlightiq-server/internal/http/response"
 	authreq "highlightiq-server/internal/requests/auth"
 	authsvc "highlightiq-server/internal/services/auth"
@@ -36,10 +39,15 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	out, err := h.svc.Login(r.Context(), authsvc.LoginInput{
-		Email:    req.Email,
-		Password: req.Password,
+		Email:     req.Email,
+		Password:  req.Password,
+		IP:        middleware.ClientIP(r),
+		UserAgent: r.UserAgent(),
 	})
 	if err != nil {
+		if writeThrottled(w, err) {
+			return
+		}
 		if errors.Is(err, authsvc.ErrInvalidCredentials) {
 			resp.JSON(w, http.StatusUnauthorized, map[string]any{
 				"message": "invalid credentials",
@@ -55,3 +63,17 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 
 	resp.JSON(w, http.StatusOK, out)
 }
+
+// writeThrottled answers 429 with Retry-After when err is a login throttle, reporting whether
+// it did.
+func writeThrottled(w http.ResponseWriter, err error) bool {
+	var te *authsvc.ThrottleError
+	if !errors.As(err, &te) {
+		return false
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(te.RetryAfter.Seconds()))))
+	resp.JSON(w, http.StatusTooManyRequests, map[string]any{
+		"message": "too many login attempts, try again later",
+	})
+	return true
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"highlightiq-server/internal/http/middleware"
+	resp "highlightiq-server/internal/http/response"
+	authreq "highlightiq-server/internal/requests/auth"
+	authsvc "highlightiq-server/internal/services/auth"
+)
+
+// POST /auth/logout
+// Revokes the access token used for the request, plus the session of refresh_token if sent.
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		resp.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+
+	var req authreq.LogoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		resp.JSON(w, http.StatusBadRequest, map[string]any{
+			"message": "invalid JSON payload",
+		})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		resp.JSON(w, http.StatusUnprocessableEntity, map[string]any{
+			"message": "validation error",
+			"errors":  err,
+		})
+		return
+	}
+
+	if err := h.svc.Logout(r.Context(), authsvc.LogoutInput{
+		UserID:        u.ID,
+		AccessJTI:     u.TokenID,
+		AccessExpires: u.TokenExpiresAt,
+		RefreshToken:  strings.TrimSpace(req.RefreshToken),
+	}); err != nil {
+		resp.JSON(w, http.StatusInternalServerError, map[string]any{"message": "internal server error"})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /auth/logout-all
+// Revokes every session of the user, on every device.
+func (h *Handler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		resp.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+
+	if err := h.svc.LogoutAll(r.Context(), authsvc.LogoutInput{
+		UserID:        u.ID,
+		AccessJTI:     u.TokenID,
+		AccessExpires: u.TokenExpiresAt,
+	}); err != nil {
+		resp.JSON(w, http.StatusInternalServerError, map[string]any{"message": "internal server error"})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"highlightiq-server/internal/http/middleware"
+	resp "highlightiq-server/internal/http/response"
+	authreq "highlightiq-server/internal/requests/auth"
+	authsvc "highlightiq-server/internal/services/auth"
+)
+
+// GET /auth/oauth/providers
+func (h *Handler) OAuthProviders(w http.ResponseWriter, r *http.Request) {
+	resp.JSON(w, http.StatusOK, map[string]any{"data": h.svc.Providers()})
+}
+
+// oauthStateCookie holds the state of the sign-in the browser started. The callback must come
+// from the same browser, so a code and state obtained by someone else cannot sign it in to
+// their account (login CSRF).
+const oauthStateCookie = "oauth_state"
+
+// POST /auth/oauth/{provider}/start
+// Returns the provider's sign-in page. It redirects back to the web app with code and state,
+// which the app posts to the callback below, with the cookie this sets.
+func (h *Handler) StartOAuth(w http.ResponseWriter, r *http.Request) {
+	out, err := h.svc.StartOAuth(r.Context(), chi.URLParam(r, "provider"))
+	if err != nil {
+		writeOAuthError(w, err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    out.State,
+		Path:     "/",
+		MaxAge:   int(out.ExpiresIn.Seconds()),
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	resp.JSON(w, http.StatusOK, out)
+}
+
+// POST /auth/oauth/{provider}/callback
+// Answers like /auth/login: a session, or a 2FA challenge.
+func (h *Handler) OAuthCallback(w http.ResponseWriter, r *http.Request) {
+	var req authreq.OAuthCallbackRequest
+	if !decodeValid(w, r, &req) {
+		return
+	}
+
+	state := strings.TrimSpace(req.State)
+	c, err := r.Cookie(oauthStateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(c.Value), []byte(state)) != 1 {
+		writeOAuthError(w, authsvc.ErrInvalidState)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: oauthStateCookie, Path: "/", MaxAge: -1, HttpOnly: true, Secure: isHTTPS(r), SameSite: http.SameSiteLaxMode})
+
+	out, err := h.svc.CompleteOAuth(r.Context(), authsvc.OAuthCallbackInput{
+		Provider:  chi.URLParam(r, "provider"),
+		Code:      strings.TrimSpace(req.Code),
+		State:     state,
+		IP:        middleware.ClientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		writeOAuthError(w, err)
+		return
+	}
+
+	resp.JSON(w, http.StatusOK, out)
+}
+
+// GET /me/identities
+func (h *Handler) Identities(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		resp.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+
+	out, err := h.svc.Identities(r.Context(), u.ID)
+	if err != nil {
+		resp.JSON(w, http.StatusInternalServerError, map[string]any{"message": "internal server error"})
+		return
+	}
+
+	resp.JSON(w, http.StatusOK, map[string]any{"data": out})
+}
+
+// POST /me/identities/{provider}/start
+func (h *Handler) StartLinkIdentity(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		resp.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+
+	out, err := h.svc.StartLinkIdentity(r.Context(), u.ID, chi.URLParam(r, "provider"))
+	if err != nil {
+		writeOAuthError(w, err)
+		return
+	}
+
+	resp.JSON(w, http.StatusOK, out)
+}
+
+// POST /me/identities/{provider}/callback
+func (h *Handler) LinkIdentity(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		resp.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+
+	var req authreq.OAuthCallbackRequest
+	if !decodeValid(w, r, &req) {
+		return
+	}
+
+	out, err := h.svc.LinkIdentity(r.Context(), authsvc.LinkIdentityInput{
+		UserID:   u.ID,
+		Provider: chi.URLParam(r, "provider"),
+		Code:     strings.TrimSpace(req.Code),
+		State:    strings.TrimSpace(req.State),
+	})
+	if err != nil {
+		writeOAuthError(w, err)
+		return
+	}
+
+	resp.JSON(w, http.StatusCreated, out)
+}
+
+// DELETE /me/identities/{provider}
+func (h *Handler) UnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		resp.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+
+	if err := h.svc.UnlinkIdentity(r.Context(), u.ID, chi.URLParam(r, "provider")); err != nil {
+		writeOAuthError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeOAuthError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, authsvc.ErrUnknownProvider):
+		resp.JSON(w, http.StatusNotFound, map[string]any{"message": "unknown provider"})
+	case errors.Is(err, authsvc.ErrNotLinked):
+		resp.JSON(w, http.StatusNotFound, map[string]any{"message": "provider not linked"})
+	case errors.Is(err, authsvc.ErrInvalidState):
+		resp.JSON(w, http.StatusBadRequest, map[string]any{"message": "invalid or expired state"})
+	case errors.Is(err, authsvc.ErrProviderFailed):
+		resp.JSON(w, http.StatusBadGateway, map[string]any{"message": "identity provider request failed"})
+	case errors.Is(err, authsvc.ErrEmailUnverified):
+		resp.JSON(w, http.StatusUnprocessableEntity, map[string]any{"message": "the provider did not share a verified email"})
+	case errors.Is(err, authsvc.ErrAccountExists):
+		resp.JSON(w, http.StatusConflict, map[string]any{"message": "an account with this email exists; sign in with your password and link the provider from your settings"})
+	case errors.Is(err, authsvc.ErrIdentityInUse):
+		resp.JSON(w, http.StatusConflict, map[string]any{"message": "this provider account is linked to another user"})
+	case errors.Is(err, authsvc.ErrAlreadyLinked):
+		resp.JSON(w, http.StatusConflict, map[string]any{"message": "another account at this provider is already linked"})
+	case errors.Is(err, authsvc.ErrEmailTaken):
+		resp.JSON(w, http.StatusConflict, map[string]any{"message": "email already registered"})
+	case errors.Is(err, authsvc.ErrLastLoginMethod):
+		resp.JSON(w, http.StatusConflict, map[string]any{"message": "set a password or link another provider first"})
+	default:
+		resp.JSON(w, http.StatusInternalServerError, map[string]any{"message": "internal server error"})
+	}
+}
+
+// isHTTPS reports whether the browser reached us over TLS, directly or through a proxy. A forged
+// header only makes the cookie stricter.
+func isHTTPS(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
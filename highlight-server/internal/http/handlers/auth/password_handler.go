@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"highlightiq-server/internal/http/middleware"
+	resp "highlightiq-server/internal/http/response"
+	authreq "highlightiq-server/internal/requests/auth"
+	authsvc "highlightiq-server/internal/services/auth"
+)
+
+// POST /auth/verify-email
+func (h *Handler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req authreq.VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		resp.JSON(w, http.StatusBadRequest, map[string]any{
+			"message": "invalid JSON payload",
+		})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		resp.JSON(w, http.StatusUnprocessableEntity, map[string]any{
+			"message": "validation error",
+			"errors":  err,
+		})
+		return
+	}
+
+	if err := h.svc.VerifyEmail(r.Context(), strings.TrimSpace(req.Token)); err != nil {
+		if errors.Is(err, authsvc.ErrInvalidToken) {
+			resp.JSON(w, http.StatusBadRequest, map[string]any{
+				"message": "invalid or expired token",
+			})
+			return
+		}
+
+		resp.JSON(w, http.StatusInternalServerError, map[string]any{
+			"message": "internal server error",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /auth/verify-email/resend
+func (h *Handler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		resp.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+
+	if err := h.svc.ResendVerification(r.Context(), u.ID); err != nil {
+		if errors.Is(err, authsvc.ErrAlreadyVerified) {
+			resp.JSON(w, http.StatusConflict, map[string]any{
+				"message": "email already verified",
+			})
+			return
+		}
+
+		resp.JSON(w, http.StatusInternalServerError, map[string]any{
+			"message": "internal server error",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// POST /auth/password/forgot
+// Always answers 202 so callers cannot tell which emails have an account.
+func (h *Handler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req authreq.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		resp.JSON(w, http.StatusBadRequest, map[string]any{
+			"message": "invalid JSON payload",
+		})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		resp.JSON(w, http.StatusUnprocessableEntity, map[string]any{
+			"message": "validation error",
+			"errors":  err,
+		})
+		return
+	}
+
+	if err := h.svc.ForgotPassword(r.Context(), strings.TrimSpace(req.Email)); err != nil {
+		resp.JSON(w, http.StatusInternalServerError, map[string]any{
+			"message": "internal server error",
+		})
+		return
+	}
+
+	resp.JSON(w, http.StatusAccepted, map[string]any{
+		"message": "if the email belongs to an account, a reset link is on its way",
+	})
+}
+
+// POST /auth/password/reset
+func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req authreq.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		resp.JSON(w, http.StatusBadRequest, map[string]any{
+			"message": "invalid JSON payload",
+		})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		resp.JSON(w, http.StatusUnprocessableEntity, map[string]any{
+			"message": "validation error",
+			"errors":  err,
+		})
+		return
+	}
+
+	if err := h.svc.ResetPassword(r.Context(), strings.TrimSpace(req.Token), req.Password); err != nil {
+		if errors.Is(err, authsvc.ErrInvalidToken) {
+			resp.JSON(w, http.StatusBadRequest, map[string]any{
+				"message": "invalid or expired token",
+			})
+			return
+		}
+
+		resp.JSON(w, http.StatusInternalServerError, map[string]any{
+			"message": "internal server error",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
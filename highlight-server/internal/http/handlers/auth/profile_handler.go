@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"highlightiq-server/internal/http/middleware"
+	resp "highlightiq-server/internal/http/response"
+	authreq "highlightiq-server/internal/requests/auth"
+	authsvc "highlightiq-server/internal/services/auth"
+)
+
+// GET /me
+func (h *Handler) Me(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		resp.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+
+	out, err := h.svc.Profile(r.Context(), u.ID)
+	if err != nil {
+		resp.JSON(w, http.StatusInternalServerError, map[string]any{"message": "internal server error"})
+		return
+	}
+
+	resp.JSON(w, http.StatusOK, out)
+}
+
+// PATCH /me
+// A new email must be confirmed again; until then the account is read-only.
+func (h *Handler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		resp.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+
+	var req authreq.UpdateProfileRequest
+	if !decodeValid(w, r, &req) {
+		return
+	}
+
+	in := authsvc.UpdateProfileInput{UserID: u.ID, CurrentPassword: req.CurrentPassword, Confirmation: req.ConfirmationCode}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		in.Name = &name
+	}
+	if req.Email != nil {
+		email := strings.TrimSpace(*req.Email)
+		in.Email = &email
+	}
+
+	out, err := h.svc.UpdateProfile(r.Context(), in)
+	if err != nil {
+		if writeThrottled(w, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, authsvc.ErrEmailTaken):
+			resp.JSON(w, http.StatusConflict, map[string]any{"message": "email already registered"})
+		case errors.Is(err, authsvc.ErrInvalidCredentials):
+			resp.JSON(w, http.StatusBadRequest, map[string]any{"message": "invalid password"})
+		case errors.Is(err, authsvc.ErrConfirmationRequired):
+			writeConfirmationRequired(w)
+		default:
+			resp.JSON(w, http.StatusInternalServerError, map[string]any{"message": "internal server error"})
+		}
+		return
+	}
+
+	resp.JSON(w, http.StatusOK, out)
+}
+
+// PUT /me/password
+// Signs out every session; the response carries a new one for the caller.
+func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		resp.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+
+	var req authreq.ChangePasswordRequest
+	if !decodeValid(w, r, &req) {
+		return
+	}
+
+	out, err := h.svc.ChangePassword(r.Context(), authsvc.ChangePasswordInput{
+		UserID:          u.ID,
+		CurrentPassword: req.CurrentPassword,
+		Confirmation:    req.ConfirmationCode,
+		NewPassword:     req.NewPassword,
+	})
+	if err != nil {
+		if writeThrottled(w, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, authsvc.ErrInvalidCredentials):
+			resp.JSON(w, http.StatusBadRequest, map[string]any{"message": "invalid password"})
+		case errors.Is(err, authsvc.ErrConfirmationRequired):
+			writeConfirmationRequired(w)
+		default:
+			resp.JSON(w, http.StatusInternalServerError, map[string]any{"message": "internal server error"})
+		}
+		return
+	}
+
+	resp.JSON(w, http.StatusOK, out)
+}
+
+// POST /me/confirmation
+// Mails a single-use confirmation_code to an account without a password (one created by signing
+// in with a provider). It stands in for the password when changing the email or password,
+// turning off 2FA and deleting the account.
+func (h *Handler) SendConfirmation(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		resp.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+
+	if err := h.svc.SendConfirmation(r.Context(), u.ID); err != nil {
+		if errors.Is(err, authsvc.ErrHasPassword) {
+			resp.JSON(w, http.StatusConflict, map[string]any{"message": "confirm with your password instead"})
+			return
+		}
+		resp.JSON(w, http.StatusInternalServerError, map[string]any{"message": "internal server error"})
+		return
+	}
+
+	resp.JSON(w, http.StatusAccepted, map[string]any{"message": "confirmation code sent"})
+}
+
+func writeConfirmationRequired(w http.ResponseWriter) {
+	resp.JSON(w, http.StatusBadRequest, map[string]any{"message": "this account has no password; confirm with a code from POST /me/confirmation"})
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	resp "highlightiq-server/internal/http/response"
+	authreq "highlightiq-server/internal/requests/auth"
+	authsvc "highlightiq-server/internal/services/auth"
+)
+
+// POST /auth/refresh
+func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req authreq.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		resp.JSON(w, http.StatusBadRequest, map[string]any{
+			"message": "invalid JSON payload",
+		})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		resp.JSON(w, http.StatusUnprocessableEntity, map[string]any{
+			"message": "validation error",
+			"errors":  err,
+		})
+		return
+	}
+
+	out, err := h.svc.Refresh(r.Context(), strings.TrimSpace(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, authsvc.ErrInvalidRefresh) {
+			resp.JSON(w, http.StatusUnauthorized, map[string]any{
+				"message": "invalid refresh token",
+			})
+			return
+		}
+
+		resp.JSON(w, http.StatusInternalServerError, map[string]any{
+			"message": "internal server error",
+		})
+		return
+	}
+
+	resp.JSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"highlightiq-server/internal/http/middleware"
+	resp "highlightiq-server/internal/http/response"
+	authreq "highlightiq-server/internal/requests/auth"
+	authsvc "highlightiq-server/internal/services/auth"
+)
+
+// POST /auth/login/verify
+// Second login step for accounts with 2FA: the challenge from /auth/login plus a code.
+func (h *Handler) VerifyLogin(w http.ResponseWriter, r *http.Request) {
+	var req authreq.LoginVerifyRequest
+	if !decodeValid(w, r, &req) {
+		return
+	}
+
+	out, err := h.svc.VerifyLogin(r.Context(), authsvc.VerifyLoginInput{
+		ChallengeToken: strings.TrimSpace(req.ChallengeToken),
+		Code:           strings.TrimSpace(req.Code),
+		IP:             middleware.ClientIP(r),
+		UserAgent:      r.UserAgent(),
+	})
+	if err != nil {
+		if writeThrottled(w, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, authsvc.ErrInvalidToken):
+			resp.JSON(w, http.StatusUnauthorized, map[string]any{"message": "invalid or expired challenge"})
+		case errors.Is(err, authsvc.ErrInvalidCode):
+			resp.JSON(w, http.StatusUnauthorized, map[string]any{"message": "invalid code"})
+		default:
+			resp.JSON(w, http.StatusInternalServerError, map[string]any{"message": "internal server error"})
+		}
+		return
+	}
+
+	resp.JSON(w, http.StatusOK, out)
+}
+
+// GET /auth/2fa
+func (h *Handler) TwoFactorStatus(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		resp.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+
+	out, err := h.svc.TwoFactorStatus(r.Context(), u.ID)
+	if err != nil {
+		resp.JSON(w, http.StatusInternalServerError, map[string]any{"message": "internal server error"})
+		return
+	}
+
+	resp.JSON(w, http.StatusOK, out)
+}
+
+// POST /auth/2fa/setup
+// Returns the secret and otpauth:// URI to show as a QR code; 2FA is off until confirmed.
+func (h *Handler) SetupTOTP(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		resp.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+
+	out, err := h.svc.SetupTOTP(r.Context(), u.ID)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	resp.JSON(w, http.StatusOK, out)
+}
+
+// POST /auth/2fa/confirm
+// The recovery codes are only in this response.
+func (h *Handler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		resp.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+
+	var req authreq.TwoFactorCodeRequest
+	if !decodeValid(w, r, &req) {
+		return
+	}
+
+	out, err := h.svc.ConfirmTOTP(r.Context(), u.ID, strings.TrimSpace(req.Code))
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	resp.JSON(w, http.StatusOK, out)
+}
+
+// POST /auth/2fa/disable
+func (h *Handler) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		resp.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+
+	var req authreq.DisableTwoFactorRequest
+	if !decodeValid(w, r, &req) {
+		return
+	}
+
+	if err := h.svc.DisableTOTP(r.Context(), u.ID, req.Password, strings.TrimSpace(req.ConfirmationCode), strings.TrimSpace(req.Code)); err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /auth/2fa/recovery-codes
+// Replaces every recovery code; the new ones are only in this response.
+func (h *Handler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		resp.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+
+	var req authreq.TwoFactorCodeRequest
+	if !decodeValid(w, r, &req) {
+		return
+	}
+
+	out, err := h.svc.RegenerateRecoveryCodes(r.Context(), u.ID, strings.TrimSpace(req.Code))
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	resp.JSON(w, http.StatusOK, out)
+}
+
+// decodeValid reads a JSON body into req and validates it, answering the client on failure.
+func decodeValid(w http.ResponseWriter, r *http.Request, req interface{ Validate() error }) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		resp.JSON(w, http.StatusBadRequest, map[string]any{
+			"message": "invalid JSON payload",
+		})
+		return false
+	}
+
+	if err := req.Validate(); err != nil {
+		resp.JSON(w, http.StatusUnprocessableEntity, map[string]any{
+			"message": "validation error",
+			"errors":  err,
+		})
+		return false
+	}
+	return true
+}
+
+func writeTwoFactorError(w http.ResponseWriter, err error) {
+	if writeThrottled(w, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, authsvc.ErrTwoFactorEnabled):
+		resp.JSON(w, http.StatusConflict, map[string]any{"message": "two-factor authentication is already enabled"})
+	case errors.Is(err, authsvc.ErrTwoFactorNotEnabled):
+		resp.JSON(w, http.StatusConflict, map[string]any{"message": "two-factor authentication is not set up"})
+	case errors.Is(err, authsvc.ErrInvalidCode):
+		resp.JSON(w, http.StatusBadRequest, map[string]any{"message": "invalid code"})
+	case errors.Is(err, authsvc.ErrInvalidCredentials):
+		resp.JSON(w, http.StatusBadRequest, map[string]any{"message": "invalid password"})
+	case errors.Is(err, authsvc.ErrConfirmationRequired):
+		writeConfirmationRequired(w)
+	default:
+		resp.JSON(w, http.StatusInternalServerError, map[string]any{"message": "internal server error"})
+	}
+}
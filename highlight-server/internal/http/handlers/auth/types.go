@@ -3,12 +3,37 @@ package auth
 import (
 	"context"
 
+	"highlightiq-server/internal/repos/identities"
 	authsvc "highlightiq-server/internal/services/auth"
 )
 
 type AuthService interface {
 	Register(ctx context.Context, in authsvc.RegisterInput) (authsvc.RegisterOutput, error)
-	Login(ctx context.Context, in authsvc.LoginInput) (authsvc.RegisterOutput, error)
+	Login(ctx context.Context, in authsvc.LoginInput) (authsvc.LoginOutput, error)
+	VerifyLogin(ctx context.Context, in authsvc.VerifyLoginInput) (authsvc.RegisterOutput, error)
+	Refresh(ctx context.Context, refreshToken string) (authsvc.RegisterOutput, error)
+	Logout(ctx context.Context, in authsvc.LogoutInput) error
+	LogoutAll(ctx context.Context, in authsvc.LogoutInput) error
+	VerifyEmail(ctx context.Context, token string) error
+	ResendVerification(ctx context.Context, userID int64) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, password string) error
+	Profile(ctx context.Context, userID int64) (authsvc.UserDTO, error)
+	UpdateProfile(ctx context.Context, in authsvc.UpdateProfileInput) (authsvc.UserDTO, error)
+	ChangePassword(ctx context.Context, in authsvc.ChangePasswordInput) (authsvc.RegisterOutput, error)
+	SendConfirmation(ctx context.Context, userID int64) error
+	TwoFactorStatus(ctx context.Context, userID int64) (authsvc.TwoFactorStatus, error)
+	SetupTOTP(ctx context.Context, userID int64) (authsvc.TOTPSetup, error)
+	ConfirmTOTP(ctx context.Context, userID int64, code string) (authsvc.RecoveryCodes, error)
+	DisableTOTP(ctx context.Context, userID int64, password string, confirmation string, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) (authsvc.RecoveryCodes, error)
+	Providers() []string
+	StartOAuth(ctx context.Context, provider string) (authsvc.OAuthStart, error)
+	CompleteOAuth(ctx context.Context, in authsvc.OAuthCallbackInput) (authsvc.LoginOutput, error)
+	StartLinkIdentity(ctx context.Context, userID int64, provider string) (authsvc.OAuthStart, error)
+	LinkIdentity(ctx context.Context, in authsvc.LinkIdentityInput) (identities.Identity, error)
+	Identities(ctx context.Context, userID int64) ([]identities.Identity, error)
+	UnlinkIdentity(ctx context.Context, userID int64, provider string) error
 }
 
 type Handler struct {
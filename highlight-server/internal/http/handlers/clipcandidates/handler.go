@@ -2,11 +2,15 @@ package clipcandidates
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/go-chi/chi/v5"
 	"highlightiq-server/internal/http/middleware"
 	"highlightiq-server/internal/http/response"
+	"highlightiq-server/internal/lifecycle"
 	reqs "highlightiq-server/internal/requests/clipcandidates"
 	svc "highlightiq-server/internal/services/clipcandidates"
+	usagesvc "highlightiq-server/internal/services/usage"
+	workspacessvc "highlightiq-server/internal/services/workspaces"
 	"io"
 	"log"
 	"net/http"
@@ -61,6 +65,18 @@ func (h *Handler) Detect(w http.ResponseWriter, r *http.Request) {
 			response.JSON(w, http.StatusNotFound, map[string]string{"message": "recording not found"})
 			return
 		}
+		if errors.Is(err, workspacessvc.ErrForbidden) {
+			response.JSON(w, http.StatusForbidden, map[string]string{"message": "forbidden"})
+			return
+		}
+		if errors.Is(err, usagesvc.ErrQuotaExceeded) {
+			response.JSON(w, http.StatusPaymentRequired, map[string]string{"message": "monthly detection quota exceeded"})
+			return
+		}
+		if errors.Is(err, lifecycle.ErrIllegalTransition) {
+			response.JSON(w, http.StatusConflict, map[string]string{"message": err.Error()})
+			return
+		}
 		log.Printf("DetectAndStore failed: %v", err)
 		response.JSON(w, http.StatusInternalServerError, map[string]string{"message": "failed to detect candidates"})
 		return
@@ -100,6 +116,12 @@ func (h *Handler) ListByRecording(w http.ResponseWriter, r *http.Request) {
 
 // PATCH /clip-candidates/{id}
 func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, map[string]string{"message": "unauthorized"})
+		return
+	}
+
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
@@ -117,7 +139,10 @@ func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.svc.UpdateStatus(r.Context(), id, req.Status); err != nil {
+	if err := h.svc.UpdateStatus(r.Context(), u.ID, id, req.Status); err != nil {
+		if h.writeAccessError(w, err) {
+			return
+		}
 		response.JSON(w, http.StatusInternalServerError, map[string]string{"message": "failed to update status"})
 		return
 	}
@@ -127,6 +152,12 @@ func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 
 // DELETE /clip-candidates/{id}
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, map[string]string{"message": "unauthorized"})
+		return
+	}
+
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
@@ -134,10 +165,29 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.svc.Delete(r.Context(), id); err != nil {
+	if err := h.svc.Delete(r.Context(), u.ID, id); err != nil {
+		if h.writeAccessError(w, err) {
+			return
+		}
 		response.JSON(w, http.StatusInternalServerError, map[string]string{"message": "failed to delete candidate"})
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// writeAccessError answers not-found, forbidden and illegal status change errors, reporting
+// whether it did.
+func (h *Handler) writeAccessError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, svc.ErrCandidateNotFound):
+		response.JSON(w, http.StatusNotFound, map[string]string{"message": "candidate not found"})
+	case errors.Is(err, workspacessvc.ErrForbidden):
+		response.JSON(w, http.StatusForbidden, map[string]string{"message": "forbidden"})
+	case errors.Is(err, lifecycle.ErrIllegalTransition):
+		response.JSON(w, http.StatusConflict, map[string]string{"message": err.Error()})
+	default:
+		return false
+	}
+	return true
+}
@@ -1,27 +1,46 @@
 package clips
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"highlightiq-server/internal/http/middleware"
 	"highlightiq-server/internal/http/response"
+	"highlightiq-server/internal/lifecycle"
+	clipsrepo "highlightiq-server/internal/repos/clips"
 	reqs "highlightiq-server/internal/requests/clips"
 	svc "highlightiq-server/internal/services/clips"
+	usagesvc "highlightiq-server/internal/services/usage"
+	workspacessvc "highlightiq-server/internal/services/workspaces"
+	"highlightiq-server/internal/storage"
 	"log"
 )
 
+type ClipsService interface {
+	Create(ctx context.Context, userID int64, in svc.CreateInput) (clipsrepo.Clip, error)
+	Get(ctx context.Context, userID int64, id int64) (clipsrepo.Clip, error)
+	List(ctx context.Context, userID int64, workspaceID int64, recordingUUID *string, tag *string) ([]clipsrepo.Clip, error)
+	Update(ctx context.Context, userID int64, id int64, in svc.UpdateInput) (clipsrepo.Clip, error)
+	Delete(ctx context.Context, userID int64, id int64) error
+	Restore(ctx context.Context, userID int64, id int64) (clipsrepo.Clip, error)
+	GetExport(ctx context.Context, userID int64, id int64) (string, string, error)
+	Export(ctx context.Context, userID int64, id int64) (clipsrepo.Clip, error)
+	GetPublicExport(ctx context.Context, id int64, exp string, sig string) (string, string, error)
+}
+
 type Handler struct {
-	svc *svc.Service
+	svc   ClipsService
+	store storage.BlobStore
 }
 
-func New(s *svc.Service) *Handler {
-	return &Handler{svc: s}
+func New(s ClipsService, store storage.BlobStore) *Handler {
+	return &Handler{svc: s, store: store}
 }
 
 type messageResponse struct {
@@ -67,6 +86,10 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		EndMS:         req.EndMS,
 	})
 	if err != nil {
+		if errors.Is(err, workspacessvc.ErrForbidden) {
+			response.JSON(w, http.StatusForbidden, messageResponse{Message: "forbidden"})
+			return
+		}
 		if err == svc.ErrNotFound {
 			response.JSON(w, http.StatusNotFound, messageResponse{Message: "recording not found"})
 			return
@@ -83,7 +106,7 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	response.JSON(w, http.StatusCreated, clip)
 }
 
-// GET /clips?recording_uuid=...
+// GET /clips?recording_uuid=...&tag=...&workspace_id=...
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	u, ok := middleware.GetAuthUser(r.Context())
 	if !ok {
@@ -97,7 +120,23 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		recPtr = &recUUID
 	}
 
-	items, err := h.svc.List(r.Context(), u.ID, recPtr)
+	tag := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("tag")))
+	var tagPtr *string
+	if tag != "" {
+		tagPtr = &tag
+	}
+
+	var workspaceID int64
+	if raw := r.URL.Query().Get("workspace_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid workspace_id"})
+			return
+		}
+		workspaceID = id
+	}
+
+	items, err := h.svc.List(r.Context(), u.ID, workspaceID, recPtr, tagPtr)
 	if err != nil {
 		if err == svc.ErrNotFound {
 			response.JSON(w, http.StatusNotFound, messageResponse{Message: "recording not found"})
@@ -174,6 +213,10 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		Status:  req.Status,
 	})
 	if err != nil {
+		if errors.Is(err, workspacessvc.ErrForbidden) {
+			response.JSON(w, http.StatusForbidden, messageResponse{Message: "forbidden"})
+			return
+		}
 		if err == svc.ErrNotFound {
 			response.JSON(w, http.StatusNotFound, messageResponse{Message: "not found"})
 			return
@@ -182,6 +225,10 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 			response.JSON(w, http.StatusBadRequest, messageResponse{Message: "bad input"})
 			return
 		}
+		if errors.Is(err, lifecycle.ErrIllegalTransition) {
+			response.JSON(w, http.StatusConflict, messageResponse{Message: err.Error()})
+			return
+		}
 		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to update clip"})
 		return
 	}
@@ -205,6 +252,10 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.svc.Delete(r.Context(), u.ID, id); err != nil {
+		if errors.Is(err, workspacessvc.ErrForbidden) {
+			response.JSON(w, http.StatusForbidden, messageResponse{Message: "forbidden"})
+			return
+		}
 		if err == svc.ErrNotFound {
 			response.JSON(w, http.StatusNotFound, messageResponse{Message: "not found"})
 			return
@@ -216,6 +267,46 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// POST /clips/{id}/restore
+func (h *Handler) Restore(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid id"})
+		return
+	}
+
+	clip, err := h.svc.Restore(r.Context(), u.ID, id)
+	if err != nil {
+		if errors.Is(err, workspacessvc.ErrForbidden) {
+			response.JSON(w, http.StatusForbidden, messageResponse{Message: "forbidden"})
+			return
+		}
+		if errors.Is(err, svc.ErrNotFound) {
+			response.JSON(w, http.StatusNotFound, messageResponse{Message: "not found"})
+			return
+		}
+		if errors.Is(err, svc.ErrRecordingDeleted) {
+			response.JSON(w, http.StatusConflict, messageResponse{Message: "recording is in the trash; restore it first"})
+			return
+		}
+		if errors.Is(err, usagesvc.ErrQuotaExceeded) {
+			response.JSON(w, http.StatusPaymentRequired, messageResponse{Message: "export storage quota exceeded"})
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to restore clip"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, clip)
+}
+
 // POST /clips/{id}/export
 func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
 	u, ok := middleware.GetAuthUser(r.Context())
@@ -233,10 +324,22 @@ func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
 
 	clip, err := h.svc.Export(r.Context(), u.ID, id)
 	if err != nil {
+		if errors.Is(err, workspacessvc.ErrForbidden) {
+			response.JSON(w, http.StatusForbidden, messageResponse{Message: "forbidden"})
+			return
+		}
 		if err == svc.ErrNotFound {
 			response.JSON(w, http.StatusNotFound, messageResponse{Message: "not found"})
 			return
 		}
+		if errors.Is(err, usagesvc.ErrQuotaExceeded) {
+			response.JSON(w, http.StatusPaymentRequired, messageResponse{Message: "export storage quota exceeded"})
+			return
+		}
+		if errors.Is(err, lifecycle.ErrIllegalTransition) {
+			response.JSON(w, http.StatusConflict, messageResponse{Message: err.Error()})
+			return
+		}
 		log.Printf("Create clip failed: %v", err)
 		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to export clip"})
 		return
@@ -274,27 +377,51 @@ func (h *Handler) Download(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	f, err := os.Open(path)
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
+	if name == "" {
+		name = filepath.Base(path)
+	}
+
+	if err := storage.Serve(w, r, h.store, path, name); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
 			response.JSON(w, http.StatusNotFound, messageResponse{Message: "file not found"})
 			return
 		}
 		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to open file"})
 		return
 	}
-	defer f.Close()
+}
 
-	info, err := f.Stat()
+// GET /public/clips/{id}?exp=&sig=
+// Unauthenticated download for automation (n8n). Access is granted by the signed query string.
+func (h *Handler) PublicDownload(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to stat file"})
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid id"})
 		return
 	}
 
-	if name == "" {
-		name = filepath.Base(path)
+	q := r.URL.Query()
+	key, name, err := h.svc.GetPublicExport(r.Context(), id, q.Get("exp"), q.Get("sig"))
+	if err != nil {
+		switch {
+		case errors.Is(err, svc.ErrLinkInvalid):
+			response.JSON(w, http.StatusForbidden, messageResponse{Message: "invalid signature"})
+		case errors.Is(err, svc.ErrLinkExpired):
+			response.JSON(w, http.StatusForbidden, messageResponse{Message: "link expired"})
+		case errors.Is(err, svc.ErrNotFound):
+			response.JSON(w, http.StatusNotFound, messageResponse{Message: "not found"})
+		default:
+			response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to download clip"})
+		}
+		return
 	}
 
-	w.Header().Set("Content-Disposition", "attachment; filename=\""+name+"\"")
-	http.ServeContent(w, r, name, info.ModTime(), f)
+	if err := storage.Stream(w, r, h.store, key, name); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			response.JSON(w, http.StatusNotFound, messageResponse{Message: "file not found"})
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to open file"})
+		return
+	}
 }
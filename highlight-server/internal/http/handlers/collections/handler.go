@@ -0,0 +1,396 @@
+package collections
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"highlightiq-server/internal/http/middleware"
+	"highlightiq-server/internal/http/response"
+	collectionsrepo "highlightiq-server/internal/repos/collections"
+	reqs "highlightiq-server/internal/requests/collections"
+	svc "highlightiq-server/internal/services/collections"
+	"highlightiq-server/internal/storage"
+)
+
+type CollectionsService interface {
+	Create(ctx context.Context, userID int64, in svc.CreateInput) (collectionsrepo.Collection, error)
+	List(ctx context.Context, userID int64) ([]collectionsrepo.Collection, error)
+	Get(ctx context.Context, userID int64, id int64) (collectionsrepo.Collection, error)
+	Update(ctx context.Context, userID int64, id int64, in svc.UpdateInput) (collectionsrepo.Collection, error)
+	Delete(ctx context.Context, userID int64, id int64) error
+	AddClip(ctx context.Context, userID int64, id int64, clipID int64) (collectionsrepo.Collection, error)
+	RemoveClip(ctx context.Context, userID int64, id int64, clipID int64) error
+	Reorder(ctx context.Context, userID int64, id int64, clipIDs []int64) (collectionsrepo.Collection, error)
+	Export(ctx context.Context, userID int64, id int64) (collectionsrepo.Collection, error)
+	GetExport(ctx context.Context, userID int64, id int64) (string, string, error)
+	Publish(ctx context.Context, userID int64, id int64) (collectionsrepo.Collection, error)
+	SetPlaylistInternal(ctx context.Context, id int64, playlistID string) (collectionsrepo.Collection, error)
+}
+
+type Handler struct {
+	svc   CollectionsService
+	store storage.BlobStore
+}
+
+func New(s CollectionsService, store storage.BlobStore) *Handler {
+	return &Handler{svc: s, store: store}
+}
+
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+// POST /collections
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	var req reqs.CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	col, err := h.svc.Create(r.Context(), u.ID, svc.CreateInput{
+		Title:       req.Title,
+		Description: req.Description,
+	})
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to create collection"})
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, col)
+}
+
+// GET /collections
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	items, err := h.svc.List(r.Context(), u.ID)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to list collections"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]any{"data": items})
+}
+
+// GET /collections/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid id"})
+		return
+	}
+
+	col, err := h.svc.Get(r.Context(), u.ID, id)
+	if err != nil {
+		h.writeError(w, err, "failed to get collection")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, col)
+}
+
+// PATCH /collections/{id}
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid id"})
+		return
+	}
+
+	var req reqs.UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	col, err := h.svc.Update(r.Context(), u.ID, id, svc.UpdateInput{
+		Title:       req.Title,
+		Description: req.Description,
+	})
+	if err != nil {
+		h.writeError(w, err, "failed to update collection")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, col)
+}
+
+// DELETE /collections/{id}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid id"})
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), u.ID, id); err != nil {
+		h.writeError(w, err, "failed to delete collection")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /collections/{id}/clips
+func (h *Handler) AddClip(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid id"})
+		return
+	}
+
+	var req reqs.AddClipRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	col, err := h.svc.AddClip(r.Context(), u.ID, id, req.ClipID)
+	if err != nil {
+		h.writeError(w, err, "failed to add clip")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, col)
+}
+
+// PUT /collections/{id}/clips
+func (h *Handler) Reorder(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid id"})
+		return
+	}
+
+	var req reqs.ReorderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	col, err := h.svc.Reorder(r.Context(), u.ID, id, req.ClipIDs)
+	if err != nil {
+		h.writeError(w, err, "failed to reorder collection")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, col)
+}
+
+// DELETE /collections/{id}/clips/{clip_id}
+func (h *Handler) RemoveClip(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid id"})
+		return
+	}
+	clipID, err := parseIDParam(r, "clip_id")
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid clip id"})
+		return
+	}
+
+	if err := h.svc.RemoveClip(r.Context(), u.ID, id, clipID); err != nil {
+		h.writeError(w, err, "failed to remove clip")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /collections/{id}/export
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid id"})
+		return
+	}
+
+	col, err := h.svc.Export(r.Context(), u.ID, id)
+	if err != nil {
+		if !errors.Is(err, svc.ErrNotFound) && !errors.Is(err, svc.ErrNotReady) {
+			log.Printf("Export collection failed: %v", err)
+		}
+		h.writeError(w, err, "failed to export collection")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, col)
+}
+
+// GET /collections/{id}/download
+func (h *Handler) Download(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid id"})
+		return
+	}
+
+	path, name, err := h.svc.GetExport(r.Context(), u.ID, id)
+	if err != nil {
+		h.writeError(w, err, "failed to download collection")
+		return
+	}
+
+	if name == "" {
+		name = filepath.Base(path)
+	}
+
+	if err := storage.Serve(w, r, h.store, path, name); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			response.JSON(w, http.StatusNotFound, messageResponse{Message: "file not found"})
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to open file"})
+		return
+	}
+}
+
+// POST /collections/{id}/publish
+func (h *Handler) Publish(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid id"})
+		return
+	}
+
+	col, err := h.svc.Publish(r.Context(), u.ID, id)
+	if err != nil {
+		if !errors.Is(err, svc.ErrNotFound) && !errors.Is(err, svc.ErrNotReady) {
+			log.Printf("Publish collection failed: %v", err)
+		}
+		h.writeError(w, err, "failed to publish collection")
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, col)
+}
+
+// POST /internal/collections/playlist
+func (h *Handler) InternalSetPlaylist(w http.ResponseWriter, r *http.Request) {
+	var req reqs.InternalPlaylistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	col, err := h.svc.SetPlaylistInternal(r.Context(), req.CollectionID, req.YoutubePlaylistID)
+	if err != nil {
+		h.writeError(w, err, "failed to update collection")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, col)
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, svc.ErrNotFound):
+		response.JSON(w, http.StatusNotFound, messageResponse{Message: "not found"})
+	case errors.Is(err, svc.ErrNotReady):
+		response.JSON(w, http.StatusConflict, messageResponse{Message: "collection not ready"})
+	case errors.Is(err, svc.ErrDuplicate):
+		response.JSON(w, http.StatusConflict, messageResponse{Message: "clip already in collection"})
+	case errors.Is(err, svc.ErrBadInput):
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "bad input"})
+	default:
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: fallback})
+	}
+}
+
+func parseIDParam(r *http.Request, param string) (int64, error) {
+	idStr := chi.URLParam(r, param)
+	return strconv.ParseInt(idStr, 10, 64)
+}
@@ -0,0 +1,77 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"highlightiq-server/internal/http/response"
+	outboxrepo "highlightiq-server/internal/repos/outbox"
+	svc "highlightiq-server/internal/services/outbox"
+)
+
+type OutboxService interface {
+	List(ctx context.Context, status string) ([]outboxrepo.Event, error)
+	Replay(ctx context.Context, id int64) (outboxrepo.Event, error)
+}
+
+type Handler struct {
+	svc OutboxService
+}
+
+func New(s OutboxService) *Handler {
+	return &Handler{svc: s}
+}
+
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+// GET /internal/outbox?status=dead
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", outboxrepo.StatusPending, outboxrepo.StatusDelivered, outboxrepo.StatusDead:
+	default:
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "status must be pending, delivered or dead"})
+		return
+	}
+
+	events, err := h.svc.List(r.Context(), status)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to list outbox events"})
+		return
+	}
+	if events == nil {
+		events = []outboxrepo.Event{}
+	}
+
+	response.JSON(w, http.StatusOK, map[string]any{"data": events})
+}
+
+// POST /internal/outbox/{id}/replay
+func (h *Handler) Replay(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid event id"})
+		return
+	}
+
+	e, err := h.svc.Replay(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, svc.ErrNotFound):
+			response.JSON(w, http.StatusNotFound, messageResponse{Message: "event not found"})
+		case errors.Is(err, svc.ErrNotDead):
+			response.JSON(w, http.StatusConflict, messageResponse{Message: "only dead events can be replayed"})
+		default:
+			response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to replay event"})
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, e)
+}
@@ -0,0 +1,637 @@
+package publications
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"highlightiq-server/internal/http/middleware"
+	"highlightiq-server/internal/http/response"
+	"highlightiq-server/internal/lifecycle"
+	pubrepo "highlightiq-server/internal/repos/publications"
+	reqs "highlightiq-server/internal/requests/publications"
+	svc "highlightiq-server/internal/services/publications"
+	workspacessvc "highlightiq-server/internal/services/workspaces"
+)
+
+type Handler struct {
+	svc *svc.Service
+}
+
+func New(s *svc.Service) *Handler {
+	return &Handler{svc: s}
+}
+
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+// view renders a publication in the response shape of the route serving it.
+type view func(pubrepo.Publication) any
+
+func asPublication(p pubrepo.Publication) any { return p }
+
+// POST /clips/{id}/publications
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	var req reqs.CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	h.create(w, r, req, asPublication)
+}
+
+func (h *Handler) create(w http.ResponseWriter, r *http.Request, req reqs.CreateRequest, render view) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	clipID, err := parseIDParam(r, "id")
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid clip id"})
+		return
+	}
+
+	status := ""
+	if req.Status != nil {
+		status = *req.Status
+	}
+
+	created, err := h.svc.Create(r.Context(), u.ID, clipID, svc.CreateInput{
+		Platform:     req.Platform,
+		ExternalID:   req.ExternalID,
+		URL:          req.URL,
+		Status:       status,
+		Metadata:     normalizeJSON(req.Metadata),
+		PublishedAt:  req.PublishedAt,
+		LastSyncedAt: req.LastSyncedAt,
+	})
+	if err != nil {
+		if err == svc.ErrNotFound {
+			response.JSON(w, http.StatusNotFound, messageResponse{Message: "clip not found"})
+			return
+		}
+		if errors.Is(err, workspacessvc.ErrForbidden) {
+			response.JSON(w, http.StatusForbidden, messageResponse{Message: "forbidden"})
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to create publication"})
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, render(created))
+}
+
+// POST /clips/{id}/publish
+func (h *Handler) Publish(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	clipID, err := parseIDParam(r, "id")
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid clip id"})
+		return
+	}
+
+	var req reqs.PublishRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	platform := ""
+	if req.Platform != nil {
+		platform = *req.Platform
+	}
+
+	queued, err := h.svc.Publish(r.Context(), u.ID, clipID, svc.PublishInput{
+		Platform:    platform,
+		Title:       req.Title,
+		Description: req.Description,
+		Tags:        req.Tags,
+		Privacy:     req.Privacy,
+		ChannelID:   req.ChannelID,
+		Metadata:    normalizeJSON(req.Metadata),
+		PublishAt:   req.PublishAt,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, svc.ErrNotFound):
+			response.JSON(w, http.StatusNotFound, messageResponse{Message: "clip not found"})
+		case errors.Is(err, workspacessvc.ErrForbidden):
+			response.JSON(w, http.StatusForbidden, messageResponse{Message: "forbidden"})
+		case errors.Is(err, svc.ErrNotExported):
+			response.JSON(w, http.StatusConflict, messageResponse{Message: "clip has not been exported"})
+		case errors.Is(err, svc.ErrNotConfigured):
+			response.JSON(w, http.StatusServiceUnavailable, messageResponse{Message: "publishing to " + platformOrDefault(platform) + " is not configured"})
+		default:
+			response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to queue publish"})
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, queued)
+}
+
+// GET /clips/{id}/publications
+func (h *Handler) ListByClip(w http.ResponseWriter, r *http.Request) {
+	h.listByClip(w, r, asPublication)
+}
+
+func (h *Handler) listByClip(w http.ResponseWriter, r *http.Request, render view) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	clipID, err := parseIDParam(r, "id")
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid clip id"})
+		return
+	}
+
+	items, err := h.svc.ListByClip(r.Context(), u.ID, clipID)
+	if err != nil {
+		if err == svc.ErrNotFound {
+			response.JSON(w, http.StatusNotFound, messageResponse{Message: "clip not found"})
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to list publications"})
+		return
+	}
+
+	out := make([]any, 0, len(items))
+	for _, it := range items {
+		out = append(out, render(it))
+	}
+
+	response.JSON(w, http.StatusOK, map[string]any{"data": out})
+}
+
+// PATCH /publications/{id}
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	var req reqs.UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	h.update(w, r, req, asPublication)
+}
+
+func (h *Handler) update(w http.ResponseWriter, r *http.Request, req reqs.UpdateRequest, render view) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid id"})
+		return
+	}
+
+	updated, err := h.svc.Update(r.Context(), u.ID, id, svc.UpdateInput{
+		URL:          req.URL,
+		Status:       req.Status,
+		PublishedAt:  req.PublishedAt,
+		LastSyncedAt: req.LastSyncedAt,
+	})
+	if err != nil {
+		if err == svc.ErrNotFound {
+			response.JSON(w, http.StatusNotFound, messageResponse{Message: "not found"})
+			return
+		}
+		if errors.Is(err, workspacessvc.ErrForbidden) {
+			response.JSON(w, http.StatusForbidden, messageResponse{Message: "forbidden"})
+			return
+		}
+		if errors.Is(err, lifecycle.ErrIllegalTransition) {
+			response.JSON(w, http.StatusConflict, messageResponse{Message: err.Error()})
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to update publication"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, render(updated))
+}
+
+// POST /internal/publications
+func (h *Handler) InternalCreate(w http.ResponseWriter, r *http.Request) {
+	var req reqs.InternalCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	h.internalCreate(w, r, req, asPublication)
+}
+
+func (h *Handler) internalCreate(w http.ResponseWriter, r *http.Request, req reqs.InternalCreateRequest, render view) {
+	status := ""
+	if req.Status != nil {
+		status = *req.Status
+	}
+
+	created, err := h.svc.CreateInternal(r.Context(), req.ClipID, svc.CreateInput{
+		PublishID:    req.PublishID,
+		Platform:     req.Platform,
+		ExternalID:   req.ExternalID,
+		URL:          req.URL,
+		Status:       status,
+		Metadata:     normalizeJSON(req.Metadata),
+		PublishedAt:  req.PublishedAt,
+		LastSyncedAt: req.LastSyncedAt,
+		Views:        req.Views,
+		Likes:        req.Likes,
+		Comments:     req.Comments,
+		Shares:       req.Shares,
+		Analytics:    normalizeJSON(req.Analytics),
+	})
+	if err != nil {
+		if err == svc.ErrNotFound {
+			response.JSON(w, http.StatusNotFound, messageResponse{Message: "clip not found"})
+			return
+		}
+		if errors.Is(err, workspacessvc.ErrForbidden) {
+			response.JSON(w, http.StatusForbidden, messageResponse{Message: "forbidden"})
+			return
+		}
+		if errors.Is(err, lifecycle.ErrIllegalTransition) {
+			response.JSON(w, http.StatusConflict, messageResponse{Message: err.Error()})
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to create publication"})
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, render(created))
+}
+
+// GET /internal/publications?platform=&status=&last_synced_before=&due=&limit=&cursor=
+// Lists what the metrics sync should look at. status is a comma-separated list;
+// last_synced_before is RFC 3339; due=true keeps only posts whose sync interval has elapsed
+// (hourly for new posts, daily for old ones). Without a limit everything is returned at once;
+// with one, pass next_cursor back as cursor for the following page.
+func (h *Handler) InternalList(w http.ResponseWriter, r *http.Request) {
+	platform := r.URL.Query().Get("platform")
+	if !validPlatform(platform) {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid platform"})
+		return
+	}
+
+	h.internalList(w, r, platform, func(it svc.SyncItem) any {
+		return struct {
+			ExternalID string `json:"external_id"`
+			syncEntry
+		}{it.ExternalID, newSyncEntry(it)}
+	})
+}
+
+// syncEntry is the part of a sync listing item shared by every route.
+type syncEntry struct {
+	ID                  int64      `json:"id"`
+	Status              string     `json:"status"`
+	PublishedAt         *time.Time `json:"published_at,omitempty"`
+	LastSyncedAt        *time.Time `json:"last_synced_at,omitempty"`
+	Priority            string     `json:"priority"`
+	SyncIntervalSeconds int        `json:"sync_interval_seconds"`
+	Due                 bool       `json:"due"`
+}
+
+func newSyncEntry(it svc.SyncItem) syncEntry {
+	return syncEntry{
+		ID:                  it.ID,
+		Status:              it.Status,
+		PublishedAt:         it.PublishedAt,
+		LastSyncedAt:        it.LastSyncedAt,
+		Priority:            it.Priority,
+		SyncIntervalSeconds: int(it.Interval / time.Second),
+		Due:                 it.Due,
+	}
+}
+
+// maxSyncPage bounds the limit of a sync listing page.
+const maxSyncPage = 1000
+
+var publicationStatuses = []string{"queued", "uploaded", "failed", "deleted"}
+
+func (h *Handler) internalList(w http.ResponseWriter, r *http.Request, platform string, render func(svc.SyncItem) any) {
+	q := r.URL.Query()
+	in := svc.SyncQuery{Platform: platform}
+
+	if raw := q.Get("status"); raw != "" {
+		for _, st := range strings.Split(raw, ",") {
+			st = strings.TrimSpace(st)
+			if !slices.Contains(publicationStatuses, st) {
+				response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid status"})
+				return
+			}
+			in.Statuses = append(in.Statuses, st)
+		}
+	}
+	if raw := q.Get("last_synced_before"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid last_synced_before"})
+			return
+		}
+		in.LastSyncedBefore = &t
+	}
+	if raw := q.Get("due"); raw != "" {
+		due, err := strconv.ParseBool(raw)
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid due"})
+			return
+		}
+		in.DueOnly = due
+	}
+	if raw := q.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxSyncPage {
+			response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid limit"})
+			return
+		}
+		in.Limit = limit
+	}
+	if raw := q.Get("cursor"); raw != "" {
+		cursor, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || cursor < 0 {
+			response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid cursor"})
+			return
+		}
+		in.Cursor = cursor
+	}
+
+	items, next, err := h.svc.ListForSync(r.Context(), in)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to list publications"})
+		return
+	}
+
+	out := make([]any, 0, len(items))
+	for _, it := range items {
+		out = append(out, render(it))
+	}
+
+	resp := map[string]any{"data": out}
+	if next != 0 {
+		resp["next_cursor"] = strconv.FormatInt(next, 10)
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// GET /internal/publications/{platform}/{external_id}
+func (h *Handler) InternalGet(w http.ResponseWriter, r *http.Request) {
+	platform := chi.URLParam(r, "platform")
+	if !validPlatform(platform) {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid platform"})
+		return
+	}
+
+	h.internalGet(w, r, platform, chi.URLParam(r, "external_id"), asPublication)
+}
+
+func (h *Handler) internalGet(w http.ResponseWriter, r *http.Request, platform string, externalID string, render view) {
+	if externalID == "" {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid external id"})
+		return
+	}
+
+	item, err := h.svc.GetByExternalID(r.Context(), platform, externalID)
+	if err != nil {
+		if err == svc.ErrNotFound {
+			response.JSON(w, http.StatusNotFound, messageResponse{Message: "not found"})
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to get publication"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, render(item))
+}
+
+// POST /internal/publications/mark-deleted
+func (h *Handler) InternalMarkDeleted(w http.ResponseWriter, r *http.Request) {
+	var req reqs.InternalMarkDeletedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	h.internalMarkDeleted(w, r, req, asPublication)
+}
+
+func (h *Handler) internalMarkDeleted(w http.ResponseWriter, r *http.Request, req reqs.InternalMarkDeletedRequest, render view) {
+	ts := req.LastSyncedAt
+	if ts == nil {
+		now := time.Now().UTC()
+		ts = &now
+	}
+
+	updated, err := h.svc.MarkDeleted(r.Context(), req.Platform, req.ExternalID, ts)
+	if err != nil {
+		if err == svc.ErrNotFound {
+			response.JSON(w, http.StatusNotFound, messageResponse{Message: "not found"})
+			return
+		}
+		if errors.Is(err, lifecycle.ErrIllegalTransition) {
+			response.JSON(w, http.StatusConflict, messageResponse{Message: err.Error()})
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to mark publication deleted"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, render(updated))
+}
+
+// POST /internal/publications/metrics
+func (h *Handler) InternalUpdateMetrics(w http.ResponseWriter, r *http.Request) {
+	var req reqs.InternalMetricsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	h.internalUpdateMetrics(w, r, req, asPublication)
+}
+
+func (h *Handler) internalUpdateMetrics(w http.ResponseWriter, r *http.Request, req reqs.InternalMetricsRequest, render view) {
+	updated, err := h.svc.UpdateByExternalID(r.Context(), req.Platform, req.ExternalID, svc.UpdateInput{
+		Views:        req.Views,
+		Likes:        req.Likes,
+		Comments:     req.Comments,
+		Shares:       req.Shares,
+		PublishedAt:  req.PublishedAt,
+		LastSyncedAt: req.LastSyncedAt,
+		Analytics:    normalizeJSON(req.Analytics),
+	})
+	if err != nil {
+		if err == svc.ErrNotFound {
+			response.JSON(w, http.StatusNotFound, messageResponse{Message: "not found"})
+			return
+		}
+		if errors.Is(err, workspacessvc.ErrForbidden) {
+			response.JSON(w, http.StatusForbidden, messageResponse{Message: "forbidden"})
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to update publication"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, render(updated))
+}
+
+// POST /internal/publications/metrics/batch
+// Applies a whole sync run in one transaction and reports each item as updated, not_found,
+// invalid or conflict (a status change the publication's current status does not allow).
+func (h *Handler) InternalUpdateMetricsBatch(w http.ResponseWriter, r *http.Request) {
+	var req reqs.InternalMetricsBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	h.internalUpdateMetricsBatch(w, r, req)
+}
+
+type batchItemResult struct {
+	Index         int    `json:"index"`
+	ExternalID    string `json:"external_id"`
+	Result        string `json:"result"`
+	PublicationID int64  `json:"publication_id,omitempty"`
+}
+
+func (h *Handler) internalUpdateMetricsBatch(w http.ResponseWriter, r *http.Request, req reqs.InternalMetricsBatchRequest) {
+	results := make([]batchItemResult, len(req.Items))
+	items := make([]svc.BatchItem, 0, len(req.Items))
+	positions := make([]int, 0, len(req.Items))
+	for i, it := range req.Items {
+		results[i] = batchItemResult{Index: i, ExternalID: it.ExternalID, Result: svc.BatchInvalid}
+		if err := it.Validate(); err != nil {
+			continue
+		}
+
+		items = append(items, svc.BatchItem{
+			Platform:   it.Platform,
+			ExternalID: it.ExternalID,
+			Deleted:    it.Deleted,
+			Update: svc.UpdateInput{
+				Views:        it.Views,
+				Likes:        it.Likes,
+				Comments:     it.Comments,
+				Shares:       it.Shares,
+				PublishedAt:  it.PublishedAt,
+				LastSyncedAt: it.LastSyncedAt,
+				Analytics:    normalizeJSON(it.Analytics),
+			},
+		})
+		positions = append(positions, i)
+	}
+
+	if len(items) > 0 {
+		applied, err := h.svc.ApplyBatch(r.Context(), items)
+		if err != nil {
+			response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to apply metrics batch"})
+			return
+		}
+		for j, res := range applied {
+			results[positions[j]].Result = res.Result
+			results[positions[j]].PublicationID = res.Publication.ID
+		}
+	}
+
+	counts := map[string]int{svc.BatchUpdated: 0, svc.BatchNotFound: 0, svc.BatchInvalid: 0, svc.BatchConflict: 0}
+	for _, res := range results {
+		counts[res.Result]++
+	}
+
+	response.JSON(w, http.StatusOK, map[string]any{
+		"data":      results,
+		"updated":   counts[svc.BatchUpdated],
+		"not_found": counts[svc.BatchNotFound],
+		"invalid":   counts[svc.BatchInvalid],
+		"conflict":  counts[svc.BatchConflict],
+	})
+}
+
+func parseIDParam(r *http.Request, param string) (int64, error) {
+	idStr := chi.URLParam(r, param)
+	return strconv.ParseInt(idStr, 10, 64)
+}
+
+func validPlatform(platform string) bool {
+	for _, p := range pubrepo.Platforms {
+		if p == platform {
+			return true
+		}
+	}
+	return false
+}
+
+func platformOrDefault(platform string) string {
+	if platform == "" {
+		return pubrepo.PlatformYouTube
+	}
+	return platform
+}
+
+// normalizeJSON turns an optional raw JSON field into the string stored in a JSON column.
+func normalizeJSON(raw *json.RawMessage) *string {
+	if raw == nil {
+		return nil
+	}
+
+	b := bytes.TrimSpace(*raw)
+	if len(b) == 0 || bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+
+	s := string(b)
+	return &s
+}
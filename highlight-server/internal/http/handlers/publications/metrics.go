@@ -0,0 +1,130 @@
+package publications
+
+import (
+	"net/http"
+	"time"
+
+	"highlightiq-server/internal/http/middleware"
+	"highlightiq-server/internal/http/response"
+	svc "highlightiq-server/internal/services/publications"
+)
+
+// GET /publications/{id}/metrics?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *Handler) Metrics(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid id"})
+		return
+	}
+
+	from, to, ok := parseMetricsRange(w, r)
+	if !ok {
+		return
+	}
+
+	out, err := h.svc.Metrics(r.Context(), u.ID, id, from, to)
+	if err != nil {
+		if err == svc.ErrNotFound {
+			response.JSON(w, http.StatusNotFound, messageResponse{Message: "not found"})
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to load metrics"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, out)
+}
+
+// GET /clips/{id}/publications/metrics
+func (h *Handler) ClipMetrics(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	clipID, err := parseIDParam(r, "id")
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid clip id"})
+		return
+	}
+
+	from, to, ok := parseMetricsRange(w, r)
+	if !ok {
+		return
+	}
+
+	out, err := h.svc.ClipMetrics(r.Context(), u.ID, clipID, from, to)
+	if err != nil {
+		if err == svc.ErrNotFound {
+			response.JSON(w, http.StatusNotFound, messageResponse{Message: "clip not found"})
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to load metrics"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, out)
+}
+
+// GET /publications/metrics
+// Sums every publication the user can see.
+func (h *Handler) UserMetrics(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	from, to, ok := parseMetricsRange(w, r)
+	if !ok {
+		return
+	}
+
+	out, err := h.svc.UserMetrics(r.Context(), u.ID, from, to)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to load metrics"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, out)
+}
+
+// parseMetricsRange reads the optional from and to days. It writes the error response itself.
+func parseMetricsRange(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	from, err := parseDay(r.URL.Query().Get("from"))
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid from date"})
+		return time.Time{}, time.Time{}, false
+	}
+	to, err := parseDay(r.URL.Query().Get("to"))
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid to date"})
+		return time.Time{}, time.Time{}, false
+	}
+
+	start, end, err := svc.MetricsRange(from, to, time.Now())
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid metrics range"})
+		return time.Time{}, time.Time{}, false
+	}
+	return start, end, true
+}
+
+// parseDay parses a YYYY-MM-DD query value; empty means not given.
+func parseDay(raw string) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
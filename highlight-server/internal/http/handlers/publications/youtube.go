@@ -0,0 +1,140 @@
+package publications
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"highlightiq-server/internal/http/response"
+	pubrepo "highlightiq-server/internal/repos/publications"
+	reqs "highlightiq-server/internal/requests/publications"
+	svc "highlightiq-server/internal/services/publications"
+)
+
+// The /youtube-publishes routes predate multi-platform publishing. They keep their original
+// request and response shapes and are served by the generic handlers with the platform fixed.
+
+// youtubePublish is the legacy response shape: a publication with the YouTube field names.
+type youtubePublish struct {
+	pubrepo.Publication
+	YoutubeVideoID string `json:"youtube_video_id"`
+	YoutubeURL     string `json:"youtube_url"`
+}
+
+func asYoutubePublish(p pubrepo.Publication) any {
+	return youtubePublish{Publication: p, YoutubeVideoID: p.ExternalID, YoutubeURL: p.URL}
+}
+
+// POST /clips/{id}/youtube-publishes
+func (h *Handler) YoutubeCreate(w http.ResponseWriter, r *http.Request) {
+	var req reqs.YoutubeCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	h.create(w, r, req.Generic(), asYoutubePublish)
+}
+
+// GET /clips/{id}/youtube-publishes
+//
+// Lists every publication of the clip, whatever the platform, as before.
+func (h *Handler) YoutubeListByClip(w http.ResponseWriter, r *http.Request) {
+	h.listByClip(w, r, asYoutubePublish)
+}
+
+// PATCH /youtube-publishes/{id}
+func (h *Handler) YoutubeUpdate(w http.ResponseWriter, r *http.Request) {
+	var req reqs.YoutubeUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	h.update(w, r, req.Generic(), asYoutubePublish)
+}
+
+// POST /internal/youtube-publishes
+func (h *Handler) YoutubeInternalCreate(w http.ResponseWriter, r *http.Request) {
+	var req reqs.YoutubeInternalCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	h.internalCreate(w, r, req.Generic(), asYoutubePublish)
+}
+
+// GET /internal/youtube-publishes
+// Takes the filters of InternalList.
+func (h *Handler) YoutubeInternalList(w http.ResponseWriter, r *http.Request) {
+	h.internalList(w, r, pubrepo.PlatformYouTube, func(it svc.SyncItem) any {
+		return struct {
+			YoutubeVideoID string `json:"youtube_video_id"`
+			syncEntry
+		}{it.ExternalID, newSyncEntry(it)}
+	})
+}
+
+// GET /internal/youtube-publishes/{youtube_video_id}
+func (h *Handler) YoutubeInternalGet(w http.ResponseWriter, r *http.Request) {
+	h.internalGet(w, r, pubrepo.PlatformYouTube, chi.URLParam(r, "youtube_video_id"), asYoutubePublish)
+}
+
+// POST /internal/youtube-publishes/mark-deleted
+func (h *Handler) YoutubeInternalMarkDeleted(w http.ResponseWriter, r *http.Request) {
+	var req reqs.YoutubeInternalMarkDeletedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	h.internalMarkDeleted(w, r, req.Generic(), asYoutubePublish)
+}
+
+// POST /internal/youtube-publishes/metrics
+func (h *Handler) YoutubeInternalUpdateMetrics(w http.ResponseWriter, r *http.Request) {
+	var req reqs.YoutubeInternalMetricsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	h.internalUpdateMetrics(w, r, req.Generic(), asYoutubePublish)
+}
+
+// POST /internal/youtube-publishes/metrics/batch
+func (h *Handler) YoutubeInternalUpdateMetricsBatch(w http.ResponseWriter, r *http.Request) {
+	var req reqs.YoutubeInternalMetricsBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	h.internalUpdateMetricsBatch(w, r, req.Generic())
+}
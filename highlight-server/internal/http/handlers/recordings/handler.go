@@ -6,6 +6,7 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
@@ -14,14 +15,17 @@ import (
 	"highlightiq-server/internal/http/response"
 	recRepo "highlightiq-server/internal/repos/recordings"
 	recReq "highlightiq-server/internal/requests/recordings"
+	usagesvc "highlightiq-server/internal/services/usage"
+	workspacessvc "highlightiq-server/internal/services/workspaces"
 )
 
 type RecordingService interface {
-	Create(ctx context.Context, userID int64, title string, originalName string, fileBytes []byte) (recRepo.Recording, error)
-	List(ctx context.Context, userID int64) ([]recRepo.Recording, error)
+	Create(ctx context.Context, userID int64, workspaceID int64, title string, originalName string, fileBytes []byte) (recRepo.Recording, error)
+	List(ctx context.Context, userID int64, workspaceID int64, tag string) ([]recRepo.Recording, error)
 	Get(ctx context.Context, userID int64, recUUID string) (recRepo.Recording, error)
 	UpdateTitle(ctx context.Context, userID int64, recUUID string, title string) error
 	Delete(ctx context.Context, userID int64, recUUID string) error
+	Restore(ctx context.Context, userID int64, recUUID string) error
 }
 
 type Handler struct {
@@ -59,14 +63,31 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 
 	title := strings.TrimSpace(r.FormValue("title"))
 
+	workspaceID, ok := parseWorkspaceID(w, r.FormValue("workspace_id"))
+	if !ok {
+		return
+	}
+
 	b, err := io.ReadAll(file)
 	if err != nil {
 		response.JSON(w, http.StatusBadRequest, map[string]any{"message": "failed to read file"})
 		return
 	}
 
-	rec, err := h.svc.Create(r.Context(), u.ID, title, header.Filename, b)
+	rec, err := h.svc.Create(r.Context(), u.ID, workspaceID, title, header.Filename, b)
 	if err != nil {
+		if errors.Is(err, usagesvc.ErrQuotaExceeded) {
+			response.JSON(w, http.StatusRequestEntityTooLarge, map[string]any{"message": "recording storage quota exceeded"})
+			return
+		}
+		if errors.Is(err, workspacessvc.ErrNotFound) {
+			response.JSON(w, http.StatusNotFound, map[string]any{"message": "workspace not found"})
+			return
+		}
+		if errors.Is(err, workspacessvc.ErrForbidden) {
+			response.JSON(w, http.StatusForbidden, map[string]any{"message": "forbidden"})
+			return
+		}
 		response.JSON(w, http.StatusInternalServerError, map[string]any{"message": "internal server error"})
 		return
 	}
@@ -81,7 +102,14 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	recs, err := h.svc.List(r.Context(), u.ID)
+	tag := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("tag")))
+
+	workspaceID, ok := parseWorkspaceID(w, r.URL.Query().Get("workspace_id"))
+	if !ok {
+		return
+	}
+
+	recs, err := h.svc.List(r.Context(), u.ID, workspaceID, tag)
 	if err != nil {
 		response.JSON(w, http.StatusInternalServerError, map[string]any{"message": "internal server error"})
 		return
@@ -142,6 +170,10 @@ func (h *Handler) UpdateTitle(w http.ResponseWriter, r *http.Request) {
 			response.JSON(w, http.StatusNotFound, map[string]any{"message": "not found"})
 			return
 		}
+		if errors.Is(err, workspacessvc.ErrForbidden) {
+			response.JSON(w, http.StatusForbidden, map[string]any{"message": "forbidden"})
+			return
+		}
 		response.JSON(w, http.StatusInternalServerError, map[string]any{"message": "internal server error"})
 		return
 	}
@@ -163,9 +195,56 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 			response.JSON(w, http.StatusNotFound, map[string]any{"message": "not found"})
 			return
 		}
+		if errors.Is(err, workspacessvc.ErrForbidden) {
+			response.JSON(w, http.StatusForbidden, map[string]any{"message": "forbidden"})
+			return
+		}
 		response.JSON(w, http.StatusInternalServerError, map[string]any{"message": "internal server error"})
 		return
 	}
 
 	response.JSON(w, http.StatusOK, map[string]any{"message": "deleted"})
 }
+
+func (h *Handler) Restore(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+
+	recUUID := chi.URLParam(r, "uuid")
+
+	if err := h.svc.Restore(r.Context(), u.ID, recUUID); err != nil {
+		if errors.Is(err, usagesvc.ErrQuotaExceeded) {
+			response.JSON(w, http.StatusRequestEntityTooLarge, map[string]any{"message": "storage quota exceeded"})
+			return
+		}
+		if errors.Is(err, recRepo.ErrNotFound) {
+			response.JSON(w, http.StatusNotFound, map[string]any{"message": "not found"})
+			return
+		}
+		if errors.Is(err, workspacessvc.ErrForbidden) {
+			response.JSON(w, http.StatusForbidden, map[string]any{"message": "forbidden"})
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, map[string]any{"message": "internal server error"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]any{"message": "restored"})
+}
+
+// parseWorkspaceID reads an optional workspace_id value; empty means "not given" (0).
+func parseWorkspaceID(w http.ResponseWriter, raw string) (int64, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, true
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		response.JSON(w, http.StatusBadRequest, map[string]any{"message": "invalid workspace_id"})
+		return 0, false
+	}
+	return id, true
+}
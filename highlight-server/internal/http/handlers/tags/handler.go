@@ -0,0 +1,157 @@
+package tags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"highlightiq-server/internal/http/middleware"
+	"highlightiq-server/internal/http/response"
+	tagsrepo "highlightiq-server/internal/repos/tags"
+	reqs "highlightiq-server/internal/requests/tags"
+	svc "highlightiq-server/internal/services/tags"
+	workspacessvc "highlightiq-server/internal/services/workspaces"
+)
+
+type TagService interface {
+	List(ctx context.Context, userID int64) ([]tagsrepo.Tag, error)
+	SetRecordingTags(ctx context.Context, userID int64, recUUID string, names []string) ([]string, error)
+	SetClipTags(ctx context.Context, userID int64, clipID int64, names []string) ([]string, error)
+	Delete(ctx context.Context, userID int64, name string) error
+}
+
+type Handler struct {
+	svc TagService
+}
+
+func New(s TagService) *Handler {
+	return &Handler{svc: s}
+}
+
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+type tagsResponse struct {
+	Tags []string `json:"tags"`
+}
+
+// GET /tags
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	items, err := h.svc.List(r.Context(), u.ID)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to list tags"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]any{"data": items})
+}
+
+// DELETE /tags/{name}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), u.ID, chi.URLParam(r, "name")); err != nil {
+		if errors.Is(err, svc.ErrNotFound) {
+			response.JSON(w, http.StatusNotFound, messageResponse{Message: "not found"})
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to delete tag"})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PUT /recordings/{uuid}/tags
+func (h *Handler) SetRecordingTags(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	req, ok := decodeSetRequest(w, r)
+	if !ok {
+		return
+	}
+
+	names, err := h.svc.SetRecordingTags(r.Context(), u.ID, chi.URLParam(r, "uuid"), req.Tags)
+	if err != nil {
+		if errors.Is(err, svc.ErrNotFound) {
+			response.JSON(w, http.StatusNotFound, messageResponse{Message: "recording not found"})
+			return
+		}
+		if errors.Is(err, workspacessvc.ErrForbidden) {
+			response.JSON(w, http.StatusForbidden, messageResponse{Message: "forbidden"})
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to set tags"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, tagsResponse{Tags: names})
+}
+
+// PUT /clips/{id}/tags
+func (h *Handler) SetClipTags(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	clipID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid id"})
+		return
+	}
+
+	req, ok := decodeSetRequest(w, r)
+	if !ok {
+		return
+	}
+
+	names, err := h.svc.SetClipTags(r.Context(), u.ID, clipID, req.Tags)
+	if err != nil {
+		if errors.Is(err, svc.ErrNotFound) {
+			response.JSON(w, http.StatusNotFound, messageResponse{Message: "clip not found"})
+			return
+		}
+		if errors.Is(err, workspacessvc.ErrForbidden) {
+			response.JSON(w, http.StatusForbidden, messageResponse{Message: "forbidden"})
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to set tags"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, tagsResponse{Tags: names})
+}
+
+func decodeSetRequest(w http.ResponseWriter, r *http.Request) (reqs.SetRequest, bool) {
+	var req reqs.SetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return reqs.SetRequest{}, false
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return reqs.SetRequest{}, false
+	}
+	return req, true
+}
@@ -0,0 +1,61 @@
+package trash
+
+import (
+	"context"
+	"net/http"
+
+	"highlightiq-server/internal/http/middleware"
+	"highlightiq-server/internal/http/response"
+	svc "highlightiq-server/internal/services/trash"
+)
+
+type TrashService interface {
+	List(ctx context.Context, userID int64) (svc.Trash, error)
+	Empty(ctx context.Context, userID int64) (svc.PurgeResult, error)
+}
+
+type Handler struct {
+	svc TrashService
+}
+
+func New(s TrashService) *Handler {
+	return &Handler{svc: s}
+}
+
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+// GET /trash
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	out, err := h.svc.List(r.Context(), u.ID)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to list trash"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, out)
+}
+
+// DELETE /trash
+func (h *Handler) Empty(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	out, err := h.svc.Empty(r.Context(), u.ID)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to empty trash"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]any{"purged": out})
+}
@@ -0,0 +1,43 @@
+package usage
+
+import (
+	"context"
+	"net/http"
+
+	"highlightiq-server/internal/http/middleware"
+	"highlightiq-server/internal/http/response"
+	svc "highlightiq-server/internal/services/usage"
+)
+
+type UsageService interface {
+	Get(ctx context.Context, userID int64) (svc.Report, error)
+}
+
+type Handler struct {
+	svc UsageService
+}
+
+func New(s UsageService) *Handler {
+	return &Handler{svc: s}
+}
+
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+// GET /me/usage
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	out, err := h.svc.Get(r.Context(), u.ID)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to load usage"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, out)
+}
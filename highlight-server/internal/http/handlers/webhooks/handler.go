@@ -0,0 +1,227 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"highlightiq-server/internal/http/middleware"
+	"highlightiq-server/internal/http/response"
+	webhooksrepo "highlightiq-server/internal/repos/webhooks"
+	reqs "highlightiq-server/internal/requests/webhooks"
+	svc "highlightiq-server/internal/services/webhooks"
+	workspacessvc "highlightiq-server/internal/services/workspaces"
+)
+
+type WebhookService interface {
+	Create(ctx context.Context, userID int64, workspaceID int64, in svc.CreateInput) (svc.Created, error)
+	List(ctx context.Context, userID int64, workspaceID int64) ([]webhooksrepo.Endpoint, error)
+	Get(ctx context.Context, userID int64, id int64) (webhooksrepo.Endpoint, error)
+	Update(ctx context.Context, userID int64, id int64, in svc.UpdateInput) (webhooksrepo.Endpoint, error)
+	Delete(ctx context.Context, userID int64, id int64) error
+	Deliveries(ctx context.Context, userID int64, id int64) ([]webhooksrepo.Delivery, error)
+	Ping(ctx context.Context, userID int64, id int64) (webhooksrepo.Delivery, error)
+}
+
+type Handler struct {
+	svc WebhookService
+}
+
+func New(s WebhookService) *Handler {
+	return &Handler{svc: s}
+}
+
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+// POST /webhooks
+// The signing secret is only in this response.
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	var req reqs.CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	var workspaceID int64
+	if req.WorkspaceID != nil {
+		workspaceID = *req.WorkspaceID
+	}
+
+	out, err := h.svc.Create(r.Context(), u.ID, workspaceID, svc.CreateInput{
+		URL:         req.URL,
+		Description: req.Description,
+		Events:      req.Events,
+	})
+	if err != nil {
+		writeError(w, err, "failed to create webhook")
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, out)
+}
+
+// GET /webhooks?workspace_id=
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	var workspaceID int64
+	if raw := r.URL.Query().Get("workspace_id"); raw != "" {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || v <= 0 {
+			response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid workspace_id"})
+			return
+		}
+		workspaceID = v
+	}
+
+	items, err := h.svc.List(r.Context(), u.ID, workspaceID)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to list webhooks"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]any{"data": items})
+}
+
+// GET /webhooks/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	u, id, ok := authAndID(w, r)
+	if !ok {
+		return
+	}
+
+	e, err := h.svc.Get(r.Context(), u.ID, id)
+	if err != nil {
+		writeError(w, err, "failed to get webhook")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, e)
+}
+
+// PATCH /webhooks/{id}
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	u, id, ok := authAndID(w, r)
+	if !ok {
+		return
+	}
+
+	var req reqs.UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	e, err := h.svc.Update(r.Context(), u.ID, id, svc.UpdateInput{
+		URL:         req.URL,
+		Description: req.Description,
+		Events:      req.Events,
+		Active:      req.Active,
+	})
+	if err != nil {
+		writeError(w, err, "failed to update webhook")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, e)
+}
+
+// DELETE /webhooks/{id}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	u, id, ok := authAndID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), u.ID, id); err != nil {
+		writeError(w, err, "failed to delete webhook")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /webhooks/{id}/deliveries
+func (h *Handler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	u, id, ok := authAndID(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.svc.Deliveries(r.Context(), u.ID, id)
+	if err != nil {
+		writeError(w, err, "failed to list deliveries")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]any{"data": items})
+}
+
+// POST /webhooks/{id}/ping
+// Queues a ping; its outcome shows up in the delivery log.
+func (h *Handler) Ping(w http.ResponseWriter, r *http.Request) {
+	u, id, ok := authAndID(w, r)
+	if !ok {
+		return
+	}
+
+	d, err := h.svc.Ping(r.Context(), u.ID, id)
+	if err != nil {
+		writeError(w, err, "failed to queue ping")
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, d)
+}
+
+func authAndID(w http.ResponseWriter, r *http.Request) (middleware.AuthUser, int64, bool) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return middleware.AuthUser{}, 0, false
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid id"})
+		return middleware.AuthUser{}, 0, false
+	}
+	return u, id, true
+}
+
+func writeError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, svc.ErrNotFound):
+		response.JSON(w, http.StatusNotFound, messageResponse{Message: "webhook not found"})
+	case errors.Is(err, svc.ErrInvalidURL):
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid url"})
+	case errors.Is(err, workspacessvc.ErrForbidden):
+		response.JSON(w, http.StatusForbidden, messageResponse{Message: "forbidden"})
+	default:
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: fallback})
+	}
+}
@@ -0,0 +1,308 @@
+package workspaces
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"highlightiq-server/internal/http/middleware"
+	"highlightiq-server/internal/http/response"
+	wsrepo "highlightiq-server/internal/repos/workspaces"
+	reqs "highlightiq-server/internal/requests/workspaces"
+	svc "highlightiq-server/internal/services/workspaces"
+)
+
+type WorkspaceService interface {
+	Create(ctx context.Context, userID int64, name string) (wsrepo.Workspace, error)
+	List(ctx context.Context, userID int64) ([]wsrepo.Workspace, error)
+	Get(ctx context.Context, userID int64, id int64) (wsrepo.Workspace, error)
+	Rename(ctx context.Context, userID int64, id int64, name string) (wsrepo.Workspace, error)
+	Members(ctx context.Context, userID int64, id int64) ([]wsrepo.Member, error)
+	UpdateMemberRole(ctx context.Context, userID int64, id int64, memberUUID string, role string) error
+	RemoveMember(ctx context.Context, userID int64, id int64, memberUUID string) error
+	Invite(ctx context.Context, userID int64, id int64, email string, role string) (svc.InvitationCreated, error)
+	Invitations(ctx context.Context, userID int64, id int64) ([]wsrepo.Invitation, error)
+	RevokeInvitation(ctx context.Context, userID int64, id int64, invitationID int64) error
+	AcceptInvitation(ctx context.Context, userID int64, email string, token string) (wsrepo.Workspace, error)
+}
+
+type Handler struct {
+	svc WorkspaceService
+}
+
+func New(s WorkspaceService) *Handler {
+	return &Handler{svc: s}
+}
+
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+// POST /workspaces
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	var req reqs.CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	ws, err := h.svc.Create(r.Context(), u.ID, req.Name)
+	if err != nil {
+		writeError(w, err, "failed to create workspace")
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, ws)
+}
+
+// GET /workspaces
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	items, err := h.svc.List(r.Context(), u.ID)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to list workspaces"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]any{"data": items})
+}
+
+// GET /workspaces/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	u, id, ok := authAndID(w, r)
+	if !ok {
+		return
+	}
+
+	ws, err := h.svc.Get(r.Context(), u.ID, id)
+	if err != nil {
+		writeError(w, err, "failed to get workspace")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, ws)
+}
+
+// PATCH /workspaces/{id}
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	u, id, ok := authAndID(w, r)
+	if !ok {
+		return
+	}
+
+	var req reqs.UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	ws, err := h.svc.Rename(r.Context(), u.ID, id, req.Name)
+	if err != nil {
+		writeError(w, err, "failed to update workspace")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, ws)
+}
+
+// GET /workspaces/{id}/members
+func (h *Handler) Members(w http.ResponseWriter, r *http.Request) {
+	u, id, ok := authAndID(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.svc.Members(r.Context(), u.ID, id)
+	if err != nil {
+		writeError(w, err, "failed to list members")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]any{"data": items})
+}
+
+// PATCH /workspaces/{id}/members/{user_id}
+func (h *Handler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	u, id, ok := authAndID(w, r)
+	if !ok {
+		return
+	}
+
+	var req reqs.MemberRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	if err := h.svc.UpdateMemberRole(r.Context(), u.ID, id, chi.URLParam(r, "user_id"), req.Role); err != nil {
+		writeError(w, err, "failed to update member")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DELETE /workspaces/{id}/members/{user_id}
+// Owners remove members; any member may remove themselves to leave.
+func (h *Handler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	u, id, ok := authAndID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.RemoveMember(r.Context(), u.ID, id, chi.URLParam(r, "user_id")); err != nil {
+		writeError(w, err, "failed to remove member")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /workspaces/{id}/invitations
+// The invitation token is only in this response; deliver it to the invitee.
+func (h *Handler) Invite(w http.ResponseWriter, r *http.Request) {
+	u, id, ok := authAndID(w, r)
+	if !ok {
+		return
+	}
+
+	var req reqs.InviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	out, err := h.svc.Invite(r.Context(), u.ID, id, req.Email, req.Role)
+	if err != nil {
+		writeError(w, err, "failed to create invitation")
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, out)
+}
+
+// GET /workspaces/{id}/invitations
+func (h *Handler) Invitations(w http.ResponseWriter, r *http.Request) {
+	u, id, ok := authAndID(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.svc.Invitations(r.Context(), u.ID, id)
+	if err != nil {
+		writeError(w, err, "failed to list invitations")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]any{"data": items})
+}
+
+// DELETE /workspaces/{id}/invitations/{invitation_id}
+func (h *Handler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	u, id, ok := authAndID(w, r)
+	if !ok {
+		return
+	}
+
+	invitationID, err := strconv.ParseInt(chi.URLParam(r, "invitation_id"), 10, 64)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid invitation_id"})
+		return
+	}
+
+	if err := h.svc.RevokeInvitation(r.Context(), u.ID, id, invitationID); err != nil {
+		writeError(w, err, "failed to revoke invitation")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /workspaces/invitations/accept
+func (h *Handler) Accept(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	var req reqs.AcceptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	ws, err := h.svc.AcceptInvitation(r.Context(), u.ID, u.Email, req.Token)
+	if err != nil {
+		writeError(w, err, "failed to accept invitation")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, ws)
+}
+
+func authAndID(w http.ResponseWriter, r *http.Request) (middleware.AuthUser, int64, bool) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return middleware.AuthUser{}, 0, false
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid id"})
+		return middleware.AuthUser{}, 0, false
+	}
+
+	return u, id, true
+}
+
+func writeError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, svc.ErrNotFound):
+		response.JSON(w, http.StatusNotFound, messageResponse{Message: "not found"})
+	case errors.Is(err, svc.ErrForbidden):
+		response.JSON(w, http.StatusForbidden, messageResponse{Message: "forbidden"})
+	case errors.Is(err, svc.ErrBadInput):
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid request"})
+	case errors.Is(err, svc.ErrInvitationInvalid):
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invitation is invalid or expired"})
+	default:
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: fallback})
+	}
+}
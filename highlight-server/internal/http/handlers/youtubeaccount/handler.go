@@ -0,0 +1,128 @@
+package youtubeaccount
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"highlightiq-server/internal/http/middleware"
+	"highlightiq-server/internal/http/response"
+	reqs "highlightiq-server/internal/requests/youtubeaccount"
+	svc "highlightiq-server/internal/services/youtubepublisher"
+)
+
+type YoutubeAccountService interface {
+	Status(ctx context.Context, userID int64) (svc.Connection, error)
+	StartConnect(ctx context.Context, userID int64) (svc.ConnectStart, error)
+	CompleteConnect(ctx context.Context, userID int64, code string, state string) (svc.Connection, error)
+	Disconnect(ctx context.Context, userID int64) error
+}
+
+type Handler struct {
+	svc YoutubeAccountService
+}
+
+func New(s YoutubeAccountService) *Handler {
+	return &Handler{svc: s}
+}
+
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+// GET /me/youtube
+func (h *Handler) Status(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	out, err := h.svc.Status(r.Context(), u.ID)
+	if err != nil {
+		log.Printf("YouTube status failed: %v", err)
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to load youtube connection"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, out)
+}
+
+// POST /me/youtube/connect
+// Returns Google's consent page; it redirects back to the web app, which posts code and state
+// to the callback.
+func (h *Handler) StartConnect(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	out, err := h.svc.StartConnect(r.Context(), u.ID)
+	if err != nil {
+		log.Printf("YouTube connect failed: %v", err)
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to start connecting youtube"})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, out)
+}
+
+// POST /me/youtube/callback
+func (h *Handler) CompleteConnect(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	var req reqs.ConnectCallbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid JSON payload"})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.JSON(w, http.StatusBadRequest, messageResponse{Message: "validation failed"})
+		return
+	}
+
+	out, err := h.svc.CompleteConnect(r.Context(), u.ID, strings.TrimSpace(req.Code), strings.TrimSpace(req.State))
+	if err != nil {
+		switch {
+		case errors.Is(err, svc.ErrInvalidState):
+			response.JSON(w, http.StatusBadRequest, messageResponse{Message: "invalid or expired state"})
+		case errors.Is(err, svc.ErrConnectFailed):
+			response.JSON(w, http.StatusBadGateway, messageResponse{Message: "youtube did not accept the authorization"})
+		default:
+			log.Printf("YouTube callback failed: %v", err)
+			response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to connect youtube"})
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, out)
+}
+
+// DELETE /me/youtube
+func (h *Handler) Disconnect(w http.ResponseWriter, r *http.Request) {
+	u, ok := middleware.GetAuthUser(r.Context())
+	if !ok {
+		response.JSON(w, http.StatusUnauthorized, messageResponse{Message: "unauthorized"})
+		return
+	}
+
+	if err := h.svc.Disconnect(r.Context(), u.ID); err != nil {
+		if errors.Is(err, svc.ErrNotConnected) {
+			response.JSON(w, http.StatusNotFound, messageResponse{Message: "no youtube channel connected"})
+			return
+		}
+		log.Printf("YouTube disconnect failed: %v", err)
+		response.JSON(w, http.StatusInternalServerError, messageResponse{Message: "failed to disconnect youtube"})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
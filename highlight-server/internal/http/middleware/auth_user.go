@@ -1,6 +1,10 @@
 package middleware
 
-import "context"
+import (
+	"context"
+	"strings"
+	"time"
+)
 
 type ctxKey string
 
@@ -10,6 +14,35 @@ type AuthUser struct {
 	ID    int64
 	UUID  string
 	Email string
+
+	// EmailVerified is false until the user confirms their address; see RequireVerified.
+	EmailVerified bool
+
+	// TokenID and TokenExpiresAt identify the access token used, so it can be revoked. TokenID
+	// is empty for tokens issued before access tokens carried one; those run out on their own.
+	TokenID        string
+	TokenExpiresAt time.Time
+
+	// APIKeyID is set when the request authenticated with a personal API key instead of a JWT;
+	// the key is then limited to Scopes.
+	APIKeyID int64
+	Scopes   []string
+}
+
+// HasScope reports whether the caller may use scope. JWT sessions may do anything; a key's
+// "<resource>:write" scope also grants "<resource>:read".
+func (u AuthUser) HasScope(scope string) bool {
+	if u.APIKeyID == 0 {
+		return true
+	}
+
+	resource, access, _ := strings.Cut(scope, ":")
+	for _, s := range u.Scopes {
+		if s == scope || (access == "read" && s == resource+":write") {
+			return true
+		}
+	}
+	return false
 }
 
 func WithAuthUser(ctx context.Context, u AuthUser) context.Context {
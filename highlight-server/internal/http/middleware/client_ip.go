@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+)
+
+// ClientIP returns the caller's address without the port. Behind a reverse proxy, enable
+// TRUST_PROXY_HEADERS so RemoteAddr is taken from X-Forwarded-For / X-Real-IP first.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
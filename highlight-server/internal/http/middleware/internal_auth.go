@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"highlightiq-server/internal/http/response"
+	"highlightiq-server/internal/reqsign"
+)
+
+const internalClientKey ctxKey = "internal_client"
+
+// maxSignedBody bounds how much of an internal request body is buffered for hashing.
+const maxSignedBody = 10 << 20
+
+// InternalClient is a named caller of the /internal routes with its own signing secret.
+// Routes are "[METHOD ]/path" patterns; a trailing "*" matches any suffix.
+type InternalClient struct {
+	Name   string
+	Secret string
+	Routes []string
+}
+
+// InternalAuth authenticates requests signed with reqsign. Requests outside the timestamp
+// window, or reusing a nonce seen within it, are rejected as replays.
+type InternalAuth struct {
+	clients map[string]InternalClient
+	window  time.Duration
+	now     func() time.Time
+
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	lastPrune time.Time
+}
+
+func NewInternalAuth(clients []InternalClient, window time.Duration) *InternalAuth {
+	if window <= 0 {
+		window = 5 * time.Minute
+	}
+
+	byName := make(map[string]InternalClient, len(clients))
+	for _, c := range clients {
+		if c.Name == "" || c.Secret == "" {
+			continue
+		}
+		byName[c.Name] = c
+	}
+
+	return &InternalAuth{
+		clients: byName,
+		window:  window,
+		now:     time.Now,
+		nonces:  make(map[string]time.Time),
+	}
+}
+
+func (a *InternalAuth) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, ok := a.clients[r.Header.Get(reqsign.HeaderClient)]
+		if !ok {
+			response.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+			return
+		}
+
+		ts, err := strconv.ParseInt(r.Header.Get(reqsign.HeaderTimestamp), 10, 64)
+		if err != nil {
+			response.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+			return
+		}
+		now := a.now()
+		if d := now.Sub(time.Unix(ts, 0)); d > a.window || d < -a.window {
+			response.JSON(w, http.StatusUnauthorized, map[string]any{"message": "request expired"})
+			return
+		}
+
+		nonce := r.Header.Get(reqsign.HeaderNonce)
+		if nonce == "" || len(nonce) > 64 {
+			response.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBody+1))
+		if err != nil || len(body) > maxSignedBody {
+			response.JSON(w, http.StatusRequestEntityTooLarge, map[string]any{"message": "request body too large"})
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		want := reqsign.Sign(c.Secret, r.Method, r.URL.RequestURI(), ts, nonce, body)
+		if !reqsign.Equal(want, r.Header.Get(reqsign.HeaderSignature)) {
+			response.JSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+			return
+		}
+
+		// Only record nonces of correctly signed requests, so garbage cannot fill the cache.
+		if !a.useNonce(c.Name+":"+nonce, time.Unix(ts, 0).Add(a.window), now) {
+			response.JSON(w, http.StatusUnauthorized, map[string]any{"message": "request replayed"})
+			return
+		}
+
+		if !c.allows(r.Method, r.URL.Path) {
+			response.JSON(w, http.StatusForbidden, map[string]any{"message": "route not allowed for client"})
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), internalClientKey, c.Name)))
+	})
+}
+
+// useNonce records key until expires and reports false if it was already recorded.
+func (a *InternalAuth) useNonce(key string, expires time.Time, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if now.Sub(a.lastPrune) > a.window {
+		for k, exp := range a.nonces {
+			if now.After(exp) {
+				delete(a.nonces, k)
+			}
+		}
+		a.lastPrune = now
+	}
+
+	if exp, ok := a.nonces[key]; ok && now.Before(exp) {
+		return false
+	}
+	a.nonces[key] = expires
+	return true
+}
+
+func (c InternalClient) allows(method, path string) bool {
+	for _, route := range c.Routes {
+		pattern := route
+		if m, p, ok := strings.Cut(route, " "); ok {
+			if !strings.EqualFold(m, method) {
+				continue
+			}
+			pattern = p
+		}
+
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+			continue
+		}
+		if strings.TrimSuffix(path, "/") == strings.TrimSuffix(pattern, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// GetInternalClient returns the name of the internal client that signed the request.
+func GetInternalClient(ctx context.Context) (string, bool) {
+	v, ok := ctx.Value(internalClientKey).(string)
+	return v, ok
+}
@@ -1,30 +1,52 @@
 package middleware
 
 import (
+	"context"
 	"errors"
+	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 
 	"highlightiq-server/internal/http/response"
+	"highlightiq-server/internal/repos/apikeys"
+	"highlightiq-server/internal/repos/sessions"
 	"highlightiq-server/internal/repos/users"
+	apikeyssvc "highlightiq-server/internal/services/apikeys"
 )
 
+// APIKeyVerifier resolves a raw personal API key. It must return apikeyssvc.ErrInvalidKey for
+// keys that are unknown or revoked.
+type APIKeyVerifier interface {
+	Verify(ctx context.Context, raw string) (apikeys.APIKey, error)
+}
+
 type JWTAuth struct {
-	usersRepo *users.Repo
-	secret    []byte
+	usersRepo    *users.Repo
+	sessionsRepo *sessions.Repo
+	apiKeys      APIKeyVerifier
+	secret       []byte
 }
 
-func NewJWTAuth(usersRepo *users.Repo, jwtSecret string) *JWTAuth {
+func NewJWTAuth(usersRepo *users.Repo, sessionsRepo *sessions.Repo, apiKeys APIKeyVerifier, jwtSecret string) *JWTAuth {
 	return &JWTAuth{
-		usersRepo: usersRepo,
-		secret:    []byte(jwtSecret),
+		usersRepo:    usersRepo,
+		sessionsRepo: sessionsRepo,
+		apiKeys:      apiKeys,
+		secret:       []byte(jwtSecret),
 	}
 }
 
 func (a *JWTAuth) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Scripts may send a personal API key either as X-API-Key or as the bearer token.
+		if key := r.Header.Get("X-API-Key"); key != "" {
+			a.serveAPIKey(w, r, next, strings.TrimSpace(key))
+			return
+		}
+
 		h := r.Header.Get("Authorization")
 		if h == "" {
 			response.JSON(w, http.StatusUnauthorized, map[string]any{"message": "missing authorization header"})
@@ -42,6 +64,10 @@ func (a *JWTAuth) Middleware(next http.Handler) http.Handler {
 			response.JSON(w, http.StatusUnauthorized, map[string]any{"message": "invalid authorization header"})
 			return
 		}
+		if strings.HasPrefix(raw, apikeyssvc.KeyPrefix) {
+			a.serveAPIKey(w, r, next, raw)
+			return
+		}
 
 		token, err := jwt.Parse(raw, func(t *jwt.Token) (any, error) {
 			// Ensure HS256
@@ -62,12 +88,32 @@ func (a *JWTAuth) Middleware(next http.Handler) http.Handler {
 		}
 
 		sub, _ := claims["sub"].(string)
-		email, _ := claims["email"].(string)
-		if sub == "" {
+		jti, _ := claims["jti"].(string)
+		var exp time.Time
+		if v, err := claims.GetExpirationTime(); err == nil && v != nil {
+			exp = v.Time
+		}
+		// Tokens without a jti predate revocation support and cannot be revoked. They are still
+		// accepted until they expire, so a deploy does not sign everyone out; one that never
+		// expires is refused.
+		if sub == "" || (jti == "" && exp.IsZero()) {
 			response.JSON(w, http.StatusUnauthorized, map[string]any{"message": "invalid token claims"})
 			return
 		}
 
+		if jti != "" {
+			revoked, err := a.sessionsRepo.IsRevoked(r.Context(), jti)
+			if err != nil {
+				log.Printf("jwt revocation check failed: %v", err)
+				response.JSON(w, http.StatusInternalServerError, map[string]any{"message": "internal server error"})
+				return
+			}
+			if revoked {
+				response.JSON(w, http.StatusUnauthorized, map[string]any{"message": "token revoked"})
+				return
+			}
+		}
+
 		u, err := a.usersRepo.GetByUUID(r.Context(), sub)
 		if err != nil {
 			if errors.Is(err, users.ErrNotFound) {
@@ -79,11 +125,53 @@ func (a *JWTAuth) Middleware(next http.Handler) http.Handler {
 		}
 
 		ctx := WithAuthUser(r.Context(), AuthUser{
-			ID:    u.ID,
-			UUID:  u.UUID,
-			Email: email,
+			ID:             u.ID,
+			UUID:           u.UUID,
+			Email:          u.Email, // the claim goes stale when the user changes their email
+			EmailVerified:  u.EmailVerifiedAt != nil,
+			TokenID:        jti,
+			TokenExpiresAt: exp,
 		})
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+func (a *JWTAuth) serveAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler, raw string) {
+	if a.apiKeys == nil {
+		response.JSON(w, http.StatusUnauthorized, map[string]any{"message": "invalid api key"})
+		return
+	}
+
+	k, err := a.apiKeys.Verify(r.Context(), raw)
+	if err != nil {
+		if errors.Is(err, apikeyssvc.ErrInvalidKey) {
+			response.JSON(w, http.StatusUnauthorized, map[string]any{"message": "invalid api key"})
+			return
+		}
+		log.Printf("api key check failed: %v", err)
+		response.JSON(w, http.StatusInternalServerError, map[string]any{"message": "internal server error"})
+		return
+	}
+
+	u, err := a.usersRepo.GetByID(r.Context(), k.UserID)
+	if err != nil {
+		if errors.Is(err, users.ErrNotFound) {
+			response.JSON(w, http.StatusUnauthorized, map[string]any{"message": "invalid api key"})
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, map[string]any{"message": "internal server error"})
+		return
+	}
+
+	ctx := WithAuthUser(r.Context(), AuthUser{
+		ID:            u.ID,
+		UUID:          u.UUID,
+		Email:         u.Email,
+		EmailVerified: u.EmailVerifiedAt != nil,
+		APIKeyID:      k.ID,
+		Scopes:        k.Scopes,
+	})
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
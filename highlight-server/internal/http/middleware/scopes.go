@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"highlightiq-server/internal/http/response"
+)
+
+// RequireScope limits API-key requests to the scopes granted to the key. The scope is derived
+// from the route: the first path segment (the second one under /me) names the resource, and GET
+// or HEAD needs read access while anything else needs write access. Routes outside the known
+// resources, such as /api-keys itself, are closed to API keys. JWT sessions pass through.
+func RequireScope(resources []string) func(http.Handler) http.Handler {
+	known := make(map[string]struct{}, len(resources))
+	for _, r := range resources {
+		known[r] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			u, ok := GetAuthUser(r.Context())
+			if !ok || u.APIKeyID == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			resource := routeResource(r.URL.Path)
+			if _, ok := known[resource]; !ok {
+				response.JSON(w, http.StatusForbidden, map[string]any{"message": "api keys cannot access this route"})
+				return
+			}
+
+			access := "write"
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				access = "read"
+			}
+
+			scope := resource + ":" + access
+			if !u.HasScope(scope) {
+				response.JSON(w, http.StatusForbidden, map[string]any{
+					"message": "api key is missing scope",
+					"scope":   scope,
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func routeResource(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if parts[0] == "me" && len(parts) > 1 {
+		return parts[1]
+	}
+	return parts[0]
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+
+	"highlightiq-server/internal/http/response"
+)
+
+// RequireVerified keeps accounts with an unconfirmed email read-only: GET and HEAD pass, every
+// other method is refused until the user follows their verification link.
+func RequireVerified(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, ok := GetAuthUser(r.Context())
+		if ok && !u.EmailVerified && r.Method != http.MethodGet && r.Method != http.MethodHead {
+			response.JSON(w, http.StatusForbidden, map[string]any{"message": "email not verified"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
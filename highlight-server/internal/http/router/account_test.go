@@ -0,0 +1,200 @@
+package router
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	accounthandlers "highlightiq-server/internal/http/handlers/account"
+	authhandlers "highlightiq-server/internal/http/handlers/auth"
+	accountsvc "highlightiq-server/internal/services/account"
+	authsvc "highlightiq-server/internal/services/auth"
+	"highlightiq-server/internal/testutils"
+)
+
+func (fakeAuthService) Profile(ctx context.Context, userID int64) (authsvc.UserDTO, error) {
+	return authsvc.UserDTO{ID: "user-uuid-1", Name: "Test User", Email: "user@test.com"}, nil
+}
+
+func (fakeAuthService) UpdateProfile(ctx context.Context, in authsvc.UpdateProfileInput) (authsvc.UserDTO, error) {
+	out := authsvc.UserDTO{ID: "user-uuid-1", Name: "Test User", Email: "user@test.com", EmailVerified: true}
+	if in.Name != nil {
+		out.Name = *in.Name
+	}
+	if in.Email != nil {
+		if err := fakeReauth(in.UserID, in.CurrentPassword, in.Confirmation); err != nil {
+			return authsvc.UserDTO{}, err
+		}
+		out.Email = *in.Email
+		out.EmailVerified = false
+	}
+	return out, nil
+}
+
+func (fakeAuthService) ChangePassword(ctx context.Context, in authsvc.ChangePasswordInput) (authsvc.RegisterOutput, error) {
+	if err := fakeReauth(in.UserID, in.CurrentPassword, in.Confirmation); err != nil {
+		return authsvc.RegisterOutput{}, err
+	}
+	return authsvc.RegisterOutput{AccessToken: "new-token", TokenType: "Bearer"}, nil
+}
+
+type fakeAccountService struct{}
+
+func (fakeAccountService) Delete(ctx context.Context, userID int64, password string, confirmation string) error {
+	if password == "shared-owner" {
+		return accountsvc.ErrSharedWorkspaces
+	}
+	if err := fakeReauth(userID, password, confirmation); err != nil {
+		if errors.Is(err, authsvc.ErrTooManyAttempts) {
+			return err
+		}
+		return accountsvc.ErrInvalidPassword
+	}
+	return nil
+}
+
+func (fakeAccountService) Export(ctx context.Context, userID int64) (accountsvc.Export, error) {
+	return accountsvc.Export{Account: accountsvc.Account{ID: "user-uuid-1", Email: "user@test.com"}}, nil
+}
+
+// WriteArchive uses the real archive writer; without media it touches no storage.
+func (fakeAccountService) WriteArchive(ctx context.Context, w io.Writer, e accountsvc.Export, includeMedia bool) error {
+	return (&accountsvc.Service{}).WriteArchive(ctx, w, e, false)
+}
+
+func TestMeUpdateEmail(t *testing.T) {
+	h := New(Handlers{Auth: authhandlers.New(fakeAuthService{})}, unverifiedAuthMW, nil)
+
+	cases := []struct {
+		name string
+		body map[string]any
+		want int
+	}{
+		{"rename", map[string]any{"name": "New Name"}, http.StatusOK},
+		{"email without password", map[string]any{"email": "new@test.com"}, http.StatusUnprocessableEntity},
+		{"email with wrong password", map[string]any{"email": "new@test.com", "current_password": "nope"}, http.StatusBadRequest},
+		{"email with password", map[string]any{"email": "new@test.com", "current_password": "password123"}, http.StatusOK},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := testutils.JSONRequest(http.MethodPatch, "/me", tc.body)
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if rr.Code != tc.want {
+				t.Fatalf("expected status %d, got %d; body=%s", tc.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestMeChangePassword(t *testing.T) {
+	h := New(Handlers{Auth: authhandlers.New(fakeAuthService{})}, fakeAuthMW, nil)
+
+	req := testutils.JSONRequest(http.MethodPut, "/me/password", map[string]any{
+		"current_password": "password123",
+		"new_password":     "new-password-123",
+	})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var resp map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+	if resp["access_token"] != "new-token" {
+		t.Fatalf("expected a new session, got %v", resp)
+	}
+}
+
+func TestMeClosedToAPIKeys(t *testing.T) {
+	h := New(Handlers{Auth: authhandlers.New(fakeAuthService{}), Account: accounthandlers.New(fakeAccountService{})}, fakeAPIKeyMW("recordings:write", "usage:read"), nil)
+
+	for _, path := range []string{"/me", "/me/export"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusForbidden {
+			t.Fatalf("%s: expected status %d, got %d", path, http.StatusForbidden, rr.Code)
+		}
+	}
+}
+
+func TestMeDelete(t *testing.T) {
+	h := New(Handlers{Account: accounthandlers.New(fakeAccountService{})}, fakeAuthMW, nil)
+
+	cases := []struct {
+		name     string
+		password string
+		want     int
+	}{
+		{"wrong password", "wrong-password", http.StatusBadRequest},
+		{"owns shared workspace", "shared-owner", http.StatusConflict},
+		{"deleted", "password123", http.StatusNoContent},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := testutils.JSONRequest(http.MethodDelete, "/me", map[string]any{"password": tc.password})
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if rr.Code != tc.want {
+				t.Fatalf("expected status %d, got %d; body=%s", tc.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestMeExport(t *testing.T) {
+	h := New(Handlers{Account: accounthandlers.New(fakeAccountService{})}, fakeAuthMW, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/me/export", nil)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/zip" {
+		t.Fatalf("expected application/zip, got %q", ct)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(rr.Body.Bytes()), int64(rr.Body.Len()))
+	if err != nil {
+		t.Fatalf("response is not a zip: %v", err)
+	}
+
+	for _, f := range zr.File {
+		if f.Name != "account.json" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open account.json: %v", err)
+		}
+		defer rc.Close()
+
+		var acc map[string]any
+		if err := json.NewDecoder(rc).Decode(&acc); err != nil {
+			t.Fatalf("account.json is not valid JSON: %v", err)
+		}
+		if acc["email"] != "user@test.com" {
+			t.Fatalf("unexpected account.json: %v", acc)
+		}
+		return
+	}
+	t.Fatalf("account.json missing from export")
+}
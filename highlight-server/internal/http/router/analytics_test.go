@@ -0,0 +1,104 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	analyticshandlers "highlightiq-server/internal/http/handlers/analytics"
+	analyticssvc "highlightiq-server/internal/services/analytics"
+)
+
+// fakeAnalyticsService echoes the input it was called with.
+type fakeAnalyticsService struct {
+	got *analyticssvc.OverviewInput
+}
+
+func (f fakeAnalyticsService) Overview(ctx context.Context, userID int64, in analyticssvc.OverviewInput) (analyticssvc.Overview, error) {
+	*f.got = in
+	return analyticssvc.Overview{From: in.From, To: in.To, Platform: in.Platform}, nil
+}
+
+func TestAnalyticsOverview(t *testing.T) {
+	var got analyticssvc.OverviewInput
+	h := New(Handlers{Analytics: analyticshandlers.New(fakeAnalyticsService{got: &got})}, fakeAuthMW, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/analytics/overview?from=2026-03-01&to=2026-03-31&platform=youtube&limit=5&tz_offset=-300", nil)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if got.From.Format("2006-01-02") != "2026-03-01" || got.To.Format("2006-01-02") != "2026-04-01" {
+		t.Fatalf("expected range [2026-03-01, 2026-04-01), got [%s, %s)", got.From, got.To)
+	}
+	if got.Platform != "youtube" || got.TopClips != 5 || got.UTCOffsetMinutes != -300 {
+		t.Fatalf("unexpected input %+v", got)
+	}
+
+	var resp map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+	if resp["platform"] != "youtube" {
+		t.Fatalf("expected platform in response, got %v", resp["platform"])
+	}
+}
+
+func TestAnalyticsOverviewValidation(t *testing.T) {
+	// The service is nil: every case must be rejected before reaching it.
+	h := New(Handlers{Analytics: analyticshandlers.New(nil)}, fakeAuthMW, nil)
+
+	cases := []struct {
+		name  string
+		query string
+	}{
+		{"bad from", "?from=yesterday"},
+		{"from after to", "?from=2026-03-10&to=2026-03-01"},
+		{"range too long", "?from=2024-01-01&to=2026-01-01"},
+		{"unknown platform", "?platform=myspace"},
+		{"zero limit", "?limit=0"},
+		{"limit too high", "?limit=500"},
+		{"bad tz_offset", "?tz_offset=east"},
+		{"tz_offset out of range", "?tz_offset=1000"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/analytics/overview"+tc.query, nil))
+
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("expected status %d, got %d; body=%s", http.StatusBadRequest, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestAnalyticsOverviewAPIKeyScope(t *testing.T) {
+	var got analyticssvc.OverviewInput
+	handler := analyticshandlers.New(fakeAnalyticsService{got: &got})
+
+	cases := []struct {
+		scope string
+		want  int
+	}{
+		{"analytics:read", http.StatusOK},
+		{"clips:read", http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.scope, func(t *testing.T) {
+			h := New(Handlers{Analytics: handler}, fakeAPIKeyMW(tc.scope), nil)
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/analytics/overview", nil))
+
+			if rr.Code != tc.want {
+				t.Fatalf("expected status %d, got %d; body=%s", tc.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
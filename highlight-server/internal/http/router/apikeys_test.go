@@ -0,0 +1,130 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apikeyshandlers "highlightiq-server/internal/http/handlers/apikeys"
+	recordinghandlers "highlightiq-server/internal/http/handlers/recordings"
+	workspaceshandlers "highlightiq-server/internal/http/handlers/workspaces"
+	"highlightiq-server/internal/http/middleware"
+	apikeysrepo "highlightiq-server/internal/repos/apikeys"
+	apikeyssvc "highlightiq-server/internal/services/apikeys"
+	"highlightiq-server/internal/testutils"
+)
+
+type fakeAPIKeyService struct{}
+
+func (fakeAPIKeyService) Create(ctx context.Context, userID int64, name string, scopes []string) (apikeyssvc.Created, error) {
+	clean, err := apikeyssvc.NormalizeScopes(scopes)
+	if err != nil {
+		return apikeyssvc.Created{}, err
+	}
+	return apikeyssvc.Created{
+		APIKey: apikeysrepo.APIKey{ID: 7, Name: name, Prefix: "hiq_0123456789ab", Scopes: clean},
+		Key:    "hiq_0123456789ab_secret",
+	}, nil
+}
+
+func (fakeAPIKeyService) List(ctx context.Context, userID int64) ([]apikeysrepo.APIKey, error) {
+	return []apikeysrepo.APIKey{{ID: 7, Name: "obs script", Prefix: "hiq_0123456789ab", Scopes: []string{"recordings:write"}}}, nil
+}
+
+func (fakeAPIKeyService) Revoke(ctx context.Context, userID int64, id int64) error {
+	if id != 7 {
+		return apikeyssvc.ErrNotFound
+	}
+	return nil
+}
+
+// fakeAPIKeyMW authenticates every request as an API key holding scopes.
+func fakeAPIKeyMW(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := middleware.WithAuthUser(r.Context(), middleware.AuthUser{
+				ID:            1,
+				UUID:          "user-uuid-1",
+				EmailVerified: true,
+				APIKeyID:      7,
+				Scopes:        scopes,
+			})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func TestAPIKeysCreate(t *testing.T) {
+	h := New(Handlers{APIKeys: apikeyshandlers.New(fakeAPIKeyService{})}, fakeAuthMW, nil)
+
+	req := testutils.JSONRequest(http.MethodPost, "/api-keys", map[string]any{
+		"name":   "obs script",
+		"scopes": []string{"recordings:write", "Clips:Read"},
+	})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+
+	var resp map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+	if resp["key"] != "hiq_0123456789ab_secret" || resp["prefix"] != "hiq_0123456789ab" {
+		t.Fatalf("unexpected response: %v", resp)
+	}
+}
+
+func TestAPIKeysCreateRejectsUnknownScope(t *testing.T) {
+	h := New(Handlers{APIKeys: apikeyshandlers.New(fakeAPIKeyService{})}, fakeAuthMW, nil)
+
+	req := testutils.JSONRequest(http.MethodPost, "/api-keys", map[string]any{
+		"name":   "bad",
+		"scopes": []string{"everything:write"},
+	})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	recHandler := recordinghandlers.New(fakeRecordingsService{})
+	keysHandler := apikeyshandlers.New(fakeAPIKeyService{})
+	wsHandler := workspaceshandlers.New(fakeWorkspaceService{})
+
+	cases := []struct {
+		name   string
+		scopes []string
+		method string
+		path   string
+		want   int
+	}{
+		{"read scope allows list", []string{"recordings:read"}, http.MethodGet, "/recordings", http.StatusOK},
+		{"write scope implies read", []string{"recordings:write"}, http.MethodGet, "/recordings", http.StatusOK},
+		{"read scope denies write", []string{"recordings:read"}, http.MethodPost, "/recordings/rec-uuid-1/restore", http.StatusForbidden},
+		{"other resource denied", []string{"clips:write"}, http.MethodGet, "/recordings", http.StatusForbidden},
+		{"keys cannot manage keys", []string{"recordings:write"}, http.MethodGet, "/api-keys", http.StatusForbidden},
+		{"keys cannot reach workspaces", []string{"workspaces:read"}, http.MethodGet, "/workspaces", http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := New(Handlers{Recordings: recHandler, APIKeys: keysHandler, Workspaces: wsHandler}, fakeAPIKeyMW(tc.scopes...), nil)
+
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if rr.Code != tc.want {
+				t.Fatalf("expected status %d, got %d; body=%s", tc.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
@@ -6,14 +6,21 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	authhandlers "highlightiq-server/internal/http/handlers/auth"
 	authsvc "highlightiq-server/internal/services/auth"
 	"highlightiq-server/internal/testutils"
 )
 
-func (fakeAuthService) Login(ctx context.Context, in authsvc.LoginInput) (authsvc.RegisterOutput, error) {
-	return authsvc.RegisterOutput{
+func (fakeAuthService) Login(ctx context.Context, in authsvc.LoginInput) (authsvc.LoginOutput, error) {
+	switch in.Email {
+	case "locked@test.com":
+		return authsvc.LoginOutput{}, &authsvc.ThrottleError{RetryAfter: 1500 * time.Millisecond}
+	case "2fa@test.com":
+		return authsvc.LoginOutput{MFARequired: true, ChallengeToken: "challenge-1", ChallengeExpiresIn: 300}, nil
+	}
+	return authsvc.LoginOutput{RegisterOutput: &authsvc.RegisterOutput{
 		User: authsvc.UserDTO{
 			ID:    "test-uuid",
 			Name:  "Test User",
@@ -21,12 +28,12 @@ func (fakeAuthService) Login(ctx context.Context, in authsvc.LoginInput) (authsv
 		},
 		AccessToken: "test-token",
 		TokenType:   "Bearer",
-	}, nil
+	}}, nil
 }
 
 func TestAuthLogin(t *testing.T) {
 	authHandler := authhandlers.New(fakeAuthService{})
-	h := New(authHandler, nil, nil, nil, nil, nil)
+	h := New(Handlers{Auth: authHandler}, nil, nil)
 
 	req := testutils.JSONRequest(http.MethodPost, "/auth/login", map[string]any{
 		"email":    "housam@test.com",
@@ -52,3 +59,21 @@ func TestAuthLogin(t *testing.T) {
 		t.Fatalf("expected user in response")
 	}
 }
+
+func TestAuthLoginThrottled(t *testing.T) {
+	h := New(Handlers{Auth: authhandlers.New(fakeAuthService{})}, nil, nil)
+
+	req := testutils.JSONRequest(http.MethodPost, "/auth/login", map[string]any{
+		"email":    "locked@test.com",
+		"password": "password123",
+	})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusTooManyRequests, rr.Code, rr.Body.String())
+	}
+	if got := rr.Header().Get("Retry-After"); got != "2" {
+		t.Fatalf("expected Retry-After rounded up to 2, got %q", got)
+	}
+}
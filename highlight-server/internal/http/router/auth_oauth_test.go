@@ -0,0 +1,154 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	authhandlers "highlightiq-server/internal/http/handlers/auth"
+	"highlightiq-server/internal/repos/identities"
+	authsvc "highlightiq-server/internal/services/auth"
+	"highlightiq-server/internal/testutils"
+)
+
+func (fakeAuthService) Providers() []string {
+	return []string{"google", "oidc"}
+}
+
+func (fakeAuthService) StartOAuth(ctx context.Context, provider string) (authsvc.OAuthStart, error) {
+	if provider != "google" {
+		return authsvc.OAuthStart{}, authsvc.ErrUnknownProvider
+	}
+	return authsvc.OAuthStart{AuthorizationURL: "https://accounts.example/authorize?state=state-1", State: "state-1", ExpiresIn: 10 * time.Minute}, nil
+}
+
+func (fakeAuthService) CompleteOAuth(ctx context.Context, in authsvc.OAuthCallbackInput) (authsvc.LoginOutput, error) {
+	if in.State != "state-1" {
+		return authsvc.LoginOutput{}, authsvc.ErrInvalidState
+	}
+	switch in.Code {
+	case "existing-unverified":
+		return authsvc.LoginOutput{}, authsvc.ErrAccountExists
+	case "2fa":
+		return authsvc.LoginOutput{MFARequired: true, ChallengeToken: "challenge-1"}, nil
+	}
+	return authsvc.LoginOutput{RegisterOutput: &authsvc.RegisterOutput{AccessToken: "oauth-token", TokenType: "Bearer"}}, nil
+}
+
+func (fakeAuthService) StartLinkIdentity(ctx context.Context, userID int64, provider string) (authsvc.OAuthStart, error) {
+	return authsvc.OAuthStart{AuthorizationURL: "https://accounts.example/authorize?state=l"}, nil
+}
+
+func (fakeAuthService) LinkIdentity(ctx context.Context, in authsvc.LinkIdentityInput) (identities.Identity, error) {
+	return identities.Identity{Provider: in.Provider}, nil
+}
+
+func (fakeAuthService) Identities(ctx context.Context, userID int64) ([]identities.Identity, error) {
+	return []identities.Identity{{Provider: "google"}}, nil
+}
+
+func (fakeAuthService) UnlinkIdentity(ctx context.Context, userID int64, provider string) error {
+	if provider == "google" {
+		return authsvc.ErrLastLoginMethod
+	}
+	return authsvc.ErrNotLinked
+}
+
+func TestOAuthStart(t *testing.T) {
+	h := New(Handlers{Auth: authhandlers.New(fakeAuthService{})}, nil, nil)
+
+	cases := []struct {
+		provider string
+		want     int
+	}{
+		{"google", http.StatusOK},
+		{"myspace", http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.provider, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/auth/oauth/"+tc.provider+"/start", nil)
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if rr.Code != tc.want {
+				t.Fatalf("expected status %d, got %d; body=%s", tc.want, rr.Code, rr.Body.String())
+			}
+			if tc.want != http.StatusOK {
+				return
+			}
+			cookies := rr.Result().Cookies()
+			if len(cookies) != 1 || cookies[0].Value != "state-1" || !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteLaxMode {
+				t.Fatalf("expected an http-only state cookie, got %v", cookies)
+			}
+		})
+	}
+}
+
+func TestOAuthCallback(t *testing.T) {
+	h := New(Handlers{Auth: authhandlers.New(fakeAuthService{})}, nil, nil)
+
+	cases := []struct {
+		name   string
+		body   map[string]any
+		cookie string
+		want   int
+	}{
+		{"signed in", map[string]any{"code": "abc", "state": "state-1"}, "state-1", http.StatusOK},
+		{"two-factor", map[string]any{"code": "2fa", "state": "state-1"}, "state-1", http.StatusOK},
+		{"bad state", map[string]any{"code": "abc", "state": "forged"}, "forged", http.StatusBadRequest},
+		{"started in another browser", map[string]any{"code": "abc", "state": "state-1"}, "", http.StatusBadRequest},
+		{"state of another sign-in", map[string]any{"code": "abc", "state": "state-1"}, "state-2", http.StatusBadRequest},
+		{"unverified local account", map[string]any{"code": "existing-unverified", "state": "state-1"}, "state-1", http.StatusConflict},
+		{"missing code", map[string]any{"state": "state-1"}, "state-1", http.StatusUnprocessableEntity},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := testutils.JSONRequest(http.MethodPost, "/auth/oauth/google/callback", tc.body)
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "oauth_state", Value: tc.cookie})
+			}
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if rr.Code != tc.want {
+				t.Fatalf("expected status %d, got %d; body=%s", tc.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestMeIdentities(t *testing.T) {
+	h := New(Handlers{Auth: authhandlers.New(fakeAuthService{})}, fakeAuthMW, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/me/identities", nil)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Data []map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0]["provider"] != "google" {
+		t.Fatalf("unexpected identities: %s", rr.Body.String())
+	}
+
+	for provider, want := range map[string]int{"google": http.StatusConflict, "twitch": http.StatusNotFound} {
+		req := httptest.NewRequest(http.MethodDelete, "/me/identities/"+provider, nil)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		if rr.Code != want {
+			t.Fatalf("unlink %s: expected status %d, got %d", provider, want, rr.Code)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authhandlers "highlightiq-server/internal/http/handlers/auth"
+	recordinghandlers "highlightiq-server/internal/http/handlers/recordings"
+	"highlightiq-server/internal/http/middleware"
+	authsvc "highlightiq-server/internal/services/auth"
+	"highlightiq-server/internal/testutils"
+)
+
+func (fakeAuthService) VerifyEmail(ctx context.Context, token string) error {
+	if token != "verify-token" {
+		return authsvc.ErrInvalidToken
+	}
+	return nil
+}
+
+func (fakeAuthService) ResendVerification(ctx context.Context, userID int64) error {
+	return nil
+}
+
+func (fakeAuthService) ForgotPassword(ctx context.Context, email string) error {
+	return nil
+}
+
+func (fakeAuthService) ResetPassword(ctx context.Context, token string, password string) error {
+	if token != "reset-token" {
+		return authsvc.ErrInvalidToken
+	}
+	return nil
+}
+
+func unverifiedAuthMW(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := middleware.WithAuthUser(r.Context(), middleware.AuthUser{
+			ID:    1,
+			UUID:  "user-uuid-1",
+			Email: "user@test.com",
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func TestAuthForgotPasswordUnknownEmail(t *testing.T) {
+	h := New(Handlers{Auth: authhandlers.New(fakeAuthService{})}, nil, nil)
+
+	req := testutils.JSONRequest(http.MethodPost, "/auth/password/forgot", map[string]any{
+		"email": "nobody@test.com",
+	})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusAccepted, rr.Code, rr.Body.String())
+	}
+}
+
+func TestAuthResetPassword(t *testing.T) {
+	h := New(Handlers{Auth: authhandlers.New(fakeAuthService{})}, nil, nil)
+
+	cases := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"valid token", "reset-token", http.StatusNoContent},
+		{"used or expired token", "old-token", http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := testutils.JSONRequest(http.MethodPost, "/auth/password/reset", map[string]any{
+				"token":    tc.token,
+				"password": "new-password-123",
+			})
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if rr.Code != tc.want {
+				t.Fatalf("expected status %d, got %d; body=%s", tc.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthVerifyEmail(t *testing.T) {
+	h := New(Handlers{Auth: authhandlers.New(fakeAuthService{})}, nil, nil)
+
+	req := testutils.JSONRequest(http.MethodPost, "/auth/verify-email", map[string]any{
+		"token": "verify-token",
+	})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusNoContent, rr.Code, rr.Body.String())
+	}
+}
+
+func TestUnverifiedUserIsReadOnly(t *testing.T) {
+	recHandler := recordinghandlers.New(fakeRecordingsService{})
+	h := New(Handlers{Auth: authhandlers.New(fakeAuthService{}), Recordings: recHandler}, unverifiedAuthMW, nil)
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{"list recordings", http.MethodGet, "/recordings", http.StatusOK},
+		{"delete recording", http.MethodDelete, "/recordings/rec-uuid-1", http.StatusForbidden},
+		{"resend verification", http.MethodPost, "/auth/verify-email/resend", http.StatusAccepted},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if rr.Code != tc.want {
+				t.Fatalf("expected status %d, got %d; body=%s", tc.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
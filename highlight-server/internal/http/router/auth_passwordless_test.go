@@ -0,0 +1,150 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	accounthandlers "highlightiq-server/internal/http/handlers/account"
+	authhandlers "highlightiq-server/internal/http/handlers/auth"
+	"highlightiq-server/internal/http/middleware"
+	authsvc "highlightiq-server/internal/services/auth"
+	"highlightiq-server/internal/testutils"
+)
+
+// passwordlessUserID signed up through a provider and never set a password.
+const passwordlessUserID = 2
+
+func passwordlessAuthMW(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := middleware.WithAuthUser(r.Context(), middleware.AuthUser{
+			ID:            passwordlessUserID,
+			UUID:          "user-uuid-2",
+			Email:         "oauth@test.com",
+			EmailVerified: true,
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// fakeReauth mirrors auth.Service.reauthenticate: the passwordless user confirms with the code
+// "confirm-ok", everyone else with "password123". The password "locked-out" stands for an
+// account whose failed logins are being throttled.
+func fakeReauth(userID int64, password string, confirmation string) error {
+	if userID != passwordlessUserID {
+		if password == "locked-out" {
+			return &authsvc.ThrottleError{RetryAfter: 30 * time.Second}
+		}
+		if password != "password123" {
+			return authsvc.ErrInvalidCredentials
+		}
+		return nil
+	}
+	switch confirmation {
+	case "":
+		return authsvc.ErrConfirmationRequired
+	case "confirm-ok":
+		return nil
+	}
+	return authsvc.ErrInvalidCredentials
+}
+
+func (fakeAuthService) SendConfirmation(ctx context.Context, userID int64) error {
+	if userID != passwordlessUserID {
+		return authsvc.ErrHasPassword
+	}
+	return nil
+}
+
+func TestMeSendConfirmation(t *testing.T) {
+	for name, tc := range map[string]struct {
+		mw   func(http.Handler) http.Handler
+		want int
+	}{
+		"passwordless": {passwordlessAuthMW, http.StatusAccepted},
+		"has password": {fakeAuthMW, http.StatusConflict},
+	} {
+		t.Run(name, func(t *testing.T) {
+			h := New(Handlers{Auth: authhandlers.New(fakeAuthService{})}, tc.mw, nil)
+
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/me/confirmation", nil))
+			if rr.Code != tc.want {
+				t.Fatalf("expected status %d, got %d; body=%s", tc.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestPasswordlessSensitiveChanges(t *testing.T) {
+	h := New(Handlers{
+		Auth:    authhandlers.New(fakeAuthService{}),
+		Account: accounthandlers.New(fakeAccountService{}),
+	}, passwordlessAuthMW, nil)
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   map[string]any
+		want   int
+	}{
+		{"change email with code", http.MethodPatch, "/me", map[string]any{"email": "new@test.com", "confirmation_code": "confirm-ok"}, http.StatusOK},
+		{"change email with wrong code", http.MethodPatch, "/me", map[string]any{"email": "new@test.com", "confirmation_code": "guess"}, http.StatusBadRequest},
+		{"change email with a password", http.MethodPatch, "/me", map[string]any{"email": "new@test.com", "current_password": "password123"}, http.StatusBadRequest},
+		{"change email without proof", http.MethodPatch, "/me", map[string]any{"email": "new@test.com"}, http.StatusUnprocessableEntity},
+
+		{"set first password", http.MethodPut, "/me/password", map[string]any{"confirmation_code": "confirm-ok", "new_password": "first-password-1"}, http.StatusOK},
+		{"set first password with wrong code", http.MethodPut, "/me/password", map[string]any{"confirmation_code": "guess", "new_password": "first-password-1"}, http.StatusBadRequest},
+		{"set first password without proof", http.MethodPut, "/me/password", map[string]any{"new_password": "first-password-1"}, http.StatusUnprocessableEntity},
+
+		{"disable 2fa with code", http.MethodPost, "/auth/2fa/disable", map[string]any{"confirmation_code": "confirm-ok", "code": "123456"}, http.StatusNoContent},
+		{"disable 2fa with a password", http.MethodPost, "/auth/2fa/disable", map[string]any{"password": "password123", "code": "123456"}, http.StatusBadRequest},
+		{"disable 2fa without proof", http.MethodPost, "/auth/2fa/disable", map[string]any{"code": "123456"}, http.StatusUnprocessableEntity},
+
+		{"delete account with code", http.MethodDelete, "/me", map[string]any{"confirmation_code": "confirm-ok"}, http.StatusNoContent},
+		{"delete account with wrong code", http.MethodDelete, "/me", map[string]any{"confirmation_code": "guess"}, http.StatusBadRequest},
+		{"delete account without proof", http.MethodDelete, "/me", map[string]any{}, http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, testutils.JSONRequest(tc.method, tc.path, tc.body))
+			if rr.Code != tc.want {
+				t.Fatalf("expected status %d, got %d; body=%s", tc.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestSensitiveChangesThrottled(t *testing.T) {
+	h := New(Handlers{
+		Auth:    authhandlers.New(fakeAuthService{}),
+		Account: accounthandlers.New(fakeAccountService{}),
+	}, fakeAuthMW, nil)
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   map[string]any
+	}{
+		{"change email", http.MethodPatch, "/me", map[string]any{"email": "new@test.com", "current_password": "locked-out"}},
+		{"change password", http.MethodPut, "/me/password", map[string]any{"current_password": "locked-out", "new_password": "new-password-1"}},
+		{"disable 2fa", http.MethodPost, "/auth/2fa/disable", map[string]any{"password": "locked-out", "code": "123456"}},
+		{"delete account", http.MethodDelete, "/me", map[string]any{"password": "locked-out"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, testutils.JSONRequest(tc.method, tc.path, tc.body))
+			if rr.Code != http.StatusTooManyRequests {
+				t.Fatalf("expected status %d, got %d; body=%s", http.StatusTooManyRequests, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authhandlers "highlightiq-server/internal/http/handlers/auth"
+	authsvc "highlightiq-server/internal/services/auth"
+	"highlightiq-server/internal/testutils"
+)
+
+func (fakeAuthService) Refresh(ctx context.Context, refreshToken string) (authsvc.RegisterOutput, error) {
+	if refreshToken != "good-refresh" {
+		return authsvc.RegisterOutput{}, authsvc.ErrInvalidRefresh
+	}
+	return authsvc.RegisterOutput{
+		User:         authsvc.UserDTO{ID: "test-uuid"},
+		AccessToken:  "new-access",
+		TokenType:    "Bearer",
+		ExpiresIn:    900,
+		RefreshToken: "next-refresh",
+	}, nil
+}
+
+func (fakeAuthService) Logout(ctx context.Context, in authsvc.LogoutInput) error {
+	return nil
+}
+
+func (fakeAuthService) LogoutAll(ctx context.Context, in authsvc.LogoutInput) error {
+	return nil
+}
+
+func TestAuthRefresh(t *testing.T) {
+	h := New(Handlers{Auth: authhandlers.New(fakeAuthService{})}, nil, nil)
+
+	req := testutils.JSONRequest(http.MethodPost, "/auth/refresh", map[string]any{
+		"refresh_token": "good-refresh",
+	})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var resp map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+	if resp["refresh_token"] != "next-refresh" {
+		t.Fatalf("expected rotated refresh_token, got %v", resp["refresh_token"])
+	}
+}
+
+func TestAuthRefreshRejectsUnknownToken(t *testing.T) {
+	h := New(Handlers{Auth: authhandlers.New(fakeAuthService{})}, nil, nil)
+
+	req := testutils.JSONRequest(http.MethodPost, "/auth/refresh", map[string]any{
+		"refresh_token": "stolen-and-rotated",
+	})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, rr.Code)
+	}
+}
+
+func TestAuthLogout(t *testing.T) {
+	h := New(Handlers{Auth: authhandlers.New(fakeAuthService{})}, fakeAuthMW, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusNoContent, rr.Code, rr.Body.String())
+	}
+}
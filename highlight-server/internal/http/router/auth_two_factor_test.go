@@ -0,0 +1,115 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authhandlers "highlightiq-server/internal/http/handlers/auth"
+	authsvc "highlightiq-server/internal/services/auth"
+	"highlightiq-server/internal/testutils"
+)
+
+func (fakeAuthService) VerifyLogin(ctx context.Context, in authsvc.VerifyLoginInput) (authsvc.RegisterOutput, error) {
+	if in.ChallengeToken != "challenge-1" {
+		return authsvc.RegisterOutput{}, authsvc.ErrInvalidToken
+	}
+	if in.Code != "123456" {
+		return authsvc.RegisterOutput{}, authsvc.ErrInvalidCode
+	}
+	return authsvc.RegisterOutput{AccessToken: "access-after-2fa", TokenType: "Bearer"}, nil
+}
+
+func (fakeAuthService) TwoFactorStatus(ctx context.Context, userID int64) (authsvc.TwoFactorStatus, error) {
+	return authsvc.TwoFactorStatus{}, nil
+}
+
+func (fakeAuthService) SetupTOTP(ctx context.Context, userID int64) (authsvc.TOTPSetup, error) {
+	return authsvc.TOTPSetup{Secret: "JBSWY3DPEHPK3PXP", OTPAuthURI: "otpauth://totp/HighlightIQ:user%40test.com?secret=JBSWY3DPEHPK3PXP"}, nil
+}
+
+func (fakeAuthService) ConfirmTOTP(ctx context.Context, userID int64, code string) (authsvc.RecoveryCodes, error) {
+	if code != "123456" {
+		return authsvc.RecoveryCodes{}, authsvc.ErrInvalidCode
+	}
+	return authsvc.RecoveryCodes{Codes: []string{"abcde-fghjk"}}, nil
+}
+
+func (fakeAuthService) DisableTOTP(ctx context.Context, userID int64, password string, confirmation string, code string) error {
+	return fakeReauth(userID, password, confirmation)
+}
+
+func (fakeAuthService) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) (authsvc.RecoveryCodes, error) {
+	return authsvc.RecoveryCodes{Codes: []string{"abcde-fghjk"}}, nil
+}
+
+func TestAuthLoginWithTwoFactor(t *testing.T) {
+	h := New(Handlers{Auth: authhandlers.New(fakeAuthService{})}, nil, nil)
+
+	req := testutils.JSONRequest(http.MethodPost, "/auth/login", map[string]any{
+		"email":    "2fa@test.com",
+		"password": "password123",
+	})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var resp map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+	if resp["mfa_required"] != true || resp["challenge_token"] != "challenge-1" {
+		t.Fatalf("expected a 2FA challenge, got %v", resp)
+	}
+	if _, ok := resp["access_token"]; ok {
+		t.Fatalf("expected no access_token before the second factor")
+	}
+
+	cases := []struct {
+		name string
+		code string
+		want int
+	}{
+		{"wrong code", "000000", http.StatusUnauthorized},
+		{"valid code", "123456", http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := testutils.JSONRequest(http.MethodPost, "/auth/login/verify", map[string]any{
+				"challenge_token": "challenge-1",
+				"code":            tc.code,
+			})
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if rr.Code != tc.want {
+				t.Fatalf("expected status %d, got %d; body=%s", tc.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestTwoFactorConfirm(t *testing.T) {
+	h := New(Handlers{Auth: authhandlers.New(fakeAuthService{})}, fakeAuthMW, nil)
+
+	req := testutils.JSONRequest(http.MethodPost, "/auth/2fa/confirm", map[string]any{"code": "123456"})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var resp authsvc.RecoveryCodes
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+	if len(resp.Codes) == 0 {
+		t.Fatalf("expected recovery codes in response")
+	}
+}
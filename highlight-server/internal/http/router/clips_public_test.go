@@ -0,0 +1,58 @@
+package router
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	clipshandlers "highlightiq-server/internal/http/handlers/clips"
+	clipssvc "highlightiq-server/internal/services/clips"
+	"highlightiq-server/internal/signedurl"
+)
+
+func newPublicClipsRouter() http.Handler {
+	signer := signedurl.New("test-secret", "http://api.test", time.Hour)
+	svc := clipssvc.New(nil, nil, nil, nil, nil, "", signer, nil, nil, nil)
+	return New(Handlers{Clips: clipshandlers.New(svc, nil)}, nil, nil)
+}
+
+func TestPublicClipDownloadRejectsBadSignature(t *testing.T) {
+	h := newPublicClipsRouter()
+
+	exp := time.Now().Add(time.Hour).Unix()
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/public/clips/7?exp=%d&sig=deadbeef", exp), nil)
+	rr := httptest.NewRecorder()
+
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusForbidden, rr.Code, rr.Body.String())
+	}
+}
+
+func TestPublicClipDownloadRejectsExpiredLink(t *testing.T) {
+	h := newPublicClipsRouter()
+
+	exp := time.Now().Add(-time.Minute).Unix()
+	m := hmac.New(sha256.New, []byte("test-secret"))
+	m.Write([]byte(fmt.Sprintf("/public/clips/7\n%d", exp)))
+	sig := hex.EncodeToString(m.Sum(nil))
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/public/clips/7?exp=%d&sig=%s", exp, sig), nil)
+	rr := httptest.NewRecorder()
+
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status %d, got %d", http.StatusForbidden, rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "expired") {
+		t.Fatalf("expected expired message, got %s", rr.Body.String())
+	}
+}
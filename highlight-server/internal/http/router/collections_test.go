@@ -0,0 +1,216 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	collectionshandlers "highlightiq-server/internal/http/handlers/collections"
+	collectionsrepo "highlightiq-server/internal/repos/collections"
+	collectionssvc "highlightiq-server/internal/services/collections"
+	"highlightiq-server/internal/testutils"
+)
+
+// fakeCollectionsService holds collection 7 with clips 1 and 2; clip 2 has not been exported.
+// Any other collection id is not found.
+type fakeCollectionsService struct{}
+
+var fakeCollectionClips = []int64{1, 2}
+
+func (fakeCollectionsService) collection(id int64) (collectionsrepo.Collection, error) {
+	if id != 7 {
+		return collectionsrepo.Collection{}, collectionssvc.ErrNotFound
+	}
+	return collectionsrepo.Collection{ID: 7, UserID: 1, Title: "Best of", ClipCount: len(fakeCollectionClips)}, nil
+}
+
+func (fakeCollectionsService) Create(ctx context.Context, userID int64, in collectionssvc.CreateInput) (collectionsrepo.Collection, error) {
+	return collectionsrepo.Collection{ID: 8, UserID: userID, Title: in.Title}, nil
+}
+
+func (fakeCollectionsService) List(ctx context.Context, userID int64) ([]collectionsrepo.Collection, error) {
+	return []collectionsrepo.Collection{{ID: 7, UserID: userID, Title: "Best of"}}, nil
+}
+
+func (f fakeCollectionsService) Get(ctx context.Context, userID int64, id int64) (collectionsrepo.Collection, error) {
+	return f.collection(id)
+}
+
+func (f fakeCollectionsService) Update(ctx context.Context, userID int64, id int64, in collectionssvc.UpdateInput) (collectionsrepo.Collection, error) {
+	return f.collection(id)
+}
+
+func (f fakeCollectionsService) Delete(ctx context.Context, userID int64, id int64) error {
+	_, err := f.collection(id)
+	return err
+}
+
+func (f fakeCollectionsService) AddClip(ctx context.Context, userID int64, id int64, clipID int64) (collectionsrepo.Collection, error) {
+	col, err := f.collection(id)
+	if err != nil {
+		return collectionsrepo.Collection{}, err
+	}
+	for _, c := range fakeCollectionClips {
+		if c == clipID {
+			return collectionsrepo.Collection{}, collectionssvc.ErrDuplicate
+		}
+	}
+	col.ClipCount++
+	return col, nil
+}
+
+func (f fakeCollectionsService) RemoveClip(ctx context.Context, userID int64, id int64, clipID int64) error {
+	_, err := f.collection(id)
+	return err
+}
+
+func (f fakeCollectionsService) Reorder(ctx context.Context, userID int64, id int64, clipIDs []int64) (collectionsrepo.Collection, error) {
+	col, err := f.collection(id)
+	if err != nil {
+		return collectionsrepo.Collection{}, err
+	}
+	if len(clipIDs) != len(fakeCollectionClips) {
+		return collectionsrepo.Collection{}, collectionssvc.ErrBadInput
+	}
+	return col, nil
+}
+
+func (f fakeCollectionsService) Export(ctx context.Context, userID int64, id int64) (collectionsrepo.Collection, error) {
+	if _, err := f.collection(id); err != nil {
+		return collectionsrepo.Collection{}, err
+	}
+	return collectionsrepo.Collection{}, collectionssvc.ErrNotReady
+}
+
+func (f fakeCollectionsService) GetExport(ctx context.Context, userID int64, id int64) (string, string, error) {
+	if _, err := f.collection(id); err != nil {
+		return "", "", err
+	}
+	return "", "", collectionssvc.ErrNotReady
+}
+
+func (f fakeCollectionsService) Publish(ctx context.Context, userID int64, id int64) (collectionsrepo.Collection, error) {
+	if _, err := f.collection(id); err != nil {
+		return collectionsrepo.Collection{}, err
+	}
+	return collectionsrepo.Collection{}, collectionssvc.ErrNotReady
+}
+
+func (f fakeCollectionsService) SetPlaylistInternal(ctx context.Context, id int64, playlistID string) (collectionsrepo.Collection, error) {
+	return f.collection(id)
+}
+
+func newCollectionsTestRouter() http.Handler {
+	return New(Handlers{Collections: collectionshandlers.New(fakeCollectionsService{}, nil)}, fakeAuthMW, nil)
+}
+
+func TestCollectionsCreate(t *testing.T) {
+	h := newCollectionsTestRouter()
+
+	cases := []struct {
+		name string
+		body any
+		want int
+	}{
+		{"valid", map[string]any{"title": "Best of"}, http.StatusCreated},
+		{"missing title", map[string]any{"description": "no title"}, http.StatusBadRequest},
+		{"title too long", map[string]any{"title": string(make([]byte, 121))}, http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, testutils.JSONRequest(http.MethodPost, "/collections", tc.body))
+			if rr.Code != tc.want {
+				t.Fatalf("expected status %d, got %d; body=%s", tc.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestCollectionsGetNotFound(t *testing.T) {
+	h := newCollectionsTestRouter()
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/collections/99", nil))
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusNotFound, rr.Code, rr.Body.String())
+	}
+}
+
+func TestCollectionsReorder(t *testing.T) {
+	h := newCollectionsTestRouter()
+
+	cases := []struct {
+		name    string
+		clipIDs []int64
+		want    int
+	}{
+		{"same clips in a new order", []int64{2, 1}, http.StatusOK},
+		{"duplicate clip", []int64{1, 1}, http.StatusBadRequest},
+		{"wrong count", []int64{2}, http.StatusBadRequest},
+		{"empty", []int64{}, http.StatusBadRequest},
+		{"invalid clip id", []int64{0, 1}, http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, testutils.JSONRequest(http.MethodPut, "/collections/7/clips", map[string]any{"clip_ids": tc.clipIDs}))
+			if rr.Code != tc.want {
+				t.Fatalf("expected status %d, got %d; body=%s", tc.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestCollectionsAddClip(t *testing.T) {
+	h := newCollectionsTestRouter()
+
+	cases := []struct {
+		name string
+		path string
+		body any
+		want int
+	}{
+		{"new clip", "/collections/7/clips", map[string]any{"clip_id": 3}, http.StatusOK},
+		{"clip already in collection", "/collections/7/clips", map[string]any{"clip_id": 1}, http.StatusConflict},
+		{"missing clip id", "/collections/7/clips", map[string]any{}, http.StatusBadRequest},
+		{"unknown collection", "/collections/99/clips", map[string]any{"clip_id": 3}, http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, testutils.JSONRequest(http.MethodPost, tc.path, tc.body))
+			if rr.Code != tc.want {
+				t.Fatalf("expected status %d, got %d; body=%s", tc.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestCollectionsNotReady(t *testing.T) {
+	h := newCollectionsTestRouter()
+
+	cases := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/collections/7/export"},
+		{http.MethodPost, "/collections/7/publish"},
+		{http.MethodGet, "/collections/7/download"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(tc.method, tc.path, nil))
+			if rr.Code != http.StatusConflict {
+				t.Fatalf("expected status %d, got %d; body=%s", http.StatusConflict, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pubhandlers "highlightiq-server/internal/http/handlers/publications"
+	"highlightiq-server/internal/http/middleware"
+	"highlightiq-server/internal/reqsign"
+)
+
+func newInternalTestRouter() http.Handler {
+	internalAuth := middleware.NewInternalAuth([]middleware.InternalClient{
+		{Name: "n8n", Secret: "n8n-secret", Routes: []string{"/internal/*"}},
+		{Name: "metrics-bot", Secret: "bot-secret", Routes: []string{"GET /internal/youtube-publishes"}},
+	}, time.Minute)
+
+	// The handler's service is nil: requests that pass auth stop at validation (400).
+	return New(Handlers{Publications: pubhandlers.New(nil)}, nil, internalAuth.Middleware)
+}
+
+func signedMetricsRequest(t *testing.T, client, secret string) *http.Request {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/internal/youtube-publishes/metrics", bytes.NewBufferString(`{}`))
+	if err := reqsign.SignRequest(req, client, secret, time.Now()); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return req
+}
+
+func TestInternalSignedRequest(t *testing.T) {
+	h := newInternalTestRouter()
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, signedMetricsRequest(t, "n8n", "n8n-secret"))
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected signed request to reach the handler (400), got %d; body=%s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestInternalRejectsBadRequests(t *testing.T) {
+	h := newInternalTestRouter()
+
+	t.Run("legacy shared secret", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/internal/youtube-publishes/metrics", bytes.NewBufferString(`{}`))
+		req.Header.Set("X-N8N-SECRET", "n8n-secret")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if rr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected %d, got %d", http.StatusUnauthorized, rr.Code)
+		}
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, signedMetricsRequest(t, "n8n", "guess"))
+		if rr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected %d, got %d", http.StatusUnauthorized, rr.Code)
+		}
+	})
+
+	t.Run("tampered body", func(t *testing.T) {
+		req := signedMetricsRequest(t, "n8n", "n8n-secret")
+		req.Body = io.NopCloser(bytes.NewBufferString(`{"youtube_video_id":"x"}`))
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if rr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected %d, got %d", http.StatusUnauthorized, rr.Code)
+		}
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		req := signedMetricsRequest(t, "n8n", "n8n-secret")
+		replay := req.Clone(req.Context())
+		replay.Body = io.NopCloser(bytes.NewBufferString(`{}`))
+
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, replay)
+		if rr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected %d, got %d", http.StatusUnauthorized, rr.Code)
+		}
+	})
+
+	t.Run("stale timestamp", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/internal/youtube-publishes/metrics", bytes.NewBufferString(`{}`))
+		_ = reqsign.SignRequest(req, "n8n", "n8n-secret", time.Now().Add(-10*time.Minute))
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if rr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected %d, got %d", http.StatusUnauthorized, rr.Code)
+		}
+	})
+
+	t.Run("route not allowed", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, signedMetricsRequest(t, "metrics-bot", "bot-secret"))
+		if rr.Code != http.StatusForbidden {
+			t.Fatalf("expected %d, got %d", http.StatusForbidden, rr.Code)
+		}
+	})
+}
+
+func TestInternalPublicationsValidation(t *testing.T) {
+	h := newInternalTestRouter()
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"list without platform", http.MethodGet, "/internal/publications", ""},
+		{"list unknown platform", http.MethodGet, "/internal/publications?platform=myspace", ""},
+		{"list unknown status", http.MethodGet, "/internal/publications?platform=youtube&status=uploaded,gone", ""},
+		{"list bad last_synced_before", http.MethodGet, "/internal/publications?platform=youtube&last_synced_before=yesterday", ""},
+		{"list bad due", http.MethodGet, "/internal/youtube-publishes?due=soon", ""},
+		{"list zero limit", http.MethodGet, "/internal/youtube-publishes?limit=0", ""},
+		{"list limit too high", http.MethodGet, "/internal/youtube-publishes?limit=5000", ""},
+		{"list bad cursor", http.MethodGet, "/internal/youtube-publishes?limit=100&cursor=abc", ""},
+		{"get unknown platform", http.MethodGet, "/internal/publications/myspace/abc", ""},
+		{"metrics with legacy fields", http.MethodPost, "/internal/publications/metrics", `{"youtube_video_id":"abc","views":1}`},
+		{"create unknown platform", http.MethodPost, "/internal/publications", `{"clip_id":1,"platform":"myspace","external_id":"abc","url":"https://example.com/abc"}`},
+		{"mark deleted without external id", http.MethodPost, "/internal/publications/mark-deleted", `{"platform":"tiktok"}`},
+		{"batch without items", http.MethodPost, "/internal/publications/metrics/batch", `{"items":[]}`},
+		{"batch with bad views", http.MethodPost, "/internal/publications/metrics/batch", `{"items":[{"platform":"x","external_id":"abc","views":"many"}]}`},
+		{"legacy batch without items", http.MethodPost, "/internal/youtube-publishes/metrics/batch", `{}`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.body))
+			if err := reqsign.SignRequest(req, "n8n", "n8n-secret", time.Now()); err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("expected status %d, got %d; body=%s", http.StatusBadRequest, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestInternalMetricsBatchReportsInvalidItems(t *testing.T) {
+	// Every item fails validation, so the nil service is never reached.
+	h := newInternalTestRouter()
+
+	body := `{"items":[
+		{"platform":"myspace","external_id":"abc","views":1},
+		{"platform":"youtube","views":1},
+		{"platform":"tiktok","external_id":"def","likes":-1, "deleted":true}
+	]}`
+	req := httptest.NewRequest(http.MethodPost, "/internal/publications/metrics/batch", bytes.NewBufferString(body))
+	if err := reqsign.SignRequest(req, "n8n", "n8n-secret", time.Now()); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var resp struct {
+		Data []struct {
+			Index  int    `json:"index"`
+			Result string `json:"result"`
+		} `json:"data"`
+		Invalid int `json:"invalid"`
+		Updated int `json:"updated"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+	if resp.Invalid != 3 || resp.Updated != 0 || len(resp.Data) != 3 {
+		t.Fatalf("expected 3 invalid items, got %s", rr.Body.String())
+	}
+	for i, item := range resp.Data {
+		if item.Index != i || item.Result != "invalid" {
+			t.Fatalf("item %d: unexpected result %+v", i, item)
+		}
+	}
+}
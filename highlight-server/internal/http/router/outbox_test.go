@@ -0,0 +1,75 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	outboxhandlers "highlightiq-server/internal/http/handlers/outbox"
+	"highlightiq-server/internal/http/middleware"
+	outboxrepo "highlightiq-server/internal/repos/outbox"
+	"highlightiq-server/internal/reqsign"
+	outboxsvc "highlightiq-server/internal/services/outbox"
+)
+
+// fakeOutboxService: event 1 is dead, event 2 was delivered.
+type fakeOutboxService struct{}
+
+func (fakeOutboxService) List(ctx context.Context, status string) ([]outboxrepo.Event, error) {
+	return []outboxrepo.Event{{ID: 1, Topic: outboxsvc.PublishTopic("youtube"), Status: outboxrepo.StatusDead}}, nil
+}
+
+func (fakeOutboxService) Replay(ctx context.Context, id int64) (outboxrepo.Event, error) {
+	switch id {
+	case 1:
+		return outboxrepo.Event{ID: 1, Status: outboxrepo.StatusPending}, nil
+	case 2:
+		return outboxrepo.Event{}, outboxsvc.ErrNotDead
+	}
+	return outboxrepo.Event{}, outboxsvc.ErrNotFound
+}
+
+func TestOutboxAdmin(t *testing.T) {
+	internalAuth := middleware.NewInternalAuth([]middleware.InternalClient{
+		{Name: "ops", Secret: "ops-secret", Routes: []string{"/internal/outbox*"}},
+	}, time.Minute)
+	h := New(Handlers{Outbox: outboxhandlers.New(fakeOutboxService{})}, nil, internalAuth.Middleware)
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{"list dead", http.MethodGet, "/internal/outbox", http.StatusOK},
+		{"list bad status", http.MethodGet, "/internal/outbox?status=lost", http.StatusBadRequest},
+		{"replay dead", http.MethodPost, "/internal/outbox/1/replay", http.StatusAccepted},
+		{"replay delivered", http.MethodPost, "/internal/outbox/2/replay", http.StatusConflict},
+		{"replay unknown", http.MethodPost, "/internal/outbox/9/replay", http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if err := reqsign.SignRequest(req, "ops", "ops-secret", time.Now()); err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if rr.Code != tc.want {
+				t.Fatalf("expected status %d, got %d; body=%s", tc.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	t.Run("unsigned", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/internal/outbox", nil))
+		if rr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, rr.Code)
+		}
+	})
+}
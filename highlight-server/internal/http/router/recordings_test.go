@@ -3,6 +3,7 @@ package router
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -10,12 +11,13 @@ import (
 	recordinghandlers "highlightiq-server/internal/http/handlers/recordings"
 	"highlightiq-server/internal/http/middleware"
 	recRepo "highlightiq-server/internal/repos/recordings"
+	usagesvc "highlightiq-server/internal/services/usage"
 	"highlightiq-server/internal/testutils"
 )
 
 type fakeRecordingsService struct{}
 
-func (fakeRecordingsService) Create(ctx context.Context, userID int64, title string, originalName string, fileBytes []byte) (recRepo.Recording, error) {
+func (fakeRecordingsService) Create(ctx context.Context, userID int64, workspaceID int64, title string, originalName string, fileBytes []byte) (recRepo.Recording, error) {
 	return recRepo.Recording{
 		ID:           1,
 		UUID:         "rec-uuid-1",
@@ -27,7 +29,7 @@ func (fakeRecordingsService) Create(ctx context.Context, userID int64, title str
 	}, nil
 }
 
-func (fakeRecordingsService) List(ctx context.Context, userID int64) ([]recRepo.Recording, error) {
+func (fakeRecordingsService) List(ctx context.Context, userID int64, workspaceID int64, tag string) ([]recRepo.Recording, error) {
 	return []recRepo.Recording{
 		{
 			ID:           1,
@@ -61,12 +63,20 @@ func (fakeRecordingsService) Delete(ctx context.Context, userID int64, recUUID s
 	return nil
 }
 
+func (fakeRecordingsService) Restore(ctx context.Context, userID int64, recUUID string) error {
+	if recUUID == "rec-uuid-full" {
+		return fmt.Errorf("%w: recording storage", usagesvc.ErrQuotaExceeded)
+	}
+	return nil
+}
+
 func fakeAuthMW(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := middleware.WithAuthUser(r.Context(), middleware.AuthUser{
-			ID:    1,
-			UUID:  "user-uuid-1",
-			Email: "user@test.com",
+			ID:            1,
+			UUID:          "user-uuid-1",
+			Email:         "user@test.com",
+			EmailVerified: true,
 		})
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -78,7 +88,7 @@ type listRecordingsResponse struct {
 
 func TestRecordingsList(t *testing.T) {
 	recHandler := recordinghandlers.New(fakeRecordingsService{})
-	h := New(nil, recHandler, nil, nil, nil, fakeAuthMW)
+	h := New(Handlers{Recordings: recHandler}, fakeAuthMW, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/recordings", nil)
 	rr := httptest.NewRecorder()
@@ -104,7 +114,7 @@ type updateTitlePayload struct {
 
 func TestRecordingsUpdateTitle(t *testing.T) {
 	recHandler := recordinghandlers.New(fakeRecordingsService{})
-	h := New(nil, recHandler, nil, nil, nil, fakeAuthMW) // ✅ fixed: added clipsHandler=nil
+	h := New(Handlers{Recordings: recHandler}, fakeAuthMW, nil) // ✅ fixed: added clipsHandler=nil
 
 	req := testutils.JSONRequest(http.MethodPatch, "/recordings/rec-uuid-1", updateTitlePayload{
 		Title: "new title",
@@ -117,3 +127,29 @@ func TestRecordingsUpdateTitle(t *testing.T) {
 		t.Fatalf("expected status %d, got %d; body=%s", http.StatusOK, rr.Code, rr.Body.String())
 	}
 }
+
+func TestRecordingsRestore(t *testing.T) {
+	recHandler := recordinghandlers.New(fakeRecordingsService{})
+	h := New(Handlers{Recordings: recHandler}, fakeAuthMW, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/recordings/rec-uuid-1/restore", nil)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+}
+
+func TestRecordingsRestoreOverQuota(t *testing.T) {
+	recHandler := recordinghandlers.New(fakeRecordingsService{})
+	h := New(Handlers{Recordings: recHandler}, fakeAuthMW, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/recordings/rec-uuid-full/restore", nil)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusRequestEntityTooLarge, rr.Code, rr.Body.String())
+	}
+}
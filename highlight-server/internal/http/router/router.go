@@ -3,59 +3,152 @@ package router
 import (
 	"net/http"
 
+	accounthandlers "highlightiq-server/internal/http/handlers/account"
+	analyticshandlers "highlightiq-server/internal/http/handlers/analytics"
+	apikeyshandlers "highlightiq-server/internal/http/handlers/apikeys"
 	authhandlers "highlightiq-server/internal/http/handlers/auth"
 	clipcandhandlers "highlightiq-server/internal/http/handlers/clipcandidates"
 	clipshandlers "highlightiq-server/internal/http/handlers/clips"
+	collectionshandlers "highlightiq-server/internal/http/handlers/collections"
+	outboxhandlers "highlightiq-server/internal/http/handlers/outbox"
+	pubhandlers "highlightiq-server/internal/http/handlers/publications"
 	recordinghandlers "highlightiq-server/internal/http/handlers/recordings"
-	yphandlers "highlightiq-server/internal/http/handlers/youtubepublishes"
+	tagshandlers "highlightiq-server/internal/http/handlers/tags"
+	trashhandlers "highlightiq-server/internal/http/handlers/trash"
+	usagehandlers "highlightiq-server/internal/http/handlers/usage"
+	webhookshandlers "highlightiq-server/internal/http/handlers/webhooks"
+	workspaceshandlers "highlightiq-server/internal/http/handlers/workspaces"
+	ytaccounthandlers "highlightiq-server/internal/http/handlers/youtubeaccount"
+	"highlightiq-server/internal/http/middleware"
+	apikeyssvc "highlightiq-server/internal/services/apikeys"
 
 	"github.com/go-chi/chi/v5"
 )
 
-func New(
-	authHandler *authhandlers.Handler,
-	recordingsHandler *recordinghandlers.Handler,
-	clipCandidatesHandler *clipcandhandlers.Handler,
-	clipsHandler *clipshandlers.Handler,
-	youtubePublishesHandler *yphandlers.Handler,
-	authMiddleware func(http.Handler) http.Handler,
-) http.Handler {
+// Handlers are the route groups the router serves. Groups whose handler is nil are not mounted.
+type Handlers struct {
+	Auth           *authhandlers.Handler
+	Recordings     *recordinghandlers.Handler
+	ClipCandidates *clipcandhandlers.Handler
+	Clips          *clipshandlers.Handler
+	Publications   *pubhandlers.Handler
+	Tags           *tagshandlers.Handler
+	Collections    *collectionshandlers.Handler
+	Trash          *trashhandlers.Handler
+	Usage          *usagehandlers.Handler
+	APIKeys        *apikeyshandlers.Handler
+	Workspaces     *workspaceshandlers.Handler
+	Account        *accounthandlers.Handler
+	YouTubeAccount *ytaccounthandlers.Handler
+	Outbox         *outboxhandlers.Handler
+	Webhooks       *webhookshandlers.Handler
+	Analytics      *analyticshandlers.Handler
+}
+
+// New mounts h. Protected routes need authMiddleware, and /internal routes internalMiddleware;
+// without them those routes are not mounted.
+func New(h Handlers, authMiddleware func(http.Handler) http.Handler, internalMiddleware func(http.Handler) http.Handler) http.Handler {
 	r := chi.NewRouter()
 
+	requireScope := middleware.RequireScope(apikeyssvc.Resources)
+
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok"))
 	})
 
 	// Public auth routes
-	if authHandler != nil {
+	if h.Auth != nil {
 		r.Route("/auth", func(r chi.Router) {
-			r.Post("/register", authHandler.Register)
-			r.Post("/login", authHandler.Login)
+			r.Post("/register", h.Auth.Register)
+			r.Post("/login", h.Auth.Login)
+			r.Post("/login/verify", h.Auth.VerifyLogin)
+			r.Post("/refresh", h.Auth.Refresh)
+			r.Post("/verify-email", h.Auth.VerifyEmail)
+			r.Post("/password/forgot", h.Auth.ForgotPassword)
+			r.Post("/password/reset", h.Auth.ResetPassword)
+
+			// Sign in with an external identity provider
+			r.Get("/oauth/providers", h.Auth.OAuthProviders)
+			r.Post("/oauth/{provider}/start", h.Auth.StartOAuth)
+			r.Post("/oauth/{provider}/callback", h.Auth.OAuthCallback)
+
+			if authMiddleware != nil {
+				r.With(authMiddleware, requireScope).Post("/logout", h.Auth.Logout)
+				r.With(authMiddleware, requireScope).Post("/logout-all", h.Auth.LogoutAll)
+				r.With(authMiddleware, requireScope).Post("/verify-email/resend", h.Auth.ResendVerification)
+
+				// Two-factor authentication (TOTP)
+				r.Route("/2fa", func(fr chi.Router) {
+					fr.Use(authMiddleware, requireScope, middleware.RequireVerified)
+					fr.Get("/", h.Auth.TwoFactorStatus)
+					fr.Post("/setup", h.Auth.SetupTOTP)
+					fr.Post("/confirm", h.Auth.ConfirmTOTP)
+					fr.Post("/disable", h.Auth.DisableTOTP)
+					fr.Post("/recovery-codes", h.Auth.RegenerateRecoveryCodes)
+				})
+			}
+		})
+	}
+
+	// Signed public downloads (no JWT; the query string carries an expiring HMAC)
+	if h.Clips != nil {
+		r.Get("/public/clips/{id}", h.Clips.PublicDownload)
+	}
+
+	// Account: profile, password, deletion and export. Unverified users may use these (to fix a
+	// mistyped email, or to leave); API keys may not, see RequireScope.
+	if authMiddleware != nil && (h.Auth != nil || h.Account != nil) {
+		r.Group(func(mr chi.Router) {
+			mr.Use(authMiddleware)
+			mr.Use(requireScope)
+
+			if h.Auth != nil {
+				mr.Get("/me", h.Auth.Me)
+				mr.Patch("/me", h.Auth.UpdateMe)
+				mr.Put("/me/password", h.Auth.ChangePassword)
+				mr.Post("/me/confirmation", h.Auth.SendConfirmation)
+
+				mr.Get("/me/identities", h.Auth.Identities)
+				mr.Post("/me/identities/{provider}/start", h.Auth.StartLinkIdentity)
+				mr.Post("/me/identities/{provider}/callback", h.Auth.LinkIdentity)
+				mr.Delete("/me/identities/{provider}", h.Auth.UnlinkIdentity)
+			}
+			if h.Account != nil {
+				mr.Delete("/me", h.Account.Delete)
+				mr.Get("/me/export", h.Account.Export)
+			}
 		})
 	}
 
-	// Protected routes (JWT required)
+	// Protected routes (JWT or scoped API key required; read-only until the email is verified)
 	if authMiddleware != nil {
 		r.Group(func(pr chi.Router) {
 			pr.Use(authMiddleware)
+			pr.Use(requireScope)
+			pr.Use(middleware.RequireVerified)
 
 			// Recordings CRUD
-			if recordingsHandler != nil {
+			if h.Recordings != nil {
 				pr.Route("/recordings", func(rr chi.Router) {
-					rr.Post("/", recordingsHandler.Create)
-					rr.Get("/", recordingsHandler.List)
+					rr.Post("/", h.Recordings.Create)
+					rr.Get("/", h.Recordings.List)
 
 					rr.Route("/{uuid}", func(r3 chi.Router) {
-						r3.Get("/", recordingsHandler.Get)
-						r3.Patch("/", recordingsHandler.UpdateTitle)
-						r3.Delete("/", recordingsHandler.Delete)
+						r3.Get("/", h.Recordings.Get)
+						r3.Patch("/", h.Recordings.UpdateTitle)
+						r3.Delete("/", h.Recordings.Delete)
+						r3.Post("/restore", h.Recordings.Restore)
+
+						if h.Tags != nil {
+							r3.Put("/tags", h.Tags.SetRecordingTags)
+						}
 
 						// Nested clip candidates for a recording
-						if clipCandidatesHandler != nil {
+						if h.ClipCandidates != nil {
 							r3.Route("/clip-candidates", func(cr chi.Router) {
-								cr.Get("/", clipCandidatesHandler.ListByRecording)
-								cr.Post("/detect", clipCandidatesHandler.Detect)
+								cr.Get("/", h.ClipCandidates.ListByRecording)
+								cr.Post("/detect", h.ClipCandidates.Detect)
 							})
 						}
 					})
@@ -63,51 +156,180 @@ func New(
 			}
 
 			// Candidate actions by id
-			if clipCandidatesHandler != nil {
+			if h.ClipCandidates != nil {
 				pr.Route("/clip-candidates/{id}", func(cr chi.Router) {
-					cr.Patch("/", clipCandidatesHandler.UpdateStatus)
-					cr.Delete("/", clipCandidatesHandler.Delete)
+					cr.Patch("/", h.ClipCandidates.UpdateStatus)
+					cr.Delete("/", h.ClipCandidates.Delete)
 				})
 			}
 
 			// Clips CRUD + export
-			if clipsHandler != nil {
+			if h.Clips != nil {
 				pr.Route("/clips", func(cr chi.Router) {
-					cr.Post("/", clipsHandler.Create)
-					cr.Get("/", clipsHandler.List)
+					cr.Post("/", h.Clips.Create)
+					cr.Get("/", h.Clips.List)
 
 					cr.Route("/{id}", func(r3 chi.Router) {
-						r3.Get("/", clipsHandler.Get)
-						r3.Patch("/", clipsHandler.Update)
-						r3.Delete("/", clipsHandler.Delete)
-						r3.Post("/export", clipsHandler.Export)
-						r3.Get("/download", clipsHandler.Download)
+						r3.Get("/", h.Clips.Get)
+						r3.Patch("/", h.Clips.Update)
+						r3.Delete("/", h.Clips.Delete)
+						r3.Post("/restore", h.Clips.Restore)
+						r3.Post("/export", h.Clips.Export)
+						r3.Get("/download", h.Clips.Download)
+
+						if h.Tags != nil {
+							r3.Put("/tags", h.Tags.SetClipTags)
+						}
 
-						if youtubePublishesHandler != nil {
+						if h.Publications != nil {
+							r3.Post("/publish", h.Publications.Publish)
+							r3.Route("/publications", func(pr chi.Router) {
+								pr.Post("/", h.Publications.Create)
+								pr.Get("/", h.Publications.ListByClip)
+								pr.Get("/metrics", h.Publications.ClipMetrics)
+							})
+							// Deprecated YouTube-only shape
 							r3.Route("/youtube-publishes", func(yr chi.Router) {
-								yr.Post("/", youtubePublishesHandler.Create)
-								yr.Get("/", youtubePublishesHandler.ListByClip)
+								yr.Post("/", h.Publications.YoutubeCreate)
+								yr.Get("/", h.Publications.YoutubeListByClip)
 							})
 						}
 					})
 				})
 			}
 
-			if youtubePublishesHandler != nil {
-				pr.Route("/youtube-publishes/{id}", func(yr chi.Router) {
-					yr.Patch("/", youtubePublishesHandler.Update)
+			if h.Publications != nil {
+				pr.Get("/publications/metrics", h.Publications.UserMetrics)
+				pr.Patch("/publications/{id}", h.Publications.Update)
+				pr.Get("/publications/{id}/metrics", h.Publications.Metrics)
+				pr.Patch("/youtube-publishes/{id}", h.Publications.YoutubeUpdate)
+				pr.Get("/youtube-publishes/{id}/metrics", h.Publications.Metrics)
+			}
+
+			if h.Tags != nil {
+				pr.Get("/tags", h.Tags.List)
+				pr.Delete("/tags/{name}", h.Tags.Delete)
+			}
+
+			// Trash (soft-deleted recordings and clips)
+			if h.Trash != nil {
+				pr.Get("/trash", h.Trash.List)
+				pr.Delete("/trash", h.Trash.Empty)
+			}
+
+			if h.Usage != nil {
+				pr.Get("/me/usage", h.Usage.Get)
+			}
+
+			// Aggregate performance of the user's publications
+			if h.Analytics != nil {
+				pr.Get("/analytics/overview", h.Analytics.Overview)
+			}
+
+			// YouTube channel for the native publisher; managed from a JWT session only
+			if h.YouTubeAccount != nil {
+				pr.Get("/me/youtube", h.YouTubeAccount.Status)
+				pr.Post("/me/youtube/connect", h.YouTubeAccount.StartConnect)
+				pr.Post("/me/youtube/callback", h.YouTubeAccount.CompleteConnect)
+				pr.Delete("/me/youtube", h.YouTubeAccount.Disconnect)
+			}
+
+			// Personal API keys; managed from a JWT session only
+			if h.APIKeys != nil {
+				pr.Route("/api-keys", func(kr chi.Router) {
+					kr.Post("/", h.APIKeys.Create)
+					kr.Get("/", h.APIKeys.List)
+					kr.Delete("/{id}", h.APIKeys.Revoke)
+				})
+			}
+
+			// Outgoing webhooks for workspace events; managed from a JWT session only
+			if h.Webhooks != nil {
+				pr.Route("/webhooks", func(wr chi.Router) {
+					wr.Post("/", h.Webhooks.Create)
+					wr.Get("/", h.Webhooks.List)
+
+					wr.Route("/{id}", func(r3 chi.Router) {
+						r3.Get("/", h.Webhooks.Get)
+						r3.Patch("/", h.Webhooks.Update)
+						r3.Delete("/", h.Webhooks.Delete)
+						r3.Get("/deliveries", h.Webhooks.Deliveries)
+						r3.Post("/ping", h.Webhooks.Ping)
+					})
+				})
+			}
+
+			// Workspaces: shared ownership of recordings, clips and publishes
+			if h.Workspaces != nil {
+				pr.Route("/workspaces", func(wr chi.Router) {
+					wr.Post("/", h.Workspaces.Create)
+					wr.Get("/", h.Workspaces.List)
+					wr.Post("/invitations/accept", h.Workspaces.Accept)
+
+					wr.Route("/{id}", func(r3 chi.Router) {
+						r3.Get("/", h.Workspaces.Get)
+						r3.Patch("/", h.Workspaces.Update)
+						r3.Get("/members", h.Workspaces.Members)
+						r3.Patch("/members/{user_id}", h.Workspaces.UpdateMember)
+						r3.Delete("/members/{user_id}", h.Workspaces.RemoveMember)
+						r3.Post("/invitations", h.Workspaces.Invite)
+						r3.Get("/invitations", h.Workspaces.Invitations)
+						r3.Delete("/invitations/{invitation_id}", h.Workspaces.RevokeInvitation)
+					})
+				})
+			}
+
+			// Collections (ordered playlists of clips)
+			if h.Collections != nil {
+				pr.Route("/collections", func(cr chi.Router) {
+					cr.Post("/", h.Collections.Create)
+					cr.Get("/", h.Collections.List)
+
+					cr.Route("/{id}", func(r3 chi.Router) {
+						r3.Get("/", h.Collections.Get)
+						r3.Patch("/", h.Collections.Update)
+						r3.Delete("/", h.Collections.Delete)
+						r3.Post("/clips", h.Collections.AddClip)
+						r3.Put("/clips", h.Collections.Reorder)
+						r3.Delete("/clips/{clip_id}", h.Collections.RemoveClip)
+						r3.Post("/export", h.Collections.Export)
+						r3.Get("/download", h.Collections.Download)
+						r3.Post("/publish", h.Collections.Publish)
+					})
 				})
 			}
 		})
 	}
 
-	if youtubePublishesHandler != nil {
+	// Internal routes for n8n and workers (signed requests, see package reqsign)
+	if internalMiddleware != nil && (h.Publications != nil || h.Collections != nil || h.Outbox != nil) {
 		r.Route("/internal", func(ir chi.Router) {
-			ir.Get("/youtube-publishes", youtubePublishesHandler.InternalList)
-			ir.Get("/youtube-publishes/{youtube_video_id}", youtubePublishesHandler.InternalGetByVideoID)
-			ir.Post("/youtube-publishes", youtubePublishesHandler.InternalCreate)
-			ir.Post("/youtube-publishes/mark-deleted", youtubePublishesHandler.InternalMarkDeleted)
-			ir.Post("/youtube-publishes/metrics", youtubePublishesHandler.InternalUpdateMetrics)
+			ir.Use(internalMiddleware)
+
+			if h.Publications != nil {
+				ir.Get("/publications", h.Publications.InternalList)
+				ir.Get("/publications/{platform}/{external_id}", h.Publications.InternalGet)
+				ir.Post("/publications", h.Publications.InternalCreate)
+				ir.Post("/publications/mark-deleted", h.Publications.InternalMarkDeleted)
+				ir.Post("/publications/metrics", h.Publications.InternalUpdateMetrics)
+				ir.Post("/publications/metrics/batch", h.Publications.InternalUpdateMetricsBatch)
+
+				// Deprecated YouTube-only shape, still used by the existing n8n workflows
+				ir.Get("/youtube-publishes", h.Publications.YoutubeInternalList)
+				ir.Get("/youtube-publishes/{youtube_video_id}", h.Publications.YoutubeInternalGet)
+				ir.Post("/youtube-publishes", h.Publications.YoutubeInternalCreate)
+				ir.Post("/youtube-publishes/mark-deleted", h.Publications.YoutubeInternalMarkDeleted)
+				ir.Post("/youtube-publishes/metrics", h.Publications.YoutubeInternalUpdateMetrics)
+				ir.Post("/youtube-publishes/metrics/batch", h.Publications.YoutubeInternalUpdateMetricsBatch)
+			}
+			if h.Collections != nil {
+				ir.Post("/collections/playlist", h.Collections.InternalSetPlaylist)
+			}
+			// Webhook deliveries: inspect and replay dead-lettered events
+			if h.Outbox != nil {
+				ir.Get("/outbox", h.Outbox.List)
+				ir.Post("/outbox/{id}/replay", h.Outbox.Replay)
+			}
 		})
 	}
 
@@ -0,0 +1,74 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	recordinghandlers "highlightiq-server/internal/http/handlers/recordings"
+	tagshandlers "highlightiq-server/internal/http/handlers/tags"
+	tagsrepo "highlightiq-server/internal/repos/tags"
+	tagssvc "highlightiq-server/internal/services/tags"
+	"highlightiq-server/internal/testutils"
+)
+
+type fakeTagsService struct{}
+
+func (fakeTagsService) List(ctx context.Context, userID int64) ([]tagsrepo.Tag, error) {
+	return []tagsrepo.Tag{{ID: 1, Name: "clutch", ClipCount: 2}}, nil
+}
+
+func (fakeTagsService) SetRecordingTags(ctx context.Context, userID int64, recUUID string, names []string) ([]string, error) {
+	return tagssvc.Normalize(names), nil
+}
+
+func (fakeTagsService) SetClipTags(ctx context.Context, userID int64, clipID int64, names []string) ([]string, error) {
+	return tagssvc.Normalize(names), nil
+}
+
+func (fakeTagsService) Delete(ctx context.Context, userID int64, name string) error {
+	return nil
+}
+
+func TestTagsList(t *testing.T) {
+	tagsHandler := tagshandlers.New(fakeTagsService{})
+	h := New(Handlers{Tags: tagsHandler}, fakeAuthMW, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/tags", nil)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+}
+
+type setTagsPayload struct {
+	Tags []string `json:"tags"`
+}
+
+func TestRecordingsSetTags(t *testing.T) {
+	recHandler := recordinghandlers.New(fakeRecordingsService{})
+	tagsHandler := tagshandlers.New(fakeTagsService{})
+	h := New(Handlers{Recordings: recHandler, Tags: tagsHandler}, fakeAuthMW, nil)
+
+	req := testutils.JSONRequest(http.MethodPut, "/recordings/rec-uuid-1/tags", setTagsPayload{
+		Tags: []string{" Clutch ", "ranked", "clutch"},
+	})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var resp setTagsPayload
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+	if len(resp.Tags) != 2 || resp.Tags[0] != "clutch" || resp.Tags[1] != "ranked" {
+		t.Fatalf("expected normalized tags [clutch ranked], got %v", resp.Tags)
+	}
+}
@@ -0,0 +1,132 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	clipshandlers "highlightiq-server/internal/http/handlers/clips"
+	trashhandlers "highlightiq-server/internal/http/handlers/trash"
+	clipsrepo "highlightiq-server/internal/repos/clips"
+	recordingsrepo "highlightiq-server/internal/repos/recordings"
+	clipssvc "highlightiq-server/internal/services/clips"
+	trashsvc "highlightiq-server/internal/services/trash"
+	usagesvc "highlightiq-server/internal/services/usage"
+)
+
+type fakeTrashService struct {
+	emptiedBy *int64
+}
+
+func (fakeTrashService) List(ctx context.Context, userID int64) (trashsvc.Trash, error) {
+	deleted := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	return trashsvc.Trash{
+		Recordings:    []recordingsrepo.Recording{{ID: 1, UUID: "rec-1", UserID: userID, Title: "Scrim", DeletedAt: &deleted}},
+		Clips:         []clipsrepo.Clip{{ID: 2, UserID: userID, RecordingID: 1, Title: "Ace", DeletedAt: &deleted}},
+		RetentionDays: 30,
+	}, nil
+}
+
+func (f fakeTrashService) Empty(ctx context.Context, userID int64) (trashsvc.PurgeResult, error) {
+	*f.emptiedBy = userID
+	return trashsvc.PurgeResult{Recordings: 1, Clips: 2}, nil
+}
+
+// fakeClipsService only implements Restore: clip 2 sits under trashed recording 1, clip 3 is
+// restorable, clip 4 does not fit in the export quota and anything else is not in the trash.
+type fakeClipsService struct {
+	clipshandlers.ClipsService
+}
+
+func (fakeClipsService) Restore(ctx context.Context, userID int64, id int64) (clipsrepo.Clip, error) {
+	switch id {
+	case 2:
+		return clipsrepo.Clip{}, clipssvc.ErrRecordingDeleted
+	case 3:
+		return clipsrepo.Clip{ID: 3, UserID: userID, Title: "Clutch"}, nil
+	case 4:
+		return clipsrepo.Clip{}, fmt.Errorf("%w: export storage", usagesvc.ErrQuotaExceeded)
+	}
+	return clipsrepo.Clip{}, clipssvc.ErrNotFound
+}
+
+func TestTrashList(t *testing.T) {
+	h := New(Handlers{Trash: trashhandlers.New(fakeTrashService{})}, fakeAuthMW, nil)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/trash", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var body struct {
+		Recordings    []map[string]any `json:"recordings"`
+		Clips         []map[string]any `json:"clips"`
+		RetentionDays int              `json:"retention_days"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(body.Recordings) != 1 || len(body.Clips) != 1 || body.RetentionDays != 30 {
+		t.Fatalf("unexpected trash listing: %s", rr.Body.String())
+	}
+	if body.Clips[0]["deleted_at"] == nil {
+		t.Fatalf("expected deleted_at on trashed clip, got %v", body.Clips[0])
+	}
+}
+
+func TestTrashEmpty(t *testing.T) {
+	var emptiedBy int64
+	h := New(Handlers{Trash: trashhandlers.New(fakeTrashService{emptiedBy: &emptiedBy})}, fakeAuthMW, nil)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/trash", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if emptiedBy != 1 {
+		t.Fatalf("expected the trash of user 1 to be emptied, got user %d", emptiedBy)
+	}
+
+	var body struct {
+		Purged trashsvc.PurgeResult `json:"purged"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if body.Purged != (trashsvc.PurgeResult{Recordings: 1, Clips: 2}) {
+		t.Fatalf("unexpected purge result %+v", body.Purged)
+	}
+}
+
+func TestClipsRestore(t *testing.T) {
+	h := New(Handlers{Clips: clipshandlers.New(fakeClipsService{}, nil)}, fakeAuthMW, nil)
+
+	cases := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"restorable", "/clips/3/restore", http.StatusOK},
+		{"recording in trash", "/clips/2/restore", http.StatusConflict},
+		{"over quota", "/clips/4/restore", http.StatusPaymentRequired},
+		{"not in trash", "/clips/99/restore", http.StatusNotFound},
+		{"invalid id", "/clips/abc/restore", http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, tc.path, nil))
+			if rr.Code != tc.want {
+				t.Fatalf("expected status %d, got %d; body=%s", tc.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	recordinghandlers "highlightiq-server/internal/http/handlers/recordings"
+	usagehandlers "highlightiq-server/internal/http/handlers/usage"
+	recRepo "highlightiq-server/internal/repos/recordings"
+	usagesvc "highlightiq-server/internal/services/usage"
+)
+
+type fakeUsageService struct{}
+
+func (fakeUsageService) Get(ctx context.Context, userID int64) (usagesvc.Report, error) {
+	return usagesvc.Report{
+		Month:            "2026-10",
+		RecordingBytes:   usagesvc.Meter{Used: 1024, Limit: 4096},
+		ExportBytes:      usagesvc.Meter{Used: 0, Limit: 2048},
+		DetectionMinutes: usagesvc.Meter{Used: 12, Limit: 600},
+	}, nil
+}
+
+// overQuotaRecordingsService rejects every upload as if the user's storage were full.
+type overQuotaRecordingsService struct {
+	fakeRecordingsService
+}
+
+func (overQuotaRecordingsService) Create(ctx context.Context, userID int64, workspaceID int64, title string, originalName string, fileBytes []byte) (recRepo.Recording, error) {
+	return recRepo.Recording{}, fmt.Errorf("%w: recording storage", usagesvc.ErrQuotaExceeded)
+}
+
+func TestMeUsage(t *testing.T) {
+	h := New(Handlers{Usage: usagehandlers.New(fakeUsageService{})}, fakeAuthMW, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/me/usage", nil)
+	rr := httptest.NewRecorder()
+
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var got usagesvc.Report
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if got.RecordingBytes.Used != 1024 || got.DetectionMinutes.Limit != 600 {
+		t.Fatalf("unexpected report: %+v", got)
+	}
+}
+
+func TestRecordingsCreateOverQuota(t *testing.T) {
+	recHandler := recordinghandlers.New(overQuotaRecordingsService{})
+	h := New(Handlers{Recordings: recHandler}, fakeAuthMW, nil)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile("file", "match.mp4")
+	_, _ = fw.Write([]byte("video"))
+	_ = mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/recordings", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rr := httptest.NewRecorder()
+
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status %d, got %d", http.StatusRequestEntityTooLarge, rr.Code)
+	}
+}
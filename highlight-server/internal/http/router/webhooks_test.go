@@ -0,0 +1,116 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	webhookshandlers "highlightiq-server/internal/http/handlers/webhooks"
+	webhooksrepo "highlightiq-server/internal/repos/webhooks"
+	webhookssvc "highlightiq-server/internal/services/webhooks"
+	"highlightiq-server/internal/testutils"
+)
+
+// fakeWebhookService: endpoint 1 exists, everything else is not found.
+type fakeWebhookService struct {
+	webhookshandlers.WebhookService
+}
+
+func (fakeWebhookService) Create(ctx context.Context, userID int64, workspaceID int64, in webhookssvc.CreateInput) (webhookssvc.Created, error) {
+	return webhookssvc.Created{
+		Endpoint: webhooksrepo.Endpoint{ID: 1, URL: in.URL, Events: in.Events, Active: true},
+		Secret:   webhookssvc.SecretPrefix + "test",
+	}, nil
+}
+
+func (fakeWebhookService) Ping(ctx context.Context, userID int64, id int64) (webhooksrepo.Delivery, error) {
+	if id != 1 {
+		return webhooksrepo.Delivery{}, webhookssvc.ErrNotFound
+	}
+	return webhooksrepo.Delivery{ID: 5, EndpointID: id, EventType: webhookssvc.EventPing, Status: webhooksrepo.StatusPending}, nil
+}
+
+func TestWebhooksCreate(t *testing.T) {
+	h := New(Handlers{Webhooks: webhookshandlers.New(fakeWebhookService{})}, fakeAuthMW, nil)
+
+	req := testutils.JSONRequest(http.MethodPost, "/webhooks", map[string]any{
+		"url":    "https://example.com/hooks",
+		"events": []string{"clip.exported", "publish.updated"},
+	})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+
+	var resp map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+	if resp["secret"] != webhookssvc.SecretPrefix+"test" {
+		t.Fatalf("expected signing secret in response, got %v", resp["secret"])
+	}
+}
+
+func TestWebhooksValidation(t *testing.T) {
+	h := New(Handlers{Webhooks: webhookshandlers.New(nil)}, fakeAuthMW, nil)
+
+	cases := []struct {
+		name string
+		body map[string]any
+	}{
+		{"missing url", map[string]any{"events": []string{"clip.exported"}}},
+		{"bad url", map[string]any{"url": "not a url", "events": []string{"clip.exported"}}},
+		{"no events", map[string]any{"url": "https://example.com/hooks", "events": []string{}}},
+		{"unknown event", map[string]any{"url": "https://example.com/hooks", "events": []string{"clip.deleted"}}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, testutils.JSONRequest(http.MethodPost, "/webhooks", tc.body))
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("expected status %d, got %d; body=%s", http.StatusBadRequest, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestWebhooksPing(t *testing.T) {
+	h := New(Handlers{Webhooks: webhookshandlers.New(fakeWebhookService{})}, fakeAuthMW, nil)
+
+	cases := []struct {
+		path string
+		want int
+	}{
+		{"/webhooks/1/ping", http.StatusAccepted},
+		{"/webhooks/9/ping", http.StatusNotFound},
+		{"/webhooks/abc/ping", http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, tc.path, nil))
+			if rr.Code != tc.want {
+				t.Fatalf("expected status %d, got %d; body=%s", tc.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestWebhooksRejectAPIKeys(t *testing.T) {
+	h := New(Handlers{Webhooks: webhookshandlers.New(fakeWebhookService{})}, fakeAPIKeyMW("recordings:write", "clips:write"), nil)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, testutils.JSONRequest(http.MethodPost, "/webhooks", map[string]any{
+		"url":    "https://example.com/hooks",
+		"events": []string{"clip.exported"},
+	}))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusForbidden, rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,125 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	recordinghandlers "highlightiq-server/internal/http/handlers/recordings"
+	workspaceshandlers "highlightiq-server/internal/http/handlers/workspaces"
+	wsrepo "highlightiq-server/internal/repos/workspaces"
+	workspacessvc "highlightiq-server/internal/services/workspaces"
+	"highlightiq-server/internal/testutils"
+)
+
+// fakeWorkspaceService: workspace 1 is owned by the test user, workspace 2 only lets them view.
+type fakeWorkspaceService struct {
+	workspaceshandlers.WorkspaceService
+}
+
+func (fakeWorkspaceService) Invite(ctx context.Context, userID int64, id int64, email string, role string) (workspacessvc.InvitationCreated, error) {
+	if id != 1 {
+		return workspacessvc.InvitationCreated{}, workspacessvc.ErrForbidden
+	}
+	return workspacessvc.InvitationCreated{
+		Invitation: wsrepo.Invitation{ID: 7, WorkspaceID: id, Email: email, Role: role},
+		Token:      "invite-token",
+	}, nil
+}
+
+func (fakeWorkspaceService) AcceptInvitation(ctx context.Context, userID int64, email string, token string) (wsrepo.Workspace, error) {
+	if token != "invite-token" || email != "user@test.com" {
+		return wsrepo.Workspace{}, workspacessvc.ErrInvitationInvalid
+	}
+	return wsrepo.Workspace{ID: 1, Name: "Team", Role: wsrepo.RoleEditor}, nil
+}
+
+type viewerRecordingsService struct {
+	fakeRecordingsService
+}
+
+func (viewerRecordingsService) Delete(ctx context.Context, userID int64, recUUID string) error {
+	return workspacessvc.ErrForbidden
+}
+
+func TestWorkspacesInvite(t *testing.T) {
+	h := New(Handlers{Workspaces: workspaceshandlers.New(fakeWorkspaceService{})}, fakeAuthMW, nil)
+
+	req := testutils.JSONRequest(http.MethodPost, "/workspaces/1/invitations", map[string]any{
+		"email": "friend@test.com",
+		"role":  "editor",
+	})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+
+	var resp map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+	if resp["token"] != "invite-token" {
+		t.Fatalf("expected invitation token in response, got %v", resp["token"])
+	}
+}
+
+func TestWorkspacesInviteRequiresOwner(t *testing.T) {
+	h := New(Handlers{Workspaces: workspaceshandlers.New(fakeWorkspaceService{})}, fakeAuthMW, nil)
+
+	req := testutils.JSONRequest(http.MethodPost, "/workspaces/2/invitations", map[string]any{
+		"email": "friend@test.com",
+		"role":  "viewer",
+	})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusForbidden, rr.Code, rr.Body.String())
+	}
+}
+
+func TestWorkspacesInviteRejectsOwnerRole(t *testing.T) {
+	h := New(Handlers{Workspaces: workspaceshandlers.New(fakeWorkspaceService{})}, fakeAuthMW, nil)
+
+	req := testutils.JSONRequest(http.MethodPost, "/workspaces/1/invitations", map[string]any{
+		"email": "friend@test.com",
+		"role":  "owner",
+	})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestWorkspacesAcceptInvitation(t *testing.T) {
+	h := New(Handlers{Workspaces: workspaceshandlers.New(fakeWorkspaceService{})}, fakeAuthMW, nil)
+
+	req := testutils.JSONRequest(http.MethodPost, "/workspaces/invitations/accept", map[string]any{
+		"token": "invite-token",
+	})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+}
+
+func TestRecordingsDeleteAsViewer(t *testing.T) {
+	recHandler := recordinghandlers.New(viewerRecordingsService{})
+	h := New(Handlers{Recordings: recHandler}, fakeAuthMW, nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/recordings/rec-uuid-1", nil)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusForbidden, rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,72 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ytaccounthandlers "highlightiq-server/internal/http/handlers/youtubeaccount"
+	ytpublishersvc "highlightiq-server/internal/services/youtubepublisher"
+	"highlightiq-server/internal/testutils"
+)
+
+type fakeYoutubeAccountService struct{}
+
+func (fakeYoutubeAccountService) Status(ctx context.Context, userID int64) (ytpublishersvc.Connection, error) {
+	return ytpublishersvc.Connection{Connected: true, ChannelID: "UC123", ChannelTitle: "Clips"}, nil
+}
+
+func (fakeYoutubeAccountService) StartConnect(ctx context.Context, userID int64) (ytpublishersvc.ConnectStart, error) {
+	return ytpublishersvc.ConnectStart{AuthorizationURL: "https://accounts.example/authorize"}, nil
+}
+
+func (fakeYoutubeAccountService) CompleteConnect(ctx context.Context, userID int64, code string, state string) (ytpublishersvc.Connection, error) {
+	if state != "state-1" {
+		return ytpublishersvc.Connection{}, ytpublishersvc.ErrInvalidState
+	}
+	return ytpublishersvc.Connection{Connected: true, ChannelID: "UC123"}, nil
+}
+
+func (fakeYoutubeAccountService) Disconnect(ctx context.Context, userID int64) error {
+	return ytpublishersvc.ErrNotConnected
+}
+
+func TestYoutubeConnect(t *testing.T) {
+	h := New(Handlers{YouTubeAccount: ytaccounthandlers.New(fakeYoutubeAccountService{})}, fakeAuthMW, nil)
+
+	cases := []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{"status", httptest.NewRequest(http.MethodGet, "/me/youtube", nil), http.StatusOK},
+		{"start", httptest.NewRequest(http.MethodPost, "/me/youtube/connect", nil), http.StatusOK},
+		{"callback", testutils.JSONRequest(http.MethodPost, "/me/youtube/callback", map[string]any{"code": "c", "state": "state-1"}), http.StatusOK},
+		{"callback with a foreign state", testutils.JSONRequest(http.MethodPost, "/me/youtube/callback", map[string]any{"code": "c", "state": "other"}), http.StatusBadRequest},
+		{"disconnect without a channel", httptest.NewRequest(http.MethodDelete, "/me/youtube", nil), http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, tc.req)
+
+			if rr.Code != tc.want {
+				t.Fatalf("expected status %d, got %d; body=%s", tc.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestYoutubeConnectClosedToAPIKeys(t *testing.T) {
+	h := New(Handlers{YouTubeAccount: ytaccounthandlers.New(fakeYoutubeAccountService{})}, fakeAPIKeyMW("clips:write", "youtube-publishes:write"), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/me/youtube", nil)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status %d, got %d", http.StatusForbidden, rr.Code)
+	}
+}
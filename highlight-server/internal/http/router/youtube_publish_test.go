@@ -0,0 +1,83 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	clipshandlers "highlightiq-server/internal/http/handlers/clips"
+	pubhandlers "highlightiq-server/internal/http/handlers/publications"
+	"highlightiq-server/internal/testutils"
+)
+
+func TestClipPublishValidation(t *testing.T) {
+	// The services are nil: every case must be rejected before reaching them.
+	h := New(Handlers{Clips: clipshandlers.New(nil, nil), Publications: pubhandlers.New(nil)}, fakeAuthMW, nil)
+
+	cases := []struct {
+		name string
+		path string
+		body map[string]any
+	}{
+		{"bad clip id", "/clips/abc/publish", map[string]any{}},
+		{"unknown platform", "/clips/1/publish", map[string]any{"platform": "myspace"}},
+		{"unknown privacy", "/clips/1/publish", map[string]any{"privacy": "friends"}},
+		{"empty title", "/clips/1/publish", map[string]any{"title": ""}},
+		{"bad publish_at", "/clips/1/publish", map[string]any{"publish_at": "tomorrow"}},
+		{"empty tag", "/clips/1/publish", map[string]any{"tags": []string{"ok", ""}}},
+		{"publication without platform", "/clips/1/publications", map[string]any{"external_id": "abc", "url": "https://example.com/abc"}},
+		{"legacy publish without video id", "/clips/1/youtube-publishes", map[string]any{"youtube_url": "https://youtu.be/abc"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := testutils.JSONRequest(http.MethodPost, tc.path, tc.body)
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("expected status %d, got %d; body=%s", http.StatusBadRequest, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestClipPublishNeedsVerifiedEmail(t *testing.T) {
+	h := New(Handlers{Clips: clipshandlers.New(nil, nil), Publications: pubhandlers.New(nil)}, unverifiedAuthMW, nil)
+
+	req := testutils.JSONRequest(http.MethodPost, "/clips/1/publish", map[string]any{"privacy": "public"})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status %d, got %d; body=%s", http.StatusForbidden, rr.Code, rr.Body.String())
+	}
+}
+
+func TestPublicationMetricsValidation(t *testing.T) {
+	// The services are nil: every case must be rejected before reaching them.
+	h := New(Handlers{Clips: clipshandlers.New(nil, nil), Publications: pubhandlers.New(nil)}, fakeAuthMW, nil)
+
+	cases := []struct {
+		name string
+		path string
+	}{
+		{"bad publication id", "/publications/abc/metrics"},
+		{"bad from", "/publications/1/metrics?from=last-week"},
+		{"bad to", "/youtube-publishes/1/metrics?to=2026-13-01"},
+		{"from after to", "/publications/1/metrics?from=2026-03-10&to=2026-03-01"},
+		{"range too long", "/publications/metrics?from=2024-01-01&to=2026-01-01"},
+		{"bad clip id", "/clips/abc/publications/metrics"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("expected status %d, got %d; body=%s", http.StatusBadRequest, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
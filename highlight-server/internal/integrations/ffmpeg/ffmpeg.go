@@ -0,0 +1,41 @@
+package ffmpeg
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ResolvePath finds the ffmpeg binary to use.
+func ResolvePath() string {
+	// 1) Allow explicit override (recommended on Windows)
+	// Can be either:
+	//   - full path to ffmpeg.exe
+	//   - directory that contains ffmpeg.exe
+	if v := strings.TrimSpace(os.Getenv("FFMPEG_PATH")); v != "" {
+		// If it's a directory, append ffmpeg(.exe)
+		if st, err := os.Stat(v); err == nil && st.IsDir() {
+			if runtime.GOOS == "windows" {
+				return filepath.Join(v, "ffmpeg.exe")
+			}
+			return filepath.Join(v, "ffmpeg")
+		}
+		return v
+	}
+
+	// 2) Try PATH lookup
+	// On Windows, try both "ffmpeg" and "ffmpeg.exe"
+	if p, err := exec.LookPath("ffmpeg"); err == nil {
+		return p
+	}
+	if runtime.GOOS == "windows" {
+		if p, err := exec.LookPath("ffmpeg.exe"); err == nil {
+			return p
+		}
+	}
+
+	// 3) Fallback (will error at runtime with a clear message)
+	return "ffmpeg"
+}
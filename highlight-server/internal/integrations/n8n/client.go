@@ -4,12 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
-
-	clipsrepo "highlightiq-server/internal/repos/clips"
 )
 
 type Client struct {
@@ -28,29 +27,33 @@ func New(webhookURL string, authHeader string) *Client {
 	}
 }
 
-type PublishPayload struct {
-	ClipID      int64    `json:"clip_id"`
-	ClipURL     string   `json:"clip_url"`
-	Title       string   `json:"title"`
-	Description *string  `json:"description,omitempty"`
-	Ratio       string   `json:"ratio,omitempty"`
-	Tags        []string `json:"tags,omitempty"`
-}
-
-func (c *Client) NotifyClipExported(ctx context.Context, clip clipsrepo.Clip, clipURL string) error {
+// Send posts an outbox event's payload to the webhook. It implements outbox.Sender.
+func (c *Client) Send(ctx context.Context, payload json.RawMessage) error {
 	if c == nil || c.webhookURL == "" {
-		return nil
+		return errors.New("n8n: webhook is not configured")
 	}
+	return c.post(ctx, payload)
+}
 
-	payload := PublishPayload{
-		ClipID:      clip.ID,
-		ClipURL:     clipURL,
-		Title:       clip.Title,
-		Description: clip.Caption,
-		Ratio:       "",
-		Tags:        nil,
+// StatusError is a webhook response with an error status.
+type StatusError struct {
+	Code int
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body != "" {
+		return fmt.Sprintf("n8n returned status %d: %s", e.Code, e.Body)
 	}
+	return fmt.Sprintf("n8n returned status %d", e.Code)
+}
+
+// Temporary reports whether retrying could help: server errors, timeouts and rate limits.
+func (e *StatusError) Temporary() bool {
+	return e.Code >= 500 || e.Code == http.StatusRequestTimeout || e.Code == http.StatusTooManyRequests
+}
 
+func (c *Client) post(ctx context.Context, payload any) error {
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("marshal n8n payload: %w", err)
@@ -73,10 +76,7 @@ func (c *Client) NotifyClipExported(ctx context.Context, clip clipsrepo.Clip, cl
 
 	if res.StatusCode >= 400 {
 		b, _ := io.ReadAll(io.LimitReader(res.Body, 8<<10))
-		if len(b) > 0 {
-			return fmt.Errorf("n8n returned status %d: %s", res.StatusCode, string(b))
-		}
-		return fmt.Errorf("n8n returned status %d", res.StatusCode)
+		return &StatusError{Code: res.StatusCode, Body: string(b)}
 	}
 
 	return nil
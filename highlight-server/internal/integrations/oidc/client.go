@@ -0,0 +1,340 @@
+// Package oidc is a small OAuth 2.0 / OpenID Connect client for "sign in with" providers. It
+// covers the authorization code flow with PKCE and reads the user from the userinfo endpoint,
+// which is authenticated by the access token obtained directly from the provider.
+package oidc
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrNoSubject = errors.New("oidc: userinfo has no subject")
+
+// Claims names the userinfo fields a provider uses. Name lists candidates in order of preference.
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified string
+	Name          []string
+}
+
+var standardClaims = Claims{
+	Subject:       "sub",
+	Email:         "email",
+	EmailVerified: "email_verified",
+	Name:          []string{"name", "preferred_username"},
+}
+
+type Config struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	// Issuer enables discovery: endpoints left empty are read from
+	// <Issuer>/.well-known/openid-configuration on first use.
+	Issuer      string
+	AuthURL     string
+	TokenURL    string
+	UserInfoURL string
+
+	// AuthParams are extra query parameters for the authorization request.
+	AuthParams map[string]string
+	// Claims defaults to the standard OIDC claim names.
+	Claims *Claims
+}
+
+// Identity is the user as the provider describes them.
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type Provider struct {
+	cfg  Config
+	http *http.Client
+
+	mu         sync.Mutex
+	discovered bool
+}
+
+func New(cfg Config) *Provider {
+	if cfg.Claims == nil {
+		c := standardClaims
+		cfg.Claims = &c
+	}
+	return &Provider{
+		cfg: cfg,
+		http: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// Google, Discord and Twitch return the settings for the providers we support out of the box.
+
+func Google(clientID, clientSecret, redirectURL string) Config {
+	return Config{
+		Name:         "google",
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+		AuthURL:      "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL:     "https://oauth2.googleapis.com/token",
+		UserInfoURL:  "https://openidconnect.googleapis.com/v1/userinfo",
+	}
+}
+
+// Discord speaks plain OAuth 2.0; its user object stands in for userinfo.
+func Discord(clientID, clientSecret, redirectURL string) Config {
+	return Config{
+		Name:         "discord",
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"identify", "email"},
+		AuthURL:      "https://discord.com/oauth2/authorize",
+		TokenURL:     "https://discord.com/api/oauth2/token",
+		UserInfoURL:  "https://discord.com/api/users/@me",
+		Claims: &Claims{
+			Subject:       "id",
+			Email:         "email",
+			EmailVerified: "verified",
+			Name:          []string{"global_name", "username"},
+		},
+	}
+}
+
+// Twitch only puts the email into userinfo when it is asked for through the claims parameter.
+func Twitch(clientID, clientSecret, redirectURL string) Config {
+	return Config{
+		Name:         "twitch",
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "user:read:email"},
+		AuthURL:      "https://id.twitch.tv/oauth2/authorize",
+		TokenURL:     "https://id.twitch.tv/oauth2/token",
+		UserInfoURL:  "https://id.twitch.tv/oauth2/userinfo",
+		AuthParams: map[string]string{
+			"claims": `{"userinfo":{"email":null,"email_verified":null,"preferred_username":null}}`,
+		},
+	}
+}
+
+// NewPKCE returns a code verifier and its S256 challenge.
+func NewPKCE() (verifier string, challenge string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	verifier = base64.RawURLEncoding.EncodeToString(b)
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// AuthCodeURL is where the browser goes to sign in. The provider sends it back to RedirectURL
+// with the code and the state unchanged.
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, codeChallenge string) (string, error) {
+	if err := p.discover(ctx); err != nil {
+		return "", err
+	}
+
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", p.cfg.RedirectURL)
+	q.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	q.Set("state", state)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	for k, v := range p.cfg.AuthParams {
+		q.Set(k, v)
+	}
+
+	sep := "?"
+	if strings.Contains(p.cfg.AuthURL, "?") {
+		sep = "&"
+	}
+	return p.cfg.AuthURL + sep + q.Encode(), nil
+}
+
+// Exchange trades the authorization code for an access token.
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string) (string, error) {
+	if err := p.discover(ctx); err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("client_secret", p.cfg.ClientSecret)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var out struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}
+	if err := p.do(req, &out); err != nil {
+		return "", err
+	}
+	if out.AccessToken == "" {
+		return "", fmt.Errorf("oidc: %s token response has no access_token (%s)", p.cfg.Name, out.Error)
+	}
+	return out.AccessToken, nil
+}
+
+// UserInfo fetches the signed-in user with the access token from Exchange.
+func (p *Provider) UserInfo(ctx context.Context, accessToken string) (Identity, error) {
+	if err := p.discover(ctx); err != nil {
+		return Identity{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.UserInfoURL, nil)
+	if err != nil {
+		return Identity{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	var raw map[string]any
+	if err := p.do(req, &raw); err != nil {
+		return Identity{}, err
+	}
+
+	c := p.cfg.Claims
+	id := Identity{
+		Subject:       claimString(raw[c.Subject]),
+		Email:         claimString(raw[c.Email]),
+		EmailVerified: claimBool(raw[c.EmailVerified]),
+	}
+	for _, name := range c.Name {
+		if v := claimString(raw[name]); v != "" {
+			id.Name = v
+			break
+		}
+	}
+	if id.Subject == "" {
+		return Identity{}, ErrNoSubject
+	}
+	return id, nil
+}
+
+func (p *Provider) do(req *http.Request, out any) error {
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("oidc: %s %s returned %d: %s", p.cfg.Name, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	return dec.Decode(out)
+}
+
+// discover fills in missing endpoints from the issuer's metadata, once.
+func (p *Provider) discover(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovered || p.cfg.Issuer == "" || (p.cfg.AuthURL != "" && p.cfg.TokenURL != "" && p.cfg.UserInfoURL != "") {
+		return nil
+	}
+
+	u := strings.TrimRight(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	var meta struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	}
+	if err := p.do(req, &meta); err != nil {
+		return err
+	}
+	if strings.TrimRight(meta.Issuer, "/") != strings.TrimRight(p.cfg.Issuer, "/") {
+		return fmt.Errorf("oidc: %s discovery returned issuer %q", p.cfg.Name, meta.Issuer)
+	}
+
+	if p.cfg.AuthURL == "" {
+		p.cfg.AuthURL = meta.AuthorizationEndpoint
+	}
+	if p.cfg.TokenURL == "" {
+		p.cfg.TokenURL = meta.TokenEndpoint
+	}
+	if p.cfg.UserInfoURL == "" {
+		p.cfg.UserInfoURL = meta.UserinfoEndpoint
+	}
+	if p.cfg.AuthURL == "" || p.cfg.TokenURL == "" || p.cfg.UserInfoURL == "" {
+		return fmt.Errorf("oidc: %s discovery is missing endpoints", p.cfg.Name)
+	}
+
+	p.discovered = true
+	return nil
+}
+
+func claimString(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	default:
+		return ""
+	}
+}
+
+// claimBool accepts true and "true"; some providers send booleans as strings.
+func claimBool(v any) bool {
+	switch t := v.(type) {
+	case bool:
+		return t
+	case string:
+		b, _ := strconv.ParseBool(t)
+		return b
+	default:
+		return false
+	}
+}
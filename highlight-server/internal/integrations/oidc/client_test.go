@@ -0,0 +1,123 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// mockProvider is a minimal OIDC provider: discovery, a token endpoint that checks the PKCE
+// verifier against the challenge sent to /authorize, and userinfo.
+func mockProvider(t *testing.T, userinfo map[string]any) (*httptest.Server, func(challenge string) string) {
+	t.Helper()
+
+	codes := map[string]string{} // code -> challenge
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 srv.URL,
+			"authorization_endpoint": srv.URL + "/authorize",
+			"token_endpoint":         srv.URL + "/token",
+			"userinfo_endpoint":      srv.URL + "/userinfo",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		challenge, ok := codes[r.PostForm.Get("code")]
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != challenge || r.PostForm.Get("client_secret") != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "at-1", "token_type": "Bearer"})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer at-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(userinfo)
+	})
+
+	authorize := func(challenge string) string {
+		code := "code-" + challenge[:8]
+		codes[code] = challenge
+		return code
+	}
+	return srv, authorize
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	srv, authorize := mockProvider(t, map[string]any{
+		"sub":            "user-42",
+		"email":          "streamer@test.com",
+		"email_verified": true,
+		"name":           "Streamer",
+	})
+
+	p := New(Config{Name: "oidc", Issuer: srv.URL, ClientID: "client", ClientSecret: "secret", RedirectURL: "http://app/cb", Scopes: []string{"openid", "email"}})
+	ctx := context.Background()
+
+	verifier, challenge, err := NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := p.AuthCodeURL(ctx, "state-1", challenge)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	u, _ := url.Parse(raw)
+	if u.Path != "/authorize" || u.Query().Get("state") != "state-1" || u.Query().Get("code_challenge_method") != "S256" {
+		t.Fatalf("unexpected authorization url %s", raw)
+	}
+
+	code := authorize(u.Query().Get("code_challenge"))
+
+	if _, err := p.Exchange(ctx, code, "wrong-verifier"); err == nil {
+		t.Fatalf("expected exchange with a wrong verifier to fail")
+	}
+
+	token, err := p.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	id, err := p.UserInfo(ctx, token)
+	if err != nil {
+		t.Fatalf("UserInfo: %v", err)
+	}
+	want := Identity{Subject: "user-42", Email: "streamer@test.com", EmailVerified: true, Name: "Streamer"}
+	if id != want {
+		t.Fatalf("got %+v, want %+v", id, want)
+	}
+}
+
+func TestUserInfoCustomClaims(t *testing.T) {
+	srv, _ := mockProvider(t, map[string]any{
+		"id":          "80351110224678912",
+		"username":    "nelly",
+		"global_name": nil,
+		"email":       "nelly@test.com",
+		"verified":    true,
+	})
+
+	cfg := Discord("client", "secret", "http://app/cb")
+	cfg.UserInfoURL = srv.URL + "/userinfo"
+	id, err := New(cfg).UserInfo(context.Background(), "at-1")
+	if err != nil {
+		t.Fatalf("UserInfo: %v", err)
+	}
+	if id.Subject != "80351110224678912" || id.Name != "nelly" || !id.EmailVerified {
+		t.Fatalf("unexpected identity %+v", id)
+	}
+}
@@ -0,0 +1,148 @@
+// Package webhook posts signed event payloads to endpoints users registered.
+//
+// Every request carries the event type, the delivery id and a signature receivers should check
+// before trusting the body:
+//
+//	X-HighlightIQ-Signature: v1=hex(HMAC-SHA256(secret, TIMESTAMP + "." + body))
+//
+// where TIMESTAMP is the X-HighlightIQ-Timestamp header (unix seconds). Receivers should also
+// reject timestamps more than a few minutes old.
+//
+// Endpoints must be https and may not resolve to loopback, private or link-local addresses; see
+// Blocked. NewUnrestricted lifts both rules for local development.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderEvent     = "X-HighlightIQ-Event"
+	HeaderDelivery  = "X-HighlightIQ-Delivery"
+	HeaderTimestamp = "X-HighlightIQ-Timestamp"
+	HeaderSignature = "X-HighlightIQ-Signature"
+)
+
+type Client struct {
+	http         *http.Client
+	resolver     *net.Resolver
+	unrestricted bool
+}
+
+// New returns a client that only calls https endpoints on public addresses.
+func New() *Client {
+	return newClient(false)
+}
+
+// NewUnrestricted returns a client that also calls plain http endpoints and private, loopback
+// and link-local addresses. It is meant for local development and tests only.
+func NewUnrestricted() *Client {
+	return newClient(true)
+}
+
+func newClient(unrestricted bool) *Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !unrestricted {
+		dialer.Control = checkDial
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// A proxy would make the connection for us, out of reach of the dial check.
+	transport.Proxy = nil
+
+	return &Client{
+		resolver:     net.DefaultResolver,
+		unrestricted: unrestricted,
+		http: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: transport,
+			// A redirect would resend the signed body somewhere the user did not register.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Request is one delivery attempt.
+type Request struct {
+	URL        string
+	Secret     string
+	EventType  string
+	DeliveryID int64
+	Body       []byte
+}
+
+// Response is what the endpoint answered. Body is cut to the first 1 KiB and only kept for 2xx
+// responses: an error page may echo whatever the endpoint (or a host behind it) chose to reveal.
+type Response struct {
+	StatusCode int
+	Body       string
+}
+
+// StatusError is a response outside 2xx.
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("endpoint returned status %d", e.Code)
+}
+
+// Temporary reports whether retrying could help: server errors, timeouts and rate limits.
+// Anything else (a 404, a 3xx) will keep failing until the endpoint is fixed.
+func (e *StatusError) Temporary() bool {
+	return e.Code >= 500 || e.Code == http.StatusRequestTimeout || e.Code == http.StatusTooManyRequests
+}
+
+// Sign returns the hex signature of body sent at timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	m.Write(body)
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// Post sends a signed delivery. A response outside 2xx is returned along with a *StatusError.
+func (c *Client) Post(ctx context.Context, in Request) (Response, error) {
+	u, err := url.Parse(in.URL)
+	if err != nil || !c.schemeAllowed(u.Scheme) {
+		return Response{}, ErrInvalidURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, in.URL, bytes.NewReader(in.Body))
+	if err != nil {
+		return Response{}, fmt.Errorf("create webhook request: %w", err)
+	}
+
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "HighlightIQ-Webhooks/1")
+	req.Header.Set(HeaderEvent, in.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(in.DeliveryID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, "v1="+Sign(in.Secret, ts, in.Body))
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return Response{}, fmt.Errorf("call webhook: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return Response{StatusCode: res.StatusCode}, &StatusError{Code: res.StatusCode}
+	}
+	b, _ := io.ReadAll(io.LimitReader(res.Body, 1<<10))
+	return Response{StatusCode: res.StatusCode, Body: string(b)}, nil
+}
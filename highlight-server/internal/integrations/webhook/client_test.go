@@ -0,0 +1,143 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestPostSignsBody(t *testing.T) {
+	body := []byte(`{"type":"clip.exported"}`)
+	var got *http.Request
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	res, err := NewUnrestricted().Post(context.Background(), Request{URL: srv.URL, Secret: "whsec_test", EventType: "clip.exported", DeliveryID: 42, Body: body})
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	if res.StatusCode != http.StatusOK || res.Body != "ok" {
+		t.Fatalf("unexpected response %+v", res)
+	}
+
+	if got.Header.Get(HeaderEvent) != "clip.exported" || got.Header.Get(HeaderDelivery) != "42" {
+		t.Fatalf("unexpected headers %v", got.Header)
+	}
+	ts, err := strconv.ParseInt(got.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("bad timestamp header: %v", err)
+	}
+	if want := "v1=" + Sign("whsec_test", ts, gotBody); got.Header.Get(HeaderSignature) != want {
+		t.Fatalf("signature %q, want %q", got.Header.Get(HeaderSignature), want)
+	}
+}
+
+func TestPostStatusError(t *testing.T) {
+	cases := []struct {
+		code      int
+		temporary bool
+	}{
+		{http.StatusInternalServerError, true},
+		{http.StatusTooManyRequests, true},
+		{http.StatusNotFound, false},
+		{http.StatusFound, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(strconv.Itoa(tc.code), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.code == http.StatusFound {
+					w.Header().Set("Location", "/elsewhere")
+				}
+				w.WriteHeader(tc.code)
+				_, _ = w.Write([]byte("internal details"))
+			}))
+			defer srv.Close()
+
+			res, err := NewUnrestricted().Post(context.Background(), Request{URL: srv.URL, Secret: "s", Body: []byte("{}")})
+			var se *StatusError
+			if !errors.As(err, &se) {
+				t.Fatalf("expected *StatusError, got %v", err)
+			}
+			if res.Body != "" {
+				t.Fatalf("expected no body for status %d, got %q", tc.code, res.Body)
+			}
+			if res.StatusCode != tc.code || se.Temporary() != tc.temporary {
+				t.Fatalf("status %d temporary %v, want %d %v", res.StatusCode, se.Temporary(), tc.code, tc.temporary)
+			}
+		})
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	cases := []struct {
+		url     string
+		invalid bool
+		blocked bool
+	}{
+		{url: "https://93.184.216.34/hook"},
+		{url: "http://93.184.216.34/hook", invalid: true},
+		{url: "ftp://93.184.216.34/hook", invalid: true},
+		{url: "/relative", invalid: true},
+		{url: "https://127.0.0.1/hook", blocked: true},
+		{url: "https://[::1]/hook", blocked: true},
+		{url: "https://[::ffff:127.0.0.1]/hook", blocked: true},
+		{url: "https://10.1.2.3/hook", blocked: true},
+		{url: "https://192.168.0.10/hook", blocked: true},
+		{url: "https://169.254.169.254/latest/meta-data", blocked: true},
+		{url: "https://100.64.0.1/hook", blocked: true},
+		{url: "https://0.0.0.0/hook", blocked: true},
+		{url: "https://[fd00:ec2::254]/hook", blocked: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.url, func(t *testing.T) {
+			err := New().CheckURL(context.Background(), tc.url)
+			var ae *AddressError
+			switch {
+			case tc.invalid && !errors.Is(err, ErrInvalidURL):
+				t.Fatalf("expected ErrInvalidURL, got %v", err)
+			case tc.blocked && !errors.As(err, &ae):
+				t.Fatalf("expected *AddressError, got %v", err)
+			case !tc.invalid && !tc.blocked && err != nil:
+				t.Fatalf("expected the url to be allowed, got %v", err)
+			}
+		})
+	}
+
+	if err := NewUnrestricted().CheckURL(context.Background(), "http://127.0.0.1:8080/hook"); err != nil {
+		t.Fatalf("expected an unrestricted client to allow local http, got %v", err)
+	}
+}
+
+// TestPostRefusesBlockedAddressOnDial covers a host that passed CheckURL and later resolves to
+// a private address: the dial itself is refused and the outbox does not retry.
+func TestPostRefusesBlockedAddressOnDial(t *testing.T) {
+	called := false
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	_, err := New().Post(context.Background(), Request{URL: srv.URL, Secret: "s", Body: []byte("{}")})
+	var ae *AddressError
+	if !errors.As(err, &ae) {
+		t.Fatalf("expected *AddressError, got %v", err)
+	}
+	var temp interface{ Temporary() bool }
+	if !errors.As(err, &temp) || temp.Temporary() {
+		t.Fatalf("expected a permanent error, got %v", err)
+	}
+	if called {
+		t.Fatal("expected the request not to reach the server")
+	}
+}
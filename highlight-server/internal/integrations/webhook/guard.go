@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+)
+
+// ErrInvalidURL is returned for endpoint URLs that are not absolute https URLs (or http, for an
+// unrestricted client).
+var ErrInvalidURL = errors.New("webhook: invalid url")
+
+// AddressError reports an endpoint that resolves to an address webhooks may not reach.
+type AddressError struct {
+	IP net.IP
+}
+
+func (e *AddressError) Error() string {
+	return fmt.Sprintf("webhook: address %s is not allowed", e.IP)
+}
+
+// Temporary is false: the endpoint keeps failing until its URL is changed.
+func (e *AddressError) Temporary() bool {
+	return false
+}
+
+// sharedAddressSpace is carrier-grade NAT (RFC 6598); thisNetwork is 0.0.0.0/8.
+var (
+	sharedAddressSpace = mustCIDR("100.64.0.0/10")
+	thisNetwork        = mustCIDR("0.0.0.0/8")
+)
+
+func mustCIDR(s string) *net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// Blocked reports whether ip is off limits to webhooks: loopback, private, link-local (which
+// includes cloud metadata at 169.254.169.254), shared, unspecified and multicast addresses.
+func Blocked(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip) ||
+		thisNetwork.Contains(ip)
+}
+
+// CheckURL validates an endpoint URL before it is saved: it must be https and every address its
+// host resolves to must be allowed. Post checks the address again when it dials, so a host that
+// later resolves elsewhere (DNS rebinding) is still refused.
+func (c *Client) CheckURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || u.Hostname() == "" || !c.schemeAllowed(u.Scheme) {
+		return ErrInvalidURL
+	}
+	if c.unrestricted {
+		return nil
+	}
+
+	if ip := net.ParseIP(u.Hostname()); ip != nil {
+		if Blocked(ip) {
+			return &AddressError{IP: ip}
+		}
+		return nil
+	}
+
+	addrs, err := c.resolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return ErrInvalidURL
+	}
+	for _, a := range addrs {
+		if Blocked(a.IP) {
+			return &AddressError{IP: a.IP}
+		}
+	}
+	return nil
+}
+
+func (c *Client) schemeAllowed(scheme string) bool {
+	return scheme == "https" || (c.unrestricted && scheme == "http")
+}
+
+// checkDial is the dialer's Control hook: it runs on the resolved address right before the
+// connection is made.
+func checkDial(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ErrInvalidURL
+	}
+	if Blocked(ip) {
+		return &AddressError{IP: ip}
+	}
+	return nil
+}
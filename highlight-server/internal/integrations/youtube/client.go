@@ -0,0 +1,409 @@
+// Package youtube talks to the YouTube Data API for the native publisher: Google OAuth with
+// offline access for a channel, and resumable video uploads.
+package youtube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrRevoked means the refresh token no longer works; the user has to connect the channel again.
+var ErrRevoked = errors.New("youtube: access revoked")
+
+// Config points the client at Google. The URLs default to the real endpoints and are only set
+// to talk to a fake server.
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+
+	AuthURL  string
+	TokenURL string
+	// APIURL is the root both the Data API (/youtube/v3) and uploads (/upload/youtube/v3) hang off.
+	APIURL string
+}
+
+type Client struct {
+	cfg  Config
+	http *http.Client
+
+	// chunkSize is how much of a video goes into one upload request.
+	chunkSize int64
+	retries   int
+	backoff   time.Duration
+}
+
+func New(cfg Config) *Client {
+	if cfg.AuthURL == "" {
+		cfg.AuthURL = "https://accounts.google.com/o/oauth2/v2/auth"
+	}
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = "https://oauth2.googleapis.com/token"
+	}
+	if cfg.APIURL == "" {
+		cfg.APIURL = "https://www.googleapis.com"
+	}
+	cfg.APIURL = strings.TrimRight(cfg.APIURL, "/")
+
+	return &Client{
+		cfg: cfg,
+		// Uploads can take long; requests are bounded by their context instead.
+		http:      &http.Client{},
+		chunkSize: 8 << 20,
+		retries:   5,
+		backoff:   500 * time.Millisecond,
+	}
+}
+
+// Token is the result of an authorization or a refresh. RefreshToken is empty on refresh.
+type Token struct {
+	AccessToken  string
+	RefreshToken string
+	Expiry       time.Time
+}
+
+type Channel struct {
+	ID    string
+	Title string
+}
+
+// Video is the metadata sent with an upload.
+type Video struct {
+	Title         string
+	Description   string
+	Tags          []string
+	PrivacyStatus string // private, unlisted or public
+}
+
+// AuthCodeURL asks for upload access to a channel. access_type=offline and prompt=consent make
+// Google return a refresh token every time.
+func (c *Client) AuthCodeURL(state string, codeChallenge string) string {
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", c.cfg.ClientID)
+	q.Set("redirect_uri", c.cfg.RedirectURL)
+	q.Set("scope", "https://www.googleapis.com/auth/youtube.upload https://www.googleapis.com/auth/youtube.readonly")
+	q.Set("state", state)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	q.Set("access_type", "offline")
+	q.Set("prompt", "consent")
+	q.Set("include_granted_scopes", "true")
+	return c.cfg.AuthURL + "?" + q.Encode()
+}
+
+// Exchange trades the authorization code for tokens.
+func (c *Client) Exchange(ctx context.Context, code string, codeVerifier string) (Token, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.cfg.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+
+	t, err := c.token(ctx, form)
+	if err != nil {
+		return Token{}, err
+	}
+	if t.RefreshToken == "" {
+		return Token{}, errors.New("youtube: no refresh token granted")
+	}
+	return t, nil
+}
+
+// Refresh gets a new access token.
+func (c *Client) Refresh(ctx context.Context, refreshToken string) (Token, error) {
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", refreshToken)
+	return c.token(ctx, form)
+}
+
+func (c *Client) token(ctx context.Context, form url.Values) (Token, error) {
+	form.Set("client_id", c.cfg.ClientID)
+	form.Set("client_secret", c.cfg.ClientSecret)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Token{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return Token{}, fmt.Errorf("youtube: token request: %w", err)
+	}
+	defer res.Body.Close()
+
+	var out struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+		Error        string `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&out); err != nil {
+		return Token{}, fmt.Errorf("youtube: token response (status %d): %w", res.StatusCode, err)
+	}
+	if out.Error == "invalid_grant" {
+		return Token{}, ErrRevoked
+	}
+	if res.StatusCode >= 300 || out.AccessToken == "" {
+		return Token{}, fmt.Errorf("youtube: token request returned %d (%s)", res.StatusCode, out.Error)
+	}
+
+	return Token{
+		AccessToken:  out.AccessToken,
+		RefreshToken: out.RefreshToken,
+		Expiry:       time.Now().Add(time.Duration(out.ExpiresIn) * time.Second),
+	}, nil
+}
+
+// Channel returns the channel the access token belongs to.
+func (c *Client) Channel(ctx context.Context, accessToken string) (Channel, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.APIURL+"/youtube/v3/channels?part=snippet&mine=true", nil)
+	if err != nil {
+		return Channel{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return Channel{}, fmt.Errorf("youtube: channels request: %w", err)
+	}
+	defer res.Body.Close()
+	if err := checkResponse(res); err != nil {
+		return Channel{}, err
+	}
+
+	var out struct {
+		Items []struct {
+			ID      string `json:"id"`
+			Snippet struct {
+				Title string `json:"title"`
+			} `json:"snippet"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return Channel{}, fmt.Errorf("youtube: channels response: %w", err)
+	}
+	if len(out.Items) == 0 {
+		return Channel{}, errors.New("youtube: the account has no channel")
+	}
+	return Channel{ID: out.Items[0].ID, Title: out.Items[0].Snippet.Title}, nil
+}
+
+// Upload sends a video with the resumable protocol and returns its id. The body is sent in
+// chunks; after a failed chunk the server is asked how much it has and the upload continues
+// from there.
+func (c *Client) Upload(ctx context.Context, accessToken string, v Video, body io.ReadSeeker, size int64, contentType string) (string, error) {
+	session, err := c.startUpload(ctx, accessToken, v, size, contentType)
+	if err != nil {
+		return "", err
+	}
+
+	var offset int64
+	failures := 0
+	resume := false
+	for {
+		var videoID string
+		var next int64
+		if resume {
+			if err := sleep(ctx, c.backoff<<failures); err != nil {
+				return "", err
+			}
+			videoID, next, err = c.queryUpload(ctx, accessToken, session, size)
+		} else {
+			videoID, next, err = c.putChunk(ctx, accessToken, session, body, offset, size)
+		}
+
+		if err == nil {
+			if videoID != "" {
+				return videoID, nil
+			}
+			if !resume {
+				failures = 0
+			}
+			offset = next
+			resume = false
+			continue
+		}
+
+		var perm *permanentError
+		if errors.As(err, &perm) || ctx.Err() != nil {
+			return "", err
+		}
+		failures++
+		if failures > c.retries {
+			return "", err
+		}
+		// Ask the server where to continue before sending more.
+		resume = true
+	}
+}
+
+// startUpload sends the metadata and returns the session URL the bytes go to.
+func (c *Client) startUpload(ctx context.Context, accessToken string, v Video, size int64, contentType string) (string, error) {
+	meta := map[string]any{
+		"snippet": map[string]any{
+			"title":       v.Title,
+			"description": v.Description,
+			"tags":        v.Tags,
+		},
+		"status": map[string]any{
+			"privacyStatus": v.PrivacyStatus,
+		},
+	}
+	b, err := json.Marshal(meta)
+	if err != nil {
+		return "", err
+	}
+
+	u := c.cfg.APIURL + "/upload/youtube/v3/videos?uploadType=resumable&part=snippet,status"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(b))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set("X-Upload-Content-Length", strconv.FormatInt(size, 10))
+	req.Header.Set("X-Upload-Content-Type", contentType)
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("youtube: starting upload: %w", err)
+	}
+	defer res.Body.Close()
+	if err := checkResponse(res); err != nil {
+		return "", err
+	}
+
+	session := res.Header.Get("Location")
+	if session == "" {
+		return "", &permanentError{msg: "youtube: upload session has no location"}
+	}
+	return session, nil
+}
+
+// putChunk sends the bytes from offset on. It returns the video id once the upload is complete,
+// otherwise the offset to continue from.
+func (c *Client) putChunk(ctx context.Context, accessToken string, session string, body io.ReadSeeker, offset int64, size int64) (string, int64, error) {
+	end := offset + c.chunkSize
+	if end > size {
+		end = size
+	}
+	if _, err := body.Seek(offset, io.SeekStart); err != nil {
+		return "", 0, &permanentError{msg: "youtube: " + err.Error()}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, session, io.LimitReader(body, end-offset))
+	if err != nil {
+		return "", 0, err
+	}
+	req.ContentLength = end - offset
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	if size > 0 {
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, end-1, size))
+	}
+
+	return c.uploadStatus(req)
+}
+
+// queryUpload asks how much of the upload the server has.
+func (c *Client) queryUpload(ctx context.Context, accessToken string, session string, size int64) (string, int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, session, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	req.ContentLength = 0
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+
+	return c.uploadStatus(req)
+}
+
+// uploadStatus reads the answer to a chunk or a status query: 200/201 carry the video, 308 says
+// how many bytes arrived.
+func (c *Client) uploadStatus(req *http.Request) (string, int64, error) {
+	res, err := c.http.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("youtube: upload: %w", err)
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusOK || res.StatusCode == http.StatusCreated:
+		var out struct {
+			ID string `json:"id"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&out); err != nil || out.ID == "" {
+			return "", 0, &permanentError{msg: "youtube: upload finished without a video id"}
+		}
+		return out.ID, 0, nil
+	case res.StatusCode == http.StatusPermanentRedirect:
+		// "Range: bytes=0-N" means N+1 bytes are stored; no header means none.
+		r := res.Header.Get("Range")
+		if r == "" {
+			return "", 0, nil
+		}
+		last, err := strconv.ParseInt(r[strings.LastIndex(r, "-")+1:], 10, 64)
+		if err != nil {
+			return "", 0, fmt.Errorf("youtube: bad upload range %q", r)
+		}
+		return "", last + 1, nil
+	}
+	return "", 0, checkResponse(res)
+}
+
+// permanentError is a failure that retrying will not fix.
+type permanentError struct {
+	msg string
+}
+
+func (e *permanentError) Error() string {
+	return e.msg
+}
+
+// checkResponse turns an error status into an error. 5xx and 429 are worth retrying; other
+// failures (bad metadata, quota, auth) are permanent.
+func checkResponse(res *http.Response) error {
+	if res.StatusCode < 300 {
+		return nil
+	}
+
+	b, _ := io.ReadAll(io.LimitReader(res.Body, 8<<10))
+	msg := strings.TrimSpace(string(b))
+	var apiErr struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(b, &apiErr) == nil && apiErr.Error.Message != "" {
+		msg = apiErr.Error.Message
+	}
+
+	err := fmt.Errorf("youtube: status %d: %s", res.StatusCode, msg)
+	if res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests {
+		return err
+	}
+	return &permanentError{msg: err.Error()}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,157 @@
+package youtube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeAPI is a minimal YouTube Data API: tokens, channels and resumable uploads. When dropAt is
+// set, the first chunk that crosses it is cut off there and answered with a 503.
+type fakeAPI struct {
+	mu       sync.Mutex
+	received bytes.Buffer
+	size     int64
+	dropAt   int64
+	dropped  bool
+	meta     map[string]any
+}
+
+func (f *fakeAPI) server(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		switch r.PostForm.Get("grant_type") {
+		case "authorization_code":
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "at-1", "refresh_token": "rt-1", "expires_in": 3600})
+		case "refresh_token":
+			if r.PostForm.Get("refresh_token") != "rt-1" {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]any{"error": "invalid_grant"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "at-2", "expires_in": 3600})
+		}
+	})
+	mux.HandleFunc("/youtube/v3/channels", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"items": []any{map[string]any{"id": "UC123", "snippet": map[string]any{"title": "Clips"}}},
+		})
+	})
+	mux.HandleFunc("/upload/youtube/v3/videos", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer at-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.size, _ = strconv.ParseInt(r.Header.Get("X-Upload-Content-Length"), 10, 64)
+		_ = json.NewDecoder(r.Body).Decode(&f.meta)
+		w.Header().Set("Location", srv.URL+"/session/1")
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/session/1", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		cr := r.Header.Get("Content-Range")
+		if !strings.HasPrefix(cr, "bytes */") {
+			var start, end, total int64
+			if _, err := fmt.Sscanf(cr, "bytes %d-%d/%d", &start, &end, &total); err != nil || start != int64(f.received.Len()) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if f.dropAt > 0 && !f.dropped && end >= f.dropAt {
+				_, _ = io.CopyN(&f.received, r.Body, f.dropAt-start)
+				f.dropped = true
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = io.Copy(&f.received, r.Body)
+		}
+
+		if int64(f.received.Len()) == f.size {
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "vid-1"})
+			return
+		}
+		if f.received.Len() > 0 {
+			w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", f.received.Len()-1))
+		}
+		w.WriteHeader(http.StatusPermanentRedirect)
+	})
+	return srv
+}
+
+func newTestClient(srv *httptest.Server) *Client {
+	c := New(Config{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://app/cb",
+		AuthURL:      srv.URL + "/authorize",
+		TokenURL:     srv.URL + "/token",
+		APIURL:       srv.URL,
+	})
+	c.chunkSize = 1000
+	c.backoff = time.Millisecond
+	return c
+}
+
+func TestResumableUpload(t *testing.T) {
+	fake := &fakeAPI{dropAt: 1500}
+	c := newTestClient(fake.server(t))
+
+	video := bytes.Repeat([]byte("0123456789"), 350)
+	id, err := c.Upload(context.Background(), "at-1", Video{Title: "Clutch", PrivacyStatus: "private"}, bytes.NewReader(video), int64(len(video)), "video/mp4")
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if id != "vid-1" {
+		t.Fatalf("got video id %q", id)
+	}
+	if !fake.dropped {
+		t.Fatalf("expected the upload to be interrupted once")
+	}
+	if !bytes.Equal(fake.received.Bytes(), video) {
+		t.Fatalf("server received %d bytes, want the %d sent", fake.received.Len(), len(video))
+	}
+	if snippet, _ := fake.meta["snippet"].(map[string]any); snippet["title"] != "Clutch" {
+		t.Fatalf("unexpected metadata %v", fake.meta)
+	}
+}
+
+func TestTokens(t *testing.T) {
+	c := newTestClient((&fakeAPI{}).server(t))
+	ctx := context.Background()
+
+	tok, err := c.Exchange(ctx, "code", "verifier")
+	if err != nil || tok.RefreshToken != "rt-1" {
+		t.Fatalf("Exchange: %+v, %v", tok, err)
+	}
+
+	tok, err = c.Refresh(ctx, "rt-1")
+	if err != nil || tok.AccessToken != "at-2" {
+		t.Fatalf("Refresh: %+v, %v", tok, err)
+	}
+
+	if _, err := c.Refresh(ctx, "revoked"); err != ErrRevoked {
+		t.Fatalf("expected ErrRevoked, got %v", err)
+	}
+
+	ch, err := c.Channel(ctx, tok.AccessToken)
+	if err != nil || ch.ID != "UC123" {
+		t.Fatalf("Channel: %+v, %v", ch, err)
+	}
+}
@@ -0,0 +1,124 @@
+// Package lifecycle defines the statuses recordings, clip candidates, clips and publications go
+// through and which changes between them are allowed. Services check a change before making
+// it; repos record every change that happens in status_transitions.
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrIllegalTransition = errors.New("lifecycle: illegal status transition")
+
+// TransitionError is a change the entity's Machine does not allow. It matches
+// ErrIllegalTransition.
+type TransitionError struct {
+	Entity string
+	From   string
+	To     string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("%s cannot go from %s to %s", e.Entity, e.From, e.To)
+}
+
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrIllegalTransition
+}
+
+// Machine lists, for every status of an entity, the statuses it may change to.
+type Machine struct {
+	Entity string
+	next   map[string][]string
+}
+
+// Valid reports whether status is one of the machine's statuses.
+func (m Machine) Valid(status string) bool {
+	_, ok := m.next[status]
+	return ok
+}
+
+// Can reports whether an entity may go from one status to another. Staying in a valid status is
+// always allowed.
+func (m Machine) Can(from string, to string) bool {
+	if !m.Valid(from) || !m.Valid(to) {
+		return false
+	}
+	if from == to {
+		return true
+	}
+	for _, s := range m.next[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Check is Can returning a *TransitionError for changes that are not allowed.
+func (m Machine) Check(from string, to string) error {
+	if !m.Can(from, to) {
+		return &TransitionError{Entity: m.Entity, From: from, To: to}
+	}
+	return nil
+}
+
+// Recording: detection moves a recording to processing and then to ready or failed; it can be
+// run again from either.
+var Recording = Machine{
+	Entity: "recording",
+	next: map[string][]string{
+		"uploaded":   {"processing"},
+		"processing": {"ready", "failed"},
+		"ready":      {"processing"},
+		"failed":     {"processing"},
+	},
+}
+
+// Candidate: a detected candidate is reviewed once, and the review can be reversed.
+var Candidate = Machine{
+	Entity: "candidate",
+	next: map[string][]string{
+		"new":      {"approved", "rejected"},
+		"approved": {"rejected"},
+		"rejected": {"approved"},
+	},
+}
+
+// Clip: exporting makes a clip ready (or failed, if the first export does not work); a ready
+// clip is published and goes back to ready once it is no longer live anywhere. An exported
+// clip never goes back to draft.
+var Clip = Machine{
+	Entity: "clip",
+	next: map[string][]string{
+		"draft":     {"ready", "failed"},
+		"failed":    {"ready"},
+		"ready":     {"published"},
+		"published": {"ready"},
+	},
+}
+
+// ClipStatus is the status a clip in status should have, given whether any of its publications
+// is live: a ready clip with a live publication is published, and a published clip without one
+// is ready again. Clips that were never exported keep their status.
+func ClipStatus(status string, live bool) string {
+	switch {
+	case status == "ready" && live:
+		return "published"
+	case status == "published" && !live:
+		return "ready"
+	}
+	return status
+}
+
+// Publication: a queued post is uploaded or fails; a failed one may still be reported uploaded
+// by a publisher that was only slow. Deleted is final.
+var Publication = Machine{
+	Entity: "publication",
+	next: map[string][]string{
+		"queued":   {"uploaded", "failed", "deleted"},
+		"failed":   {"uploaded", "deleted"},
+		"uploaded": {"deleted"},
+		"deleted":  {},
+	},
+}
@@ -0,0 +1,72 @@
+package lifecycle
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMachines(t *testing.T) {
+	cases := []struct {
+		m        Machine
+		from, to string
+		ok       bool
+	}{
+		{Recording, "uploaded", "processing", true},
+		{Recording, "uploaded", "ready", false},
+		{Recording, "failed", "processing", true},
+		{Candidate, "new", "approved", true},
+		{Candidate, "rejected", "new", false},
+		{Clip, "draft", "ready", true},
+		{Clip, "published", "draft", false},
+		{Clip, "ready", "draft", false},
+		{Clip, "published", "ready", true},
+		{Publication, "queued", "uploaded", true},
+		{Publication, "uploaded", "queued", false},
+		{Publication, "deleted", "uploaded", false},
+		{Publication, "deleted", "deleted", true},
+		{Publication, "queued", "gone", false},
+	}
+
+	for _, tc := range cases {
+		if got := tc.m.Can(tc.from, tc.to); got != tc.ok {
+			t.Errorf("%s %s -> %s: expected %v, got %v", tc.m.Entity, tc.from, tc.to, tc.ok, got)
+		}
+	}
+}
+
+func TestCheckError(t *testing.T) {
+	err := Clip.Check("published", "draft")
+	if !errors.Is(err, ErrIllegalTransition) {
+		t.Fatalf("expected ErrIllegalTransition, got %v", err)
+	}
+	if err.Error() != "clip cannot go from published to draft" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+	if err := Clip.Check("draft", "ready"); err != nil {
+		t.Fatalf("expected allowed transition, got %v", err)
+	}
+}
+
+func TestClipStatus(t *testing.T) {
+	cases := []struct {
+		status string
+		live   bool
+		want   string
+	}{
+		{"ready", true, "published"},
+		{"ready", false, "ready"},
+		{"published", false, "ready"},
+		{"published", true, "published"},
+		{"draft", true, "draft"},
+		{"failed", true, "failed"},
+	}
+
+	for _, tc := range cases {
+		if got := ClipStatus(tc.status, tc.live); got != tc.want {
+			t.Errorf("%s (live=%v): expected %s, got %s", tc.status, tc.live, tc.want, got)
+		}
+		if got := ClipStatus(tc.status, tc.live); got != tc.status && !Clip.Can(tc.status, got) {
+			t.Errorf("%s -> %s is not a clip transition", tc.status, got)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Log prints every message to the server log instead of sending it. For local development.
+type Log struct{}
+
+func (Log) Send(ctx context.Context, m Message) error {
+	log.Printf("mail: to=%s subject=%q\n%s", m.To, m.Subject, m.Body)
+	return nil
+}
+
+// File writes each message as an .eml file into a directory, so tests and local setups can
+// open the links they contain.
+type File struct {
+	dir  string
+	from string
+}
+
+func NewFile(dir string, from string) *File {
+	return &File{dir: dir, from: from}
+}
+
+func (f *File) Send(ctx context.Context, m Message) error {
+	if err := os.MkdirAll(f.dir, 0o755); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%s-%d.eml", now.UTC().Format("20060102T150405"), now.UnixNano())
+	return os.WriteFile(filepath.Join(f.dir, name), render(f.from, m, now), 0o600)
+}
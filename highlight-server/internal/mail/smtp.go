@@ -0,0 +1,90 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPConfig points at a mail relay. Username may be empty for relays that do not need auth.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// SMTP sends mail through a relay, upgrading to TLS when the server offers STARTTLS.
+type SMTP struct {
+	cfg     SMTPConfig
+	timeout time.Duration
+}
+
+func NewSMTP(cfg SMTPConfig) *SMTP {
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	return &SMTP{cfg: cfg, timeout: 30 * time.Second}
+}
+
+func (s *SMTP) Send(ctx context.Context, m Message) error {
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+
+	d := net.Dialer{Timeout: s.timeout}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	// net/smtp has no context support; a deadline bounds the whole conversation instead.
+	deadline := time.Now().Add(s.timeout)
+	if dl, ok := ctx.Deadline(); ok && dl.Before(deadline) {
+		deadline = dl
+	}
+	_ = conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	// From may carry a display name ("App <no-reply@example.com>"); the envelope wants the address.
+	sender := s.cfg.From
+	if a, err := netmail.ParseAddress(s.cfg.From); err == nil {
+		sender = a.Address
+	}
+	if err := c.Mail(sender); err != nil {
+		return err
+	}
+	if err := c.Rcpt(m.To); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(render(s.cfg.From, m, time.Now())); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,173 @@
+package analytics
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"highlightiq-server/internal/repos/workspaces"
+)
+
+type Repo struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Repo {
+	return &Repo{db: db}
+}
+
+// publishedAt is when a publication went live; rows n8n recorded without a date fall back to
+// when we learned about them.
+const publishedAt = `COALESCE(p.published_at, p.created_at)`
+
+// from joins the publications a filter covers to their clips and, when there is one, the
+// candidate each clip was cut from. Publications that never went live and clips in the trash
+// are left out.
+func from(f Filter) (string, []any) {
+	q := `
+		FROM publications p
+		JOIN clips c ON c.id = p.clip_id AND c.deleted_at IS NULL
+		LEFT JOIN clip_candidates cc ON cc.id = c.candidate_id
+		WHERE ` + workspaces.Readable("p.workspace_id") + `
+		  AND p.status IN ('uploaded','deleted')
+		  AND ` + publishedAt + ` >= ? AND ` + publishedAt + ` < ?
+	`
+	args := []any{f.UserID, f.From.UTC(), f.To.UTC()}
+	if f.Platform != "" {
+		q += ` AND p.platform = ?`
+		args = append(args, f.Platform)
+	}
+	return q, args
+}
+
+func (r *Repo) Totals(ctx context.Context, f Filter) (Totals, error) {
+	where, args := from(f)
+	q := `
+		SELECT COUNT(*), COUNT(DISTINCT p.clip_id),
+		       COALESCE(SUM(p.views), 0), COALESCE(SUM(p.likes), 0),
+		       COALESCE(SUM(p.comments), 0), COALESCE(SUM(p.shares), 0)
+	` + where
+
+	var t Totals
+	if err := r.db.QueryRowContext(ctx, q, args...).Scan(
+		&t.Publications, &t.Clips, &t.Views, &t.Likes, &t.Comments, &t.Shares,
+	); err != nil {
+		return Totals{}, err
+	}
+	return t, nil
+}
+
+// TopClips returns the clips with the most views, most first.
+func (r *Repo) TopClips(ctx context.Context, f Filter, limit int) ([]ClipStats, error) {
+	where, args := from(f)
+	q := `
+		SELECT c.id, c.title, c.duration_seconds, MAX(cc.score), COUNT(*),
+		       COALESCE(SUM(p.views), 0), COALESCE(SUM(p.likes), 0)
+	` + where + `
+		GROUP BY c.id, c.title, c.duration_seconds
+		ORDER BY SUM(p.views) DESC, c.id
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, q, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]ClipStats, 0, limit)
+	for rows.Next() {
+		var c ClipStats
+		var score sql.NullFloat64
+		if err := rows.Scan(&c.ClipID, &c.Title, &c.DurationSeconds, &score, &c.Publications, &c.Views, &c.Likes); err != nil {
+			return nil, err
+		}
+		if score.Valid {
+			v := score.Float64
+			c.Score = &v
+		}
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ByDuration groups by clip length. Group i holds clips shorter than bounds[i] seconds (and at
+// least bounds[i-1]); the last group holds everything longer.
+func (r *Repo) ByDuration(ctx context.Context, f Filter, bounds []float64) ([]Group, error) {
+	expr, args := bucket("c.duration_seconds", bounds)
+	return r.group(ctx, f, expr, args)
+}
+
+// ByScore groups by the detection score of the clip's candidate, like ByDuration. Clips made
+// by hand have no score and are grouped under -1.
+func (r *Repo) ByScore(ctx context.Context, f Filter, bounds []float64) ([]Group, error) {
+	expr, args := bucket("cc.score", bounds)
+	return r.group(ctx, f, `CASE WHEN cc.id IS NULL THEN -1 ELSE `+expr+` END`, args)
+}
+
+// ByWeekday groups by the day of the week publications went live, 0 for Sunday. Times are
+// shifted by offsetMinutes from UTC first.
+func (r *Repo) ByWeekday(ctx context.Context, f Filter, offsetMinutes int) ([]Group, error) {
+	return r.group(ctx, f, `DAYOFWEEK(DATE_ADD(`+publishedAt+`, INTERVAL ? MINUTE)) - 1`, []any{offsetMinutes})
+}
+
+// ByHour groups by the hour of the day publications went live, like ByWeekday.
+func (r *Repo) ByHour(ctx context.Context, f Filter, offsetMinutes int) ([]Group, error) {
+	return r.group(ctx, f, `HOUR(DATE_ADD(`+publishedAt+`, INTERVAL ? MINUTE))`, []any{offsetMinutes})
+}
+
+// group aggregates the filtered publications by the integer key expr computes. Only keys with
+// publications are returned, in key order.
+func (r *Repo) group(ctx context.Context, f Filter, expr string, exprArgs []any) ([]Group, error) {
+	where, args := from(f)
+	q := `
+		SELECT ` + expr + ` AS k, COUNT(DISTINCT p.clip_id), COUNT(*),
+		       COALESCE(SUM(p.views), 0), COALESCE(SUM(p.likes), 0),
+		       COALESCE(AVG(p.views), 0), COALESCE(AVG(p.likes), 0)
+	` + where + `
+		GROUP BY k
+		ORDER BY k
+	`
+
+	rows, err := r.db.QueryContext(ctx, q, append(append([]any{}, exprArgs...), args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Group
+	for rows.Next() {
+		var g Group
+		if err := rows.Scan(&g.Key, &g.Clips, &g.Publications, &g.Views, &g.Likes, &g.AvgViews, &g.AvgLikes); err != nil {
+			return nil, err
+		}
+		out = append(out, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// bucket returns a CASE expression numbering the bucket col falls in: 0 below bounds[0], and
+// len(bounds) at or above the last bound.
+func bucket(col string, bounds []float64) (string, []any) {
+	var b strings.Builder
+	args := make([]any, 0, len(bounds))
+
+	b.WriteString("CASE")
+	for i, bound := range bounds {
+		b.WriteString(" WHEN " + col + " < ? THEN ")
+		b.WriteString(strconv.Itoa(i))
+		args = append(args, bound)
+	}
+	b.WriteString(" ELSE " + strconv.Itoa(len(bounds)) + " END")
+
+	return b.String(), args
+}
@@ -0,0 +1,44 @@
+package analytics
+
+import "time"
+
+// Filter selects the publications an aggregate covers: those the user can read that went live
+// in [From, To), optionally on one platform.
+type Filter struct {
+	UserID   int64
+	From     time.Time
+	To       time.Time
+	Platform string // empty for every platform
+}
+
+type Totals struct {
+	Publications int   `json:"publications"`
+	Clips        int   `json:"clips"`
+	Views        int64 `json:"views"`
+	Likes        int64 `json:"likes"`
+	Comments     int64 `json:"comments"`
+	Shares       int64 `json:"shares"`
+}
+
+// ClipStats is one clip's performance summed over its publications.
+type ClipStats struct {
+	ClipID          int64    `json:"clip_id"`
+	Title           string   `json:"title"`
+	DurationSeconds int      `json:"duration_seconds"`
+	Score           *float64 `json:"score,omitempty"` // detection score of the candidate it came from
+	Publications    int      `json:"publications"`
+	Views           int64    `json:"views"`
+	Likes           int64    `json:"likes"`
+}
+
+// Group is the performance of the publications sharing one bucket or time slot. Averages are
+// per publication.
+type Group struct {
+	Key          int
+	Clips        int
+	Publications int
+	Views        int64
+	Likes        int64
+	AvgViews     float64
+	AvgLikes     float64
+}
@@ -0,0 +1,146 @@
+package apikeys
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+type Repo struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Repo {
+	return &Repo{db: db}
+}
+
+const selectKey = `
+	SELECT id, user_id, name, prefix, scopes, last_used_at, revoked_at, created_at
+	FROM api_keys
+`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanKey(row rowScanner) (APIKey, error) {
+	var k APIKey
+	var scopes string
+	var lastUsed, revoked sql.NullTime
+
+	if err := row.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &scopes, &lastUsed, &revoked, &k.CreatedAt); err != nil {
+		return APIKey{}, err
+	}
+
+	k.Scopes = []string{}
+	if scopes != "" {
+		k.Scopes = strings.Split(scopes, ",")
+	}
+	if lastUsed.Valid {
+		v := lastUsed.Time
+		k.LastUsedAt = &v
+	}
+	if revoked.Valid {
+		v := revoked.Time
+		k.RevokedAt = &v
+	}
+	return k, nil
+}
+
+func (r *Repo) Create(ctx context.Context, p CreateParams) (APIKey, error) {
+	const q = `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	res, err := r.db.ExecContext(ctx, q, p.UserID, p.Name, p.Prefix, p.KeyHash, strings.Join(p.Scopes, ","))
+	if err != nil {
+		return APIKey{}, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return APIKey{}, err
+	}
+
+	k, err := scanKey(r.db.QueryRowContext(ctx, selectKey+` WHERE id = ? LIMIT 1`, id))
+	if err != nil {
+		return APIKey{}, err
+	}
+	return k, nil
+}
+
+// ListByUser returns the user's keys that have not been revoked, newest first.
+func (r *Repo) ListByUser(ctx context.Context, userID int64) ([]APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, selectKey+`
+		WHERE user_id = ? AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []APIKey
+	for rows.Next() {
+		k, err := scanKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetActiveByHash looks up a key for authentication. Revoked keys are not returned.
+func (r *Repo) GetActiveByHash(ctx context.Context, keyHash string) (APIKey, error) {
+	k, err := scanKey(r.db.QueryRowContext(ctx, selectKey+`
+		WHERE key_hash = ? AND revoked_at IS NULL
+		LIMIT 1
+	`, keyHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return APIKey{}, ErrNotFound
+	}
+	if err != nil {
+		return APIKey{}, err
+	}
+	return k, nil
+}
+
+func (r *Repo) RevokeByIDForUser(ctx context.Context, userID int64, id int64) error {
+	const q = `
+		UPDATE api_keys
+		SET revoked_at = UTC_TIMESTAMP()
+		WHERE user_id = ? AND id = ? AND revoked_at IS NULL
+		LIMIT 1
+	`
+	res, err := r.db.ExecContext(ctx, q, userID, id)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed records use of a key. Writes are skipped if it was already touched within the
+// last minute, so busy scripts do not turn every request into an UPDATE.
+func (r *Repo) TouchLastUsed(ctx context.Context, id int64) error {
+	const q = `
+		UPDATE api_keys
+		SET last_used_at = UTC_TIMESTAMP()
+		WHERE id = ? AND (last_used_at IS NULL OR last_used_at < UTC_TIMESTAMP() - INTERVAL 1 MINUTE)
+		LIMIT 1
+	`
+	_, err := r.db.ExecContext(ctx, q, id)
+	return err
+}
@@ -0,0 +1,27 @@
+package apikeys
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNotFound = errors.New("apikeys: not found")
+
+type APIKey struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type CreateParams struct {
+	UserID  int64
+	Name    string
+	Prefix  string
+	KeyHash string
+	Scopes  []string
+}
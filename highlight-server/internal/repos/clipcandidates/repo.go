@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+
+	"highlightiq-server/internal/repos/transitions"
+	"highlightiq-server/internal/repos/workspaces"
 )
 
 var ErrNotFound = errors.New("clipcandidates: not found")
@@ -87,25 +90,9 @@ func (r *Repo) ListByRecordingID(ctx context.Context, recordingID int64) ([]Cand
 	return out, nil
 }
 
-func (r *Repo) UpdateStatus(ctx context.Context, id int64, status string) error {
-	const q = `
-		UPDATE clip_candidates
-		SET status = ?
-		WHERE id = ?
-		LIMIT 1
-	`
-	res, err := r.db.ExecContext(ctx, q, status, id)
-	if err != nil {
-		return err
-	}
-	aff, err := res.RowsAffected()
-	if err != nil {
-		return err
-	}
-	if aff == 0 {
-		return ErrNotFound
-	}
-	return nil
+// SetStatus moves a candidate from one status to another and records the change.
+func (r *Repo) SetStatus(ctx context.Context, id int64, from string, to string, actorID *int64) error {
+	return transitions.Set(ctx, r.db, "clip_candidates", transitions.EntityCandidate, id, from, to, actorID)
 }
 
 func (r *Repo) Delete(ctx context.Context, id int64) error {
@@ -124,19 +111,20 @@ func (r *Repo) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+// GetByIDForUser returns a candidate whose recording is in one of the user's workspaces.
 func (r *Repo) GetByIDForUser(ctx context.Context, userID int64, id int64) (Candidate, error) {
-	const q = `
-		SELECT c.id, c.recording_id, c.start_ms, c.end_ms, c.score, c.detected_signals, c.status, c.created_at, c.updated_at
+	q := `
+		SELECT c.id, c.recording_id, r.workspace_id, c.start_ms, c.end_ms, c.score, c.detected_signals, c.status, c.created_at, c.updated_at
 		FROM clip_candidates c
 		JOIN recordings r ON r.id = c.recording_id
-		WHERE c.id = ? AND r.user_id = ?
+		WHERE c.id = ? AND ` + workspaces.Readable("r.workspace_id") + `
 		LIMIT 1
 	`
 
 	var c Candidate
 	var detected sql.NullString
 	err := r.db.QueryRowContext(ctx, q, id, userID).Scan(
-		&c.ID, &c.RecordingID, &c.StartMS, &c.EndMS, &c.Score, &detected, &c.Status, &c.CreatedAt, &c.UpdatedAt,
+		&c.ID, &c.RecordingID, &c.WorkspaceID, &c.StartMS, &c.EndMS, &c.Score, &detected, &c.Status, &c.CreatedAt, &c.UpdatedAt,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
 		return Candidate{}, ErrNotFound
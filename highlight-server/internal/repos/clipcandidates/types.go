@@ -5,6 +5,7 @@ import "time"
 type Candidate struct {
 	ID           int64
 	RecordingID  int64
+	WorkspaceID  int64 // of the recording; only set by GetByIDForUser
 	StartMS      int
 	EndMS        int
 	Score        float64
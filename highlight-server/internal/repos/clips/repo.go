@@ -5,10 +5,20 @@ import (
 	"database/sql"
 	"errors"
 	"strings"
+	"time"
+
+	"highlightiq-server/internal/lifecycle"
+	"highlightiq-server/internal/repos/transitions"
+	"highlightiq-server/internal/repos/workspaces"
 )
 
 var ErrNotFound = errors.New("clips: not found")
 
+// activeClip hides clips that are in the trash themselves or belong to a trashed recording.
+const activeClip = `clips.deleted_at IS NULL AND NOT EXISTS (
+	SELECT 1 FROM recordings rec WHERE rec.id = clips.recording_id AND rec.deleted_at IS NOT NULL
+)`
+
 type Repo struct {
 	db *sql.DB
 }
@@ -17,6 +27,9 @@ func New(db *sql.DB) *Repo {
 	return &Repo{db: db}
 }
 
+// Methods ending in ForUser are scoped by workspace membership: reads see every clip in the
+// user's workspaces, writes only those in workspaces where the user is owner or editor.
+
 func (r *Repo) Create(ctx context.Context, p CreateParams) (Clip, error) {
 	durationSeconds := 0
 	if p.EndMS > p.StartMS {
@@ -28,12 +41,12 @@ func (r *Repo) Create(ctx context.Context, p CreateParams) (Clip, error) {
 	}
 
 	const q = `
-		INSERT INTO clips (user_id, recording_id, candidate_id, title, caption, start_ms, end_ms, duration_seconds, status, export_path)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO clips (user_id, workspace_id, recording_id, candidate_id, title, caption, start_ms, end_ms, duration_seconds, status, export_path)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	res, err := r.db.ExecContext(ctx, q,
-		p.UserID, p.RecordingID, p.CandidateID, p.Title, p.Caption, p.StartMS, p.EndMS, durationSeconds, p.Status, p.ExportPath,
+		p.UserID, p.WorkspaceID, p.RecordingID, p.CandidateID, p.Title, p.Caption, p.StartMS, p.EndMS, durationSeconds, p.Status, p.ExportPath,
 	)
 	if err != nil {
 		return Clip{}, err
@@ -48,10 +61,10 @@ func (r *Repo) Create(ctx context.Context, p CreateParams) (Clip, error) {
 }
 
 func (r *Repo) GetByIDForUser(ctx context.Context, userID int64, id int64) (Clip, error) {
-	const q = `
-		SELECT id, user_id, recording_id, candidate_id, title, caption, start_ms, end_ms, duration_seconds, status, export_path, created_at, updated_at
+	q := `
+		SELECT id, user_id, workspace_id, recording_id, candidate_id, title, caption, start_ms, end_ms, duration_seconds, status, export_path, created_at, updated_at
 		FROM clips
-		WHERE user_id = ? AND id = ?
+		WHERE ` + workspaces.Readable("clips.workspace_id") + ` AND id = ? AND ` + activeClip + `
 		LIMIT 1
 	`
 
@@ -61,7 +74,7 @@ func (r *Repo) GetByIDForUser(ctx context.Context, userID int64, id int64) (Clip
 	var export sql.NullString
 
 	err := r.db.QueryRowContext(ctx, q, userID, id).Scan(
-		&c.ID, &c.UserID, &c.RecordingID, &cand, &c.Title, &caption, &c.StartMS, &c.EndMS, &c.DurationSeconds,
+		&c.ID, &c.UserID, &c.WorkspaceID, &c.RecordingID, &cand, &c.Title, &caption, &c.StartMS, &c.EndMS, &c.DurationSeconds,
 		&c.Status, &export, &c.CreatedAt, &c.UpdatedAt,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
@@ -89,7 +102,7 @@ func (r *Repo) GetByIDForUser(ctx context.Context, userID int64, id int64) (Clip
 
 func (r *Repo) GetByID(ctx context.Context, id int64) (Clip, error) {
 	const q = `
-		SELECT id, user_id, recording_id, candidate_id, title, caption, start_ms, end_ms, duration_seconds, status, export_path, created_at, updated_at
+		SELECT id, user_id, workspace_id, recording_id, candidate_id, title, caption, start_ms, end_ms, duration_seconds, status, export_path, created_at, updated_at
 		FROM clips
 		WHERE id = ?
 		LIMIT 1
@@ -101,7 +114,7 @@ func (r *Repo) GetByID(ctx context.Context, id int64) (Clip, error) {
 	var export sql.NullString
 
 	err := r.db.QueryRowContext(ctx, q, id).Scan(
-		&c.ID, &c.UserID, &c.RecordingID, &cand, &c.Title, &caption, &c.StartMS, &c.EndMS, &c.DurationSeconds,
+		&c.ID, &c.UserID, &c.WorkspaceID, &c.RecordingID, &cand, &c.Title, &caption, &c.StartMS, &c.EndMS, &c.DurationSeconds,
 		&c.Status, &export, &c.CreatedAt, &c.UpdatedAt,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
@@ -127,20 +140,36 @@ func (r *Repo) GetByID(ctx context.Context, id int64) (Clip, error) {
 	return c, nil
 }
 
-func (r *Repo) ListByUser(ctx context.Context, userID int64, recordingID *int64) ([]Clip, error) {
+// ListByUser lists clips across the user's workspaces, or only workspaceID when it is not 0. A tag
+// matches the user's own tags only.
+func (r *Repo) ListByUser(ctx context.Context, userID int64, workspaceID int64, recordingID *int64, tag *string) ([]Clip, error) {
 	var sb strings.Builder
 	sb.WriteString(`
-		SELECT id, user_id, recording_id, candidate_id, title, caption, start_ms, end_ms, duration_seconds, status, export_path, created_at, updated_at
+		SELECT id, user_id, workspace_id, recording_id, candidate_id, title, caption, start_ms, end_ms, duration_seconds, status, export_path, created_at, updated_at
 		FROM clips
-		WHERE user_id = ?
+		WHERE ` + workspaces.Readable("clips.workspace_id") + ` AND ` + activeClip + `
 	`)
 	args := []interface{}{userID}
 
+	if workspaceID != 0 {
+		sb.WriteString(" AND workspace_id = ?")
+		args = append(args, workspaceID)
+	}
+
 	if recordingID != nil {
 		sb.WriteString(" AND recording_id = ?")
 		args = append(args, *recordingID)
 	}
 
+	if tag != nil {
+		sb.WriteString(` AND EXISTS (
+			SELECT 1 FROM clip_tags ct
+			JOIN tags t ON t.id = ct.tag_id
+			WHERE ct.clip_id = clips.id AND t.name = ? AND t.user_id = ?
+		)`)
+		args = append(args, *tag, userID)
+	}
+
 	sb.WriteString(" ORDER BY created_at DESC")
 
 	rows, err := r.db.QueryContext(ctx, sb.String(), args...)
@@ -157,7 +186,7 @@ func (r *Repo) ListByUser(ctx context.Context, userID int64, recordingID *int64)
 		var export sql.NullString
 
 		if err := rows.Scan(
-			&c.ID, &c.UserID, &c.RecordingID, &cand, &c.Title, &caption, &c.StartMS, &c.EndMS, &c.DurationSeconds,
+			&c.ID, &c.UserID, &c.WorkspaceID, &c.RecordingID, &cand, &c.Title, &caption, &c.StartMS, &c.EndMS, &c.DurationSeconds,
 			&c.Status, &export, &c.CreatedAt, &c.UpdatedAt,
 		); err != nil {
 			return nil, err
@@ -206,10 +235,6 @@ func (r *Repo) UpdateByIDForUser(ctx context.Context, userID int64, id int64, p
 		setParts = append(setParts, "end_ms = ?")
 		args = append(args, *p.EndMS)
 	}
-	if p.Status != nil {
-		setParts = append(setParts, "status = ?")
-		args = append(args, *p.Status)
-	}
 	if p.ExportPath != nil {
 		setParts = append(setParts, "export_path = ?")
 		args = append(args, *p.ExportPath)
@@ -223,7 +248,7 @@ func (r *Repo) UpdateByIDForUser(ctx context.Context, userID int64, id int64, p
 	q := `
 		UPDATE clips
 		SET ` + strings.Join(setParts, ", ") + `
-		WHERE user_id = ? AND id = ?
+		WHERE ` + workspaces.Readable("clips.workspace_id") + ` AND id = ? AND ` + activeClip + `
 		LIMIT 1
 	`
 
@@ -253,15 +278,16 @@ func (r *Repo) UpdateByIDForUser(ctx context.Context, userID int64, id int64, p
 			durationSeconds = (cur.EndMS - cur.StartMS) / 1000
 		}
 		_, _ = r.db.ExecContext(ctx, `
-			UPDATE clips SET duration_seconds = ? WHERE user_id = ? AND id = ? LIMIT 1
-		`, durationSeconds, userID, id)
+			UPDATE clips SET duration_seconds = ? WHERE id = ? LIMIT 1
+		`, durationSeconds, id)
 	}
 
 	return r.GetByIDForUser(ctx, userID, id)
 }
 
-func (r *Repo) DeleteByIDForUser(ctx context.Context, userID int64, id int64) error {
-	const q = `DELETE FROM clips WHERE user_id = ? AND id = ? LIMIT 1`
+// SoftDeleteByIDForUser moves a clip to the trash.
+func (r *Repo) SoftDeleteByIDForUser(ctx context.Context, userID int64, id int64) error {
+	q := `UPDATE clips SET deleted_at = UTC_TIMESTAMP() WHERE ` + workspaces.Editable("clips.workspace_id") + ` AND id = ? AND ` + activeClip + ` LIMIT 1`
 	res, err := r.db.ExecContext(ctx, q, userID, id)
 	if err != nil {
 		return err
@@ -275,3 +301,271 @@ func (r *Repo) DeleteByIDForUser(ctx context.Context, userID int64, id int64) er
 	}
 	return nil
 }
+
+// RestoreByIDForUser takes a clip back out of the trash.
+func (r *Repo) RestoreByIDForUser(ctx context.Context, userID int64, id int64) (Clip, error) {
+	q := `UPDATE clips SET deleted_at = NULL WHERE ` + workspaces.Editable("clips.workspace_id") + ` AND id = ? AND deleted_at IS NOT NULL LIMIT 1`
+	res, err := r.db.ExecContext(ctx, q, userID, id)
+	if err != nil {
+		return Clip{}, err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return Clip{}, err
+	}
+	if aff == 0 {
+		return Clip{}, ErrNotFound
+	}
+	return r.GetByIDForUser(ctx, userID, id)
+}
+
+// GetDeletedByIDForUser returns a clip that is currently in the trash.
+func (r *Repo) GetDeletedByIDForUser(ctx context.Context, userID int64, id int64) (Clip, error) {
+	q := `
+		SELECT id, user_id, workspace_id, recording_id, candidate_id, title, caption, start_ms, end_ms, duration_seconds, status, export_path, deleted_at, created_at, updated_at
+		FROM clips
+		WHERE ` + workspaces.Readable("clips.workspace_id") + ` AND id = ? AND deleted_at IS NOT NULL
+		LIMIT 1
+	`
+
+	items, err := r.listDeleted(ctx, q, userID, id)
+	if err != nil {
+		return Clip{}, err
+	}
+	if len(items) == 0 {
+		return Clip{}, ErrNotFound
+	}
+	return items[0], nil
+}
+
+// ListDeletedByUser returns trashed clips of the user's workspaces, most recently deleted first.
+func (r *Repo) ListDeletedByUser(ctx context.Context, userID int64) ([]Clip, error) {
+	q := `
+		SELECT id, user_id, workspace_id, recording_id, candidate_id, title, caption, start_ms, end_ms, duration_seconds, status, export_path, deleted_at, created_at, updated_at
+		FROM clips
+		WHERE ` + workspaces.Readable("clips.workspace_id") + ` AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`
+	return r.listDeleted(ctx, q, userID)
+}
+
+// ListDeletedBefore returns trashed clips deleted before the cutoff that the user may purge: their
+// own clips and every clip in workspaces they own. Editors cannot purge a teammate's trash. A
+// userID of 0 matches every clip.
+func (r *Repo) ListDeletedBefore(ctx context.Context, userID int64, cutoff time.Time) ([]Clip, error) {
+	q := `
+		SELECT id, user_id, workspace_id, recording_id, candidate_id, title, caption, start_ms, end_ms, duration_seconds, status, export_path, deleted_at, created_at, updated_at
+		FROM clips
+		WHERE deleted_at IS NOT NULL AND deleted_at < ?
+		  AND (? = 0 OR clips.user_id = ? OR ` + workspaces.Owned("clips.workspace_id") + `)
+		ORDER BY deleted_at ASC
+	`
+	return r.listDeleted(ctx, q, cutoff, userID, userID, userID)
+}
+
+// ListForAccount returns every clip that goes away with the user's account, trashed or not:
+// their own clips, clips in workspaces they own and clips cut from their recordings.
+func (r *Repo) ListForAccount(ctx context.Context, userID int64) ([]Clip, error) {
+	const q = `
+		SELECT id, user_id, workspace_id, recording_id, candidate_id, title, caption, start_ms, end_ms, duration_seconds, status, export_path, deleted_at, created_at, updated_at
+		FROM clips
+		WHERE user_id = ?
+		   OR workspace_id IN (SELECT id FROM workspaces WHERE owner_user_id = ?)
+		   OR recording_id IN (SELECT id FROM recordings WHERE user_id = ?)
+		ORDER BY created_at ASC
+	`
+	return r.listDeleted(ctx, q, userID, userID, userID)
+}
+
+func (r *Repo) listDeleted(ctx context.Context, q string, args ...interface{}) ([]Clip, error) {
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Clip
+	for rows.Next() {
+		var c Clip
+		var cand sql.NullInt64
+		var caption sql.NullString
+		var export sql.NullString
+		var deletedAt sql.NullTime
+
+		if err := rows.Scan(
+			&c.ID, &c.UserID, &c.WorkspaceID, &c.RecordingID, &cand, &c.Title, &caption, &c.StartMS, &c.EndMS, &c.DurationSeconds,
+			&c.Status, &export, &deletedAt, &c.CreatedAt, &c.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if cand.Valid {
+			v := cand.Int64
+			c.CandidateID = &v
+		}
+		if caption.Valid {
+			v := caption.String
+			c.Caption = &v
+		}
+		if export.Valid {
+			v := export.String
+			c.ExportPath = &v
+		}
+		if deletedAt.Valid {
+			t := deletedAt.Time
+			c.DeletedAt = &t
+		}
+
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ListExportPathsByRecordingID returns every export file under a recording, trashed clips included.
+func (r *Repo) ListExportPathsByRecordingID(ctx context.Context, recordingID int64) ([]string, error) {
+	const q = `
+		SELECT export_path
+		FROM clips
+		WHERE recording_id = ? AND export_path IS NOT NULL AND export_path <> ''
+	`
+
+	rows, err := r.db.QueryContext(ctx, q, recordingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HardDeleteByID permanently removes a trashed clip row.
+func (r *Repo) HardDeleteByID(ctx context.Context, id int64) error {
+	const q = `DELETE FROM clips WHERE id = ? AND deleted_at IS NOT NULL LIMIT 1`
+	res, err := r.db.ExecContext(ctx, q, id)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetExportPathByID returns the export key of a clip that is not in the trash. It is used by
+// the signed public download route, which has no user context.
+func (r *Repo) GetExportPathByID(ctx context.Context, id int64) (string, error) {
+	const q = `
+		SELECT export_path
+		FROM clips
+		WHERE id = ? AND ` + activeClip + `
+		LIMIT 1
+	`
+
+	var export sql.NullString
+	err := r.db.QueryRowContext(ctx, q, id).Scan(&export)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && (!export.Valid || export.String == "")) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return export.String, nil
+}
+
+// GetExportBytes returns the size of the clip's current export (0 if never exported).
+func (r *Repo) GetExportBytes(ctx context.Context, id int64) (int64, error) {
+	var n int64
+	err := r.db.QueryRowContext(ctx, `SELECT export_bytes FROM clips WHERE id = ? LIMIT 1`, id).Scan(&n)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	return n, err
+}
+
+// SetStatus moves a clip from one status to another and records the change. actorID is nil for
+// changes made by the system.
+func (r *Repo) SetStatus(ctx context.Context, id int64, from string, to string, actorID *int64) error {
+	return transitions.Set(ctx, r.db, "clips", transitions.EntityClip, id, from, to, actorID)
+}
+
+// Querier is a *sql.Tx, or anything else that can read and write within one.
+type Querier interface {
+	transitions.Execer
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// SyncStatus brings the clip's status in line with its publications, as lifecycle.ClipStatus
+// has it, within the caller's transaction. Repos that change publications call it in the same
+// transaction, so a clip is published exactly while one of its publications is live.
+func SyncStatus(ctx context.Context, q Querier, id int64, actorID *int64) error {
+	var from string
+	var live bool
+	err := q.QueryRowContext(ctx, `SELECT status FROM clips WHERE id = ? FOR UPDATE`, id).Scan(&from)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = q.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM publications WHERE clip_id = ? AND status = 'uploaded')`, id).Scan(&live)
+	if err != nil {
+		return err
+	}
+
+	to := lifecycle.ClipStatus(from, live)
+	if to == from {
+		return nil
+	}
+	if _, err := q.ExecContext(ctx, `UPDATE clips SET status = ? WHERE id = ? LIMIT 1`, to, id); err != nil {
+		return err
+	}
+	return transitions.Insert(ctx, q, transitions.EntityClip, id, from, to, actorID)
+}
+
+// CountLivePublications returns how many of the clip's publications are up on their platform.
+func (r *Repo) CountLivePublications(ctx context.Context, id int64) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM publications WHERE clip_id = ? AND status = 'uploaded'`, id).Scan(&n)
+	return n, err
+}
+
+func (r *Repo) SetExportBytes(ctx context.Context, id int64, n int64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE clips SET export_bytes = ? WHERE id = ? LIMIT 1`, n, id)
+	return err
+}
+
+// GetWorkspaceIDByIDForUser returns the workspace of a clip the user can see, trashed or not.
+// Services use it to check edit rights before changing the clip.
+func (r *Repo) GetWorkspaceIDByIDForUser(ctx context.Context, userID int64, id int64) (int64, error) {
+	q := `
+		SELECT workspace_id
+		FROM clips
+		WHERE ` + workspaces.Readable("clips.workspace_id") + ` AND id = ?
+		LIMIT 1
+	`
+	var wsID int64
+	err := r.db.QueryRowContext(ctx, q, userID, id).Scan(&wsID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	return wsID, err
+}
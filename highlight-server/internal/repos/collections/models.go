@@ -0,0 +1,48 @@
+package collections
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotFound  = errors.New("collections: not found")
+	ErrDuplicate = errors.New("collections: clip already in collection")
+)
+
+type Collection struct {
+	ID                int64     `json:"id"`
+	UserID            int64     `json:"user_id"`
+	Title             string    `json:"title"`
+	Description       *string   `json:"description,omitempty"`
+	ExportPath        *string   `json:"export_path,omitempty"`
+	YoutubePlaylistID *string   `json:"youtube_playlist_id,omitempty"`
+	ClipCount         int       `json:"clip_count"`
+	Items             []Item    `json:"items,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// Item is a clip inside a collection, in playback order.
+type Item struct {
+	ClipID          int64   `json:"clip_id"`
+	Position        int     `json:"position"`
+	Title           string  `json:"title"`
+	DurationSeconds int     `json:"duration_seconds"`
+	Status          string  `json:"status"`
+	ExportPath      *string `json:"export_path,omitempty"`
+	YoutubeVideoID  *string `json:"youtube_video_id,omitempty"`
+}
+
+type CreateParams struct {
+	UserID      int64
+	Title       string
+	Description *string
+}
+
+type UpdateParams struct {
+	Title             *string
+	Description       *string
+	ExportPath        *string
+	YoutubePlaylistID *string
+}
@@ -0,0 +1,344 @@
+package collections
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+type Repo struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Repo {
+	return &Repo{db: db}
+}
+
+const selectCollection = `
+	SELECT c.id, c.user_id, c.title, c.description, c.export_path, c.youtube_playlist_id,
+	       (SELECT COUNT(*) FROM collection_clips cc WHERE cc.collection_id = c.id) AS clip_count,
+	       c.created_at, c.updated_at
+	FROM collections c
+`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCollection(row rowScanner) (Collection, error) {
+	var c Collection
+	var description sql.NullString
+	var export sql.NullString
+	var playlist sql.NullString
+
+	if err := row.Scan(
+		&c.ID, &c.UserID, &c.Title, &description, &export, &playlist, &c.ClipCount, &c.CreatedAt, &c.UpdatedAt,
+	); err != nil {
+		return Collection{}, err
+	}
+
+	if description.Valid {
+		v := description.String
+		c.Description = &v
+	}
+	if export.Valid {
+		v := export.String
+		c.ExportPath = &v
+	}
+	if playlist.Valid {
+		v := playlist.String
+		c.YoutubePlaylistID = &v
+	}
+	return c, nil
+}
+
+func (r *Repo) Create(ctx context.Context, p CreateParams) (Collection, error) {
+	const q = `
+		INSERT INTO collections (user_id, title, description)
+		VALUES (?, ?, ?)
+	`
+
+	res, err := r.db.ExecContext(ctx, q, p.UserID, p.Title, p.Description)
+	if err != nil {
+		return Collection{}, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Collection{}, err
+	}
+
+	return r.GetByIDForUser(ctx, p.UserID, id)
+}
+
+func (r *Repo) GetByIDForUser(ctx context.Context, userID int64, id int64) (Collection, error) {
+	q := selectCollection + `
+		WHERE c.user_id = ? AND c.id = ?
+		LIMIT 1
+	`
+
+	c, err := scanCollection(r.db.QueryRowContext(ctx, q, userID, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Collection{}, ErrNotFound
+	}
+	if err != nil {
+		return Collection{}, err
+	}
+	return c, nil
+}
+
+func (r *Repo) GetByID(ctx context.Context, id int64) (Collection, error) {
+	q := selectCollection + `
+		WHERE c.id = ?
+		LIMIT 1
+	`
+
+	c, err := scanCollection(r.db.QueryRowContext(ctx, q, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Collection{}, ErrNotFound
+	}
+	if err != nil {
+		return Collection{}, err
+	}
+	return c, nil
+}
+
+func (r *Repo) ListByUser(ctx context.Context, userID int64) ([]Collection, error) {
+	q := selectCollection + `
+		WHERE c.user_id = ?
+		ORDER BY c.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Collection
+	for rows.Next() {
+		c, err := scanCollection(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *Repo) UpdateByIDForUser(ctx context.Context, userID int64, id int64, p UpdateParams) (Collection, error) {
+	if _, err := r.GetByIDForUser(ctx, userID, id); err != nil {
+		return Collection{}, err
+	}
+	if err := r.update(ctx, id, p); err != nil {
+		return Collection{}, err
+	}
+	return r.GetByIDForUser(ctx, userID, id)
+}
+
+func (r *Repo) UpdateByID(ctx context.Context, id int64, p UpdateParams) (Collection, error) {
+	if _, err := r.GetByID(ctx, id); err != nil {
+		return Collection{}, err
+	}
+	if err := r.update(ctx, id, p); err != nil {
+		return Collection{}, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *Repo) update(ctx context.Context, id int64, p UpdateParams) error {
+	setParts := make([]string, 0, 4)
+	args := make([]interface{}, 0, 5)
+
+	if p.Title != nil {
+		setParts = append(setParts, "title = ?")
+		args = append(args, *p.Title)
+	}
+	if p.Description != nil {
+		setParts = append(setParts, "description = ?")
+		args = append(args, *p.Description)
+	}
+	if p.ExportPath != nil {
+		setParts = append(setParts, "export_path = ?")
+		args = append(args, *p.ExportPath)
+	}
+	if p.YoutubePlaylistID != nil {
+		setParts = append(setParts, "youtube_playlist_id = ?")
+		args = append(args, *p.YoutubePlaylistID)
+	}
+
+	if len(setParts) == 0 {
+		return nil
+	}
+
+	q := `
+		UPDATE collections
+		SET ` + strings.Join(setParts, ", ") + `
+		WHERE id = ?
+		LIMIT 1
+	`
+	args = append(args, id)
+
+	_, err := r.db.ExecContext(ctx, q, args...)
+	return err
+}
+
+func (r *Repo) DeleteByIDForUser(ctx context.Context, userID int64, id int64) error {
+	const q = `DELETE FROM collections WHERE user_id = ? AND id = ? LIMIT 1`
+	res, err := r.db.ExecContext(ctx, q, userID, id)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListItems returns the clips of a collection ordered by position. YoutubeVideoID is the
+// most recent uploaded YouTube publication of each clip, if any. Clips in workspaces the
+// collection's owner no longer belongs to are left out but keep their row, so they come back
+// if the owner rejoins.
+func (r *Repo) ListItems(ctx context.Context, collectionID int64) ([]Item, error) {
+	const q = `
+		SELECT cc.clip_id, cc.position, cl.title, cl.duration_seconds, cl.status, cl.export_path,
+		       (
+		         SELECT p.external_id
+		         FROM publications p
+		         WHERE p.clip_id = cl.id AND p.platform = 'youtube' AND p.status = 'uploaded'
+		         ORDER BY p.created_at DESC
+		         LIMIT 1
+		       ) AS youtube_video_id
+		FROM collection_clips cc
+		JOIN clips cl ON cl.id = cc.clip_id
+		JOIN recordings rec ON rec.id = cl.recording_id
+		WHERE cc.collection_id = ? AND cl.deleted_at IS NULL AND rec.deleted_at IS NULL
+		  AND cl.workspace_id IN (
+		    SELECT wm.workspace_id
+		    FROM workspace_members wm
+		    JOIN collections c ON c.user_id = wm.user_id
+		    WHERE c.id = cc.collection_id
+		  )
+		ORDER BY cc.position ASC, cc.created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, q, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Item
+	for rows.Next() {
+		var it Item
+		var export sql.NullString
+		var videoID sql.NullString
+		if err := rows.Scan(
+			&it.ClipID, &it.Position, &it.Title, &it.DurationSeconds, &it.Status, &export, &videoID,
+		); err != nil {
+			return nil, err
+		}
+		if export.Valid {
+			v := export.String
+			it.ExportPath = &v
+		}
+		if videoID.Valid {
+			v := videoID.String
+			it.YoutubeVideoID = &v
+		}
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AddClip appends a clip at the end of the collection.
+func (r *Repo) AddClip(ctx context.Context, collectionID int64, clipID int64) error {
+	const q = `
+		INSERT INTO collection_clips (collection_id, clip_id, position)
+		SELECT ?, ?, COALESCE(MAX(position), 0) + 1
+		FROM collection_clips
+		WHERE collection_id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, q, collectionID, clipID, collectionID)
+	if err != nil {
+		var me *mysql.MySQLError
+		if errors.As(err, &me) && me.Number == 1062 {
+			return ErrDuplicate
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *Repo) RemoveClip(ctx context.Context, collectionID int64, clipID int64) error {
+	const q = `DELETE FROM collection_clips WHERE collection_id = ? AND clip_id = ? LIMIT 1`
+	res, err := r.db.ExecContext(ctx, q, collectionID, clipID)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Reorder sets positions 1..n following the order of clipIDs. Every id must already be in the collection.
+func (r *Repo) Reorder(ctx context.Context, collectionID int64, clipIDs []int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	const q = `
+		UPDATE collection_clips
+		SET position = ?
+		WHERE collection_id = ? AND clip_id = ?
+		LIMIT 1
+	`
+
+	for i, clipID := range clipIDs {
+		res, err := tx.ExecContext(ctx, q, i+1, collectionID, clipID)
+		if err != nil {
+			return err
+		}
+		aff, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		// MySQL reports 0 affected rows when the position is unchanged, so confirm membership explicitly.
+		if aff == 0 {
+			var one int
+			err := tx.QueryRowContext(ctx,
+				`SELECT 1 FROM collection_clips WHERE collection_id = ? AND clip_id = ? LIMIT 1`,
+				collectionID, clipID,
+			).Scan(&one)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
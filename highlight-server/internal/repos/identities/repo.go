@@ -0,0 +1,165 @@
+package identities
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Repo stores linked provider identities and the state of authorization requests in flight.
+type Repo struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Repo {
+	return &Repo{db: db}
+}
+
+const selectIdentity = `
+	SELECT id, user_id, provider, subject, email, last_login_at, created_at
+	FROM user_identities
+`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanIdentity(row rowScanner) (Identity, error) {
+	var i Identity
+	var email sql.NullString
+	var lastLogin sql.NullTime
+	if err := row.Scan(&i.ID, &i.UserID, &i.Provider, &i.Subject, &email, &lastLogin, &i.CreatedAt); err != nil {
+		return Identity{}, err
+	}
+	if email.Valid {
+		v := email.String
+		i.Email = &v
+	}
+	if lastLogin.Valid {
+		t := lastLogin.Time
+		i.LastLoginAt = &t
+	}
+	return i, nil
+}
+
+func (r *Repo) GetBySubject(ctx context.Context, provider string, subject string) (Identity, error) {
+	i, err := scanIdentity(r.db.QueryRowContext(ctx, selectIdentity+`WHERE provider = ? AND subject = ? LIMIT 1`, provider, subject))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Identity{}, ErrNotFound
+	}
+	return i, err
+}
+
+func (r *Repo) ListByUser(ctx context.Context, userID int64) ([]Identity, error) {
+	rows, err := r.db.QueryContext(ctx, selectIdentity+`WHERE user_id = ? ORDER BY created_at ASC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Identity{}
+	for rows.Next() {
+		i, err := scanIdentity(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, i)
+	}
+	return out, rows.Err()
+}
+
+// Create links an identity. ErrDuplicate means the provider account is linked to someone already,
+// or the user already has an account at that provider linked.
+func (r *Repo) Create(ctx context.Context, p CreateParams) error {
+	const q = `
+		INSERT INTO user_identities (user_id, provider, subject, email)
+		VALUES (?, ?, ?, NULLIF(?, ''))
+	`
+	_, err := r.db.ExecContext(ctx, q, p.UserID, p.Provider, p.Subject, p.Email)
+	var me *mysql.MySQLError
+	if errors.As(err, &me) && me.Number == 1062 {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *Repo) TouchLastLogin(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE user_identities SET last_login_at = UTC_TIMESTAMP() WHERE id = ? LIMIT 1`, id)
+	return err
+}
+
+func (r *Repo) Delete(ctx context.Context, userID int64, provider string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM user_identities WHERE user_id = ? AND provider = ? LIMIT 1`, userID, provider)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repo) CreateState(ctx context.Context, p CreateStateParams) error {
+	const q = `
+		INSERT INTO oauth_states (state_hash, provider, code_verifier, link_user_id, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx, q, p.StateHash, p.Provider, p.CodeVerifier, p.LinkUserID, p.ExpiresAt.UTC())
+	return err
+}
+
+// ConsumeState removes a state and returns it. Unknown and expired states give ErrNotFound; of two
+// concurrent calls with the same state only one succeeds.
+func (r *Repo) ConsumeState(ctx context.Context, stateHash string) (State, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return State{}, err
+	}
+	defer tx.Rollback()
+
+	const sel = `
+		SELECT id, provider, code_verifier, link_user_id
+		FROM oauth_states
+		WHERE state_hash = ? AND expires_at > UTC_TIMESTAMP()
+		LIMIT 1
+		FOR UPDATE
+	`
+	var id int64
+	var s State
+	var link sql.NullInt64
+	err = tx.QueryRowContext(ctx, sel, stateHash).Scan(&id, &s.Provider, &s.CodeVerifier, &link)
+	if errors.Is(err, sql.ErrNoRows) {
+		return State{}, ErrNotFound
+	}
+	if err != nil {
+		return State{}, err
+	}
+	if link.Valid {
+		v := link.Int64
+		s.LinkUserID = &v
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM oauth_states WHERE id = ? LIMIT 1`, id); err != nil {
+		return State{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return State{}, err
+	}
+	return s, nil
+}
+
+// DeleteExpiredStates drops authorization requests that were never completed.
+func (r *Repo) DeleteExpiredStates(ctx context.Context) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM oauth_states WHERE expires_at < UTC_TIMESTAMP()`)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
@@ -0,0 +1,44 @@
+package identities
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotFound  = errors.New("identities: not found")
+	ErrDuplicate = errors.New("identities: already linked")
+)
+
+// Identity links a user to their account at an external provider.
+type Identity struct {
+	ID          int64      `json:"-"`
+	UserID      int64      `json:"-"`
+	Provider    string     `json:"provider"`
+	Subject     string     `json:"-"`
+	Email       *string    `json:"email,omitempty"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type CreateParams struct {
+	UserID   int64
+	Provider string
+	Subject  string
+	Email    string
+}
+
+// State is an authorization request in flight.
+type State struct {
+	Provider     string
+	CodeVerifier string
+	LinkUserID   *int64
+}
+
+type CreateStateParams struct {
+	StateHash    string
+	Provider     string
+	CodeVerifier string
+	LinkUserID   *int64
+	ExpiresAt    time.Time
+}
@@ -0,0 +1,82 @@
+package loginattempts
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Repo records every login attempt. The rows are both the audit trail and the input for
+// brute-force throttling.
+type Repo struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Repo {
+	return &Repo{db: db}
+}
+
+func (r *Repo) Record(ctx context.Context, a Attempt) error {
+	const q = `
+		INSERT INTO login_attempts (email, ip, user_id, outcome, user_agent, created_at)
+		VALUES (?, ?, ?, ?, ?, UTC_TIMESTAMP())
+	`
+	_, err := r.db.ExecContext(ctx, q, a.Email, a.IP, a.UserID, a.Outcome, truncate(a.UserAgent, 255))
+	return err
+}
+
+// FailuresByEmail counts failed attempts for email since the given time. A successful login
+// resets the count.
+func (r *Repo) FailuresByEmail(ctx context.Context, email string, since time.Time) (Failures, error) {
+	const q = `
+		SELECT COUNT(*), MAX(created_at)
+		FROM login_attempts
+		WHERE email = ? AND outcome IN ('invalid_credentials','invalid_code')
+		  AND created_at > GREATEST(?, COALESCE(
+		      (SELECT MAX(s.created_at) FROM login_attempts s WHERE s.email = ? AND s.outcome = 'success'), ?))
+	`
+	since = since.UTC()
+	return r.failures(ctx, q, email, since, email, since)
+}
+
+// FailuresByIP counts failed attempts from ip since the given time. Successes do not reset it,
+// so an attacker cannot clear the count by signing in to an account of their own.
+func (r *Repo) FailuresByIP(ctx context.Context, ip string, since time.Time) (Failures, error) {
+	const q = `
+		SELECT COUNT(*), MAX(created_at)
+		FROM login_attempts
+		WHERE ip = ? AND outcome IN ('invalid_credentials','invalid_code') AND created_at > ?
+	`
+	return r.failures(ctx, q, ip, since.UTC())
+}
+
+func (r *Repo) failures(ctx context.Context, q string, args ...interface{}) (Failures, error) {
+	var f Failures
+	var last sql.NullTime
+	if err := r.db.QueryRowContext(ctx, q, args...).Scan(&f.Count, &last); err != nil {
+		return Failures{}, err
+	}
+	if last.Valid {
+		f.Last = last.Time
+	}
+	return f, nil
+}
+
+// DeleteBefore drops audit rows older than cutoff.
+func (r *Repo) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM login_attempts WHERE created_at < ?`, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
+
+// truncate cuts s to at most n characters (the column is VARCHAR, so runes, not bytes).
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
@@ -0,0 +1,26 @@
+package loginattempts
+
+import "time"
+
+// Outcomes of a login attempt. Wrong passwords and wrong second-factor codes count towards
+// throttling; throttled attempts are kept for the audit trail but never extend a lockout.
+const (
+	OutcomeSuccess            = "success"
+	OutcomeInvalidCredentials = "invalid_credentials"
+	OutcomeInvalidCode        = "invalid_code"
+	OutcomeThrottled          = "throttled"
+)
+
+type Attempt struct {
+	Email     string
+	IP        string
+	UserID    *int64
+	Outcome   string
+	UserAgent string
+}
+
+// Failures summarises recent failed attempts for one email or IP.
+type Failures struct {
+	Count int
+	Last  time.Time
+}
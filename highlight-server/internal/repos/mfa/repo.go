@@ -0,0 +1,175 @@
+package mfa
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Repo stores TOTP enrollments and recovery codes.
+type Repo struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Repo {
+	return &Repo{db: db}
+}
+
+func (r *Repo) GetTOTP(ctx context.Context, userID int64) (TOTP, error) {
+	const q = `
+		SELECT user_id, secret_enc, confirmed_at, last_used_step
+		FROM user_totp
+		WHERE user_id = ?
+		LIMIT 1
+	`
+
+	var t TOTP
+	var confirmed sql.NullTime
+	err := r.db.QueryRowContext(ctx, q, userID).Scan(&t.UserID, &t.SecretEnc, &confirmed, &t.LastUsedStep)
+	if errors.Is(err, sql.ErrNoRows) {
+		return TOTP{}, ErrNotFound
+	}
+	if err != nil {
+		return TOTP{}, err
+	}
+	if confirmed.Valid {
+		v := confirmed.Time
+		t.ConfirmedAt = &v
+	}
+	return t, nil
+}
+
+// SavePending starts (or restarts) an enrollment. A confirmed enrollment is left alone; the
+// caller must disable it first.
+func (r *Repo) SavePending(ctx context.Context, userID int64, secretEnc string) error {
+	const q = `
+		INSERT INTO user_totp (user_id, secret_enc)
+		VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE
+			secret_enc = IF(confirmed_at IS NULL, VALUES(secret_enc), secret_enc),
+			last_used_step = IF(confirmed_at IS NULL, 0, last_used_step)
+	`
+	_, err := r.db.ExecContext(ctx, q, userID, secretEnc)
+	return err
+}
+
+// Confirm enables a pending enrollment and replaces the user's recovery codes, in one step.
+func (r *Repo) Confirm(ctx context.Context, userID int64, step int64, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const q = `
+		UPDATE user_totp
+		SET confirmed_at = UTC_TIMESTAMP(), last_used_step = ?
+		WHERE user_id = ? AND confirmed_at IS NULL
+		LIMIT 1
+	`
+	res, err := tx.ExecContext(ctx, q, step, userID)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+
+	if err := replaceCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UseStep records that the code for step was used. It reports false when that step (or a later
+// one) was already used, which means the code is being replayed.
+func (r *Repo) UseStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	const q = `
+		UPDATE user_totp
+		SET last_used_step = ?
+		WHERE user_id = ? AND confirmed_at IS NOT NULL AND last_used_step < ?
+		LIMIT 1
+	`
+	res, err := r.db.ExecContext(ctx, q, step, userID, step)
+	if err != nil {
+		return false, err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return aff > 0, nil
+}
+
+// Delete turns 2FA off and drops the recovery codes.
+func (r *Repo) Delete(ctx context.Context, userID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *Repo) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UseRecoveryCode spends a recovery code. It reports false for unknown or already used codes.
+func (r *Repo) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	const q = `
+		UPDATE user_recovery_codes
+		SET used_at = UTC_TIMESTAMP()
+		WHERE user_id = ? AND code_hash = ? AND used_at IS NULL
+		LIMIT 1
+	`
+	res, err := r.db.ExecContext(ctx, q, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return aff > 0, nil
+}
+
+func (r *Repo) CountRecoveryCodes(ctx context.Context, userID int64) (int, error) {
+	const q = `SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = ? AND used_at IS NULL`
+	var n int
+	err := r.db.QueryRowContext(ctx, q, userID).Scan(&n)
+	return n, err
+}
+
+func replaceCodes(ctx context.Context, tx *sql.Tx, userID int64, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	for _, h := range codeHashes {
+		const q = `INSERT INTO user_recovery_codes (user_id, code_hash) VALUES (?, ?)`
+		if _, err := tx.ExecContext(ctx, q, userID, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package mfa
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNotFound = errors.New("mfa: not found")
+
+// TOTP is a user's authenticator enrollment. It is pending until ConfirmedAt is set.
+type TOTP struct {
+	UserID       int64
+	SecretEnc    string
+	ConfirmedAt  *time.Time
+	LastUsedStep int64
+}
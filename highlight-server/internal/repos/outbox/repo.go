@@ -0,0 +1,201 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Repo stores outgoing webhook events until they are delivered.
+type Repo struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Repo {
+	return &Repo{db: db}
+}
+
+// Execer is a *sql.DB or a *sql.Tx; other repos pass their transaction to Insert so the event is
+// only written if the change it announces is.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Insert queues an event for immediate delivery. payload is marshalled to JSON.
+func Insert(ctx context.Context, ex Execer, topic string, payload any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	const q = `
+		INSERT INTO outbox_events (topic, payload, next_attempt_at)
+		VALUES (?, ?, UTC_TIMESTAMP())
+	`
+	_, err = ex.ExecContext(ctx, q, topic, string(b))
+	return err
+}
+
+// Enqueue is Insert outside of a transaction, for events that do not accompany a change.
+func (r *Repo) Enqueue(ctx context.Context, topic string, payload any) error {
+	return Insert(ctx, r.db, topic, payload)
+}
+
+const selectEvent = `
+	SELECT id, topic, payload, status, attempts, next_attempt_at, last_error, delivered_at, created_at, updated_at
+	FROM outbox_events
+`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEvent(row rowScanner) (Event, error) {
+	var e Event
+	var payload string
+	var lastError sql.NullString
+	var deliveredAt sql.NullTime
+
+	if err := row.Scan(
+		&e.ID, &e.Topic, &payload, &e.Status, &e.Attempts, &e.NextAttemptAt, &lastError, &deliveredAt, &e.CreatedAt, &e.UpdatedAt,
+	); err != nil {
+		return Event{}, err
+	}
+
+	e.Payload = json.RawMessage(payload)
+	if lastError.Valid {
+		v := lastError.String
+		e.LastError = &v
+	}
+	if deliveredAt.Valid {
+		t := deliveredAt.Time
+		e.DeliveredAt = &t
+	}
+	return e, nil
+}
+
+func (r *Repo) GetByID(ctx context.Context, id int64) (Event, error) {
+	e, err := scanEvent(r.db.QueryRowContext(ctx, selectEvent+`WHERE id = ? LIMIT 1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Event{}, ErrNotFound
+	}
+	return e, err
+}
+
+// ListDue returns pending events whose next attempt is not after now, oldest first.
+func (r *Repo) ListDue(ctx context.Context, now time.Time, limit int) ([]Event, error) {
+	q := selectEvent + `
+		WHERE status = 'pending' AND next_attempt_at <= ?
+		ORDER BY next_attempt_at, id
+		LIMIT ?
+	`
+	return r.list(ctx, q, now.UTC(), limit)
+}
+
+// ListByStatus returns events in a status, most recently changed first.
+func (r *Repo) ListByStatus(ctx context.Context, status string, limit int) ([]Event, error) {
+	q := selectEvent + `
+		WHERE status = ?
+		ORDER BY updated_at DESC, id DESC
+		LIMIT ?
+	`
+	return r.list(ctx, q, status, limit)
+}
+
+func (r *Repo) list(ctx context.Context, q string, args ...any) ([]Event, error) {
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Event
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Lease pushes a due event's next attempt out to until, so no other dispatcher picks it up while
+// it is being delivered. It reports false when the event was no longer due.
+func (r *Repo) Lease(ctx context.Context, id int64, now time.Time, until time.Time) (bool, error) {
+	const q = `
+		UPDATE outbox_events
+		SET next_attempt_at = ?
+		WHERE id = ? AND status = 'pending' AND next_attempt_at <= ?
+		LIMIT 1
+	`
+	res, err := r.db.ExecContext(ctx, q, until.UTC(), id, now.UTC())
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (r *Repo) MarkDelivered(ctx context.Context, id int64) error {
+	const q = `
+		UPDATE outbox_events
+		SET status = 'delivered', attempts = attempts + 1, last_error = NULL, delivered_at = UTC_TIMESTAMP()
+		WHERE id = ?
+		LIMIT 1
+	`
+	_, err := r.db.ExecContext(ctx, q, id)
+	return err
+}
+
+// Retry records a failed attempt and schedules the next one.
+func (r *Repo) Retry(ctx context.Context, id int64, next time.Time, lastError string) error {
+	const q = `
+		UPDATE outbox_events
+		SET attempts = attempts + 1, next_attempt_at = ?, last_error = ?
+		WHERE id = ?
+		LIMIT 1
+	`
+	_, err := r.db.ExecContext(ctx, q, next.UTC(), lastError, id)
+	return err
+}
+
+// Bury records a failed attempt and dead-letters the event.
+func (r *Repo) Bury(ctx context.Context, id int64, lastError string) error {
+	const q = `
+		UPDATE outbox_events
+		SET status = 'dead', attempts = attempts + 1, last_error = ?
+		WHERE id = ?
+		LIMIT 1
+	`
+	_, err := r.db.ExecContext(ctx, q, lastError, id)
+	return err
+}
+
+// Replay makes a dead event pending again with a fresh attempt budget. It reports false when the
+// event is not dead.
+func (r *Repo) Replay(ctx context.Context, id int64) (bool, error) {
+	const q = `
+		UPDATE outbox_events
+		SET status = 'pending', attempts = 0, next_attempt_at = UTC_TIMESTAMP()
+		WHERE id = ? AND status = 'dead'
+		LIMIT 1
+	`
+	res, err := r.db.ExecContext(ctx, q, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
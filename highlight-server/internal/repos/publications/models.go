@@ -0,0 +1,116 @@
+package publications
+
+import "time"
+
+// Platforms a clip can be published to.
+const (
+	PlatformYouTube   = "youtube"
+	PlatformTikTok    = "tiktok"
+	PlatformInstagram = "instagram"
+	PlatformX         = "x"
+	PlatformTwitch    = "twitch"
+)
+
+var Platforms = []string{PlatformYouTube, PlatformTikTok, PlatformInstagram, PlatformX, PlatformTwitch}
+
+// Publication is a clip published (or queued to be published) to one platform.
+type Publication struct {
+	ID           int64      `json:"id"`
+	ClipID       int64      `json:"clip_id"`
+	WorkspaceID  int64      `json:"workspace_id"`
+	Platform     string     `json:"platform"`
+	ExternalID   string     `json:"external_id"` // the platform's post/video id; empty until the upload finishes
+	URL          string     `json:"url"`
+	Status       string     `json:"status"`
+	ErrorMessage *string    `json:"error_message,omitempty"`
+	Title        *string    `json:"title,omitempty"`
+	Description  *string    `json:"description,omitempty"`
+	Tags         []string   `json:"tags,omitempty"`
+	Privacy      *string    `json:"privacy,omitempty"`
+	ChannelID    *string    `json:"channel_id,omitempty"`
+	Metadata     *string    `json:"metadata,omitempty"` // platform-specific options, JSON
+	PublishAt    *time.Time `json:"publish_at,omitempty"`
+	RequestedBy  *int64     `json:"-"`
+	DispatchedAt *time.Time `json:"dispatched_at,omitempty"`
+	PublishedAt  *time.Time `json:"published_at,omitempty"`
+	LastSyncedAt *time.Time `json:"last_synced_at,omitempty"`
+	Views        int        `json:"views"`
+	Likes        int        `json:"likes"`
+	Comments     int        `json:"comments"`
+	Shares       int        `json:"shares"`
+	Analytics    *string    `json:"analytics,omitempty"` // platform-specific metrics, JSON
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+type CreateParams struct {
+	ClipID       int64
+	WorkspaceID  int64
+	Platform     string
+	ExternalID   string
+	URL          string
+	Status       string
+	Metadata     *string
+	PublishedAt  *time.Time
+	LastSyncedAt *time.Time
+	Views        int
+	Likes        int
+	Comments     int
+	Shares       int
+	Analytics    *string
+
+	// ActorID is the user recording the publication, credited with the clip status change it
+	// causes; nil for the system.
+	ActorID *int64
+}
+
+// RequestParams describes an explicit publish request. Empty Description and ChannelID are
+// stored as NULL; a nil PublishAt means as soon as possible.
+type RequestParams struct {
+	ClipID      int64
+	WorkspaceID int64
+	Platform    string
+	RequestedBy int64
+	Title       string
+	Description string
+	Tags        []string
+	Privacy     string
+	ChannelID   string
+	Metadata    *string
+	PublishAt   *time.Time
+}
+
+type UpdateParams struct {
+	ExternalID   *string
+	URL          *string
+	Status       *string
+	PublishedAt  *time.Time
+	LastSyncedAt *time.Time
+	Views        *int
+	Likes        *int
+	Comments     *int
+	Shares       *int
+	Analytics    *string
+	ErrorMessage *string // "" clears it
+
+	// ActorID is the user making a status change, recorded in its history; nil for the system.
+	ActorID *int64
+}
+
+// SyncFilter selects the posts a metrics sync run should look at.
+type SyncFilter struct {
+	Platform         string
+	Statuses         []string   // any status when empty
+	LastSyncedBefore *time.Time // posts never synced always match
+	Due              *SyncDue
+	AfterID          int64 // cursor: only ids above it
+	Limit            int   // 0 for no limit
+}
+
+// SyncDue matches posts whose sync interval has elapsed: posts that went live since NewSince
+// are due once last synced before NewSyncedBefore, older ones once before OldSyncedBefore.
+type SyncDue struct {
+	NewSince        time.Time
+	NewSyncedBefore time.Time
+	OldSyncedBefore time.Time
+}
@@ -0,0 +1,695 @@
+package publications
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"highlightiq-server/internal/lifecycle"
+	"highlightiq-server/internal/repos/clips"
+	"highlightiq-server/internal/repos/outbox"
+	"highlightiq-server/internal/repos/transitions"
+	"highlightiq-server/internal/repos/workspaces"
+)
+
+var ErrNotFound = errors.New("publications: not found")
+
+type Repo struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Repo {
+	return &Repo{db: db}
+}
+
+const selectPublication = `
+	SELECT p.id, p.clip_id, p.workspace_id, p.platform, COALESCE(p.external_id, ''), COALESCE(p.url, ''), p.status, p.error_message,
+	       p.title, p.description, p.tags, p.privacy, p.channel_id, p.metadata, p.publish_at, p.requested_by, p.dispatched_at,
+	       p.published_at, p.last_synced_at, p.views, p.likes, p.comments, p.shares, p.analytics, p.created_at, p.updated_at
+	FROM publications p
+`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPublication(row rowScanner) (Publication, error) {
+	var p Publication
+	var errorMessage sql.NullString
+	var title sql.NullString
+	var description sql.NullString
+	var tags sql.NullString
+	var privacy sql.NullString
+	var channelID sql.NullString
+	var metadata sql.NullString
+	var publishAt sql.NullTime
+	var requestedBy sql.NullInt64
+	var dispatchedAt sql.NullTime
+	var publishedAt sql.NullTime
+	var lastSyncedAt sql.NullTime
+	var analytics sql.NullString
+
+	if err := row.Scan(
+		&p.ID,
+		&p.ClipID,
+		&p.WorkspaceID,
+		&p.Platform,
+		&p.ExternalID,
+		&p.URL,
+		&p.Status,
+		&errorMessage,
+		&title,
+		&description,
+		&tags,
+		&privacy,
+		&channelID,
+		&metadata,
+		&publishAt,
+		&requestedBy,
+		&dispatchedAt,
+		&publishedAt,
+		&lastSyncedAt,
+		&p.Views,
+		&p.Likes,
+		&p.Comments,
+		&p.Shares,
+		&analytics,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	); err != nil {
+		return Publication{}, err
+	}
+
+	if errorMessage.Valid {
+		v := errorMessage.String
+		p.ErrorMessage = &v
+	}
+	if title.Valid {
+		v := title.String
+		p.Title = &v
+	}
+	if description.Valid {
+		v := description.String
+		p.Description = &v
+	}
+	if tags.Valid {
+		if err := json.Unmarshal([]byte(tags.String), &p.Tags); err != nil {
+			return Publication{}, err
+		}
+	}
+	if privacy.Valid {
+		v := privacy.String
+		p.Privacy = &v
+	}
+	if channelID.Valid {
+		v := channelID.String
+		p.ChannelID = &v
+	}
+	if metadata.Valid {
+		v := metadata.String
+		p.Metadata = &v
+	}
+	if publishAt.Valid {
+		t := publishAt.Time
+		p.PublishAt = &t
+	}
+	if requestedBy.Valid {
+		v := requestedBy.Int64
+		p.RequestedBy = &v
+	}
+	if dispatchedAt.Valid {
+		t := dispatchedAt.Time
+		p.DispatchedAt = &t
+	}
+	if publishedAt.Valid {
+		t := publishedAt.Time
+		p.PublishedAt = &t
+	}
+	if lastSyncedAt.Valid {
+		t := lastSyncedAt.Time
+		p.LastSyncedAt = &t
+	}
+	if analytics.Valid {
+		v := analytics.String
+		p.Analytics = &v
+	}
+	return p, nil
+}
+
+// Create records a publication. A live one publishes its clip, in the same transaction.
+func (r *Repo) Create(ctx context.Context, p CreateParams) (Publication, error) {
+	if p.Status == "" {
+		p.Status = "uploaded"
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Publication{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	const q = `
+		INSERT INTO publications (
+			clip_id, workspace_id, platform, external_id, url, status, metadata, published_at, last_synced_at,
+			views, likes, comments, shares, analytics, dispatched_at
+		)
+		VALUES (?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?, ?, ?, ?, ?, ?, ?, ?, ?, UTC_TIMESTAMP())
+	`
+
+	res, err := tx.ExecContext(ctx, q,
+		p.ClipID,
+		p.WorkspaceID,
+		p.Platform,
+		p.ExternalID,
+		p.URL,
+		p.Status,
+		p.Metadata,
+		p.PublishedAt,
+		p.LastSyncedAt,
+		p.Views,
+		p.Likes,
+		p.Comments,
+		p.Shares,
+		p.Analytics,
+	)
+	if err != nil {
+		return Publication{}, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Publication{}, err
+	}
+
+	if err := clips.SyncStatus(ctx, tx, p.ClipID, p.ActorID); err != nil {
+		return Publication{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Publication{}, err
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// CreateRequest queues a publish request. It stays queued, undispatched, until the scheduler
+// picks it up at or after PublishAt.
+func (r *Repo) CreateRequest(ctx context.Context, p RequestParams) (Publication, error) {
+	var tags any
+	if len(p.Tags) > 0 {
+		b, err := json.Marshal(p.Tags)
+		if err != nil {
+			return Publication{}, err
+		}
+		tags = string(b)
+	}
+
+	var publishAt any
+	if p.PublishAt != nil {
+		publishAt = p.PublishAt.UTC()
+	}
+
+	const q = `
+		INSERT INTO publications (
+			clip_id, workspace_id, platform, status, title, description, tags, privacy, channel_id, metadata, publish_at, requested_by
+		)
+		VALUES (?, ?, ?, 'queued', ?, NULLIF(?, ''), ?, ?, NULLIF(?, ''), ?, ?, ?)
+	`
+
+	res, err := r.db.ExecContext(ctx, q,
+		p.ClipID,
+		p.WorkspaceID,
+		p.Platform,
+		p.Title,
+		p.Description,
+		tags,
+		p.Privacy,
+		p.ChannelID,
+		p.Metadata,
+		publishAt,
+		p.RequestedBy,
+	)
+	if err != nil {
+		return Publication{}, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Publication{}, err
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+func (r *Repo) GetByID(ctx context.Context, id int64) (Publication, error) {
+	q := selectPublication + `
+		WHERE p.id = ?
+		LIMIT 1
+	`
+
+	pub, err := scanPublication(r.db.QueryRowContext(ctx, q, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Publication{}, ErrNotFound
+	}
+	return pub, err
+}
+
+func (r *Repo) GetByIDForUser(ctx context.Context, userID int64, id int64) (Publication, error) {
+	q := selectPublication + `
+		WHERE ` + workspaces.Readable("p.workspace_id") + ` AND p.id = ?
+		LIMIT 1
+	`
+
+	pub, err := scanPublication(r.db.QueryRowContext(ctx, q, userID, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Publication{}, ErrNotFound
+	}
+	return pub, err
+}
+
+// GetByExternalID finds a publication by the platform's id for it.
+func (r *Repo) GetByExternalID(ctx context.Context, platform string, externalID string) (Publication, error) {
+	q := selectPublication + `
+		WHERE p.platform = ? AND p.external_id = ?
+		LIMIT 1
+	`
+
+	pub, err := scanPublication(r.db.QueryRowContext(ctx, q, platform, externalID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Publication{}, ErrNotFound
+	}
+	return pub, err
+}
+
+func (r *Repo) ListByClipIDForUser(ctx context.Context, userID int64, clipID int64) ([]Publication, error) {
+	q := selectPublication + `
+		WHERE ` + workspaces.Readable("p.workspace_id") + ` AND p.clip_id = ?
+		ORDER BY p.created_at DESC
+	`
+
+	return r.list(ctx, q, userID, clipID)
+}
+
+// ListDue returns queued publish requests that have not been handed to the publisher and whose
+// publish_at, if any, is not after now. Oldest first.
+func (r *Repo) ListDue(ctx context.Context, now time.Time, limit int) ([]Publication, error) {
+	q := selectPublication + `
+		WHERE p.status = 'queued' AND p.dispatched_at IS NULL
+		  AND (p.publish_at IS NULL OR p.publish_at <= ?)
+		ORDER BY COALESCE(p.publish_at, p.created_at), p.id
+		LIMIT ?
+	`
+
+	return r.list(ctx, q, now.UTC(), limit)
+}
+
+func (r *Repo) list(ctx context.Context, q string, args ...any) ([]Publication, error) {
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Publication
+	for rows.Next() {
+		pub, err := scanPublication(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, pub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ListForSync returns the platform's published posts matching f, in id order.
+func (r *Repo) ListForSync(ctx context.Context, f SyncFilter) ([]Publication, error) {
+	where := []string{"p.platform = ?", "p.external_id IS NOT NULL", "p.id > ?"}
+	args := []any{f.Platform, f.AfterID}
+
+	if len(f.Statuses) > 0 {
+		where = append(where, "p.status IN (?"+strings.Repeat(", ?", len(f.Statuses)-1)+")")
+		for _, st := range f.Statuses {
+			args = append(args, st)
+		}
+	}
+	if f.LastSyncedBefore != nil {
+		where = append(where, "(p.last_synced_at IS NULL OR p.last_synced_at < ?)")
+		args = append(args, f.LastSyncedBefore.UTC())
+	}
+	if f.Due != nil {
+		where = append(where, `(p.last_synced_at IS NULL
+			OR (COALESCE(p.published_at, p.created_at) >= ? AND p.last_synced_at < ?)
+			OR (COALESCE(p.published_at, p.created_at) < ? AND p.last_synced_at < ?))`)
+		args = append(args, f.Due.NewSince.UTC(), f.Due.NewSyncedBefore.UTC(), f.Due.NewSince.UTC(), f.Due.OldSyncedBefore.UTC())
+	}
+
+	q := selectPublication + `
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY p.id
+	`
+	if f.Limit > 0 {
+		q += ` LIMIT ?`
+		args = append(args, f.Limit)
+	}
+
+	return r.list(ctx, q, args...)
+}
+
+func (r *Repo) UpdateByIDForUser(ctx context.Context, userID int64, id int64, p UpdateParams) (Publication, error) {
+	// Ensure the record belongs to the user.
+	if _, err := r.GetByIDForUser(ctx, userID, id); err != nil {
+		return Publication{}, err
+	}
+
+	setParts, args := updateSet(p)
+
+	if len(setParts) == 0 {
+		return r.GetByIDForUser(ctx, userID, id)
+	}
+
+	aff, err := r.update(ctx, id, p, setParts, args)
+	if err != nil {
+		return Publication{}, err
+	}
+	if aff == 0 {
+		return Publication{}, ErrNotFound
+	}
+
+	return r.GetByIDForUser(ctx, userID, id)
+}
+
+func (r *Repo) UpdateByExternalID(ctx context.Context, platform string, externalID string, p UpdateParams) (Publication, error) {
+	current, err := r.GetByExternalID(ctx, platform, externalID)
+	if err != nil {
+		return Publication{}, err
+	}
+
+	return r.UpdateByID(ctx, current.ID, p)
+}
+
+// UpdateByID is UpdateByIDForUser without the access check, for background jobs.
+func (r *Repo) UpdateByID(ctx context.Context, id int64, p UpdateParams) (Publication, error) {
+	setParts, args := updateSet(p)
+	if len(setParts) == 0 {
+		return r.GetByID(ctx, id)
+	}
+
+	if _, err := r.update(ctx, id, p, setParts, args); err != nil {
+		return Publication{}, err
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// update applies the SET clause to the publication and, when metrics changed, appends them to
+// its snapshot history in the same transaction. Status changes are checked against
+// lifecycle.Publication, recorded with the update, and carried over to the clip's status. It
+// returns the number of rows changed.
+func (r *Repo) update(ctx context.Context, id int64, p UpdateParams, setParts []string, args []interface{}) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	aff, err := applyUpdate(ctx, tx, id, p, setParts, args)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return aff, nil
+}
+
+// applyUpdate is update within the caller's transaction. A status change the publication's
+// current status does not allow returns a *lifecycle.TransitionError and changes nothing.
+func applyUpdate(ctx context.Context, db querier, id int64, p UpdateParams, setParts []string, args []interface{}) (int64, error) {
+	var from string
+	var clipID int64
+	if p.Status != nil {
+		err := db.QueryRowContext(ctx, `SELECT status, clip_id FROM publications WHERE id = ? FOR UPDATE`, id).Scan(&from, &clipID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if err := lifecycle.Publication.Check(from, *p.Status); err != nil {
+			return 0, err
+		}
+	}
+
+	q := `
+		UPDATE publications
+		SET ` + strings.Join(setParts, ", ") + `
+		WHERE id = ?
+		LIMIT 1
+	`
+
+	args = append(args, id)
+
+	res, err := db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return 0, err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if p.Status != nil && *p.Status != from {
+		if err := transitions.Insert(ctx, db, transitions.EntityPublication, id, from, *p.Status, p.ActorID); err != nil {
+			return 0, err
+		}
+		if err := clips.SyncStatus(ctx, db, clipID, p.ActorID); err != nil {
+			return 0, err
+		}
+	}
+
+	// A sync that found the same numbers changes no row but is still a data point.
+	if hasMetrics(p) {
+		if err := snapshot(ctx, db, id, p.LastSyncedAt); err != nil {
+			return 0, err
+		}
+	}
+	return aff, nil
+}
+
+// ExternalUpdate is one entry of UpdateBatchByExternalID.
+type ExternalUpdate struct {
+	Platform   string
+	ExternalID string
+	Params     UpdateParams
+}
+
+// ExternalResult is the outcome of one ExternalUpdate. Publication has a zero ID when no
+// publication matched. Illegal is set when the status change was not allowed; the entry is then
+// not applied and Publication is as it was.
+type ExternalResult struct {
+	Publication Publication
+	Illegal     bool
+}
+
+// UpdateBatchByExternalID applies every update in one transaction and returns the outcomes in
+// the same order.
+func (r *Repo) UpdateBatchByExternalID(ctx context.Context, updates []ExternalUpdate) ([]ExternalResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	byExternalID := selectPublication + `
+		WHERE p.platform = ? AND p.external_id = ?
+		LIMIT 1
+		FOR UPDATE
+	`
+	byID := selectPublication + `
+		WHERE p.id = ?
+		LIMIT 1
+	`
+
+	out := make([]ExternalResult, len(updates))
+	for i, u := range updates {
+		pub, err := scanPublication(tx.QueryRowContext(ctx, byExternalID, u.Platform, u.ExternalID))
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if u.Params.Status != nil && !lifecycle.Publication.Can(pub.Status, *u.Params.Status) {
+			out[i] = ExternalResult{Publication: pub, Illegal: true}
+			continue
+		}
+
+		if setParts, args := updateSet(u.Params); len(setParts) > 0 {
+			if _, err := applyUpdate(ctx, tx, pub.ID, u.Params, setParts, args); err != nil {
+				return nil, err
+			}
+			if pub, err = scanPublication(tx.QueryRowContext(ctx, byID, pub.ID)); err != nil {
+				return nil, err
+			}
+		}
+		out[i] = ExternalResult{Publication: pub}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Claim marks a due publish request as handed to the publisher. It reports false when another
+// scheduler got there first, or the request is no longer queued.
+func (r *Repo) Claim(ctx context.Context, id int64) (bool, error) {
+	const q = `
+		UPDATE publications
+		SET dispatched_at = UTC_TIMESTAMP()
+		WHERE id = ? AND status = 'queued' AND dispatched_at IS NULL
+		LIMIT 1
+	`
+	res, err := r.db.ExecContext(ctx, q, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// ClaimWithEvent is Claim that also queues an outbox event in the same transaction, so the
+// request is handed off exactly when the event exists.
+func (r *Repo) ClaimWithEvent(ctx context.Context, id int64, topic string, payload any) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	const q = `
+		UPDATE publications
+		SET dispatched_at = UTC_TIMESTAMP()
+		WHERE id = ? AND status = 'queued' AND dispatched_at IS NULL
+		LIMIT 1
+	`
+	res, err := tx.ExecContext(ctx, q, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n != 1 {
+		return false, nil
+	}
+
+	if err := outbox.Insert(ctx, tx, topic, payload); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// FailStale marks the platform's publications that were handed to the publisher before the
+// cutoff and are still queued as failed, and returns how many there were.
+func (r *Repo) FailStale(ctx context.Context, platform string, before time.Time, message string) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	const stale = `platform = ? AND status = 'queued' AND dispatched_at < ?`
+
+	const history = `
+		INSERT INTO status_transitions (entity, entity_id, from_status, to_status)
+		SELECT 'publication', id, status, 'failed'
+		FROM publications
+		WHERE ` + stale + `
+		FOR UPDATE
+	`
+	if _, err := tx.ExecContext(ctx, history, platform, before.UTC()); err != nil {
+		return 0, err
+	}
+
+	const q = `
+		UPDATE publications
+		SET status = 'failed', error_message = ?
+		WHERE ` + stale + `
+	`
+	res, err := tx.ExecContext(ctx, q, message, platform, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func updateSet(p UpdateParams) ([]string, []interface{}) {
+	setParts := make([]string, 0, 10)
+	args := make([]interface{}, 0, 10)
+
+	if p.ExternalID != nil {
+		setParts = append(setParts, "external_id = ?")
+		args = append(args, *p.ExternalID)
+	}
+	if p.URL != nil {
+		setParts = append(setParts, "url = ?")
+		args = append(args, *p.URL)
+	}
+	if p.Status != nil {
+		setParts = append(setParts, "status = ?")
+		args = append(args, *p.Status)
+	}
+	if p.PublishedAt != nil {
+		setParts = append(setParts, "published_at = ?")
+		args = append(args, *p.PublishedAt)
+	}
+	if p.LastSyncedAt != nil {
+		setParts = append(setParts, "last_synced_at = ?")
+		args = append(args, *p.LastSyncedAt)
+	}
+	if p.Views != nil {
+		setParts = append(setParts, "views = ?")
+		args = append(args, *p.Views)
+	}
+	if p.Likes != nil {
+		setParts = append(setParts, "likes = ?")
+		args = append(args, *p.Likes)
+	}
+	if p.Comments != nil {
+		setParts = append(setParts, "comments = ?")
+		args = append(args, *p.Comments)
+	}
+	if p.Shares != nil {
+		setParts = append(setParts, "shares = ?")
+		args = append(args, *p.Shares)
+	}
+	if p.Analytics != nil {
+		setParts = append(setParts, "analytics = ?")
+		args = append(args, *p.Analytics)
+	}
+	if p.ErrorMessage != nil {
+		setParts = append(setParts, "error_message = NULLIF(?, '')")
+		args = append(args, *p.ErrorMessage)
+	}
+
+	return setParts, args
+}
@@ -0,0 +1,193 @@
+package publications
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"highlightiq-server/internal/repos/workspaces"
+)
+
+// Snapshot granularities. Raw snapshots are one per metrics sync; daily ones are what is left
+// of a day's raw snapshots once they are downsampled.
+const (
+	GranularityRaw   = "raw"
+	GranularityDaily = "daily"
+)
+
+// Snapshot is a publication's metrics as of one sync.
+type Snapshot struct {
+	PublicationID int64     `json:"publication_id"`
+	Granularity   string    `json:"granularity"`
+	Views         int       `json:"views"`
+	Likes         int       `json:"likes"`
+	Comments      int       `json:"comments"`
+	Shares        int       `json:"shares"`
+	CapturedAt    time.Time `json:"captured_at"`
+}
+
+// SnapshotSeries is the snapshots captured in a time range, along with each publication's last
+// snapshot before it, which is where the range starts from.
+type SnapshotSeries struct {
+	Before    []Snapshot
+	Snapshots []Snapshot
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// querier is an execer that can also read, such as a *sql.Tx.
+type querier interface {
+	execer
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// snapshot appends the publication's current metrics to its history. capturedAt defaults to now.
+func snapshot(ctx context.Context, db execer, id int64, capturedAt *time.Time) error {
+	var at any
+	if capturedAt != nil {
+		at = capturedAt.UTC()
+	}
+
+	const q = `
+		INSERT INTO publish_metrics_snapshots (publication_id, views, likes, comments, shares, captured_at)
+		SELECT id, views, likes, comments, shares, COALESCE(?, UTC_TIMESTAMP())
+		FROM publications
+		WHERE id = ?
+	`
+	_, err := db.ExecContext(ctx, q, at, id)
+	return err
+}
+
+// hasMetrics reports whether p changes any of the numbers kept in the snapshot history.
+func hasMetrics(p UpdateParams) bool {
+	return p.Views != nil || p.Likes != nil || p.Comments != nil || p.Shares != nil
+}
+
+// SnapshotsByPublication returns one publication's snapshots captured in [from, to).
+func (r *Repo) SnapshotsByPublication(ctx context.Context, id int64, from time.Time, to time.Time) (SnapshotSeries, error) {
+	return r.snapshots(ctx, `s.publication_id = ?`, []any{id}, from, to)
+}
+
+// SnapshotsByClipForUser returns the snapshots of a clip's publications the user can read.
+func (r *Repo) SnapshotsByClipForUser(ctx context.Context, userID int64, clipID int64, from time.Time, to time.Time) (SnapshotSeries, error) {
+	scope := `s.publication_id IN (
+		SELECT p.id FROM publications p
+		WHERE ` + workspaces.Readable("p.workspace_id") + ` AND p.clip_id = ?
+	)`
+	return r.snapshots(ctx, scope, []any{userID, clipID}, from, to)
+}
+
+// SnapshotsForUser returns the snapshots of every publication the user can read.
+func (r *Repo) SnapshotsForUser(ctx context.Context, userID int64, from time.Time, to time.Time) (SnapshotSeries, error) {
+	scope := `s.publication_id IN (
+		SELECT p.id FROM publications p
+		WHERE ` + workspaces.Readable("p.workspace_id") + `
+	)`
+	return r.snapshots(ctx, scope, []any{userID}, from, to)
+}
+
+func (r *Repo) snapshots(ctx context.Context, scope string, args []any, from time.Time, to time.Time) (SnapshotSeries, error) {
+	var out SnapshotSeries
+
+	before := `
+		SELECT s.publication_id, s.granularity, s.views, s.likes, s.comments, s.shares, s.captured_at
+		FROM publish_metrics_snapshots s
+		JOIN (
+			SELECT s.publication_id, MAX(s.captured_at) AS captured_at
+			FROM publish_metrics_snapshots s
+			WHERE ` + scope + ` AND s.captured_at < ?
+			GROUP BY s.publication_id
+		) last ON last.publication_id = s.publication_id AND last.captured_at = s.captured_at
+		ORDER BY s.publication_id, s.id
+	`
+	list, err := r.listSnapshots(ctx, before, append(append([]any{}, args...), from.UTC())...)
+	if err != nil {
+		return SnapshotSeries{}, err
+	}
+	// Keep one per publication should two share a timestamp.
+	for i, snap := range list {
+		if i+1 < len(list) && list[i+1].PublicationID == snap.PublicationID {
+			continue
+		}
+		out.Before = append(out.Before, snap)
+	}
+
+	inRange := `
+		SELECT s.publication_id, s.granularity, s.views, s.likes, s.comments, s.shares, s.captured_at
+		FROM publish_metrics_snapshots s
+		WHERE ` + scope + ` AND s.captured_at >= ? AND s.captured_at < ?
+		ORDER BY s.captured_at, s.id
+	`
+	out.Snapshots, err = r.listSnapshots(ctx, inRange, append(append([]any{}, args...), from.UTC(), to.UTC())...)
+	if err != nil {
+		return SnapshotSeries{}, err
+	}
+
+	return out, nil
+}
+
+func (r *Repo) listSnapshots(ctx context.Context, q string, args ...any) ([]Snapshot, error) {
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Snapshot
+	for rows.Next() {
+		var s Snapshot
+		if err := rows.Scan(&s.PublicationID, &s.Granularity, &s.Views, &s.Likes, &s.Comments, &s.Shares, &s.CapturedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// DownsampleSnapshots keeps only the last raw snapshot of each publication and day captured
+// before the cutoff, marks those daily, and returns how many snapshots were removed.
+func (r *Repo) DownsampleSnapshots(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// The grouped derived table is materialized, which lets MySQL delete from the table it reads.
+	const del = `
+		DELETE s FROM publish_metrics_snapshots s
+		JOIN (
+			SELECT publication_id, DATE(captured_at) AS day, MAX(id) AS keep_id
+			FROM publish_metrics_snapshots
+			WHERE granularity = 'raw' AND captured_at < ?
+			GROUP BY publication_id, DATE(captured_at)
+		) k ON k.publication_id = s.publication_id AND k.day = DATE(s.captured_at)
+		WHERE s.granularity = 'raw' AND s.captured_at < ? AND s.id <> k.keep_id
+	`
+	cutoff := before.UTC()
+	res, err := tx.ExecContext(ctx, del, cutoff, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+
+	const mark = `
+		UPDATE publish_metrics_snapshots
+		SET granularity = 'daily'
+		WHERE granularity = 'raw' AND captured_at < ?
+	`
+	if _, err := tx.ExecContext(ctx, mark, cutoff); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
@@ -4,6 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
+	"time"
+
+	"highlightiq-server/internal/repos/transitions"
+	"highlightiq-server/internal/repos/workspaces"
 )
 
 type Repo struct {
@@ -14,14 +19,17 @@ func New(db *sql.DB) *Repo {
 	return &Repo{db: db}
 }
 
+// Methods ending in ForUser are scoped by workspace membership: reads see every recording in
+// the user's workspaces, writes only those in workspaces where the user is owner or editor.
+
 func (r *Repo) Create(ctx context.Context, p CreateParams) (Recording, error) {
 	const q = `
-		INSERT INTO recordings (uuid, user_id, title, original_filename, storage_path, duration_seconds, status)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO recordings (uuid, user_id, workspace_id, title, original_filename, storage_path, size_bytes, duration_seconds, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	res, err := r.db.ExecContext(ctx, q,
-		p.UUID, p.UserID, p.Title, p.OriginalName, p.StoragePath, p.DurationSeconds, p.Status,
+		p.UUID, p.UserID, p.WorkspaceID, p.Title, p.OriginalName, p.StoragePath, p.SizeBytes, p.DurationSeconds, p.Status,
 	)
 	if err != nil {
 		return Recording{}, err
@@ -38,11 +46,12 @@ func (r *Repo) Create(ctx context.Context, p CreateParams) (Recording, error) {
 
 func (r *Repo) GetByUUIDForUser(ctx context.Context, userID int64, recUUID string, fallbackID int64) (Recording, error) {
 	// If fallbackID is 0, ignore it; otherwise allow either match (helps Create return)
-	const q = `
-		SELECT id, uuid, user_id, title, original_filename, storage_path, duration_seconds, status, created_at, updated_at
+	q := `
+		SELECT id, uuid, user_id, workspace_id, title, original_filename, storage_path, duration_seconds, status, created_at, updated_at
 		FROM recordings
-		WHERE user_id = ?
+		WHERE ` + workspaces.Readable("workspace_id") + `
 		  AND (uuid = ? OR (? <> 0 AND id = ?))
+		  AND deleted_at IS NULL
 		LIMIT 1
 	`
 
@@ -51,6 +60,7 @@ func (r *Repo) GetByUUIDForUser(ctx context.Context, userID int64, recUUID strin
 		&rec.ID,
 		&rec.UUID,
 		&rec.UserID,
+		&rec.WorkspaceID,
 		&rec.Title,
 		&rec.OriginalName,
 		&rec.StoragePath,
@@ -69,15 +79,34 @@ func (r *Repo) GetByUUIDForUser(ctx context.Context, userID int64, recUUID strin
 	return rec, nil
 }
 
-func (r *Repo) ListByUser(ctx context.Context, userID int64) ([]Recording, error) {
-	const q = `
-		SELECT id, uuid, user_id, title, original_filename, storage_path, duration_seconds, status, created_at, updated_at
+// ListByUser lists recordings across the user's workspaces, or only workspaceID when it is not 0. A tag
+// matches the user's own tags only.
+func (r *Repo) ListByUser(ctx context.Context, userID int64, workspaceID int64, tag *string) ([]Recording, error) {
+	var sb strings.Builder
+	sb.WriteString(`
+		SELECT id, uuid, user_id, workspace_id, title, original_filename, storage_path, duration_seconds, status, created_at, updated_at
 		FROM recordings
-		WHERE user_id = ?
-		ORDER BY created_at DESC
-	`
+		WHERE ` + workspaces.Readable("workspace_id") + ` AND deleted_at IS NULL
+	`)
+	args := []interface{}{userID}
+
+	if workspaceID != 0 {
+		sb.WriteString(" AND workspace_id = ?")
+		args = append(args, workspaceID)
+	}
 
-	rows, err := r.db.QueryContext(ctx, q, userID)
+	if tag != nil {
+		sb.WriteString(` AND EXISTS (
+			SELECT 1 FROM recording_tags rt
+			JOIN tags t ON t.id = rt.tag_id
+			WHERE rt.recording_id = recordings.id AND t.name = ? AND t.user_id = ?
+		)`)
+		args = append(args, *tag, userID)
+	}
+
+	sb.WriteString(" ORDER BY created_at DESC")
+
+	rows, err := r.db.QueryContext(ctx, sb.String(), args...)
 	if err != nil {
 		return nil, err
 	}
@@ -90,6 +119,7 @@ func (r *Repo) ListByUser(ctx context.Context, userID int64) ([]Recording, error
 			&rec.ID,
 			&rec.UUID,
 			&rec.UserID,
+			&rec.WorkspaceID,
 			&rec.Title,
 			&rec.OriginalName,
 			&rec.StoragePath,
@@ -109,10 +139,10 @@ func (r *Repo) ListByUser(ctx context.Context, userID int64) ([]Recording, error
 }
 
 func (r *Repo) UpdateTitleByUUIDForUser(ctx context.Context, userID int64, recUUID string, title string) error {
-	const q = `
+	q := `
 		UPDATE recordings
 		SET title = ?
-		WHERE user_id = ? AND uuid = ?
+		WHERE ` + workspaces.Editable("workspace_id") + ` AND uuid = ? AND deleted_at IS NULL
 	`
 
 	res, err := r.db.ExecContext(ctx, q, title, userID, recUUID)
@@ -130,39 +160,156 @@ func (r *Repo) UpdateTitleByUUIDForUser(ctx context.Context, userID int64, recUU
 	return nil
 }
 
-func (r *Repo) DeleteByUUIDForUser(ctx context.Context, userID int64, recUUID string) (string, error) {
-	// We return storage_path so service can delete the file from disk
-	const sel = `
-		SELECT storage_path
-		FROM recordings
-		WHERE user_id = ? AND uuid = ?
+// SetStatus moves a recording from one status to another and records the change. actorID is
+// nil for changes made by the system.
+func (r *Repo) SetStatus(ctx context.Context, id int64, from string, to string, actorID *int64) error {
+	return transitions.Set(ctx, r.db, "recordings", transitions.EntityRecording, id, from, to, actorID)
+}
+
+// SoftDeleteByUUIDForUser moves a recording to the trash. Its clips are hidden with it.
+func (r *Repo) SoftDeleteByUUIDForUser(ctx context.Context, userID int64, recUUID string) error {
+	q := `
+		UPDATE recordings
+		SET deleted_at = UTC_TIMESTAMP()
+		WHERE ` + workspaces.Editable("workspace_id") + ` AND uuid = ? AND deleted_at IS NULL
 		LIMIT 1
 	`
-	var path string
-	err := r.db.QueryRowContext(ctx, sel, userID, recUUID).Scan(&path)
-	if errors.Is(err, sql.ErrNoRows) {
-		return "", ErrNotFound
+
+	res, err := r.db.ExecContext(ctx, q, userID, recUUID)
+	if err != nil {
+		return err
 	}
+	aff, err := res.RowsAffected()
 	if err != nil {
-		return "", err
+		return err
 	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
 
-	const del = `
-		DELETE FROM recordings
-		WHERE user_id = ? AND uuid = ?
+// RestoreByUUIDForUser takes a recording back out of the trash.
+func (r *Repo) RestoreByUUIDForUser(ctx context.Context, userID int64, recUUID string) error {
+	q := `
+		UPDATE recordings
+		SET deleted_at = NULL
+		WHERE ` + workspaces.Editable("workspace_id") + ` AND uuid = ? AND deleted_at IS NOT NULL
+		LIMIT 1
 	`
-	_, err = r.db.ExecContext(ctx, del, userID, recUUID)
+
+	res, err := r.db.ExecContext(ctx, q, userID, recUUID)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
 	if err != nil {
-		return "", err
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
 	}
-	return path, nil
+	return nil
 }
 
-func (r *Repo) GetStoragePathByIDForUser(ctx context.Context, userID int64, recordingID int64) (string, error) {
+// ListDeletedByUser returns trashed recordings of the user's workspaces, most recently deleted first.
+func (r *Repo) ListDeletedByUser(ctx context.Context, userID int64) ([]Recording, error) {
+	q := `
+		SELECT id, uuid, user_id, workspace_id, title, original_filename, storage_path, duration_seconds, status, deleted_at, created_at, updated_at
+		FROM recordings
+		WHERE ` + workspaces.Readable("workspace_id") + ` AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`
+	return r.listDeleted(ctx, q, userID)
+}
+
+// ListDeletedBefore returns trashed recordings deleted before the cutoff that the user may purge:
+// their own uploads and every recording in workspaces they own. A userID of 0 matches every
+// recording.
+func (r *Repo) ListDeletedBefore(ctx context.Context, userID int64, cutoff time.Time) ([]Recording, error) {
+	q := `
+		SELECT id, uuid, user_id, workspace_id, title, original_filename, storage_path, duration_seconds, status, deleted_at, created_at, updated_at
+		FROM recordings
+		WHERE deleted_at IS NOT NULL AND deleted_at < ?
+		  AND (? = 0 OR user_id = ? OR ` + workspaces.Owned("workspace_id") + `)
+		ORDER BY deleted_at ASC
+	`
+	return r.listDeleted(ctx, q, cutoff, userID, userID, userID)
+}
+
+// ListForAccount returns every recording that goes away with the user's account, trashed or
+// not: their own uploads and everything in workspaces they own.
+func (r *Repo) ListForAccount(ctx context.Context, userID int64) ([]Recording, error) {
 	const q = `
+		SELECT id, uuid, user_id, workspace_id, title, original_filename, storage_path, duration_seconds, status, deleted_at, created_at, updated_at
+		FROM recordings
+		WHERE user_id = ? OR workspace_id IN (SELECT id FROM workspaces WHERE owner_user_id = ?)
+		ORDER BY created_at ASC
+	`
+	return r.listDeleted(ctx, q, userID, userID)
+}
+
+func (r *Repo) listDeleted(ctx context.Context, q string, args ...interface{}) ([]Recording, error) {
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Recording
+	for rows.Next() {
+		var rec Recording
+		var deletedAt sql.NullTime
+		if err := rows.Scan(
+			&rec.ID,
+			&rec.UUID,
+			&rec.UserID,
+			&rec.WorkspaceID,
+			&rec.Title,
+			&rec.OriginalName,
+			&rec.StoragePath,
+			&rec.DurationSeconds,
+			&rec.Status,
+			&deletedAt,
+			&rec.CreatedAt,
+			&rec.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if deletedAt.Valid {
+			t := deletedAt.Time
+			rec.DeletedAt = &t
+		}
+		out = append(out, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HardDeleteByID permanently removes a trashed recording. Candidates, clips and publishes cascade.
+func (r *Repo) HardDeleteByID(ctx context.Context, id int64) error {
+	const q = `DELETE FROM recordings WHERE id = ? AND deleted_at IS NOT NULL LIMIT 1`
+	res, err := r.db.ExecContext(ctx, q, id)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repo) GetStoragePathByIDForUser(ctx context.Context, userID int64, recordingID int64) (string, error) {
+	q := `
 		SELECT storage_path
 		FROM recordings
-		WHERE user_id = ? AND id = ?
+		WHERE ` + workspaces.Readable("workspace_id") + ` AND id = ? AND deleted_at IS NULL
 		LIMIT 1
 	`
 	var path string
@@ -172,3 +319,36 @@ func (r *Repo) GetStoragePathByIDForUser(ctx context.Context, userID int64, reco
 	}
 	return path, err
 }
+
+// GetIDByUUIDForUser returns the id of a recording the user can see, trashed or not.
+func (r *Repo) GetIDByUUIDForUser(ctx context.Context, userID int64, recUUID string) (int64, error) {
+	q := `
+		SELECT id
+		FROM recordings
+		WHERE ` + workspaces.Readable("workspace_id") + ` AND uuid = ?
+		LIMIT 1
+	`
+	var id int64
+	err := r.db.QueryRowContext(ctx, q, userID, recUUID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	return id, err
+}
+
+// GetWorkspaceIDByUUIDForUser returns the workspace of a recording the user can see, trashed
+// or not. Services use it to check edit rights before changing the recording.
+func (r *Repo) GetWorkspaceIDByUUIDForUser(ctx context.Context, userID int64, recUUID string) (int64, error) {
+	q := `
+		SELECT workspace_id
+		FROM recordings
+		WHERE ` + workspaces.Readable("workspace_id") + ` AND uuid = ?
+		LIMIT 1
+	`
+	var id int64
+	err := r.db.QueryRowContext(ctx, q, userID, recUUID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	return id, err
+}
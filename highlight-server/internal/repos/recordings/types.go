@@ -11,11 +11,14 @@ type Recording struct {
 	ID              int64
 	UUID            string
 	UserID          int64
+	WorkspaceID     int64
 	Title           string
 	OriginalName    string
 	StoragePath     string
 	DurationSeconds int
 	Status          string
+	Tags            []string
+	DeletedAt       *time.Time
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 }
@@ -23,9 +26,11 @@ type Recording struct {
 type CreateParams struct {
 	UUID            string
 	UserID          int64
+	WorkspaceID     int64
 	Title           string
 	OriginalName    string
 	StoragePath     string
+	SizeBytes       int64
 	DurationSeconds int
 	Status          string
 }
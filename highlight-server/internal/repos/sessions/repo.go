@@ -0,0 +1,151 @@
+package sessions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type Repo struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Repo {
+	return &Repo{db: db}
+}
+
+func (r *Repo) Create(ctx context.Context, p CreateParams) error {
+	const q = `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, access_jti, access_expires_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx, q,
+		p.UserID, p.FamilyID, p.TokenHash, p.AccessJTI, p.AccessExpiresAt.UTC(), p.ExpiresAt.UTC(),
+	)
+	return err
+}
+
+func (r *Repo) GetByHash(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	const q = `
+		SELECT id, user_id, family_id, access_jti, access_expires_at, expires_at, rotated_at, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = ?
+		LIMIT 1
+	`
+
+	var t RefreshToken
+	var rotated, revoked sql.NullTime
+	err := r.db.QueryRowContext(ctx, q, tokenHash).Scan(
+		&t.ID, &t.UserID, &t.FamilyID, &t.AccessJTI, &t.AccessExpiresAt, &t.ExpiresAt, &rotated, &revoked, &t.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return RefreshToken{}, ErrNotFound
+	}
+	if err != nil {
+		return RefreshToken{}, err
+	}
+
+	if rotated.Valid {
+		v := rotated.Time
+		t.RotatedAt = &v
+	}
+	if revoked.Valid {
+		v := revoked.Time
+		t.RevokedAt = &v
+	}
+	return t, nil
+}
+
+// MarkRotated flags a token as used. It reports false when the token was already rotated or
+// revoked, which happens when two requests race with the same token.
+func (r *Repo) MarkRotated(ctx context.Context, id int64) (bool, error) {
+	const q = `
+		UPDATE refresh_tokens
+		SET rotated_at = UTC_TIMESTAMP()
+		WHERE id = ? AND rotated_at IS NULL AND revoked_at IS NULL
+		LIMIT 1
+	`
+	res, err := r.db.ExecContext(ctx, q, id)
+	if err != nil {
+		return false, err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return aff > 0, nil
+}
+
+// RevokeFamily revokes every refresh token of a login and denylists the access tokens issued
+// with them that have not expired yet.
+func (r *Repo) RevokeFamily(ctx context.Context, familyID string) error {
+	return r.revoke(ctx, `family_id = ?`, familyID)
+}
+
+// RevokeAllForUser does RevokeFamily for every login of the user.
+func (r *Repo) RevokeAllForUser(ctx context.Context, userID int64) error {
+	return r.revoke(ctx, `user_id = ?`, userID)
+}
+
+func (r *Repo) revoke(ctx context.Context, where string, arg interface{}) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT IGNORE INTO revoked_tokens (jti, user_id, expires_at)
+		SELECT access_jti, user_id, access_expires_at
+		FROM refresh_tokens
+		WHERE `+where+` AND access_expires_at > UTC_TIMESTAMP()
+	`, arg); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = UTC_TIMESTAMP()
+		WHERE `+where+` AND revoked_at IS NULL
+	`, arg); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RevokeAccessToken denylists a single access token until it expires.
+func (r *Repo) RevokeAccessToken(ctx context.Context, userID int64, jti string, expiresAt time.Time) error {
+	const q = `INSERT IGNORE INTO revoked_tokens (jti, user_id, expires_at) VALUES (?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, q, jti, userID, expiresAt.UTC())
+	return err
+}
+
+func (r *Repo) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	var one int
+	err := r.db.QueryRowContext(ctx, `SELECT 1 FROM revoked_tokens WHERE jti = ? LIMIT 1`, jti).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteExpired drops refresh tokens and denylist entries that can no longer be used.
+func (r *Repo) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE expires_at < UTC_TIMESTAMP()`)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+
+	res, err = r.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at < UTC_TIMESTAMP()`)
+	if err != nil {
+		return n, err
+	}
+	m, _ := res.RowsAffected()
+	return n + m, nil
+}
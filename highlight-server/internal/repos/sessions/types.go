@@ -0,0 +1,31 @@
+package sessions
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNotFound = errors.New("sessions: not found")
+
+// RefreshToken is a row in refresh_tokens. All tokens issued by rotating the same login share
+// a FamilyID.
+type RefreshToken struct {
+	ID              int64
+	UserID          int64
+	FamilyID        string
+	AccessJTI       string
+	AccessExpiresAt time.Time
+	ExpiresAt       time.Time
+	RotatedAt       *time.Time
+	RevokedAt       *time.Time
+	CreatedAt       time.Time
+}
+
+type CreateParams struct {
+	UserID          int64
+	FamilyID        string
+	TokenHash       string
+	AccessJTI       string
+	AccessExpiresAt time.Time
+	ExpiresAt       time.Time
+}
@@ -0,0 +1,156 @@
+package tags
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+type Repo struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Repo {
+	return &Repo{db: db}
+}
+
+func (r *Repo) ListByUser(ctx context.Context, userID int64) ([]Tag, error) {
+	const q = `
+		SELECT t.id, t.name,
+		       (SELECT COUNT(*) FROM clip_tags ct WHERE ct.tag_id = t.id) AS clip_count,
+		       (SELECT COUNT(*) FROM recording_tags rt WHERE rt.tag_id = t.id) AS recording_count
+		FROM tags t
+		WHERE t.user_id = ?
+		ORDER BY t.name ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Tag
+	for rows.Next() {
+		var t Tag
+		if err := rows.Scan(&t.ID, &t.Name, &t.ClipCount, &t.RecordingCount); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SetForClip replaces the user's tags on a clip. Tags are per user, so tags teammates put on a
+// shared clip are left alone. Missing tags are created for the user.
+func (r *Repo) SetForClip(ctx context.Context, userID int64, clipID int64, names []string) error {
+	return r.setFor(ctx, userID, "clip_tags", "clip_id", clipID, names)
+}
+
+// SetForRecording replaces the user's tags on a recording, like SetForClip.
+func (r *Repo) SetForRecording(ctx context.Context, userID int64, recordingID int64, names []string) error {
+	return r.setFor(ctx, userID, "recording_tags", "recording_id", recordingID, names)
+}
+
+func (r *Repo) setFor(ctx context.Context, userID int64, table, column string, ownerID int64, names []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	unlink := `
+		DELETE FROM ` + table + `
+		WHERE ` + column + ` = ? AND tag_id IN (SELECT id FROM tags WHERE user_id = ?)
+	`
+	if _, err := tx.ExecContext(ctx, unlink, ownerID, userID); err != nil {
+		return err
+	}
+
+	const ensure = `INSERT IGNORE INTO tags (user_id, name) VALUES (?, ?)`
+	link := `
+		INSERT INTO ` + table + ` (` + column + `, tag_id)
+		SELECT ?, id FROM tags WHERE user_id = ? AND name = ?
+	`
+
+	for _, name := range names {
+		if _, err := tx.ExecContext(ctx, ensure, userID, name); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, link, ownerID, userID, name); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// NamesForClips returns tag names keyed by clip id. A name several members used is listed once.
+func (r *Repo) NamesForClips(ctx context.Context, clipIDs []int64) (map[int64][]string, error) {
+	return r.namesFor(ctx, "clip_tags", "clip_id", clipIDs)
+}
+
+// NamesForRecordings returns tag names keyed by recording id.
+func (r *Repo) NamesForRecordings(ctx context.Context, recordingIDs []int64) (map[int64][]string, error) {
+	return r.namesFor(ctx, "recording_tags", "recording_id", recordingIDs)
+}
+
+func (r *Repo) namesFor(ctx context.Context, table, column string, ids []int64) (map[int64][]string, error) {
+	out := make(map[int64][]string, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+
+	placeholders := make([]string, 0, len(ids))
+	args := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		placeholders = append(placeholders, "?")
+		args = append(args, id)
+	}
+
+	q := `
+		SELECT DISTINCT x.` + column + `, t.name
+		FROM ` + table + ` x
+		JOIN tags t ON t.id = x.tag_id
+		WHERE x.` + column + ` IN (` + strings.Join(placeholders, ", ") + `)
+		ORDER BY t.name ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		out[id] = append(out[id], name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *Repo) DeleteByNameForUser(ctx context.Context, userID int64, name string) error {
+	const q = `DELETE FROM tags WHERE user_id = ? AND name = ? LIMIT 1`
+	res, err := r.db.ExecContext(ctx, q, userID, name)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package tags
+
+import "errors"
+
+var ErrNotFound = errors.New("tags: not found")
+
+// Tag is a user-defined label that can be attached to clips and recordings.
+type Tag struct {
+	ID             int64  `json:"id"`
+	Name           string `json:"name"`
+	ClipCount      int    `json:"clip_count"`
+	RecordingCount int    `json:"recording_count"`
+}
@@ -0,0 +1,72 @@
+// Package transitions records status changes. Repos call Insert inside the transaction that
+// makes the change, so the history has exactly the changes that happened.
+package transitions
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"highlightiq-server/internal/lifecycle"
+)
+
+// Entities whose status changes are recorded.
+const (
+	EntityRecording   = "recording"
+	EntityCandidate   = "candidate"
+	EntityClip        = "clip"
+	EntityPublication = "publication"
+)
+
+// ErrStatusChanged means the entity was no longer in the status a change was checked against,
+// because something else changed it first.
+var ErrStatusChanged = fmt.Errorf("%w: status changed concurrently", lifecycle.ErrIllegalTransition)
+
+// Execer is a *sql.DB or a *sql.Tx.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Insert records that an entity went from one status to another. actorID is nil for changes
+// made by the system.
+func Insert(ctx context.Context, ex Execer, entity string, entityID int64, from string, to string, actorID *int64) error {
+	const q = `
+		INSERT INTO status_transitions (entity, entity_id, from_status, to_status, actor_user_id)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	_, err := ex.ExecContext(ctx, q, entity, entityID, from, to, actorID)
+	return err
+}
+
+// Set moves the row of table with the given id from one status to another and records the
+// change, in one transaction. It returns ErrStatusChanged when the row is no longer in from.
+// Setting the status it already has does nothing.
+func Set(ctx context.Context, db *sql.DB, table string, entity string, id int64, from string, to string, actorID *int64) error {
+	if from == to {
+		return nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	q := `UPDATE ` + table + ` SET status = ? WHERE id = ? AND status = ? LIMIT 1`
+	res, err := tx.ExecContext(ctx, q, to, id, from)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrStatusChanged
+	}
+
+	if err := Insert(ctx, tx, entity, id, from, to, actorID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,153 @@
+package usage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+type Repo struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Repo {
+	return &Repo{db: db}
+}
+
+func (r *Repo) Get(ctx context.Context, userID int64, month string) (Usage, error) {
+	const q = `
+		SELECT
+		  COALESCE((SELECT recording_bytes FROM user_usage WHERE user_id = ?), 0),
+		  COALESCE((SELECT export_bytes FROM user_usage WHERE user_id = ?), 0),
+		  COALESCE((SELECT seconds FROM detection_usage WHERE user_id = ? AND month = ?), 0)
+	`
+
+	var u Usage
+	if err := r.db.QueryRowContext(ctx, q, userID, userID, userID, month).Scan(
+		&u.RecordingBytes, &u.ExportBytes, &u.DetectionSeconds,
+	); err != nil {
+		return Usage{}, err
+	}
+	return u, nil
+}
+
+func (r *Repo) GetQuota(ctx context.Context, userID int64) (Quota, error) {
+	const q = `
+		SELECT recording_bytes, export_bytes, detection_minutes
+		FROM user_quotas
+		WHERE user_id = ?
+		LIMIT 1
+	`
+
+	var rec, exp, det sql.NullInt64
+	err := r.db.QueryRowContext(ctx, q, userID).Scan(&rec, &exp, &det)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Quota{}, nil
+	}
+	if err != nil {
+		return Quota{}, err
+	}
+
+	var out Quota
+	if rec.Valid {
+		v := rec.Int64
+		out.RecordingBytes = &v
+	}
+	if exp.Valid {
+		v := exp.Int64
+		out.ExportBytes = &v
+	}
+	if det.Valid {
+		v := det.Int64
+		out.DetectionMinutes = &v
+	}
+	return out, nil
+}
+
+// AddRecordingBytes adds delta to the user's recording bytes. A positive delta is only applied
+// if the new total stays within limit (limit <= 0 means unlimited); ok reports whether it was.
+func (r *Repo) AddRecordingBytes(ctx context.Context, userID int64, delta int64, limit int64) (bool, error) {
+	return r.add(ctx, "recording_bytes", userID, delta, limit)
+}
+
+// AddExportBytes is AddRecordingBytes for exported files.
+func (r *Repo) AddExportBytes(ctx context.Context, userID int64, delta int64, limit int64) (bool, error) {
+	return r.add(ctx, "export_bytes", userID, delta, limit)
+}
+
+// add applies the change in a single conditional UPDATE so concurrent uploads cannot both
+// squeeze under the limit. col is always one of the constants above.
+func (r *Repo) add(ctx context.Context, col string, userID int64, delta int64, limit int64) (bool, error) {
+	if _, err := r.db.ExecContext(ctx, `INSERT IGNORE INTO user_usage (user_id) VALUES (?)`, userID); err != nil {
+		return false, err
+	}
+
+	q := `UPDATE user_usage SET ` + col + ` = GREATEST(0, ` + col + ` + ?) WHERE user_id = ?`
+	args := []interface{}{delta, userID}
+	if delta > 0 && limit > 0 {
+		q += ` AND ` + col + ` + ? <= ?`
+		args = append(args, delta, limit)
+	}
+
+	res, err := r.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, err
+	}
+	if delta <= 0 {
+		return true, nil
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return aff > 0, nil
+}
+
+func (r *Repo) AddDetectionSeconds(ctx context.Context, userID int64, month string, seconds int64) error {
+	const q = `
+		INSERT INTO detection_usage (user_id, month, seconds)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE seconds = seconds + VALUES(seconds)
+	`
+	_, err := r.db.ExecContext(ctx, q, userID, month, seconds)
+	return err
+}
+
+// ClipHolding returns what a clip holds, whether or not it is in the trash.
+func (r *Repo) ClipHolding(ctx context.Context, clipID int64) (Holding, error) {
+	const q = `SELECT user_id, export_bytes FROM clips WHERE id = ? LIMIT 1`
+	var h Holding
+	err := r.db.QueryRowContext(ctx, q, clipID).Scan(&h.UserID, &h.ExportBytes)
+	return h, err
+}
+
+// RecordingHolding returns what a recording holds, whether or not it is in the trash: its upload
+// for the uploader and the exports of its clips for their owners, one Holding per user. Clips
+// trashed on their own are left out: they gave their bytes back when they were trashed.
+func (r *Repo) RecordingHolding(ctx context.Context, recordingID int64) ([]Holding, error) {
+	const q = `
+		SELECT user_id, SUM(recording_bytes), SUM(export_bytes)
+		FROM (
+		  SELECT user_id, size_bytes AS recording_bytes, 0 AS export_bytes FROM recordings WHERE id = ?
+		  UNION ALL
+		  SELECT user_id, 0, export_bytes FROM clips WHERE recording_id = ? AND deleted_at IS NULL
+		) held
+		GROUP BY user_id
+		ORDER BY user_id
+	`
+	rows, err := r.db.QueryContext(ctx, q, recordingID, recordingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Holding
+	for rows.Next() {
+		var h Holding
+		if err := rows.Scan(&h.UserID, &h.RecordingBytes, &h.ExportBytes); err != nil {
+			return nil, err
+		}
+		out = append(out, h)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,24 @@
+package usage
+
+// Usage is a user's current consumption. DetectionSeconds covers a single month.
+type Usage struct {
+	RecordingBytes   int64
+	ExportBytes      int64
+	DetectionSeconds int64
+}
+
+// Quota holds per-user overrides. Nil fields fall back to the configured defaults.
+type Quota struct {
+	RecordingBytes   *int64
+	ExportBytes      *int64
+	DetectionMinutes *int64
+}
+
+// Holding is the storage an item counts against one user: the uploader for a recording's upload,
+// the clip's owner for its export. A recording holds the exports of its clips as well, so it can
+// hold something for several users.
+type Holding struct {
+	UserID         int64
+	RecordingBytes int64
+	ExportBytes    int64
+}
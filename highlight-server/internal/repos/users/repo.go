@@ -17,15 +17,16 @@ func New(db *sql.DB) *Repo {
 
 func (r *Repo) GetByEmail(ctx context.Context, email string) (User, error) {
 	const q = `
-		SELECT id, uuid, name, email, password_hash
+		SELECT id, uuid, name, email, password_hash, email_verified_at
 		FROM users
 		WHERE email = ?
 		LIMIT 1
 	`
 
 	var u User
+	var verifiedAt sql.NullTime
 	err := r.db.QueryRowContext(ctx, q, email).Scan(
-		&u.ID, &u.UUID, &u.Name, &u.Email, &u.PasswordHash,
+		&u.ID, &u.UUID, &u.Name, &u.Email, &u.PasswordHash, &verifiedAt,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -34,6 +35,10 @@ func (r *Repo) GetByEmail(ctx context.Context, email string) (User, error) {
 	if err != nil {
 		return User{}, err
 	}
+	if verifiedAt.Valid {
+		v := verifiedAt.Time
+		u.EmailVerifiedAt = &v
+	}
 	return u, nil
 }
 
@@ -64,15 +69,43 @@ func (r *Repo) Create(ctx context.Context, p CreateParams) (User, error) {
 
 func (r *Repo) GetByUUID(ctx context.Context, userUUID string) (User, error) {
 	const q = `
-		SELECT id, uuid, name, email, password_hash
+		SELECT id, uuid, name, email, password_hash, email_verified_at
 		FROM users
 		WHERE uuid = ?
 		LIMIT 1
 	`
 
 	var u User
+	var verifiedAt sql.NullTime
 	err := r.db.QueryRowContext(ctx, q, userUUID).Scan(
-		&u.ID, &u.UUID, &u.Name, &u.Email, &u.PasswordHash,
+		&u.ID, &u.UUID, &u.Name, &u.Email, &u.PasswordHash, &verifiedAt,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return User{}, ErrNotFound
+	}
+	if err != nil {
+		return User{}, err
+	}
+	if verifiedAt.Valid {
+		v := verifiedAt.Time
+		u.EmailVerifiedAt = &v
+	}
+	return u, nil
+}
+
+func (r *Repo) GetByID(ctx context.Context, id int64) (User, error) {
+	const q = `
+		SELECT id, uuid, name, email, password_hash, email_verified_at
+		FROM users
+		WHERE id = ?
+		LIMIT 1
+	`
+
+	var u User
+	var verifiedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, q, id).Scan(
+		&u.ID, &u.UUID, &u.Name, &u.Email, &u.PasswordHash, &verifiedAt,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -81,5 +114,79 @@ func (r *Repo) GetByUUID(ctx context.Context, userUUID string) (User, error) {
 	if err != nil {
 		return User{}, err
 	}
+	if verifiedAt.Valid {
+		v := verifiedAt.Time
+		u.EmailVerifiedAt = &v
+	}
 	return u, nil
 }
+
+// MarkEmailVerified records that the user proved they own their email address. It keeps the
+// first verification time.
+func (r *Repo) MarkEmailVerified(ctx context.Context, id int64) error {
+	const q = `
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, UTC_TIMESTAMP())
+		WHERE id = ?
+		LIMIT 1
+	`
+	_, err := r.db.ExecContext(ctx, q, id)
+	return err
+}
+
+func (r *Repo) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	const q = `UPDATE users SET password_hash = ? WHERE id = ? LIMIT 1`
+	res, err := r.db.ExecContext(ctx, q, passwordHash, id)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repo) UpdateName(ctx context.Context, id int64, name string) error {
+	const q = `UPDATE users SET name = ? WHERE id = ? LIMIT 1`
+	_, err := r.db.ExecContext(ctx, q, name, id)
+	return err
+}
+
+// UpdateEmail changes the address and marks it unverified until the user confirms it again.
+func (r *Repo) UpdateEmail(ctx context.Context, id int64, email string) error {
+	const q = `UPDATE users SET email = ?, email_verified_at = NULL WHERE id = ? LIMIT 1`
+	res, err := r.db.ExecContext(ctx, q, email, id)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes the user. Their sessions, keys, tokens, uploads and the workspaces they own
+// go with them through ON DELETE CASCADE; stored files are the caller's job.
+func (r *Repo) Delete(ctx context.Context, id int64) error {
+	const q = `DELETE FROM users WHERE id = ? LIMIT 1`
+	res, err := r.db.ExecContext(ctx, q, id)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
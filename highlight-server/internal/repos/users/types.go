@@ -1,6 +1,9 @@
 package users
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var ErrNotFound = errors.New("users: not found")
 
@@ -11,6 +14,9 @@ type User struct {
 	Name         string
 	Email        string
 	PasswordHash string
+
+	// EmailVerifiedAt is nil until the user follows the verification link.
+	EmailVerifiedAt *time.Time
 }
 
 type CreateParams struct {
@@ -0,0 +1,107 @@
+package usertokens
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Repo stores single-use, expiring tokens sent to users by email.
+type Repo struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Repo {
+	return &Repo{db: db}
+}
+
+// Create stores a new token and drops any unused token the user had for the same purpose, so
+// only the latest email works.
+func (r *Repo) Create(ctx context.Context, p CreateParams) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const del = `DELETE FROM user_tokens WHERE user_id = ? AND purpose = ? AND used_at IS NULL`
+	if _, err := tx.ExecContext(ctx, del, p.UserID, p.Purpose); err != nil {
+		return err
+	}
+
+	const ins = `
+		INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at)
+		VALUES (?, ?, ?, ?)
+	`
+	if _, err := tx.ExecContext(ctx, ins, p.UserID, p.Purpose, p.TokenHash, p.ExpiresAt.UTC()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Consume marks a valid token as used and returns its user. Unknown, expired and already used
+// tokens give ErrNotFound; of two concurrent calls with the same token only one succeeds.
+func (r *Repo) Consume(ctx context.Context, purpose string, tokenHash string) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	const sel = `
+		SELECT id, user_id
+		FROM user_tokens
+		WHERE token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > UTC_TIMESTAMP()
+		LIMIT 1
+		FOR UPDATE
+	`
+	var id, userID int64
+	err = tx.QueryRowContext(ctx, sel, tokenHash, purpose).Scan(&id, &userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	const upd = `UPDATE user_tokens SET used_at = UTC_TIMESTAMP() WHERE id = ? LIMIT 1`
+	if _, err := tx.ExecContext(ctx, upd, id); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// Lookup returns the user of a valid token without using it up.
+func (r *Repo) Lookup(ctx context.Context, purpose string, tokenHash string) (int64, error) {
+	const q = `
+		SELECT user_id
+		FROM user_tokens
+		WHERE token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > UTC_TIMESTAMP()
+		LIMIT 1
+	`
+	var userID int64
+	err := r.db.QueryRowContext(ctx, q, tokenHash, purpose).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// DeleteExpired drops tokens that can no longer be used.
+func (r *Repo) DeleteExpired(ctx context.Context) (int64, error) {
+	const q = `DELETE FROM user_tokens WHERE expires_at < UTC_TIMESTAMP() OR used_at IS NOT NULL`
+	res, err := r.db.ExecContext(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
@@ -0,0 +1,23 @@
+package usertokens
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNotFound = errors.New("user_tokens: not found")
+
+// Purposes a token can be issued for. A token only works for its own purpose.
+const (
+	PurposeVerifyEmail    = "verify_email"
+	PurposePasswordReset  = "password_reset"
+	PurposeLoginChallenge = "login_challenge"
+	PurposeConfirm        = "confirm"
+)
+
+type CreateParams struct {
+	UserID    int64
+	Purpose   string
+	TokenHash string
+	ExpiresAt time.Time
+}
@@ -0,0 +1,324 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"highlightiq-server/internal/repos/outbox"
+	"highlightiq-server/internal/repos/workspaces"
+)
+
+// Repo stores webhook endpoints and the log of deliveries to them.
+type Repo struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Repo {
+	return &Repo{db: db}
+}
+
+const selectEndpoint = `
+	SELECT e.id, e.workspace_id, e.created_by, e.url, e.description, e.events, e.secret, e.active, e.created_at, e.updated_at
+	FROM webhook_endpoints e
+`
+
+const selectDelivery = `
+	SELECT d.id, d.endpoint_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.response_status,
+	       d.response_body, d.last_error, d.last_attempt_at, d.delivered_at, d.created_at, d.updated_at
+	FROM webhook_deliveries d
+`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEndpoint(row rowScanner) (Endpoint, error) {
+	var e Endpoint
+	var createdBy sql.NullInt64
+	var description sql.NullString
+	var events string
+
+	if err := row.Scan(&e.ID, &e.WorkspaceID, &createdBy, &e.URL, &description, &events, &e.Secret, &e.Active, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		return Endpoint{}, err
+	}
+
+	if createdBy.Valid {
+		v := createdBy.Int64
+		e.CreatedBy = &v
+	}
+	if description.Valid {
+		v := description.String
+		e.Description = &v
+	}
+	if err := json.Unmarshal([]byte(events), &e.Events); err != nil {
+		return Endpoint{}, err
+	}
+	return e, nil
+}
+
+func scanDelivery(row rowScanner) (Delivery, error) {
+	var d Delivery
+	var payload string
+	var responseStatus sql.NullInt64
+	var responseBody, lastError sql.NullString
+	var lastAttemptAt, deliveredAt sql.NullTime
+
+	if err := row.Scan(
+		&d.ID, &d.EndpointID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts, &responseStatus,
+		&responseBody, &lastError, &lastAttemptAt, &deliveredAt, &d.CreatedAt, &d.UpdatedAt,
+	); err != nil {
+		return Delivery{}, err
+	}
+
+	d.Payload = json.RawMessage(payload)
+	if responseStatus.Valid {
+		v := int(responseStatus.Int64)
+		d.ResponseStatus = &v
+	}
+	if responseBody.Valid {
+		v := responseBody.String
+		d.ResponseBody = &v
+	}
+	if lastError.Valid {
+		v := lastError.String
+		d.LastError = &v
+	}
+	if lastAttemptAt.Valid {
+		t := lastAttemptAt.Time
+		d.LastAttemptAt = &t
+	}
+	if deliveredAt.Valid {
+		t := deliveredAt.Time
+		d.DeliveredAt = &t
+	}
+	return d, nil
+}
+
+func (r *Repo) Create(ctx context.Context, p CreateParams) (Endpoint, error) {
+	events, err := json.Marshal(p.Events)
+	if err != nil {
+		return Endpoint{}, err
+	}
+
+	const q = `
+		INSERT INTO webhook_endpoints (workspace_id, created_by, url, description, events, secret)
+		VALUES (?, ?, ?, NULLIF(?, ''), ?, ?)
+	`
+
+	res, err := r.db.ExecContext(ctx, q, p.WorkspaceID, p.CreatedBy, p.URL, p.Description, string(events), p.Secret)
+	if err != nil {
+		return Endpoint{}, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Endpoint{}, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *Repo) GetByID(ctx context.Context, id int64) (Endpoint, error) {
+	e, err := scanEndpoint(r.db.QueryRowContext(ctx, selectEndpoint+` WHERE e.id = ? LIMIT 1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Endpoint{}, ErrNotFound
+	}
+	return e, err
+}
+
+// GetByIDForUser returns an endpoint in a workspace the user can edit. Endpoints are only ever
+// shown to editors: their URLs may carry credentials.
+func (r *Repo) GetByIDForUser(ctx context.Context, userID int64, id int64) (Endpoint, error) {
+	q := selectEndpoint + ` WHERE ` + workspaces.Editable("e.workspace_id") + ` AND e.id = ? LIMIT 1`
+
+	e, err := scanEndpoint(r.db.QueryRowContext(ctx, q, userID, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Endpoint{}, ErrNotFound
+	}
+	return e, err
+}
+
+// ListForUser returns the endpoints of workspaces the user can edit, or of workspaceID only
+// when it is not 0, oldest first.
+func (r *Repo) ListForUser(ctx context.Context, userID int64, workspaceID int64) ([]Endpoint, error) {
+	q := selectEndpoint + ` WHERE ` + workspaces.Editable("e.workspace_id")
+	args := []any{userID}
+	if workspaceID != 0 {
+		q += ` AND e.workspace_id = ?`
+		args = append(args, workspaceID)
+	}
+	q += ` ORDER BY e.created_at ASC, e.id ASC`
+
+	return r.listEndpoints(ctx, q, args...)
+}
+
+// ListSubscribed returns the active endpoints of a workspace subscribed to eventType.
+func (r *Repo) ListSubscribed(ctx context.Context, workspaceID int64, eventType string) ([]Endpoint, error) {
+	return r.listEndpoints(ctx, selectEndpoint+`
+		WHERE e.workspace_id = ? AND e.active = 1 AND JSON_CONTAINS(e.events, JSON_QUOTE(?))
+		ORDER BY e.id ASC
+	`, workspaceID, eventType)
+}
+
+func (r *Repo) listEndpoints(ctx context.Context, q string, args ...any) ([]Endpoint, error) {
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]Endpoint, 0)
+	for rows.Next() {
+		e, err := scanEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+func (r *Repo) Update(ctx context.Context, id int64, p UpdateParams) (Endpoint, error) {
+	sets := make([]string, 0, 4)
+	args := make([]any, 0, 5)
+
+	if p.URL != nil {
+		sets = append(sets, "url = ?")
+		args = append(args, *p.URL)
+	}
+	if p.Description != nil {
+		sets = append(sets, "description = NULLIF(?, '')")
+		args = append(args, *p.Description)
+	}
+	if p.Events != nil {
+		events, err := json.Marshal(p.Events)
+		if err != nil {
+			return Endpoint{}, err
+		}
+		sets = append(sets, "events = ?")
+		args = append(args, string(events))
+	}
+	if p.Active != nil {
+		sets = append(sets, "active = ?")
+		args = append(args, *p.Active)
+	}
+
+	if len(sets) > 0 {
+		args = append(args, id)
+		q := `UPDATE webhook_endpoints SET ` + strings.Join(sets, ", ") + ` WHERE id = ?`
+		if _, err := r.db.ExecContext(ctx, q, args...); err != nil {
+			return Endpoint{}, err
+		}
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+func (r *Repo) Delete(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM webhook_endpoints WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Queue logs the deliveries and writes an outbox event on topic for each, in one transaction.
+func (r *Repo) Queue(ctx context.Context, topic string, deliveries []NewDelivery) ([]int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	const q = `
+		INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload)
+		VALUES (?, ?, ?, ?)
+	`
+
+	ids := make([]int64, 0, len(deliveries))
+	for _, d := range deliveries {
+		res, err := tx.ExecContext(ctx, q, d.EndpointID, d.EventID, d.EventType, string(d.Payload))
+		if err != nil {
+			return nil, err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		if err := outbox.Insert(ctx, tx, topic, Queued{DeliveryID: id}); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *Repo) GetDelivery(ctx context.Context, id int64) (Delivery, error) {
+	d, err := scanDelivery(r.db.QueryRowContext(ctx, selectDelivery+` WHERE d.id = ? LIMIT 1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Delivery{}, ErrNotFound
+	}
+	return d, err
+}
+
+// ListDeliveries returns an endpoint's most recent deliveries, newest first.
+func (r *Repo) ListDeliveries(ctx context.Context, endpointID int64, limit int) ([]Delivery, error) {
+	rows, err := r.db.QueryContext(ctx, selectDelivery+`
+		WHERE d.endpoint_id = ?
+		ORDER BY d.id DESC
+		LIMIT ?
+	`, endpointID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]Delivery, 0)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// RecordAttempt logs the outcome of a delivery attempt.
+func (r *Repo) RecordAttempt(ctx context.Context, id int64, a Attempt) error {
+	status := StatusDelivered
+	if a.Error != "" {
+		status = StatusFailed
+	}
+
+	const q = `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = attempts + 1, response_status = NULLIF(?, 0), response_body = NULLIF(?, ''),
+		    last_error = NULLIF(?, ''), last_attempt_at = UTC_TIMESTAMP(),
+		    delivered_at = IF(? = 'delivered', UTC_TIMESTAMP(), delivered_at)
+		WHERE id = ?
+	`
+	_, err := r.db.ExecContext(ctx, q, status, a.ResponseStatus, truncate(a.ResponseBody, 1000), truncate(a.Error, 500), status, id)
+	return err
+}
+
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
@@ -0,0 +1,85 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrNotFound = errors.New("webhooks: not found")
+
+// Delivery statuses. A failed delivery may still be retried by the outbox.
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+type Endpoint struct {
+	ID          int64     `json:"id"`
+	WorkspaceID int64     `json:"workspace_id"`
+	CreatedBy   *int64    `json:"-"`
+	URL         string    `json:"url"`
+	Description *string   `json:"description,omitempty"`
+	Events      []string  `json:"events"`
+	Secret      string    `json:"-"` // sealed
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Delivery is one event sent (or to be sent) to one endpoint, with the outcome of its latest
+// attempt.
+type Delivery struct {
+	ID             int64           `json:"id"`
+	EndpointID     int64           `json:"endpoint_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus *int            `json:"response_status,omitempty"`
+	ResponseBody   *string         `json:"response_body,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+type CreateParams struct {
+	WorkspaceID int64
+	CreatedBy   int64
+	URL         string
+	Description string // "" is stored as NULL
+	Events      []string
+	Secret      string // sealed
+}
+
+// UpdateParams changes the fields that are set; a nil Events keeps the subscriptions.
+type UpdateParams struct {
+	URL         *string
+	Description *string // "" clears it
+	Events      []string
+	Active      *bool
+}
+
+// NewDelivery is an event to queue for one endpoint.
+type NewDelivery struct {
+	EndpointID int64
+	EventID    string
+	EventType  string
+	Payload    []byte
+}
+
+// Queued is the outbox payload asking for a delivery to be made.
+type Queued struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+// Attempt is the outcome of one delivery attempt.
+type Attempt struct {
+	ResponseStatus int // 0 when no response came back
+	ResponseBody   string
+	Error          string // "" when delivered
+}
@@ -0,0 +1,375 @@
+package workspaces
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+)
+
+type Repo struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Repo {
+	return &Repo{db: db}
+}
+
+// Create inserts a workspace and makes ownerID its owner. Creating a second personal workspace
+// for the same user returns ErrDuplicate.
+func (r *Repo) Create(ctx context.Context, name string, ownerID int64, personal bool) (Workspace, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Workspace{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var personalFlag interface{}
+	if personal {
+		personalFlag = 1
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO workspaces (uuid, name, owner_user_id, personal)
+		VALUES (?, ?, ?, ?)
+	`, uuid.NewString(), name, ownerID, personalFlag)
+	if err != nil {
+		if isDuplicate(err) {
+			return Workspace{}, ErrDuplicate
+		}
+		return Workspace{}, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Workspace{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO workspace_members (workspace_id, user_id, role) VALUES (?, ?, 'owner')
+	`, id, ownerID); err != nil {
+		return Workspace{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Workspace{}, err
+	}
+	return r.GetForUser(ctx, ownerID, id)
+}
+
+const selectWorkspace = `
+	SELECT w.id, w.uuid, w.name, w.owner_user_id, w.personal IS NOT NULL, wm.role, w.created_at, w.updated_at
+	FROM workspaces w
+	JOIN workspace_members wm ON wm.workspace_id = w.id AND wm.user_id = ?
+`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWorkspace(row rowScanner) (Workspace, error) {
+	var w Workspace
+	err := row.Scan(&w.ID, &w.UUID, &w.Name, &w.OwnerUserID, &w.Personal, &w.Role, &w.CreatedAt, &w.UpdatedAt)
+	return w, err
+}
+
+// GetForUser returns a workspace the user is a member of, with the user's role.
+func (r *Repo) GetForUser(ctx context.Context, userID int64, id int64) (Workspace, error) {
+	w, err := scanWorkspace(r.db.QueryRowContext(ctx, selectWorkspace+` WHERE w.id = ? LIMIT 1`, userID, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Workspace{}, ErrNotFound
+	}
+	if err != nil {
+		return Workspace{}, err
+	}
+	return w, nil
+}
+
+func (r *Repo) GetPersonal(ctx context.Context, userID int64) (Workspace, error) {
+	w, err := scanWorkspace(r.db.QueryRowContext(ctx, selectWorkspace+`
+		WHERE w.owner_user_id = ? AND w.personal = 1
+		LIMIT 1
+	`, userID, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Workspace{}, ErrNotFound
+	}
+	if err != nil {
+		return Workspace{}, err
+	}
+	return w, nil
+}
+
+// ListForUser returns every workspace the user belongs to, personal one first.
+func (r *Repo) ListForUser(ctx context.Context, userID int64) ([]Workspace, error) {
+	rows, err := r.db.QueryContext(ctx, selectWorkspace+`
+		ORDER BY w.personal IS NULL, w.name
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Workspace
+	for rows.Next() {
+		w, err := scanWorkspace(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *Repo) Rename(ctx context.Context, id int64, name string) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE workspaces SET name = ? WHERE id = ? LIMIT 1`, name, id)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		// MySQL reports 0 rows when the name did not change; tell that apart from a missing row.
+		var one int
+		err := r.db.QueryRowContext(ctx, `SELECT 1 FROM workspaces WHERE id = ?`, id).Scan(&one)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Role returns the user's role in the workspace, or ErrNotFound if they are not a member.
+func (r *Repo) Role(ctx context.Context, workspaceID int64, userID int64) (string, error) {
+	var role string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT role FROM workspace_members WHERE workspace_id = ? AND user_id = ? LIMIT 1
+	`, workspaceID, userID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return role, err
+}
+
+// CountOtherMembersOfOwned counts the members, besides the user, of workspaces the user owns.
+// Deleting the owner deletes those workspaces, so this must be 0 first.
+func (r *Repo) CountOtherMembersOfOwned(ctx context.Context, userID int64) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM workspace_members wm
+		JOIN workspaces w ON w.id = wm.workspace_id
+		WHERE w.owner_user_id = ? AND wm.user_id <> ?
+	`, userID, userID).Scan(&n)
+	return n, err
+}
+
+func (r *Repo) ListMembers(ctx context.Context, workspaceID int64) ([]Member, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT u.id, u.uuid, u.name, u.email, wm.role, wm.created_at
+		FROM workspace_members wm
+		JOIN users u ON u.id = wm.user_id
+		WHERE wm.workspace_id = ?
+		ORDER BY FIELD(wm.role, 'owner', 'editor', 'viewer'), u.name
+	`, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Member
+	for rows.Next() {
+		var m Member
+		if err := rows.Scan(&m.UserID, &m.UserUUID, &m.Name, &m.Email, &m.Role, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SetMemberRole changes a member's role. The owner's row is never changed here; callers check
+// membership first, since an unchanged role also affects no rows.
+func (r *Repo) SetMemberRole(ctx context.Context, workspaceID int64, userID int64, role string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE workspace_members
+		SET role = ?
+		WHERE workspace_id = ? AND user_id = ? AND role <> 'owner'
+		LIMIT 1
+	`, role, workspaceID, userID)
+	return err
+}
+
+// RemoveMember takes a user out of the workspace. The owner cannot be removed.
+func (r *Repo) RemoveMember(ctx context.Context, workspaceID int64, userID int64) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM workspace_members
+		WHERE workspace_id = ? AND user_id = ? AND role <> 'owner'
+		LIMIT 1
+	`, workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repo) CreateInvitation(ctx context.Context, p CreateInvitationParams) (Invitation, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO workspace_invitations (workspace_id, email, role, token_hash, invited_by, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, p.WorkspaceID, p.Email, p.Role, p.TokenHash, p.InvitedBy, p.ExpiresAt.UTC())
+	if err != nil {
+		return Invitation{}, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Invitation{}, err
+	}
+
+	items, err := r.listInvitations(ctx, `WHERE id = ?`, id)
+	if err != nil {
+		return Invitation{}, err
+	}
+	if len(items) == 0 {
+		return Invitation{}, ErrNotFound
+	}
+	return items[0], nil
+}
+
+// ListPendingInvitations returns invitations that were neither accepted nor expired.
+func (r *Repo) ListPendingInvitations(ctx context.Context, workspaceID int64) ([]Invitation, error) {
+	return r.listInvitations(ctx, `
+		WHERE workspace_id = ? AND accepted_at IS NULL AND expires_at > UTC_TIMESTAMP()
+		ORDER BY created_at DESC
+	`, workspaceID)
+}
+
+// GetPendingInvitationByHash looks up an invitation that can still be accepted.
+func (r *Repo) GetPendingInvitationByHash(ctx context.Context, tokenHash string) (Invitation, error) {
+	items, err := r.listInvitations(ctx, `
+		WHERE token_hash = ? AND accepted_at IS NULL AND expires_at > UTC_TIMESTAMP()
+		LIMIT 1
+	`, tokenHash)
+	if err != nil {
+		return Invitation{}, err
+	}
+	if len(items) == 0 {
+		return Invitation{}, ErrNotFound
+	}
+	return items[0], nil
+}
+
+func (r *Repo) listInvitations(ctx context.Context, where string, args ...interface{}) ([]Invitation, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, workspace_id, email, role, invited_by, expires_at, accepted_at, created_at
+		FROM workspace_invitations
+	`+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Invitation
+	for rows.Next() {
+		var inv Invitation
+		var accepted sql.NullTime
+		if err := rows.Scan(&inv.ID, &inv.WorkspaceID, &inv.Email, &inv.Role, &inv.InvitedBy, &inv.ExpiresAt, &accepted, &inv.CreatedAt); err != nil {
+			return nil, err
+		}
+		if accepted.Valid {
+			v := accepted.Time
+			inv.AcceptedAt = &v
+		}
+		out = append(out, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *Repo) DeleteInvitation(ctx context.Context, workspaceID int64, id int64) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM workspace_invitations
+		WHERE workspace_id = ? AND id = ? AND accepted_at IS NULL
+		LIMIT 1
+	`, workspaceID, id)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// AcceptInvitation marks the invitation used and adds the user with its role. Existing members
+// keep their membership but take the invited role, unless they own the workspace.
+func (r *Repo) AcceptInvitation(ctx context.Context, inv Invitation, userID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE workspace_invitations
+		SET accepted_at = UTC_TIMESTAMP()
+		WHERE id = ? AND accepted_at IS NULL AND expires_at > UTC_TIMESTAMP()
+		LIMIT 1
+	`, inv.ID)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		// Someone else used it first.
+		return ErrNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO workspace_members (workspace_id, user_id, role)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE role = IF(role = 'owner', role, VALUES(role))
+	`, inv.WorkspaceID, userID, inv.Role); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func isDuplicate(err error) bool {
+	var me *mysql.MySQLError
+	return errors.As(err, &me) && me.Number == 1062
+}
+
+// NormalizeEmail is how invitation addresses are stored and compared.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
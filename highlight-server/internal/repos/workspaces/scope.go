@@ -0,0 +1,22 @@
+package workspaces
+
+// Readable returns a WHERE fragment limiting col (a workspace_id column) to workspaces the user
+// is a member of. The fragment takes the user id as its single argument.
+func Readable(col string) string {
+	return col + ` IN (SELECT wm.workspace_id FROM workspace_members wm WHERE wm.user_id = ?)`
+}
+
+// Editable is Readable restricted to workspaces where the user may change content.
+func Editable(col string) string {
+	return col + ` IN (SELECT wm.workspace_id FROM workspace_members wm WHERE wm.user_id = ? AND wm.role IN ('owner','editor'))`
+}
+
+// Owned is Readable restricted to workspaces the user owns.
+func Owned(col string) string {
+	return col + ` IN (SELECT wm.workspace_id FROM workspace_members wm WHERE wm.user_id = ? AND wm.role = 'owner')`
+}
+
+// CanEdit reports whether role may create, change and delete content.
+func CanEdit(role string) bool {
+	return role == RoleOwner || role == RoleEditor
+}
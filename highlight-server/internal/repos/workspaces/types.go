@@ -0,0 +1,57 @@
+package workspaces
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotFound  = errors.New("workspaces: not found")
+	ErrDuplicate = errors.New("workspaces: duplicate")
+)
+
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+type Workspace struct {
+	ID          int64     `json:"id"`
+	UUID        string    `json:"uuid"`
+	Name        string    `json:"name"`
+	OwnerUserID int64     `json:"owner_user_id"`
+	Personal    bool      `json:"personal"`
+	Role        string    `json:"role,omitempty"` // the requesting user's role
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type Member struct {
+	UserID    int64     `json:"-"`
+	UserUUID  string    `json:"user_id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Invitation struct {
+	ID          int64      `json:"id"`
+	WorkspaceID int64      `json:"workspace_id"`
+	Email       string     `json:"email"`
+	Role        string     `json:"role"`
+	InvitedBy   int64      `json:"-"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type CreateInvitationParams struct {
+	WorkspaceID int64
+	Email       string
+	Role        string
+	TokenHash   string
+	InvitedBy   int64
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,96 @@
+package youtubeaccounts
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type Repo struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Repo {
+	return &Repo{db: db}
+}
+
+func (r *Repo) Get(ctx context.Context, userID int64) (Account, error) {
+	const q = `
+		SELECT user_id, channel_id, channel_title, refresh_token_enc, access_token_enc, access_expires_at, created_at
+		FROM youtube_accounts
+		WHERE user_id = ?
+		LIMIT 1
+	`
+
+	var a Account
+	var access sql.NullString
+	var expires sql.NullTime
+	err := r.db.QueryRowContext(ctx, q, userID).Scan(
+		&a.UserID,
+		&a.ChannelID,
+		&a.ChannelTitle,
+		&a.RefreshTokenEnc,
+		&access,
+		&expires,
+		&a.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Account{}, ErrNotFound
+	}
+	if err != nil {
+		return Account{}, err
+	}
+	if access.Valid {
+		v := access.String
+		a.AccessTokenEnc = &v
+	}
+	if expires.Valid {
+		v := expires.Time
+		a.AccessExpiresAt = &v
+	}
+	return a, nil
+}
+
+// Save connects a channel, replacing an earlier connection of the same user.
+func (r *Repo) Save(ctx context.Context, p SaveParams) error {
+	const q = `
+		INSERT INTO youtube_accounts (user_id, channel_id, channel_title, refresh_token_enc, access_token_enc, access_expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			channel_id = VALUES(channel_id),
+			channel_title = VALUES(channel_title),
+			refresh_token_enc = VALUES(refresh_token_enc),
+			access_token_enc = VALUES(access_token_enc),
+			access_expires_at = VALUES(access_expires_at)
+	`
+	_, err := r.db.ExecContext(ctx, q, p.UserID, p.ChannelID, p.ChannelTitle, p.RefreshTokenEnc, p.AccessTokenEnc, p.AccessExpiresAt.UTC())
+	return err
+}
+
+// UpdateAccessToken stores a refreshed access token.
+func (r *Repo) UpdateAccessToken(ctx context.Context, userID int64, accessTokenEnc string, expiresAt time.Time) error {
+	const q = `
+		UPDATE youtube_accounts
+		SET access_token_enc = ?, access_expires_at = ?
+		WHERE user_id = ?
+		LIMIT 1
+	`
+	_, err := r.db.ExecContext(ctx, q, accessTokenEnc, expiresAt.UTC(), userID)
+	return err
+}
+
+func (r *Repo) Delete(ctx context.Context, userID int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM youtube_accounts WHERE user_id = ? LIMIT 1`, userID)
+	if err != nil {
+		return err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package youtubeaccounts
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNotFound = errors.New("youtube_accounts: not found")
+
+// Account is a user's connected YouTube channel. The token fields are sealed; only the
+// publisher opens them.
+type Account struct {
+	UserID          int64
+	ChannelID       string
+	ChannelTitle    string
+	RefreshTokenEnc string
+	AccessTokenEnc  *string
+	AccessExpiresAt *time.Time
+	CreatedAt       time.Time
+}
+
+type SaveParams struct {
+	UserID          int64
+	ChannelID       string
+	ChannelTitle    string
+	RefreshTokenEnc string
+	AccessTokenEnc  string
+	AccessExpiresAt time.Time
+}
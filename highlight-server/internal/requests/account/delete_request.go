@@ -0,0 +1,12 @@
+package account
+
+// DeleteRequest confirms account deletion with the current password, or with confirmation_code
+// for accounts without one.
+type DeleteRequest struct {
+	Password         string `json:"password" validate:"required_without=ConfirmationCode,max=72"`
+	ConfirmationCode string `json:"confirmation_code" validate:"omitempty,max=200"`
+}
+
+func (r DeleteRequest) Validate() error {
+	return validate.Struct(r)
+}
@@ -1,4 +1,4 @@
-package youtubepublishes
+package account
 
 import "github.com/go-playground/validator/v10"
 
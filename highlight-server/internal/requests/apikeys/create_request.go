@@ -0,0 +1,10 @@
+package apikeys
+
+type CreateRequest struct {
+	Name   string   `json:"name" validate:"required,max=60"`
+	Scopes []string `json:"scopes" validate:"required,min=1,max=20,dive,required,max=40"`
+}
+
+func (r CreateRequest) Validate() error {
+	return validate.Struct(r)
+}
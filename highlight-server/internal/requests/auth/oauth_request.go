@@ -0,0 +1,10 @@
+package auth
+
+import "strings"
+
+func (r OAuthCallbackRequest) Validate() error {
+	clean := r
+	clean.Code = strings.TrimSpace(clean.Code)
+	clean.State = strings.TrimSpace(clean.State)
+	return validateRequest(clean)
+}
@@ -0,0 +1,21 @@
+package auth
+
+import "strings"
+
+func (r VerifyEmailRequest) Validate() error {
+	clean := r
+	clean.Token = strings.TrimSpace(clean.Token)
+	return validateRequest(clean)
+}
+
+func (r ForgotPasswordRequest) Validate() error {
+	clean := r
+	clean.Email = strings.TrimSpace(clean.Email)
+	return validateRequest(clean)
+}
+
+func (r ResetPasswordRequest) Validate() error {
+	clean := r
+	clean.Token = strings.TrimSpace(clean.Token)
+	return validateRequest(clean)
+}
@@ -0,0 +1,29 @@
+package auth
+
+import "strings"
+
+func (r UpdateProfileRequest) Validate() error {
+	clean := r
+	if clean.Name != nil {
+		name := strings.TrimSpace(*clean.Name)
+		clean.Name = &name
+	}
+	if clean.Email != nil {
+		email := strings.TrimSpace(*clean.Email)
+		clean.Email = &email
+	}
+	if err := validateRequest(clean); err != nil {
+		return err
+	}
+	clean.ConfirmationCode = strings.TrimSpace(clean.ConfirmationCode)
+	if clean.Email != nil && clean.CurrentPassword == "" && clean.ConfirmationCode == "" {
+		return ValidationError{"current_password": "current_password or confirmation_code is required to change the email"}
+	}
+	return nil
+}
+
+func (r ChangePasswordRequest) Validate() error {
+	clean := r
+	clean.ConfirmationCode = strings.TrimSpace(clean.ConfirmationCode)
+	return validateRequest(clean)
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func (r RefreshRequest) Validate() error {
+	clean := r
+	clean.RefreshToken = strings.TrimSpace(clean.RefreshToken)
+	return validateRequest(clean)
+}
+
+func (r LogoutRequest) Validate() error {
+	clean := r
+	clean.RefreshToken = strings.TrimSpace(clean.RefreshToken)
+	return validateRequest(clean)
+}
+
+// validateRequest runs the struct tags of req and reports failures keyed by JSON field name.
+func validateRequest(req any) error {
+	err := validate.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	errs := ValidationError{}
+
+	ve, ok := err.(validator.ValidationErrors)
+	if !ok {
+		errs["general"] = "invalid request"
+		return errs
+	}
+
+	t := reflect.TypeOf(req)
+	for _, fe := range ve {
+		key := strings.ToLower(fe.Field())
+		if f, ok := t.FieldByName(fe.Field()); ok {
+			if tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+				key = tag
+			}
+		}
+		errs[key] = messageForFieldError(key, fe)
+	}
+	return errs
+}
@@ -0,0 +1,23 @@
+package auth
+
+import "strings"
+
+func (r LoginVerifyRequest) Validate() error {
+	clean := r
+	clean.ChallengeToken = strings.TrimSpace(clean.ChallengeToken)
+	clean.Code = strings.TrimSpace(clean.Code)
+	return validateRequest(clean)
+}
+
+func (r TwoFactorCodeRequest) Validate() error {
+	clean := r
+	clean.Code = strings.TrimSpace(clean.Code)
+	return validateRequest(clean)
+}
+
+func (r DisableTwoFactorRequest) Validate() error {
+	clean := r
+	clean.Code = strings.TrimSpace(clean.Code)
+	clean.ConfirmationCode = strings.TrimSpace(clean.ConfirmationCode)
+	return validateRequest(clean)
+}
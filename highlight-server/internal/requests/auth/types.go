@@ -17,3 +17,65 @@ type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email,max=120"`
 	Password string `json:"password" validate:"required,min=8,max=72"`
 }
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required,max=200"`
+}
+
+// LogoutRequest may carry the refresh token of the session being closed.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"omitempty,max=200"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required,max=200"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email,max=120"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required,max=200"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+type LoginVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required,max=200"`
+	Code           string `json:"code" validate:"required,max=20"`
+}
+
+// TwoFactorCodeRequest carries a TOTP code, or a recovery code where those are accepted.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,max=20"`
+}
+
+// DisableTwoFactorRequest needs the password, or confirmation_code for accounts without one.
+type DisableTwoFactorRequest struct {
+	Password         string `json:"password" validate:"required_without=ConfirmationCode,max=72"`
+	ConfirmationCode string `json:"confirmation_code" validate:"omitempty,max=200"`
+	Code             string `json:"code" validate:"required,max=20"`
+}
+
+// UpdateProfileRequest is a partial update; current_password (or confirmation_code, for
+// accounts without a password) is required to change the email.
+type UpdateProfileRequest struct {
+	Name             *string `json:"name" validate:"omitempty,min=2,max=60"`
+	Email            *string `json:"email" validate:"omitempty,email,max=120"`
+	CurrentPassword  string  `json:"current_password" validate:"omitempty,max=72"`
+	ConfirmationCode string  `json:"confirmation_code" validate:"omitempty,max=200"`
+}
+
+// ChangePasswordRequest needs current_password, or confirmation_code to set the first password
+// of an account created through a provider.
+type ChangePasswordRequest struct {
+	CurrentPassword  string `json:"current_password" validate:"required_without=ConfirmationCode,max=72"`
+	ConfirmationCode string `json:"confirmation_code" validate:"omitempty,max=200"`
+	NewPassword      string `json:"new_password" validate:"required,min=8,max=72"`
+}
+
+// OAuthCallbackRequest is what the provider sent back to the web app's redirect page.
+type OAuthCallbackRequest struct {
+	Code  string `json:"code" validate:"required,max=2048"`
+	State string `json:"state" validate:"required,max=200"`
+}
@@ -0,0 +1,18 @@
+package collections
+
+type AddClipRequest struct {
+	ClipID int64 `json:"clip_id" validate:"required,gt=0"`
+}
+
+func (r AddClipRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// ReorderRequest carries every clip id of the collection in the desired order, each once.
+type ReorderRequest struct {
+	ClipIDs []int64 `json:"clip_ids" validate:"required,min=1,unique,dive,gt=0"`
+}
+
+func (r ReorderRequest) Validate() error {
+	return validate.Struct(r)
+}
@@ -0,0 +1,10 @@
+package collections
+
+type CreateRequest struct {
+	Title       string  `json:"title" validate:"required,min=1,max=120"`
+	Description *string `json:"description" validate:"omitempty,max=5000"`
+}
+
+func (r CreateRequest) Validate() error {
+	return validate.Struct(r)
+}
@@ -0,0 +1,10 @@
+package collections
+
+type InternalPlaylistRequest struct {
+	CollectionID      int64  `json:"collection_id" validate:"required,gt=0"`
+	YoutubePlaylistID string `json:"youtube_playlist_id" validate:"required,max=64"`
+}
+
+func (r InternalPlaylistRequest) Validate() error {
+	return validate.Struct(r)
+}
@@ -0,0 +1,10 @@
+package collections
+
+type UpdateRequest struct {
+	Title       *string `json:"title" validate:"omitempty,min=1,max=120"`
+	Description *string `json:"description" validate:"omitempty,max=5000"`
+}
+
+func (r UpdateRequest) Validate() error {
+	return validate.Struct(r)
+}
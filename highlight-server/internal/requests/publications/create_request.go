@@ -0,0 +1,21 @@
+package publications
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type CreateRequest struct {
+	Platform   string  `json:"platform" validate:"required,oneof=youtube tiktok instagram x twitch"`
+	ExternalID string  `json:"external_id" validate:"required,max=64"`
+	URL        string  `json:"url" validate:"required,max=500"`
+	Status     *string `json:"status" validate:"omitempty,oneof=queued uploaded failed"`
+
+	PublishedAt  *time.Time       `json:"published_at" validate:"omitempty"`
+	LastSyncedAt *time.Time       `json:"last_synced_at" validate:"omitempty"`
+	Metadata     *json.RawMessage `json:"metadata,omitempty"`
+}
+
+func (r CreateRequest) Validate() error {
+	return validate.Struct(r)
+}
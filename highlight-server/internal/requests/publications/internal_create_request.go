@@ -0,0 +1,31 @@
+package publications
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type InternalCreateRequest struct {
+	// PublishID names the publish request being reported on; without it a new row is recorded.
+	PublishID  *int64  `json:"publish_id" validate:"omitempty,gt=0"`
+	ClipID     int64   `json:"clip_id" validate:"required,gt=0"`
+	Platform   string  `json:"platform" validate:"required,oneof=youtube tiktok instagram x twitch"`
+	ExternalID string  `json:"external_id" validate:"required,max=64"`
+	URL        string  `json:"url" validate:"required,max=500"`
+	Status     *string `json:"status" validate:"omitempty,oneof=queued uploaded failed"`
+
+	PublishedAt  *time.Time `json:"published_at" validate:"omitempty"`
+	LastSyncedAt *time.Time `json:"last_synced_at" validate:"omitempty"`
+
+	Views    int `json:"views" validate:"gte=0"`
+	Likes    int `json:"likes" validate:"gte=0"`
+	Comments int `json:"comments" validate:"gte=0"`
+	Shares   int `json:"shares" validate:"gte=0"`
+
+	Metadata  *json.RawMessage `json:"metadata,omitempty"`
+	Analytics *json.RawMessage `json:"analytics,omitempty"`
+}
+
+func (r InternalCreateRequest) Validate() error {
+	return validate.Struct(r)
+}
@@ -0,0 +1,13 @@
+package publications
+
+import "time"
+
+type InternalMarkDeletedRequest struct {
+	Platform     string     `json:"platform" validate:"required,oneof=youtube tiktok instagram x twitch"`
+	ExternalID   string     `json:"external_id" validate:"required,max=64"`
+	LastSyncedAt *time.Time `json:"last_synced_at" validate:"omitempty"`
+}
+
+func (r InternalMarkDeletedRequest) Validate() error {
+	return validate.Struct(r)
+}
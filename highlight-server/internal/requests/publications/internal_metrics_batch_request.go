@@ -0,0 +1,22 @@
+package publications
+
+// InternalMetricsBatchItem is an InternalMetricsRequest that can also report the post as
+// deleted.
+type InternalMetricsBatchItem struct {
+	InternalMetricsRequest
+	Deleted bool `json:"deleted"`
+}
+
+func (r InternalMetricsBatchItem) Validate() error {
+	return validate.Struct(r)
+}
+
+// InternalMetricsBatchRequest carries a whole sync run. Items are validated one by one, so a bad
+// item is reported on its own instead of failing the batch.
+type InternalMetricsBatchRequest struct {
+	Items []InternalMetricsBatchItem `json:"items" validate:"required,min=1,max=500"`
+}
+
+func (r InternalMetricsBatchRequest) Validate() error {
+	return validate.Struct(r)
+}
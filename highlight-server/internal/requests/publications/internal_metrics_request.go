@@ -1,4 +1,4 @@
-package youtubepublishes
+package publications
 
 import (
 	"encoding/json"
@@ -6,11 +6,13 @@ import (
 )
 
 type InternalMetricsRequest struct {
-	YoutubeVideoID string `json:"youtube_video_id" validate:"required,max=32"`
+	Platform   string `json:"platform" validate:"required,oneof=youtube tiktok instagram x twitch"`
+	ExternalID string `json:"external_id" validate:"required,max=64"`
 
 	Views    *int `json:"views" validate:"omitempty,gte=0"`
 	Likes    *int `json:"likes" validate:"omitempty,gte=0"`
 	Comments *int `json:"comments" validate:"omitempty,gte=0"`
+	Shares   *int `json:"shares" validate:"omitempty,gte=0"`
 
 	PublishedAt  *time.Time       `json:"published_at" validate:"omitempty"`
 	LastSyncedAt *time.Time       `json:"last_synced_at" validate:"omitempty"`
@@ -0,0 +1,25 @@
+package publications
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// PublishRequest asks for an exported clip to be published. Omitted metadata comes from the clip;
+// platform defaults to youtube, privacy to private and publish_at to now. Metadata carries
+// platform-specific options (e.g. a TikTok sound or an Instagram cover frame) through to the publisher.
+type PublishRequest struct {
+	Platform    *string  `json:"platform" validate:"omitempty,oneof=youtube tiktok instagram x twitch"`
+	Title       *string  `json:"title" validate:"omitempty,min=1,max=100"`
+	Description *string  `json:"description" validate:"omitempty,max=5000"`
+	Tags        []string `json:"tags" validate:"omitempty,max=30,dive,min=1,max=100"`
+	Privacy     *string  `json:"privacy" validate:"omitempty,oneof=public unlisted private"`
+	ChannelID   *string  `json:"channel_id" validate:"omitempty,max=64"`
+
+	Metadata  *json.RawMessage `json:"metadata,omitempty"`
+	PublishAt *time.Time       `json:"publish_at" validate:"omitempty"`
+}
+
+func (r PublishRequest) Validate() error {
+	return validate.Struct(r)
+}
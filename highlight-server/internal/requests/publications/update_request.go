@@ -1,10 +1,10 @@
-package youtubepublishes
+package publications
 
 import "time"
 
 type UpdateRequest struct {
-	YoutubeURL *string `json:"youtube_url" validate:"omitempty,max=255"`
-	Status     *string `json:"status" validate:"omitempty,oneof=queued uploaded failed"`
+	URL    *string `json:"url" validate:"omitempty,max=500"`
+	Status *string `json:"status" validate:"omitempty,oneof=queued uploaded failed"`
 
 	PublishedAt  *time.Time `json:"published_at" validate:"omitempty"`
 	LastSyncedAt *time.Time `json:"last_synced_at" validate:"omitempty"`
@@ -0,0 +1,159 @@
+package publications
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// The YouTube-only request shapes below predate multi-platform publishing. They are still
+// accepted on the /youtube-publishes routes and map onto the generic requests.
+
+type YoutubeCreateRequest struct {
+	YoutubeVideoID string  `json:"youtube_video_id" validate:"required,max=32"`
+	YoutubeURL     string  `json:"youtube_url" validate:"required,max=255"`
+	Status         *string `json:"status" validate:"omitempty,oneof=queued uploaded failed"`
+
+	PublishedAt  *time.Time `json:"published_at" validate:"omitempty"`
+	LastSyncedAt *time.Time `json:"last_synced_at" validate:"omitempty"`
+}
+
+func (r YoutubeCreateRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+func (r YoutubeCreateRequest) Generic() CreateRequest {
+	return CreateRequest{
+		Platform:     "youtube",
+		ExternalID:   r.YoutubeVideoID,
+		URL:          r.YoutubeURL,
+		Status:       r.Status,
+		PublishedAt:  r.PublishedAt,
+		LastSyncedAt: r.LastSyncedAt,
+	}
+}
+
+type YoutubeUpdateRequest struct {
+	YoutubeURL *string `json:"youtube_url" validate:"omitempty,max=255"`
+	Status     *string `json:"status" validate:"omitempty,oneof=queued uploaded failed"`
+
+	PublishedAt  *time.Time `json:"published_at" validate:"omitempty"`
+	LastSyncedAt *time.Time `json:"last_synced_at" validate:"omitempty"`
+}
+
+func (r YoutubeUpdateRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+func (r YoutubeUpdateRequest) Generic() UpdateRequest {
+	return UpdateRequest{
+		URL:          r.YoutubeURL,
+		Status:       r.Status,
+		PublishedAt:  r.PublishedAt,
+		LastSyncedAt: r.LastSyncedAt,
+	}
+}
+
+type YoutubeInternalCreateRequest struct {
+	PublishID      *int64  `json:"publish_id" validate:"omitempty,gt=0"`
+	ClipID         int64   `json:"clip_id" validate:"required,gt=0"`
+	YoutubeVideoID string  `json:"youtube_video_id" validate:"required,max=32"`
+	YoutubeURL     string  `json:"youtube_url" validate:"required,max=255"`
+	Status         *string `json:"status" validate:"omitempty,oneof=queued uploaded failed"`
+
+	PublishedAt  *time.Time `json:"published_at" validate:"omitempty"`
+	LastSyncedAt *time.Time `json:"last_synced_at" validate:"omitempty"`
+
+	Views    int `json:"views" validate:"gte=0"`
+	Likes    int `json:"likes" validate:"gte=0"`
+	Comments int `json:"comments" validate:"gte=0"`
+
+	Analytics *json.RawMessage `json:"analytics,omitempty"`
+}
+
+func (r YoutubeInternalCreateRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+func (r YoutubeInternalCreateRequest) Generic() InternalCreateRequest {
+	return InternalCreateRequest{
+		PublishID:    r.PublishID,
+		ClipID:       r.ClipID,
+		Platform:     "youtube",
+		ExternalID:   r.YoutubeVideoID,
+		URL:          r.YoutubeURL,
+		Status:       r.Status,
+		PublishedAt:  r.PublishedAt,
+		LastSyncedAt: r.LastSyncedAt,
+		Views:        r.Views,
+		Likes:        r.Likes,
+		Comments:     r.Comments,
+		Analytics:    r.Analytics,
+	}
+}
+
+type YoutubeInternalMarkDeletedRequest struct {
+	YoutubeVideoID string     `json:"youtube_video_id" validate:"required,max=32"`
+	LastSyncedAt   *time.Time `json:"last_synced_at" validate:"omitempty"`
+}
+
+func (r YoutubeInternalMarkDeletedRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+func (r YoutubeInternalMarkDeletedRequest) Generic() InternalMarkDeletedRequest {
+	return InternalMarkDeletedRequest{
+		Platform:     "youtube",
+		ExternalID:   r.YoutubeVideoID,
+		LastSyncedAt: r.LastSyncedAt,
+	}
+}
+
+type YoutubeInternalMetricsRequest struct {
+	YoutubeVideoID string `json:"youtube_video_id" validate:"required,max=32"`
+
+	Views    *int `json:"views" validate:"omitempty,gte=0"`
+	Likes    *int `json:"likes" validate:"omitempty,gte=0"`
+	Comments *int `json:"comments" validate:"omitempty,gte=0"`
+
+	PublishedAt  *time.Time       `json:"published_at" validate:"omitempty"`
+	LastSyncedAt *time.Time       `json:"last_synced_at" validate:"omitempty"`
+	Analytics    *json.RawMessage `json:"analytics,omitempty"`
+}
+
+func (r YoutubeInternalMetricsRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+func (r YoutubeInternalMetricsRequest) Generic() InternalMetricsRequest {
+	return InternalMetricsRequest{
+		Platform:     "youtube",
+		ExternalID:   r.YoutubeVideoID,
+		Views:        r.Views,
+		Likes:        r.Likes,
+		Comments:     r.Comments,
+		PublishedAt:  r.PublishedAt,
+		LastSyncedAt: r.LastSyncedAt,
+		Analytics:    r.Analytics,
+	}
+}
+
+type YoutubeInternalMetricsBatchItem struct {
+	YoutubeInternalMetricsRequest
+	Deleted bool `json:"deleted"`
+}
+
+type YoutubeInternalMetricsBatchRequest struct {
+	Items []YoutubeInternalMetricsBatchItem `json:"items" validate:"required,min=1,max=500"`
+}
+
+func (r YoutubeInternalMetricsBatchRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+func (r YoutubeInternalMetricsBatchRequest) Generic() InternalMetricsBatchRequest {
+	items := make([]InternalMetricsBatchItem, len(r.Items))
+	for i, it := range r.Items {
+		items[i] = InternalMetricsBatchItem{InternalMetricsRequest: it.Generic(), Deleted: it.Deleted}
+	}
+	return InternalMetricsBatchRequest{Items: items}
+}
@@ -0,0 +1,9 @@
+package tags
+
+type SetRequest struct {
+	Tags []string `json:"tags" validate:"max=30,dive,required,max=40"`
+}
+
+func (r SetRequest) Validate() error {
+	return validate.Struct(r)
+}
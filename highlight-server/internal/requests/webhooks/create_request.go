@@ -0,0 +1,13 @@
+package webhooks
+
+// CreateRequest registers an endpoint; workspace_id defaults to the personal workspace.
+type CreateRequest struct {
+	WorkspaceID *int64   `json:"workspace_id" validate:"omitempty,gt=0"`
+	URL         string   `json:"url" validate:"required,http_url,max=500"`
+	Description string   `json:"description" validate:"max=255"`
+	Events      []string `json:"events" validate:"required,min=1,max=20,dive,oneof=recording.uploaded detection.completed candidate.approved clip.exported publish.updated publish.deleted"`
+}
+
+func (r CreateRequest) Validate() error {
+	return validate.Struct(r)
+}
@@ -0,0 +1,12 @@
+package webhooks
+
+type UpdateRequest struct {
+	URL         *string  `json:"url" validate:"omitempty,http_url,max=500"`
+	Description *string  `json:"description" validate:"omitempty,max=255"`
+	Events      []string `json:"events" validate:"omitempty,min=1,max=20,dive,oneof=recording.uploaded detection.completed candidate.approved clip.exported publish.updated publish.deleted"`
+	Active      *bool    `json:"active"`
+}
+
+func (r UpdateRequest) Validate() error {
+	return validate.Struct(r)
+}
@@ -0,0 +1,5 @@
+package webhooks
+
+import "github.com/go-playground/validator/v10"
+
+var validate = validator.New()
@@ -0,0 +1,9 @@
+package workspaces
+
+type AcceptRequest struct {
+	Token string `json:"token" validate:"required,max=200"`
+}
+
+func (r AcceptRequest) Validate() error {
+	return validate.Struct(r)
+}
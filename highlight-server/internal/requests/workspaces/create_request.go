@@ -0,0 +1,9 @@
+package workspaces
+
+type CreateRequest struct {
+	Name string `json:"name" validate:"required,max=80"`
+}
+
+func (r CreateRequest) Validate() error {
+	return validate.Struct(r)
+}
@@ -0,0 +1,10 @@
+package workspaces
+
+type InviteRequest struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+	Role  string `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+func (r InviteRequest) Validate() error {
+	return validate.Struct(r)
+}
@@ -0,0 +1,9 @@
+package workspaces
+
+type MemberRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+func (r MemberRoleRequest) Validate() error {
+	return validate.Struct(r)
+}
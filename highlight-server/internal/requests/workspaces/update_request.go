@@ -0,0 +1,9 @@
+package workspaces
+
+type UpdateRequest struct {
+	Name string `json:"name" validate:"required,max=80"`
+}
+
+func (r UpdateRequest) Validate() error {
+	return validate.Struct(r)
+}
@@ -0,0 +1,11 @@
+package youtubeaccount
+
+// ConnectCallbackRequest is what Google sent back to the web app's redirect page.
+type ConnectCallbackRequest struct {
+	Code  string `json:"code" validate:"required,max=2048"`
+	State string `json:"state" validate:"required,max=200"`
+}
+
+func (r ConnectCallbackRequest) Validate() error {
+	return validate.Struct(r)
+}
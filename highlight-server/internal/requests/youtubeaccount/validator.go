@@ -0,0 +1,5 @@
+package youtubeaccount
+
+import "github.com/go-playground/validator/v10"
+
+var validate = validator.New()
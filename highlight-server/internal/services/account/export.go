@@ -0,0 +1,260 @@
+package account
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"time"
+
+	apikeysrepo "highlightiq-server/internal/repos/apikeys"
+	clipsrepo "highlightiq-server/internal/repos/clips"
+	collectionsrepo "highlightiq-server/internal/repos/collections"
+	pubrepo "highlightiq-server/internal/repos/publications"
+	tagsrepo "highlightiq-server/internal/repos/tags"
+	wsrepo "highlightiq-server/internal/repos/workspaces"
+	usagesvc "highlightiq-server/internal/services/usage"
+	"highlightiq-server/internal/storage"
+)
+
+// Export is everything stored about the user: their profile, memberships and the content they
+// created, trashed items included. Content other members added to shared workspaces is theirs
+// and is left out.
+type Export struct {
+	ExportedAt     time.Time                    `json:"exported_at"`
+	Account        Account                      `json:"account"`
+	Workspaces     []wsrepo.Workspace           `json:"workspaces"`
+	Recordings     []Recording                  `json:"recordings"`
+	ClipCandidates []Candidate                  `json:"clip_candidates"`
+	Clips          []clipsrepo.Clip             `json:"clips"`
+	Publications   []pubrepo.Publication        `json:"publications"`
+	Tags           []tagsrepo.Tag               `json:"tags"`
+	Collections    []collectionsrepo.Collection `json:"collections"`
+	APIKeys        []apikeysrepo.APIKey         `json:"api_keys"`
+	Usage          usagesvc.Report              `json:"usage"`
+}
+
+type Account struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+}
+
+type Recording struct {
+	ID              int64      `json:"id"`
+	UUID            string     `json:"uuid"`
+	WorkspaceID     int64      `json:"workspace_id"`
+	Title           string     `json:"title"`
+	OriginalName    string     `json:"original_filename"`
+	StoragePath     string     `json:"storage_path"`
+	DurationSeconds int        `json:"duration_seconds"`
+	Status          string     `json:"status"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+type Candidate struct {
+	ID          int64           `json:"id"`
+	RecordingID int64           `json:"recording_id"`
+	StartMS     int             `json:"start_ms"`
+	EndMS       int             `json:"end_ms"`
+	Score       float64         `json:"score"`
+	Detected    json.RawMessage `json:"detected,omitempty"`
+	Status      string          `json:"status"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// Export gathers the user's data. The archive itself is written by WriteArchive, so failures
+// here can still be reported before a download starts.
+func (s *Service) Export(ctx context.Context, userID int64) (Export, error) {
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return Export{}, err
+	}
+
+	out := Export{
+		ExportedAt: time.Now().UTC(),
+		Account: Account{
+			ID:              u.UUID,
+			Name:            u.Name,
+			Email:           u.Email,
+			EmailVerifiedAt: u.EmailVerifiedAt,
+		},
+		Recordings:     []Recording{},
+		ClipCandidates: []Candidate{},
+		Clips:          []clipsrepo.Clip{},
+		Publications:   []pubrepo.Publication{},
+	}
+
+	if out.Workspaces, err = s.workspaces.ListForUser(ctx, u.ID); err != nil {
+		return Export{}, err
+	}
+
+	recs, err := s.recordings.ListForAccount(ctx, u.ID)
+	if err != nil {
+		return Export{}, err
+	}
+	for _, rec := range recs {
+		if rec.UserID != u.ID {
+			continue
+		}
+		out.Recordings = append(out.Recordings, Recording{
+			ID:              rec.ID,
+			UUID:            rec.UUID,
+			WorkspaceID:     rec.WorkspaceID,
+			Title:           rec.Title,
+			OriginalName:    rec.OriginalName,
+			StoragePath:     rec.StoragePath,
+			DurationSeconds: rec.DurationSeconds,
+			Status:          rec.Status,
+			DeletedAt:       rec.DeletedAt,
+			CreatedAt:       rec.CreatedAt,
+			UpdatedAt:       rec.UpdatedAt,
+		})
+
+		cands, err := s.candidates.ListByRecordingID(ctx, rec.ID)
+		if err != nil {
+			return Export{}, err
+		}
+		for _, c := range cands {
+			cand := Candidate{
+				ID:          c.ID,
+				RecordingID: c.RecordingID,
+				StartMS:     c.StartMS,
+				EndMS:       c.EndMS,
+				Score:       c.Score,
+				Status:      c.Status,
+				CreatedAt:   c.CreatedAt,
+				UpdatedAt:   c.UpdatedAt,
+			}
+			if c.DetectedJSON != nil && json.Valid([]byte(*c.DetectedJSON)) {
+				cand.Detected = json.RawMessage(*c.DetectedJSON)
+			}
+			out.ClipCandidates = append(out.ClipCandidates, cand)
+		}
+	}
+
+	clips, err := s.clips.ListForAccount(ctx, u.ID)
+	if err != nil {
+		return Export{}, err
+	}
+	for _, c := range clips {
+		if c.UserID != u.ID {
+			continue
+		}
+		out.Clips = append(out.Clips, c)
+
+		pubs, err := s.publishes.ListByClipIDForUser(ctx, u.ID, c.ID)
+		if err != nil {
+			return Export{}, err
+		}
+		out.Publications = append(out.Publications, pubs...)
+	}
+
+	if out.Tags, err = s.tags.ListByUser(ctx, u.ID); err != nil {
+		return Export{}, err
+	}
+
+	if out.Collections, err = s.collections.ListByUser(ctx, u.ID); err != nil {
+		return Export{}, err
+	}
+	for i := range out.Collections {
+		items, err := s.collections.ListItems(ctx, out.Collections[i].ID)
+		if err != nil {
+			return Export{}, err
+		}
+		out.Collections[i].Items = items
+	}
+
+	if out.APIKeys, err = s.apiKeys.ListByUser(ctx, u.ID); err != nil {
+		return Export{}, err
+	}
+
+	if s.usage != nil {
+		if out.Usage, err = s.usage.Get(ctx, u.ID); err != nil {
+			return Export{}, err
+		}
+	}
+
+	return out, nil
+}
+
+// WriteArchive writes the export as a zip: one JSON file per section and, with includeMedia,
+// the original recordings and clip exports under media/. Files missing from storage are skipped.
+func (s *Service) WriteArchive(ctx context.Context, w io.Writer, e Export, includeMedia bool) error {
+	zw := zip.NewWriter(w)
+
+	sections := []struct {
+		name string
+		data any
+	}{
+		{"account.json", e.Account},
+		{"workspaces.json", e.Workspaces},
+		{"recordings.json", e.Recordings},
+		{"clip_candidates.json", e.ClipCandidates},
+		{"clips.json", e.Clips},
+		{"publications.json", e.Publications},
+		{"tags.json", e.Tags},
+		{"collections.json", e.Collections},
+		{"api_keys.json", e.APIKeys},
+		{"usage.json", e.Usage},
+	}
+	for _, sec := range sections {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: sec.name, Method: zip.Deflate, Modified: e.ExportedAt})
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(sec.data); err != nil {
+			return err
+		}
+	}
+
+	if includeMedia {
+		for _, rec := range e.Recordings {
+			name := "media/recordings/" + rec.UUID + path.Ext(rec.StoragePath)
+			if err := s.addMedia(ctx, zw, s.recFiles, rec.StoragePath, name); err != nil {
+				return err
+			}
+		}
+		for _, c := range e.Clips {
+			if c.ExportPath == nil || *c.ExportPath == "" {
+				continue
+			}
+			name := fmt.Sprintf("media/clips/%d%s", c.ID, path.Ext(*c.ExportPath))
+			if err := s.addMedia(ctx, zw, s.clipFiles, *c.ExportPath, name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return zw.Close()
+}
+
+// addMedia copies a stored object into the archive uncompressed; video does not shrink further.
+func (s *Service) addMedia(ctx context.Context, zw *zip.Writer, files *storage.Cache, key string, name string) error {
+	body, info, err := files.Store().Open(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Printf("account: export skipped missing file %q", key)
+			return nil
+		}
+		return err
+	}
+	defer body.Close()
+
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: info.ModTime})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, body)
+	return err
+}
@@ -0,0 +1,206 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	apikeysrepo "highlightiq-server/internal/repos/apikeys"
+	clipcandidatesrepo "highlightiq-server/internal/repos/clipcandidates"
+	clipsrepo "highlightiq-server/internal/repos/clips"
+	collectionsrepo "highlightiq-server/internal/repos/collections"
+	pubrepo "highlightiq-server/internal/repos/publications"
+	recordingsrepo "highlightiq-server/internal/repos/recordings"
+	tagsrepo "highlightiq-server/internal/repos/tags"
+	usagerepo "highlightiq-server/internal/repos/usage"
+	"highlightiq-server/internal/repos/users"
+	wsrepo "highlightiq-server/internal/repos/workspaces"
+	usagesvc "highlightiq-server/internal/services/usage"
+	"highlightiq-server/internal/storage"
+)
+
+var (
+	// ErrInvalidPassword: wrong password, or a wrong or missing confirmation code for an account
+	// without one.
+	ErrInvalidPassword = errors.New("account: invalid password")
+	// ErrSharedWorkspaces: the user owns workspaces that other people are still members of.
+	ErrSharedWorkspaces = errors.New("account: owns workspaces with other members")
+)
+
+// Reauthenticator checks that the caller holds the account: the password, or a mailed
+// confirmation code for accounts created through a provider. See auth.Service.ConfirmIdentity.
+type Reauthenticator interface {
+	ConfirmIdentity(ctx context.Context, userID int64, password string, confirmation string) (bool, error)
+}
+
+type Service struct {
+	users       *users.Repo
+	reauth      Reauthenticator
+	workspaces  *wsrepo.Repo
+	recordings  *recordingsrepo.Repo
+	candidates  *clipcandidatesrepo.Repo
+	clips       *clipsrepo.Repo
+	publishes   *pubrepo.Repo
+	tags        *tagsrepo.Repo
+	collections *collectionsrepo.Repo
+	apiKeys     *apikeysrepo.Repo
+	usage       *usagesvc.Service
+	recFiles    *storage.Cache
+	clipFiles   *storage.Cache
+}
+
+func New(
+	usersRepo *users.Repo,
+	reauth Reauthenticator,
+	workspaces *wsrepo.Repo,
+	recordings *recordingsrepo.Repo,
+	candidates *clipcandidatesrepo.Repo,
+	clips *clipsrepo.Repo,
+	publishes *pubrepo.Repo,
+	tags *tagsrepo.Repo,
+	collections *collectionsrepo.Repo,
+	apiKeys *apikeysrepo.Repo,
+	usage *usagesvc.Service,
+	recFiles *storage.Cache,
+	clipFiles *storage.Cache,
+) *Service {
+	return &Service{
+		users:       usersRepo,
+		reauth:      reauth,
+		workspaces:  workspaces,
+		recordings:  recordings,
+		candidates:  candidates,
+		clips:       clips,
+		publishes:   publishes,
+		tags:        tags,
+		collections: collections,
+		apiKeys:     apiKeys,
+		usage:       usage,
+		recFiles:    recFiles,
+		clipFiles:   clipFiles,
+	}
+}
+
+// Delete permanently removes the account after checking the password (or confirmation code, for
+// accounts without a password). Rows go through the cascades on users; recordings, exports and
+// compilations are removed from storage afterwards. Owners of shared workspaces must remove the
+// other members first, since those workspaces and everything in them are deleted too.
+func (s *Service) Delete(ctx context.Context, userID int64, password string, confirmation string) error {
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	ok, err := s.reauth.ConfirmIdentity(ctx, u.ID, password, confirmation)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidPassword
+	}
+
+	n, err := s.workspaces.CountOtherMembersOfOwned(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrSharedWorkspaces
+	}
+
+	// Collect the files before the rows that point at them are gone.
+	recs, err := s.recordings.ListForAccount(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	clips, err := s.clips.ListForAccount(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	cols, err := s.collections.ListByUser(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+
+	// The user's own usage rows cascade away; content of other people that disappears with the
+	// account (clips cut from the user's recordings, uploads of former members of workspaces the
+	// user owns) is given back to them once the rows are gone.
+	var held []usagerepo.Holding
+	if s.usage != nil {
+		held, err = s.heldByOthers(ctx, u.ID, recs, clips)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.users.Delete(ctx, u.ID); err != nil {
+		return err
+	}
+
+	// Best-effort from here on: the account is gone either way.
+	if s.usage != nil {
+		if err := s.usage.Release(ctx, held...); err != nil {
+			log.Printf("account: releasing usage of user %d's content failed: %v", u.ID, err)
+		}
+	}
+	for _, rec := range recs {
+		removeFile(ctx, s.recFiles, rec.StoragePath)
+	}
+	for _, c := range clips {
+		if c.ExportPath != nil && *c.ExportPath != "" {
+			removeFile(ctx, s.clipFiles, *c.ExportPath)
+		}
+	}
+	for _, col := range cols {
+		if col.ExportPath != nil && *col.ExportPath != "" {
+			removeFile(ctx, s.clipFiles, *col.ExportPath)
+		}
+	}
+
+	log.Printf("account: deleted user %d (%d recordings, %d clips)", u.ID, len(recs), len(clips))
+	return nil
+}
+
+// heldByOthers returns what the account's content outside the trash counts against other users.
+// Trashed items, and clips of trashed recordings, hold nothing.
+func (s *Service) heldByOthers(ctx context.Context, userID int64, recs []recordingsrepo.Recording, clips []clipsrepo.Clip) ([]usagerepo.Holding, error) {
+	var held []usagerepo.Holding
+	// covered: recordings whose clips are accounted for with the recording.
+	covered := make(map[int64]bool, len(recs))
+	for _, rec := range recs {
+		if rec.DeletedAt != nil {
+			covered[rec.ID] = true
+			continue
+		}
+		if rec.UserID == userID {
+			continue
+		}
+		hs, err := s.usage.RecordingHolding(ctx, rec.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range hs {
+			if h.UserID != userID {
+				held = append(held, h)
+			}
+		}
+		covered[rec.ID] = true
+	}
+	for _, c := range clips {
+		if c.UserID == userID || c.DeletedAt != nil || covered[c.RecordingID] {
+			continue
+		}
+		h, err := s.usage.ClipHolding(ctx, c.ID)
+		if err != nil {
+			return nil, err
+		}
+		held = append(held, h)
+	}
+	return held, nil
+}
+
+// removeFile deletes an object and any cached copy. Failures are only logged.
+func removeFile(ctx context.Context, files *storage.Cache, key string) {
+	if err := files.Store().Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("account: delete %q failed: %v", key, err)
+	}
+	files.Evict(key)
+}
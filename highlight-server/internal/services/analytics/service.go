@@ -0,0 +1,205 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	analyticsrepo "highlightiq-server/internal/repos/analytics"
+)
+
+var ErrInvalidRange = errors.New("analytics: invalid range")
+
+const (
+	// DefaultDays is how far back the overview goes when no start is given.
+	DefaultDays = 90
+	// MaxDays bounds the overview's range.
+	MaxDays = 366
+
+	DefaultTopClips = 10
+	MaxTopClips     = 50
+)
+
+// bucketing splits a value into ranges: bounds are the exclusive upper ends of every range but
+// the last, and there is one more label than bounds.
+type bucketing struct {
+	bounds []float64
+	labels []string
+}
+
+var durationBuckets = bucketing{
+	bounds: []float64{15, 30, 60, 180},
+	labels: []string{"0-15s", "15-30s", "30-60s", "1-3m", "3m+"},
+}
+
+var scoreBuckets = bucketing{
+	bounds: []float64{0.2, 0.4, 0.6, 0.8},
+	labels: []string{"0.0-0.2", "0.2-0.4", "0.4-0.6", "0.6-0.8", "0.8+"},
+}
+
+// noScoreLabel is the score bucket of clips that were not cut from a detected candidate.
+const noScoreLabel = "none"
+
+var weekdays = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+type Service struct {
+	repo *analyticsrepo.Repo
+}
+
+func New(repo *analyticsrepo.Repo) *Service {
+	return &Service{repo: repo}
+}
+
+type OverviewInput struct {
+	From     time.Time
+	To       time.Time
+	Platform string // empty for every platform
+	TopClips int
+	// UTCOffsetMinutes is the viewer's timezone, for the weekday and hour comparisons.
+	UTCOffsetMinutes int
+}
+
+// Bucket is the performance of the publications of clips in one duration or score range.
+// Averages are per publication.
+type Bucket struct {
+	Label        string  `json:"label"`
+	Clips        int     `json:"clips"`
+	Publications int     `json:"publications"`
+	Views        int64   `json:"views"`
+	Likes        int64   `json:"likes"`
+	AvgViews     float64 `json:"avg_views"`
+	AvgLikes     float64 `json:"avg_likes"`
+}
+
+// Slot is the performance of the publications that went live on one weekday or hour.
+type Slot struct {
+	Weekday      string  `json:"weekday,omitempty"`
+	Hour         *int    `json:"hour,omitempty"`
+	Publications int     `json:"publications"`
+	Views        int64   `json:"views"`
+	AvgViews     float64 `json:"avg_views"`
+	AvgLikes     float64 `json:"avg_likes"`
+}
+
+type Overview struct {
+	From       time.Time                 `json:"from"`
+	To         time.Time                 `json:"to"`
+	Platform   string                    `json:"platform,omitempty"`
+	Totals     analyticsrepo.Totals      `json:"totals"`
+	TopClips   []analyticsrepo.ClipStats `json:"top_clips"`
+	ByDuration []Bucket                  `json:"by_duration"`
+	ByScore    []Bucket                  `json:"by_score"`
+	ByWeekday  []Slot                    `json:"by_weekday"`
+	ByHour     []Slot                    `json:"by_hour"`
+}
+
+// Range resolves an optional [from, to) range: to defaults to now and from to DefaultDays
+// before it. Both are truncated to UTC days, and to includes its whole day.
+func Range(from *time.Time, to *time.Time, now time.Time) (time.Time, time.Time, error) {
+	end := startOfDay(now).AddDate(0, 0, 1)
+	if to != nil {
+		end = startOfDay(*to).AddDate(0, 0, 1)
+	}
+	start := end.AddDate(0, 0, -DefaultDays)
+	if from != nil {
+		start = startOfDay(*from)
+	}
+
+	if !start.Before(end) || end.Sub(start) > MaxDays*24*time.Hour {
+		return time.Time{}, time.Time{}, ErrInvalidRange
+	}
+	return start, end, nil
+}
+
+// Overview summarizes how the publications the user can see that went live in the range
+// performed, using the latest metrics synced for each.
+func (s *Service) Overview(ctx context.Context, userID int64, in OverviewInput) (Overview, error) {
+	f := analyticsrepo.Filter{UserID: userID, From: in.From, To: in.To, Platform: in.Platform}
+	out := Overview{From: in.From, To: in.To, Platform: in.Platform}
+
+	var err error
+	if out.Totals, err = s.repo.Totals(ctx, f); err != nil {
+		return Overview{}, err
+	}
+
+	limit := in.TopClips
+	if limit <= 0 {
+		limit = DefaultTopClips
+	}
+	if out.TopClips, err = s.repo.TopClips(ctx, f, limit); err != nil {
+		return Overview{}, err
+	}
+
+	byDuration, err := s.repo.ByDuration(ctx, f, durationBuckets.bounds)
+	if err != nil {
+		return Overview{}, err
+	}
+	out.ByDuration = buckets(byDuration, durationBuckets)
+
+	byScore, err := s.repo.ByScore(ctx, f, scoreBuckets.bounds)
+	if err != nil {
+		return Overview{}, err
+	}
+	out.ByScore = buckets(byScore, scoreBuckets)
+
+	byWeekday, err := s.repo.ByWeekday(ctx, f, in.UTCOffsetMinutes)
+	if err != nil {
+		return Overview{}, err
+	}
+	out.ByWeekday = slots(byWeekday, len(weekdays), func(sl *Slot, key int) { sl.Weekday = weekdays[key] })
+
+	byHour, err := s.repo.ByHour(ctx, f, in.UTCOffsetMinutes)
+	if err != nil {
+		return Overview{}, err
+	}
+	out.ByHour = slots(byHour, 24, func(sl *Slot, key int) { h := key; sl.Hour = &h })
+
+	return out, nil
+}
+
+// buckets labels the groups the repo found and fills in the empty ones, so every range is
+// listed. The group of values without a score, if any, comes last.
+func buckets(groups []analyticsrepo.Group, b bucketing) []Bucket {
+	out := make([]Bucket, len(b.labels))
+	for i, label := range b.labels {
+		out[i].Label = label
+	}
+
+	for _, g := range groups {
+		bucket := Bucket{Clips: g.Clips, Publications: g.Publications, Views: g.Views, Likes: g.Likes, AvgViews: g.AvgViews, AvgLikes: g.AvgLikes}
+		if g.Key < 0 || g.Key >= len(out) {
+			bucket.Label = noScoreLabel
+			out = append(out, bucket)
+			continue
+		}
+		bucket.Label = out[g.Key].Label
+		out[g.Key] = bucket
+	}
+
+	return out
+}
+
+// slots lists n time slots, filling in those without publications.
+func slots(groups []analyticsrepo.Group, n int, name func(*Slot, int)) []Slot {
+	out := make([]Slot, n)
+	for i := range out {
+		name(&out[i], i)
+	}
+
+	for _, g := range groups {
+		if g.Key < 0 || g.Key >= n {
+			continue
+		}
+		out[g.Key].Publications = g.Publications
+		out[g.Key].Views = g.Views
+		out[g.Key].AvgViews = g.AvgViews
+		out[g.Key].AvgLikes = g.AvgLikes
+	}
+
+	return out
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
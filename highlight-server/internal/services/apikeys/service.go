@@ -0,0 +1,167 @@
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+
+	apikeysrepo "highlightiq-server/internal/repos/apikeys"
+)
+
+var (
+	ErrNotFound     = errors.New("apikeys: not found")
+	ErrInvalidScope = errors.New("apikeys: invalid scope")
+	ErrInvalidKey   = errors.New("apikeys: invalid key")
+)
+
+// KeyPrefix starts every personal API key, so the auth middleware can tell keys from JWTs and
+// secret scanners can recognise leaked ones.
+const KeyPrefix = "hiq_"
+
+// Resources that API keys can be scoped to. A scope is "<resource>:read" or "<resource>:write".
+// Workspaces are left out like /api-keys and /me: a leaked key must not be able to invite
+// members or change roles.
+var Resources = []string{
+	"recordings",
+	"clip-candidates",
+	"clips",
+	"publications",
+	"youtube-publishes",
+	"tags",
+	"collections",
+	"trash",
+	"usage",
+	"analytics",
+}
+
+type Service struct {
+	keys *apikeysrepo.Repo
+}
+
+func New(keys *apikeysrepo.Repo) *Service {
+	return &Service{keys: keys}
+}
+
+// Created is returned once, on creation; Key is never shown again.
+type Created struct {
+	apikeysrepo.APIKey
+	Key string `json:"key"`
+}
+
+func (s *Service) Create(ctx context.Context, userID int64, name string, scopes []string) (Created, error) {
+	clean, err := NormalizeScopes(scopes)
+	if err != nil {
+		return Created{}, err
+	}
+
+	raw, prefix, err := newKey()
+	if err != nil {
+		return Created{}, err
+	}
+
+	k, err := s.keys.Create(ctx, apikeysrepo.CreateParams{
+		UserID:  userID,
+		Name:    strings.TrimSpace(name),
+		Prefix:  prefix,
+		KeyHash: HashKey(raw),
+		Scopes:  clean,
+	})
+	if err != nil {
+		return Created{}, err
+	}
+
+	return Created{APIKey: k, Key: raw}, nil
+}
+
+func (s *Service) List(ctx context.Context, userID int64) ([]apikeysrepo.APIKey, error) {
+	return s.keys.ListByUser(ctx, userID)
+}
+
+func (s *Service) Revoke(ctx context.Context, userID int64, id int64) error {
+	err := s.keys.RevokeByIDForUser(ctx, userID, id)
+	if errors.Is(err, apikeysrepo.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Verify resolves a raw key to its active row and records the use.
+func (s *Service) Verify(ctx context.Context, raw string) (apikeysrepo.APIKey, error) {
+	if !strings.HasPrefix(raw, KeyPrefix) {
+		return apikeysrepo.APIKey{}, ErrInvalidKey
+	}
+
+	k, err := s.keys.GetActiveByHash(ctx, HashKey(raw))
+	if errors.Is(err, apikeysrepo.ErrNotFound) {
+		return apikeysrepo.APIKey{}, ErrInvalidKey
+	}
+	if err != nil {
+		return apikeysrepo.APIKey{}, err
+	}
+
+	// Losing a last-used timestamp is not worth failing the request over.
+	if err := s.keys.TouchLastUsed(ctx, k.ID); err != nil {
+		log.Printf("apikeys: touch last used for key %d: %v", k.ID, err)
+	}
+	return k, nil
+}
+
+// NormalizeScopes lowercases and de-duplicates scopes, rejecting any that are not known.
+func NormalizeScopes(scopes []string) ([]string, error) {
+	out := make([]string, 0, len(scopes))
+	seen := make(map[string]struct{}, len(scopes))
+	for _, sc := range scopes {
+		sc = strings.ToLower(strings.TrimSpace(sc))
+		if !validScope(sc) {
+			return nil, ErrInvalidScope
+		}
+		if _, ok := seen[sc]; ok {
+			continue
+		}
+		seen[sc] = struct{}{}
+		out = append(out, sc)
+	}
+	if len(out) == 0 {
+		return nil, ErrInvalidScope
+	}
+	return out, nil
+}
+
+func validScope(scope string) bool {
+	resource, access, ok := strings.Cut(scope, ":")
+	if !ok || (access != "read" && access != "write") {
+		return false
+	}
+	for _, r := range Resources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// newKey returns the full key and the part of it that is safe to display.
+func newKey() (string, string, error) {
+	id := make([]byte, 6)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	prefix := KeyPrefix + hex.EncodeToString(id)
+	return prefix + "_" + base64.RawURLEncoding.EncodeToString(secret), prefix, nil
+}
+
+// HashKey is what gets stored, so a database leak does not hand out usable keys.
+func HashKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -2,7 +2,13 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/go-sql-driver/mysql"
@@ -10,20 +16,72 @@ import (
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
+	"highlightiq-server/internal/integrations/oidc"
+	"highlightiq-server/internal/mail"
+	"highlightiq-server/internal/repos/identities"
+	"highlightiq-server/internal/repos/loginattempts"
+	"highlightiq-server/internal/repos/mfa"
+	"highlightiq-server/internal/repos/sessions"
 	"highlightiq-server/internal/repos/users"
+	"highlightiq-server/internal/repos/usertokens"
+	"highlightiq-server/internal/secretbox"
 )
 
 type Service struct {
-	users     *users.Repo
-	jwtSecret []byte
-	tokenTTL  time.Duration
+	users        *users.Repo
+	sessions     *sessions.Repo
+	tokens       *usertokens.Repo
+	attempts     *loginattempts.Repo
+	mfa          *mfa.Repo
+	identities   *identities.Repo
+	providers    map[string]*oidc.Provider
+	secrets      *secretbox.Box
+	mailer       mail.Mailer
+	appBaseURL   string
+	jwtSecret    []byte
+	tokenTTL     time.Duration
+	refreshTTL   time.Duration
+	verifyTTL    time.Duration
+	resetTTL     time.Duration
+	challengeTTL time.Duration
+	oauthTTL     time.Duration
+	confirmTTL   time.Duration
+	emailLimit   LoginLimit
+	ipLimit      LoginLimit
+	auditTTL     time.Duration
 }
 
-func New(usersRepo *users.Repo, jwtSecret string) *Service {
+// New builds the auth service. secrets encrypts TOTP keys at rest; providers are the enabled
+// "sign in with" providers; appBaseURL is the web app that serves the pages the emailed
+// verification and reset links open.
+func New(usersRepo *users.Repo, sessionsRepo *sessions.Repo, tokensRepo *usertokens.Repo, attemptsRepo *loginattempts.Repo, mfaRepo *mfa.Repo, identitiesRepo *identities.Repo, providers []*oidc.Provider, secrets *secretbox.Box, mailer mail.Mailer, appBaseURL string, jwtSecret string) *Service {
+	byName := make(map[string]*oidc.Provider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+
 	return &Service{
-		users:     usersRepo,
-		jwtSecret: []byte(jwtSecret),
-		tokenTTL:  24 * time.Hour,
+		users:        usersRepo,
+		sessions:     sessionsRepo,
+		tokens:       tokensRepo,
+		attempts:     attemptsRepo,
+		mfa:          mfaRepo,
+		identities:   identitiesRepo,
+		providers:    byName,
+		secrets:      secrets,
+		mailer:       mailer,
+		appBaseURL:   strings.TrimRight(appBaseURL, "/"),
+		jwtSecret:    []byte(jwtSecret),
+		tokenTTL:     15 * time.Minute,
+		refreshTTL:   30 * 24 * time.Hour,
+		verifyTTL:    48 * time.Hour,
+		resetTTL:     time.Hour,
+		challengeTTL: 5 * time.Minute,
+		oauthTTL:     10 * time.Minute,
+		confirmTTL:   15 * time.Minute,
+		emailLimit:   defaultEmailLimit,
+		ipLimit:      defaultIPLimit,
+		auditTTL:     90 * 24 * time.Hour,
 	}
 }
 
@@ -58,21 +116,14 @@ func (s *Service) Register(ctx context.Context, in RegisterInput) (RegisterOutpu
 		return RegisterOutput{}, err
 	}
 
-	// 4) Issue JWT
-	token, err := s.signJWT(u.UUID, u.Email)
-	if err != nil {
-		return RegisterOutput{}, err
+	// 4) Ask the user to confirm the address. The account stays restricted until they do, so a
+	// failed email is only logged; they can request another one.
+	if err := s.sendVerification(ctx, u); err != nil {
+		log.Printf("auth: verification email for user %d failed: %v", u.ID, err)
 	}
 
-	return RegisterOutput{
-		User: UserDTO{
-			ID:    u.UUID,
-			Name:  u.Name,
-			Email: u.Email,
-		},
-		AccessToken: token,
-		TokenType:   "Bearer",
-	}, nil
+	// 5) Start a session (access + refresh token)
+	return s.issue(ctx, u, uuid.NewString())
 }
 
 func isDuplicateEmail(err error) bool {
@@ -80,46 +131,221 @@ func isDuplicateEmail(err error) bool {
 	return errors.As(err, &me) && me.Number == 1062
 }
 
-func (s *Service) signJWT(userUUID, email string) (string, error) {
+// signJWT issues a short-lived access token. The jti lets a single token be revoked.
+func (s *Service) signJWT(userUUID, email string, jti string, exp time.Time) (string, error) {
 	claims := jwt.MapClaims{
 		"sub":   userUUID,
 		"email": email,
+		"jti":   jti,
 		"iat":   time.Now().Unix(),
-		"exp":   time.Now().Add(s.tokenTTL).Unix(),
+		"exp":   exp.Unix(),
 	}
 
 	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return t.SignedString(s.jwtSecret)
 }
 
-func (s *Service) Login(ctx context.Context, in LoginInput) (RegisterOutput, error) {
-	// 1) Find user by email
-	u, err := s.users.GetByEmail(ctx, in.Email)
+// issue signs an access token and stores a new refresh token in the given family.
+func (s *Service) issue(ctx context.Context, u users.User, familyID string) (RegisterOutput, error) {
+	now := time.Now()
+	jti := uuid.NewString()
+	accessExp := now.Add(s.tokenTTL)
+
+	token, err := s.signJWT(u.UUID, u.Email, jti, accessExp)
+	if err != nil {
+		return RegisterOutput{}, err
+	}
+
+	refresh, err := newToken()
+	if err != nil {
+		return RegisterOutput{}, err
+	}
+
+	if err := s.sessions.Create(ctx, sessions.CreateParams{
+		UserID:          u.ID,
+		FamilyID:        familyID,
+		TokenHash:       hashToken(refresh),
+		AccessJTI:       jti,
+		AccessExpiresAt: accessExp,
+		ExpiresAt:       now.Add(s.refreshTTL),
+	}); err != nil {
+		return RegisterOutput{}, err
+	}
+
+	return RegisterOutput{
+		User:         toUserDTO(u),
+		AccessToken:  token,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.tokenTTL.Seconds()),
+		RefreshToken: refresh,
+	}, nil
+}
+
+// Refresh trades a refresh token for a new access/refresh pair. Each refresh token works once;
+// presenting one that was already rotated means it leaked, so the whole session is revoked.
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (RegisterOutput, error) {
+	t, err := s.sessions.GetByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, sessions.ErrNotFound) {
+			return RegisterOutput{}, ErrInvalidRefresh
+		}
+		return RegisterOutput{}, err
+	}
+
+	if t.RevokedAt != nil || time.Now().After(t.ExpiresAt) {
+		return RegisterOutput{}, ErrInvalidRefresh
+	}
+
+	rotated := false
+	if t.RotatedAt == nil {
+		rotated, err = s.sessions.MarkRotated(ctx, t.ID)
+		if err != nil {
+			return RegisterOutput{}, err
+		}
+	}
+	if !rotated {
+		log.Printf("auth: refresh token reuse for user %d, revoking session %s", t.UserID, t.FamilyID)
+		if err := s.sessions.RevokeFamily(ctx, t.FamilyID); err != nil {
+			return RegisterOutput{}, err
+		}
+		return RegisterOutput{}, ErrInvalidRefresh
+	}
+
+	u, err := s.users.GetByID(ctx, t.UserID)
 	if err != nil {
 		if errors.Is(err, users.ErrNotFound) {
-			return RegisterOutput{}, ErrInvalidCredentials
+			return RegisterOutput{}, ErrInvalidRefresh
 		}
 		return RegisterOutput{}, err
 	}
 
-	// 2) Compare password
+	return s.issue(ctx, u, t.FamilyID)
+}
+
+// Logout ends the current session: the presented access token is revoked and, when given, the
+// refresh token's whole family as well.
+func (s *Service) Logout(ctx context.Context, in LogoutInput) error {
+	if in.RefreshToken != "" {
+		t, err := s.sessions.GetByHash(ctx, hashToken(in.RefreshToken))
+		if err != nil && !errors.Is(err, sessions.ErrNotFound) {
+			return err
+		}
+		// Only the owner may end a session with it.
+		if err == nil && t.UserID == in.UserID {
+			if err := s.sessions.RevokeFamily(ctx, t.FamilyID); err != nil {
+				return err
+			}
+		}
+	}
+
+	if in.AccessJTI == "" {
+		return nil
+	}
+	return s.sessions.RevokeAccessToken(ctx, in.UserID, in.AccessJTI, in.AccessExpires)
+}
+
+// LogoutAll revokes every session of the user, including the one making the request.
+func (s *Service) LogoutAll(ctx context.Context, in LogoutInput) error {
+	if err := s.sessions.RevokeAllForUser(ctx, in.UserID); err != nil {
+		return err
+	}
+	if in.AccessJTI == "" {
+		return nil
+	}
+	return s.sessions.RevokeAccessToken(ctx, in.UserID, in.AccessJTI, in.AccessExpires)
+}
+
+// Run removes expired refresh tokens, denylist entries, email tokens, abandoned OAuth requests
+// and old login audit rows every interval until ctx is cancelled.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		if _, err := s.sessions.DeleteExpired(ctx); err != nil {
+			log.Printf("auth: token cleanup failed: %v", err)
+		}
+		if _, err := s.tokens.DeleteExpired(ctx); err != nil {
+			log.Printf("auth: email token cleanup failed: %v", err)
+		}
+		if _, err := s.identities.DeleteExpiredStates(ctx); err != nil {
+			log.Printf("auth: oauth state cleanup failed: %v", err)
+		}
+		if _, err := s.attempts.DeleteBefore(ctx, time.Now().Add(-s.auditTTL)); err != nil {
+			log.Printf("auth: login audit cleanup failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken is what gets stored, so a database leak does not hand out usable tokens.
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// Login checks the password. Accounts with 2FA get a challenge token instead of a session; it is
+// exchanged together with a code through VerifyLogin.
+func (s *Service) Login(ctx context.Context, in LoginInput) (LoginOutput, error) {
+	email := normalizeLoginEmail(in.Email)
+
+	// 1) Refuse while the email or IP is backing off. This does not depend on whether the
+	// account exists, so it reveals nothing about it.
+	if err := s.checkThrottle(ctx, email, in.IP); err != nil {
+		var te *ThrottleError
+		if errors.As(err, &te) {
+			s.recordAttempt(ctx, in, email, nil, loginattempts.OutcomeThrottled)
+		}
+		return LoginOutput{}, err
+	}
+
+	// 2) Find user by email. A miss still pays for a bcrypt compare so it takes as long as a
+	// wrong password.
+	u, err := s.users.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, users.ErrNotFound) {
+			_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(in.Password))
+			s.recordAttempt(ctx, in, email, nil, loginattempts.OutcomeInvalidCredentials)
+			return LoginOutput{}, ErrInvalidCredentials
+		}
+		return LoginOutput{}, err
+	}
+
+	// 3) Compare password
 	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(in.Password)); err != nil {
-		return RegisterOutput{}, ErrInvalidCredentials
+		s.recordAttempt(ctx, in, email, &u.ID, loginattempts.OutcomeInvalidCredentials)
+		return LoginOutput{}, ErrInvalidCredentials
 	}
 
-	// 3) Issue JWT (same signing as Register)
-	token, err := s.signJWT(u.UUID, u.Email)
+	// 4) With 2FA the password alone proves little; nothing is recorded as a success yet, or
+	// each correct password would reset the throttle on guessing codes.
+	needsCode, err := s.requiresTOTP(ctx, u.ID)
 	if err != nil {
-		return RegisterOutput{}, err
+		return LoginOutput{}, err
+	}
+	if needsCode {
+		return s.startChallenge(ctx, u.ID)
 	}
 
-	return RegisterOutput{
-		User: UserDTO{
-			ID:    u.UUID,
-			Name:  u.Name,
-			Email: u.Email,
-		},
-		AccessToken: token,
-		TokenType:   "Bearer",
-	}, nil
+	s.recordAttempt(ctx, in, email, &u.ID, loginattempts.OutcomeSuccess)
+
+	// 5) Start a session (same as Register)
+	out, err := s.issue(ctx, u, uuid.NewString())
+	if err != nil {
+		return LoginOutput{}, err
+	}
+	return LoginOutput{RegisterOutput: &out}, nil
 }
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"highlightiq-server/internal/mail"
+	"highlightiq-server/internal/repos/users"
+	"highlightiq-server/internal/repos/usertokens"
+)
+
+// VerifyEmail confirms the address of the user the token was sent to.
+func (s *Service) VerifyEmail(ctx context.Context, token string) error {
+	userID, err := s.tokens.Consume(ctx, usertokens.PurposeVerifyEmail, hashToken(strings.TrimSpace(token)))
+	if err != nil {
+		if errors.Is(err, usertokens.ErrNotFound) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+	return s.users.MarkEmailVerified(ctx, userID)
+}
+
+// ResendVerification mails a fresh verification link; earlier links stop working.
+func (s *Service) ResendVerification(ctx context.Context, userID int64) error {
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.EmailVerifiedAt != nil {
+		return ErrAlreadyVerified
+	}
+	return s.sendVerification(ctx, u)
+}
+
+// ForgotPassword mails a reset link if the email belongs to an account. Unknown addresses are
+// not reported, so the endpoint cannot be used to find out who has an account.
+func (s *Service) ForgotPassword(ctx context.Context, email string) error {
+	u, err := s.users.GetByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		if errors.Is(err, users.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := s.newUserToken(ctx, u.ID, usertokens.PurposePasswordReset, s.resetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.appBaseURL + "/reset-password?token=" + url.QueryEscape(token)
+	if err := s.mailer.Send(ctx, mail.Message{
+		To:      u.Email,
+		Subject: "Reset your HighlightIQ password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your HighlightIQ account. "+
+			"To choose a new one, open this link within %s:\n\n%s\n\n"+
+			"If this wasn't you, ignore this email; your password stays the same.\n",
+			u.Name, humanTTL(s.resetTTL), link),
+	}); err != nil {
+		// Same answer as for an unknown address; the failure is for the operator.
+		log.Printf("auth: password reset email for user %d failed: %v", u.ID, err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password with a token from ForgotPassword. Every session of the user
+// is signed out, and the email counts as verified since the link reached its inbox.
+func (s *Service) ResetPassword(ctx context.Context, token string, password string) error {
+	userID, err := s.tokens.Consume(ctx, usertokens.PurposePasswordReset, hashToken(strings.TrimSpace(token)))
+	if err != nil {
+		if errors.Is(err, usertokens.ErrNotFound) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.users.UpdatePassword(ctx, userID, string(hash)); err != nil {
+		if errors.Is(err, users.ErrNotFound) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+	if err := s.users.MarkEmailVerified(ctx, userID); err != nil {
+		return err
+	}
+	return s.sessions.RevokeAllForUser(ctx, userID)
+}
+
+func (s *Service) sendVerification(ctx context.Context, u users.User) error {
+	token, err := s.newUserToken(ctx, u.ID, usertokens.PurposeVerifyEmail, s.verifyTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.appBaseURL + "/verify-email?token=" + url.QueryEscape(token)
+	return s.mailer.Send(ctx, mail.Message{
+		To:      u.Email,
+		Subject: "Confirm your HighlightIQ email",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening this link within %s:\n\n%s\n\n"+
+			"Until then you can sign in, but not upload or publish anything.\n",
+			u.Name, humanTTL(s.verifyTTL), link),
+	})
+}
+
+// newUserToken stores the hash of a fresh single-use token and returns the token itself.
+// Earlier unused tokens of the same purpose stop working.
+func (s *Service) newUserToken(ctx context.Context, userID int64, purpose string, ttl time.Duration) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.tokens.Create(ctx, usertokens.CreateParams{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func humanTTL(d time.Duration) string {
+	if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%d days", int(d/(24*time.Hour)))
+	}
+	if d >= time.Hour && d%time.Hour == 0 {
+		if d == time.Hour {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%d minutes", int(d/time.Minute))
+}
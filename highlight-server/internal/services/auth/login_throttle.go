@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"highlightiq-server/internal/repos/loginattempts"
+)
+
+// LoginLimit throttles failed logins for one key (an email or an IP). Failures are counted over
+// the Window leading up to the last one. The first Free failures cost nothing; after that each
+// attempt must wait Base, doubling per failure up to MaxDelay. At Lockout failures the key is
+// locked for LockoutFor after the last failure, even when that outlasts Window.
+type LoginLimit struct {
+	Window     time.Duration
+	Free       int
+	Base       time.Duration
+	MaxDelay   time.Duration
+	Lockout    int
+	LockoutFor time.Duration
+}
+
+// span is how far back a failure can still hold the key up.
+func (l LoginLimit) span() time.Duration {
+	return max(l.Window, l.LockoutFor, l.MaxDelay)
+}
+
+// wait returns how long after the last failure the next attempt is allowed.
+func (l LoginLimit) wait(failures int) time.Duration {
+	if failures >= l.Lockout {
+		return l.LockoutFor
+	}
+	if failures < l.Free {
+		return 0
+	}
+
+	d := l.Base << uint(failures-l.Free)
+	if d <= 0 || d > l.MaxDelay {
+		d = l.MaxDelay
+	}
+	return d
+}
+
+var (
+	defaultEmailLimit = LoginLimit{
+		Window:     15 * time.Minute,
+		Free:       3,
+		Base:       time.Second,
+		MaxDelay:   time.Minute,
+		Lockout:    10,
+		LockoutFor: 15 * time.Minute,
+	}
+	// One IP legitimately serves many users (offices, NAT), so it gets more room.
+	defaultIPLimit = LoginLimit{
+		Window:     15 * time.Minute,
+		Free:       20,
+		Base:       time.Second,
+		MaxDelay:   time.Minute,
+		Lockout:    100,
+		LockoutFor: 30 * time.Minute,
+	}
+)
+
+// ThrottleError is returned by Login while an email or IP is backing off or locked. It matches
+// ErrTooManyAttempts with errors.Is.
+type ThrottleError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottleError) Error() string {
+	return fmt.Sprintf("auth: too many login attempts, retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+func (e *ThrottleError) Is(target error) bool {
+	return target == ErrTooManyAttempts
+}
+
+// dummyHash is compared against when the email has no account, so a miss takes as long as a
+// wrong password and response times do not reveal which emails are registered.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("highlightiq-timing-equaliser"), bcrypt.DefaultCost)
+
+func normalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// checkThrottle reports the longer of the email and IP waits still outstanding, or nil.
+func (s *Service) checkThrottle(ctx context.Context, email string, ip string) error {
+	now := time.Now()
+	var retry time.Duration
+
+	byEmail, err := lastWindow(ctx, s.attempts.FailuresByEmail, email, s.emailLimit, now)
+	if err != nil {
+		return err
+	}
+	if w := remaining(byEmail, s.emailLimit, now); w > retry {
+		retry = w
+	}
+
+	if ip != "" {
+		byIP, err := lastWindow(ctx, s.attempts.FailuresByIP, ip, s.ipLimit, now)
+		if err != nil {
+			return err
+		}
+		if w := remaining(byIP, s.ipLimit, now); w > retry {
+			retry = w
+		}
+	}
+
+	if retry > 0 {
+		return &ThrottleError{RetryAfter: retry}
+	}
+	return nil
+}
+
+// lastWindow counts the failures in the Window that ends at the most recent failure. Anchoring on
+// the last failure rather than on now keeps a lockout in force for all of LockoutFor: counting
+// back from now, the oldest failures would age out of Window and lift it early.
+func lastWindow(
+	ctx context.Context,
+	query func(ctx context.Context, key string, since time.Time) (loginattempts.Failures, error),
+	key string,
+	l LoginLimit,
+	now time.Time,
+) (loginattempts.Failures, error) {
+	earliest := now.Add(-l.span())
+	f, err := query(ctx, key, earliest)
+	if err != nil || f.Count == 0 {
+		return f, err
+	}
+	if since := f.Last.Add(-l.Window); since.After(earliest) {
+		return query(ctx, key, since)
+	}
+	return f, nil
+}
+
+func remaining(f loginattempts.Failures, l LoginLimit, now time.Time) time.Duration {
+	if f.Count == 0 {
+		return 0
+	}
+	if w := f.Last.Add(l.wait(f.Count)).Sub(now); w > 0 {
+		return w
+	}
+	return 0
+}
+
+// recordAttempt writes the audit row. Failing to record must not change the answer the caller
+// gets, so errors are only logged.
+func (s *Service) recordAttempt(ctx context.Context, in LoginInput, email string, userID *int64, outcome string) {
+	if err := s.attempts.Record(ctx, loginattempts.Attempt{
+		Email:     email,
+		IP:        in.IP,
+		UserID:    userID,
+		Outcome:   outcome,
+		UserAgent: in.UserAgent,
+	}); err != nil {
+		log.Printf("auth: recording login attempt failed: %v", err)
+	}
+}
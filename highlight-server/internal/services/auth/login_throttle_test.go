@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"highlightiq-server/internal/repos/loginattempts"
+)
+
+// failuresAt answers like the loginattempts repo for a fixed list of failure times.
+func failuresAt(times []time.Time) func(context.Context, string, time.Time) (loginattempts.Failures, error) {
+	return func(_ context.Context, _ string, since time.Time) (loginattempts.Failures, error) {
+		var f loginattempts.Failures
+		for _, t := range times {
+			if t.After(since) {
+				f.Count++
+				if t.After(f.Last) {
+					f.Last = t
+				}
+			}
+		}
+		return f, nil
+	}
+}
+
+func TestLockoutOutlastsWindow(t *testing.T) {
+	l := LoginLimit{
+		Window:     15 * time.Minute,
+		Free:       3,
+		Base:       time.Second,
+		MaxDelay:   time.Minute,
+		Lockout:    5,
+		LockoutFor: 30 * time.Minute,
+	}
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	var times []time.Time
+	for i := range 5 {
+		times = append(times, start.Add(time.Duration(i)*2*time.Minute))
+	}
+	last := times[len(times)-1]
+
+	for _, tc := range []struct {
+		name  string
+		after time.Duration
+		want  time.Duration
+	}{
+		{"right after the lockout", time.Minute, 29 * time.Minute},
+		{"once the first failures leave the window", 20 * time.Minute, 10 * time.Minute},
+		{"after the lockout", 31 * time.Minute, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			now := last.Add(tc.after)
+			f, err := lastWindow(context.Background(), failuresAt(times), "key", l, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := remaining(f, l, now); got != tc.want {
+				t.Fatalf("expected %s to wait, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestBackoffCountsTheWindowBeforeTheLastFailure(t *testing.T) {
+	l := defaultEmailLimit
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// An old burst followed, much later, by a single failure: only the latter counts.
+	times := []time.Time{
+		now.Add(-40 * time.Minute), now.Add(-39 * time.Minute), now.Add(-38 * time.Minute),
+		now.Add(-37 * time.Minute), now.Add(-36 * time.Minute),
+		now.Add(-time.Second),
+	}
+	f, err := lastWindow(context.Background(), failuresAt(times), "key", l, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Count != 1 {
+		t.Fatalf("expected 1 failure in the window, got %d", f.Count)
+	}
+	if got := remaining(f, l, now); got != 0 {
+		t.Fatalf("expected no wait, got %s", got)
+	}
+}
@@ -0,0 +1,324 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"highlightiq-server/internal/integrations/oidc"
+	"highlightiq-server/internal/repos/identities"
+	"highlightiq-server/internal/repos/loginattempts"
+	"highlightiq-server/internal/repos/users"
+)
+
+// Providers lists the enabled identity providers, for the sign-in page.
+func (s *Service) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StartOAuth begins signing in with a provider.
+func (s *Service) StartOAuth(ctx context.Context, provider string) (OAuthStart, error) {
+	return s.startAuthorization(ctx, provider, nil)
+}
+
+// StartLinkIdentity begins linking a provider to the signed-in user.
+func (s *Service) StartLinkIdentity(ctx context.Context, userID int64, provider string) (OAuthStart, error) {
+	return s.startAuthorization(ctx, provider, &userID)
+}
+
+// startAuthorization stores the state and PKCE verifier for the callback and returns the
+// provider's authorization URL. Only the state's hash is kept.
+func (s *Service) startAuthorization(ctx context.Context, provider string, linkUserID *int64) (OAuthStart, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return OAuthStart{}, ErrUnknownProvider
+	}
+
+	state, err := newToken()
+	if err != nil {
+		return OAuthStart{}, err
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return OAuthStart{}, err
+	}
+
+	authURL, err := p.AuthCodeURL(ctx, state, challenge)
+	if err != nil {
+		log.Printf("auth: %s authorization url failed: %v", provider, err)
+		return OAuthStart{}, ErrProviderFailed
+	}
+
+	if err := s.identities.CreateState(ctx, identities.CreateStateParams{
+		StateHash:    hashToken(state),
+		Provider:     provider,
+		CodeVerifier: verifier,
+		LinkUserID:   linkUserID,
+		ExpiresAt:    time.Now().Add(s.oauthTTL),
+	}); err != nil {
+		return OAuthStart{}, err
+	}
+
+	return OAuthStart{AuthorizationURL: authURL, State: state, ExpiresIn: s.oauthTTL}, nil
+}
+
+// CompleteOAuth signs in with the provider's answer. A known identity signs in its user; an
+// unknown one is linked to the account with the same verified email, or gets a new account.
+// Accounts with 2FA still get a challenge instead of a session.
+func (s *Service) CompleteOAuth(ctx context.Context, in OAuthCallbackInput) (LoginOutput, error) {
+	p, st, err := s.consumeState(ctx, in.Provider, in.State)
+	if err != nil {
+		return LoginOutput{}, err
+	}
+	// A link request must be finished by the signed-in user, not turned into a sign-in.
+	if st.LinkUserID != nil {
+		return LoginOutput{}, ErrInvalidState
+	}
+
+	id, err := s.fetchIdentity(ctx, p, in.Code, st.CodeVerifier)
+	if err != nil {
+		return LoginOutput{}, err
+	}
+
+	u, err := s.userForIdentity(ctx, in.Provider, id)
+	if err != nil {
+		return LoginOutput{}, err
+	}
+
+	needsCode, err := s.requiresTOTP(ctx, u.ID)
+	if err != nil {
+		return LoginOutput{}, err
+	}
+	if needsCode {
+		return s.startChallenge(ctx, u.ID)
+	}
+
+	s.recordAttempt(ctx, LoginInput{IP: in.IP, UserAgent: in.UserAgent}, u.Email, &u.ID, loginattempts.OutcomeSuccess)
+
+	out, err := s.issue(ctx, u, uuid.NewString())
+	if err != nil {
+		return LoginOutput{}, err
+	}
+	return LoginOutput{RegisterOutput: &out}, nil
+}
+
+// userForIdentity resolves, links or creates the user behind a provider identity.
+func (s *Service) userForIdentity(ctx context.Context, provider string, id oidc.Identity) (users.User, error) {
+	linked, err := s.identities.GetBySubject(ctx, provider, id.Subject)
+	if err == nil {
+		if err := s.identities.TouchLastLogin(ctx, linked.ID); err != nil {
+			log.Printf("auth: touching identity %d failed: %v", linked.ID, err)
+		}
+		return s.users.GetByID(ctx, linked.UserID)
+	}
+	if !errors.Is(err, identities.ErrNotFound) {
+		return users.User{}, err
+	}
+
+	// Matching by email is only safe when the provider vouches for the address.
+	email := normalizeLoginEmail(id.Email)
+	if email == "" || !id.EmailVerified {
+		return users.User{}, ErrEmailUnverified
+	}
+
+	u, err := s.users.GetByEmail(ctx, email)
+	existing := err == nil
+	switch {
+	case err == nil:
+		// Whoever registered an unverified account may not own the address; they have to sign
+		// in with the password and link the provider from their settings.
+		if u.EmailVerifiedAt == nil {
+			return users.User{}, ErrAccountExists
+		}
+	case errors.Is(err, users.ErrNotFound):
+		u, err = s.createOAuthUser(ctx, email, id.Name)
+		if err != nil {
+			return users.User{}, err
+		}
+	default:
+		return users.User{}, err
+	}
+
+	if err := s.identities.Create(ctx, identities.CreateParams{
+		UserID:   u.ID,
+		Provider: provider,
+		Subject:  id.Subject,
+		Email:    email,
+	}); err != nil {
+		if errors.Is(err, identities.ErrDuplicate) {
+			// The account already has another identity at this provider.
+			return users.User{}, ErrAlreadyLinked
+		}
+		return users.User{}, err
+	}
+	if existing {
+		s.notifyLinked(ctx, u, provider)
+	}
+	return u, nil
+}
+
+// createOAuthUser registers a user without a password; the provider already verified the email.
+func (s *Service) createOAuthUser(ctx context.Context, email string, name string) (users.User, error) {
+	name = strings.TrimSpace(name)
+	if utf8.RuneCountInString(name) < 2 {
+		name = email[:strings.Index(email, "@")]
+	}
+	if utf8.RuneCountInString(name) > 60 {
+		name = string([]rune(name)[:60])
+	}
+
+	u, err := s.users.Create(ctx, users.CreateParams{
+		UUID:  uuid.NewString(),
+		Name:  name,
+		Email: email,
+	})
+	if err != nil {
+		if isDuplicateEmail(err) {
+			return users.User{}, ErrEmailTaken
+		}
+		return users.User{}, err
+	}
+
+	if err := s.users.MarkEmailVerified(ctx, u.ID); err != nil {
+		return users.User{}, err
+	}
+	now := time.Now()
+	u.EmailVerifiedAt = &now
+	return u, nil
+}
+
+// LinkIdentity finishes linking a provider to the signed-in user.
+func (s *Service) LinkIdentity(ctx context.Context, in LinkIdentityInput) (identities.Identity, error) {
+	p, st, err := s.consumeState(ctx, in.Provider, in.State)
+	if err != nil {
+		return identities.Identity{}, err
+	}
+	if st.LinkUserID == nil || *st.LinkUserID != in.UserID {
+		return identities.Identity{}, ErrInvalidState
+	}
+
+	id, err := s.fetchIdentity(ctx, p, in.Code, st.CodeVerifier)
+	if err != nil {
+		return identities.Identity{}, err
+	}
+
+	existing, err := s.identities.GetBySubject(ctx, in.Provider, id.Subject)
+	if err == nil {
+		if existing.UserID == in.UserID {
+			return existing, nil
+		}
+		return identities.Identity{}, ErrIdentityInUse
+	}
+	if !errors.Is(err, identities.ErrNotFound) {
+		return identities.Identity{}, err
+	}
+
+	if err := s.identities.Create(ctx, identities.CreateParams{
+		UserID:   in.UserID,
+		Provider: in.Provider,
+		Subject:  id.Subject,
+		Email:    normalizeLoginEmail(id.Email),
+	}); err != nil {
+		if errors.Is(err, identities.ErrDuplicate) {
+			return identities.Identity{}, ErrAlreadyLinked
+		}
+		return identities.Identity{}, err
+	}
+
+	if u, err := s.users.GetByID(ctx, in.UserID); err == nil {
+		s.notifyLinked(ctx, u, in.Provider)
+	}
+
+	return s.identities.GetBySubject(ctx, in.Provider, id.Subject)
+}
+
+func (s *Service) notifyLinked(ctx context.Context, u users.User, provider string) {
+	s.notify(ctx, u.ID, u.Email, "A sign-in method was added to your HighlightIQ account",
+		fmt.Sprintf("Hi %s,\n\nYou can now sign in to HighlightIQ with %s.\n\nIf this wasn't you, "+
+			"remove it under your account settings and reset your password.\n", u.Name, provider))
+}
+
+// Identities lists the providers linked to the user.
+func (s *Service) Identities(ctx context.Context, userID int64) ([]identities.Identity, error) {
+	return s.identities.ListByUser(ctx, userID)
+}
+
+// UnlinkIdentity removes a linked provider, unless it is the only way left to sign in.
+func (s *Service) UnlinkIdentity(ctx context.Context, userID int64, provider string) error {
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if u.PasswordHash == "" {
+		linked, err := s.identities.ListByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		others := 0
+		for _, l := range linked {
+			if l.Provider != provider {
+				others++
+			}
+		}
+		if others == 0 {
+			return ErrLastLoginMethod
+		}
+	}
+
+	if err := s.identities.Delete(ctx, userID, provider); err != nil {
+		if errors.Is(err, identities.ErrNotFound) {
+			return ErrNotLinked
+		}
+		return err
+	}
+	return nil
+}
+
+// consumeState uses up the state from the callback. It must exist, be unexpired and belong to
+// the provider the callback came in for.
+func (s *Service) consumeState(ctx context.Context, provider string, state string) (*oidc.Provider, identities.State, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, identities.State{}, ErrUnknownProvider
+	}
+
+	st, err := s.identities.ConsumeState(ctx, hashToken(state))
+	if err != nil {
+		if errors.Is(err, identities.ErrNotFound) {
+			return nil, identities.State{}, ErrInvalidState
+		}
+		return nil, identities.State{}, err
+	}
+	if st.Provider != provider {
+		return nil, identities.State{}, ErrInvalidState
+	}
+	return p, st, nil
+}
+
+// fetchIdentity redeems the code and reads the user from the provider.
+func (s *Service) fetchIdentity(ctx context.Context, p *oidc.Provider, code string, verifier string) (oidc.Identity, error) {
+	token, err := p.Exchange(ctx, code, verifier)
+	if err != nil {
+		log.Printf("auth: %s code exchange failed: %v", p.Name(), err)
+		return oidc.Identity{}, ErrProviderFailed
+	}
+	id, err := p.UserInfo(ctx, token)
+	if err != nil {
+		log.Printf("auth: %s userinfo failed: %v", p.Name(), err)
+		return oidc.Identity{}, ErrProviderFailed
+	}
+	return id, nil
+}
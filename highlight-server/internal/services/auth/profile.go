@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"highlightiq-server/internal/mail"
+	"highlightiq-server/internal/repos/users"
+)
+
+func (s *Service) Profile(ctx context.Context, userID int64) (UserDTO, error) {
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return UserDTO{}, err
+	}
+	return toUserDTO(u), nil
+}
+
+// UpdateProfile renames the user and/or changes their email. A new email needs the current
+// password (or a confirmation code, for accounts without one), is unverified until the link sent
+// to it is opened, and the old address is told about the change.
+func (s *Service) UpdateProfile(ctx context.Context, in UpdateProfileInput) (UserDTO, error) {
+	u, err := s.users.GetByID(ctx, in.UserID)
+	if err != nil {
+		return UserDTO{}, err
+	}
+
+	if in.Name != nil && *in.Name != u.Name {
+		if err := s.users.UpdateName(ctx, u.ID, *in.Name); err != nil {
+			return UserDTO{}, err
+		}
+		u.Name = *in.Name
+	}
+
+	if in.Email == nil || strings.EqualFold(*in.Email, u.Email) {
+		return toUserDTO(u), nil
+	}
+
+	if err := s.reauthenticate(ctx, u, in.CurrentPassword, in.Confirmation); err != nil {
+		return UserDTO{}, err
+	}
+
+	_, err = s.users.GetByEmail(ctx, *in.Email)
+	if err == nil {
+		return UserDTO{}, ErrEmailTaken
+	}
+	if !errors.Is(err, users.ErrNotFound) {
+		return UserDTO{}, err
+	}
+
+	if err := s.users.UpdateEmail(ctx, u.ID, *in.Email); err != nil {
+		if isDuplicateEmail(err) {
+			return UserDTO{}, ErrEmailTaken
+		}
+		return UserDTO{}, err
+	}
+
+	oldEmail := u.Email
+	u.Email = *in.Email
+	u.EmailVerifiedAt = nil
+
+	s.notify(ctx, u.ID, oldEmail, "Your HighlightIQ email was changed",
+		fmt.Sprintf("Hi %s,\n\nThe email address of your HighlightIQ account was changed to %s.\n\n"+
+			"If this wasn't you, reset your password and contact support right away.\n",
+			u.Name, u.Email))
+
+	if err := s.sendVerification(ctx, u); err != nil {
+		log.Printf("auth: verification email for user %d failed: %v", u.ID, err)
+	}
+
+	return toUserDTO(u), nil
+}
+
+// ChangePassword replaces the password after checking the current one. Accounts created through
+// a provider have none; they set their first password with a confirmation code. Every session,
+// including the caller's, is signed out; the returned session replaces the caller's.
+func (s *Service) ChangePassword(ctx context.Context, in ChangePasswordInput) (RegisterOutput, error) {
+	u, err := s.users.GetByID(ctx, in.UserID)
+	if err != nil {
+		return RegisterOutput{}, err
+	}
+
+	if err := s.reauthenticate(ctx, u, in.CurrentPassword, in.Confirmation); err != nil {
+		return RegisterOutput{}, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(in.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return RegisterOutput{}, err
+	}
+	if err := s.users.UpdatePassword(ctx, u.ID, string(hash)); err != nil {
+		return RegisterOutput{}, err
+	}
+	if err := s.sessions.RevokeAllForUser(ctx, u.ID); err != nil {
+		return RegisterOutput{}, err
+	}
+
+	s.notify(ctx, u.ID, u.Email, "Your HighlightIQ password was changed",
+		fmt.Sprintf("Hi %s,\n\nThe password of your HighlightIQ account was just changed and all other "+
+			"sessions were signed out.\n\nIf this wasn't you, reset your password right away.\n", u.Name))
+
+	return s.issue(ctx, u, uuid.NewString())
+}
+
+// notify sends a security notice. It is informational, so failures are only logged.
+func (s *Service) notify(ctx context.Context, userID int64, to string, subject string, body string) {
+	if err := s.mailer.Send(ctx, mail.Message{To: to, Subject: subject, Body: body}); err != nil {
+		log.Printf("auth: notice %q for user %d failed: %v", subject, userID, err)
+	}
+}
+
+func toUserDTO(u users.User) UserDTO {
+	return UserDTO{
+		ID:            u.UUID,
+		Name:          u.Name,
+		Email:         u.Email,
+		EmailVerified: u.EmailVerifiedAt != nil,
+	}
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"highlightiq-server/internal/mail"
+	"highlightiq-server/internal/repos/loginattempts"
+	"highlightiq-server/internal/repos/users"
+	"highlightiq-server/internal/repos/usertokens"
+)
+
+// SendConfirmation mails a single-use code to an account without a password (one created by
+// signing in with a provider). The code stands in for the password on sensitive changes: the
+// email, the password itself, turning off 2FA and deleting the account.
+func (s *Service) SendConfirmation(ctx context.Context, userID int64) error {
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.PasswordHash != "" {
+		return ErrHasPassword
+	}
+
+	code, err := s.newUserToken(ctx, u.ID, usertokens.PurposeConfirm, s.confirmTTL)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, mail.Message{
+		To:      u.Email,
+		Subject: "Your HighlightIQ confirmation code",
+		Body: fmt.Sprintf("Hi %s,\n\nUse this code within %s to confirm the change to your account:\n\n%s\n\n"+
+			"If this wasn't you, someone may have access to one of your sessions; sign out everywhere "+
+			"from your account settings.\n",
+			u.Name, humanTTL(s.confirmTTL), code),
+	})
+}
+
+// ConfirmIdentity reports whether the caller proved they hold the account; see reauthenticate.
+// It is for other services guarding sensitive changes.
+func (s *Service) ConfirmIdentity(ctx context.Context, userID int64, password string, confirmation string) (bool, error) {
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	err = s.reauthenticate(ctx, u, password, confirmation)
+	if errors.Is(err, ErrInvalidCredentials) || errors.Is(err, ErrConfirmationRequired) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// reauthenticate checks the current password, or for accounts without one a code from
+// SendConfirmation, which is used up. Wrong passwords are throttled like failed logins, so a
+// stolen session cannot be used to guess the password faster than a login could, but under the
+// user's reauthKey: they do not lock the owner out of logging in. Successes are not recorded, so
+// a known password cannot be used to clear the count of wrong 2FA codes.
+func (s *Service) reauthenticate(ctx context.Context, u users.User, password string, confirmation string) error {
+	if u.PasswordHash != "" {
+		key := reauthKey(u.ID)
+		if err := s.checkThrottle(ctx, key, ""); err != nil {
+			return err
+		}
+		if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) != nil {
+			s.recordAttempt(ctx, LoginInput{}, key, &u.ID, loginattempts.OutcomeInvalidCredentials)
+			return ErrInvalidCredentials
+		}
+		return nil
+	}
+
+	confirmation = strings.TrimSpace(confirmation)
+	if confirmation == "" {
+		return ErrConfirmationRequired
+	}
+	userID, err := s.tokens.Consume(ctx, usertokens.PurposeConfirm, hashToken(confirmation))
+	if err != nil {
+		if errors.Is(err, usertokens.ErrNotFound) {
+			return ErrInvalidCredentials
+		}
+		return err
+	}
+	if userID != u.ID {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
+
+// reauthSecondFactor is checkSecondFactor for a signed-in user, throttled and counted under their
+// reauthKey like reauthenticate's password checks.
+func (s *Service) reauthSecondFactor(ctx context.Context, u users.User, code string) error {
+	key := reauthKey(u.ID)
+	if err := s.checkThrottle(ctx, key, ""); err != nil {
+		return err
+	}
+	if err := s.checkSecondFactor(ctx, u.ID, code); err != nil {
+		if errors.Is(err, ErrInvalidCode) {
+			s.recordAttempt(ctx, LoginInput{}, key, &u.ID, loginattempts.OutcomeInvalidCode)
+		}
+		return err
+	}
+	return nil
+}
+
+// reauthKey stands in for the login email when throttling checks made from a session. Login
+// emails are validated, so it never matches one.
+func reauthKey(userID int64) string {
+	return fmt.Sprintf("reauth:%d", userID)
+}
@@ -0,0 +1,292 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"highlightiq-server/internal/repos/loginattempts"
+	"highlightiq-server/internal/repos/mfa"
+	"highlightiq-server/internal/repos/users"
+	"highlightiq-server/internal/repos/usertokens"
+	"highlightiq-server/internal/totp"
+)
+
+const (
+	totpIssuer        = "HighlightIQ"
+	recoveryCodeCount = 10
+)
+
+// SetupTOTP starts enrolling an authenticator app. 2FA is not enforced until ConfirmTOTP
+// proves the app produces valid codes; calling this again replaces a pending secret.
+func (s *Service) SetupTOTP(ctx context.Context, userID int64) (TOTPSetup, error) {
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return TOTPSetup{}, err
+	}
+
+	cur, err := s.mfa.GetTOTP(ctx, userID)
+	if err != nil && !errors.Is(err, mfa.ErrNotFound) {
+		return TOTPSetup{}, err
+	}
+	if err == nil && cur.ConfirmedAt != nil {
+		return TOTPSetup{}, ErrTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return TOTPSetup{}, err
+	}
+	sealed, err := s.secrets.Seal(secret)
+	if err != nil {
+		return TOTPSetup{}, err
+	}
+	if err := s.mfa.SavePending(ctx, userID, sealed); err != nil {
+		return TOTPSetup{}, err
+	}
+
+	return TOTPSetup{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(totpIssuer, u.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP turns 2FA on with a first code from the app and returns the recovery codes. They
+// are shown only this once.
+func (s *Service) ConfirmTOTP(ctx context.Context, userID int64, code string) (RecoveryCodes, error) {
+	cur, err := s.mfa.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mfa.ErrNotFound) {
+			return RecoveryCodes{}, ErrTwoFactorNotEnabled
+		}
+		return RecoveryCodes{}, err
+	}
+	if cur.ConfirmedAt != nil {
+		return RecoveryCodes{}, ErrTwoFactorEnabled
+	}
+
+	secret, err := s.secrets.Open(cur.SecretEnc)
+	if err != nil {
+		return RecoveryCodes{}, err
+	}
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return RecoveryCodes{}, ErrInvalidCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return RecoveryCodes{}, err
+	}
+	if err := s.mfa.Confirm(ctx, userID, step, hashes); err != nil {
+		if errors.Is(err, mfa.ErrNotFound) {
+			return RecoveryCodes{}, ErrTwoFactorEnabled
+		}
+		return RecoveryCodes{}, err
+	}
+	return RecoveryCodes{Codes: codes}, nil
+}
+
+// DisableTOTP turns 2FA off. It takes the password (or a confirmation code, for accounts without
+// one) and a current code (or recovery code), so a stolen session alone cannot remove the second
+// factor. Wrong passwords and codes are throttled, see reauthenticate.
+func (s *Service) DisableTOTP(ctx context.Context, userID int64, password string, confirmation string, code string) error {
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.reauthenticate(ctx, u, password, confirmation); err != nil {
+		return err
+	}
+	if err := s.reauthSecondFactor(ctx, u, code); err != nil {
+		return err
+	}
+	return s.mfa.Delete(ctx, userID)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes; the old ones stop working. Wrong codes are
+// throttled like those of DisableTOTP.
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) (RecoveryCodes, error) {
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return RecoveryCodes{}, err
+	}
+	if err := s.reauthSecondFactor(ctx, u, code); err != nil {
+		return RecoveryCodes{}, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return RecoveryCodes{}, err
+	}
+	if err := s.mfa.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return RecoveryCodes{}, err
+	}
+	return RecoveryCodes{Codes: codes}, nil
+}
+
+func (s *Service) TwoFactorStatus(ctx context.Context, userID int64) (TwoFactorStatus, error) {
+	cur, err := s.mfa.GetTOTP(ctx, userID)
+	if errors.Is(err, mfa.ErrNotFound) {
+		return TwoFactorStatus{}, nil
+	}
+	if err != nil {
+		return TwoFactorStatus{}, err
+	}
+	if cur.ConfirmedAt == nil {
+		return TwoFactorStatus{Pending: true}, nil
+	}
+
+	n, err := s.mfa.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return TwoFactorStatus{}, err
+	}
+	return TwoFactorStatus{Enabled: true, RecoveryCodesRemaining: n}, nil
+}
+
+// VerifyLogin finishes a login that Login answered with a challenge. Wrong codes count as
+// failed logins for the account's email, so the second factor is throttled like the password.
+func (s *Service) VerifyLogin(ctx context.Context, in VerifyLoginInput) (RegisterOutput, error) {
+	challenge := hashToken(strings.TrimSpace(in.ChallengeToken))
+	userID, err := s.tokens.Lookup(ctx, usertokens.PurposeLoginChallenge, challenge)
+	if err != nil {
+		if errors.Is(err, usertokens.ErrNotFound) {
+			return RegisterOutput{}, ErrInvalidToken
+		}
+		return RegisterOutput{}, err
+	}
+
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, users.ErrNotFound) {
+			return RegisterOutput{}, ErrInvalidToken
+		}
+		return RegisterOutput{}, err
+	}
+
+	attempt := LoginInput{Email: u.Email, IP: in.IP, UserAgent: in.UserAgent}
+	email := normalizeLoginEmail(u.Email)
+	if err := s.checkThrottle(ctx, email, in.IP); err != nil {
+		var te *ThrottleError
+		if errors.As(err, &te) {
+			s.recordAttempt(ctx, attempt, email, &u.ID, loginattempts.OutcomeThrottled)
+		}
+		return RegisterOutput{}, err
+	}
+
+	if err := s.checkSecondFactor(ctx, u.ID, in.Code); err != nil {
+		if errors.Is(err, ErrInvalidCode) {
+			s.recordAttempt(ctx, attempt, email, &u.ID, loginattempts.OutcomeInvalidCode)
+		}
+		return RegisterOutput{}, err
+	}
+
+	// The challenge is spent only now, so a mistyped code can be retried with the same one.
+	if _, err := s.tokens.Consume(ctx, usertokens.PurposeLoginChallenge, challenge); err != nil {
+		if errors.Is(err, usertokens.ErrNotFound) {
+			return RegisterOutput{}, ErrInvalidToken
+		}
+		return RegisterOutput{}, err
+	}
+
+	s.recordAttempt(ctx, attempt, email, &u.ID, loginattempts.OutcomeSuccess)
+	return s.issue(ctx, u, uuid.NewString())
+}
+
+// startChallenge hands out the token for the second login step.
+func (s *Service) startChallenge(ctx context.Context, userID int64) (LoginOutput, error) {
+	token, err := s.newUserToken(ctx, userID, usertokens.PurposeLoginChallenge, s.challengeTTL)
+	if err != nil {
+		return LoginOutput{}, err
+	}
+	return LoginOutput{
+		MFARequired:        true,
+		ChallengeToken:     token,
+		ChallengeExpiresIn: int64(s.challengeTTL.Seconds()),
+	}, nil
+}
+
+// requiresTOTP reports whether the user has a confirmed authenticator.
+func (s *Service) requiresTOTP(ctx context.Context, userID int64) (bool, error) {
+	t, err := s.mfa.GetTOTP(ctx, userID)
+	if errors.Is(err, mfa.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return t.ConfirmedAt != nil, nil
+}
+
+// checkSecondFactor accepts a current TOTP code or an unused recovery code. Each is good once.
+func (s *Service) checkSecondFactor(ctx context.Context, userID int64, code string) error {
+	t, err := s.mfa.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mfa.ErrNotFound) {
+			return ErrTwoFactorNotEnabled
+		}
+		return err
+	}
+	if t.ConfirmedAt == nil {
+		return ErrTwoFactorNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) > totp.Digits {
+		ok, err := s.mfa.UseRecoveryCode(ctx, userID, hashToken(normalizeRecoveryCode(code)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInvalidCode
+		}
+		return nil
+	}
+
+	secret, err := s.secrets.Open(t.SecretEnc)
+	if err != nil {
+		return err
+	}
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return ErrInvalidCode
+	}
+	fresh, err := s.mfa.UseStep(ctx, userID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+// newRecoveryCodes returns codes like "k7d2q-xm4pa" and the hashes to store.
+func newRecoveryCodes() ([]string, []string, error) {
+	// 32 symbols, so a random byte maps onto them without bias; no i, l or o to misread.
+	const alphabet = "abcdefghjkmnpqrstuvwxyz123456789"
+
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		for j := range b {
+			b[j] = alphabet[int(b[j])%len(alphabet)]
+		}
+		code := string(b[:5]) + "-" + string(b[5:])
+		codes = append(codes, code)
+		hashes = append(hashes, hashToken(normalizeRecoveryCode(code)))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
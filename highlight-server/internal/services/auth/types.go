@@ -1,10 +1,36 @@
 package auth
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	ErrEmailTaken         = errors.New("auth: email already registered")
 	ErrInvalidCredentials = errors.New("auth: invalid credentials")
+	ErrInvalidRefresh     = errors.New("auth: invalid refresh token")
+	ErrInvalidToken       = errors.New("auth: invalid or expired token")
+	ErrAlreadyVerified    = errors.New("auth: email already verified")
+	ErrTooManyAttempts    = errors.New("auth: too many login attempts")
+
+	ErrInvalidCode         = errors.New("auth: invalid two-factor code")
+	ErrTwoFactorEnabled    = errors.New("auth: two-factor authentication already enabled")
+	ErrTwoFactorNotEnabled = errors.New("auth: two-factor authentication not enabled")
+
+	ErrUnknownProvider = errors.New("auth: unknown identity provider")
+	ErrInvalidState    = errors.New("auth: invalid or expired oauth state")
+	ErrProviderFailed  = errors.New("auth: identity provider request failed")
+	ErrEmailUnverified = errors.New("auth: provider did not return a verified email")
+	ErrAccountExists   = errors.New("auth: an unverified account already uses this email")
+	ErrIdentityInUse   = errors.New("auth: identity linked to another account")
+	ErrAlreadyLinked   = errors.New("auth: provider already linked")
+	ErrNotLinked       = errors.New("auth: provider not linked")
+	ErrLastLoginMethod = errors.New("auth: cannot remove the last way to sign in")
+
+	// ErrConfirmationRequired: the account has no password, so a sensitive change needs a code
+	// from SendConfirmation instead.
+	ErrConfirmationRequired = errors.New("auth: confirmation code required")
+	ErrHasPassword          = errors.New("auth: account has a password")
 )
 
 // RegisterInput is what the service needs (already validated by the request layer).
@@ -15,17 +41,113 @@ type RegisterInput struct {
 }
 
 type UserDTO struct {
-	ID    string `json:"id"` // public id (uuid)
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	ID            string `json:"id"` // public id (uuid)
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
 }
 
 type RegisterOutput struct {
-	User        UserDTO `json:"user"`
-	AccessToken string  `json:"access_token"`
-	TokenType   string  `json:"token_type"`
+	User         UserDTO `json:"user"`
+	AccessToken  string  `json:"access_token"`
+	TokenType    string  `json:"token_type"`
+	ExpiresIn    int64   `json:"expires_in"` // access token lifetime in seconds
+	RefreshToken string  `json:"refresh_token"`
 }
 type LoginInput struct {
 	Email    string
 	Password string
+
+	// IP and UserAgent identify the client for throttling and the login audit.
+	IP        string
+	UserAgent string
+}
+
+// UpdateProfileInput changes only the fields that are set. CurrentPassword is needed when the
+// email changes, or Confirmation for accounts without a password.
+type UpdateProfileInput struct {
+	UserID          int64
+	Name            *string
+	Email           *string
+	CurrentPassword string
+	Confirmation    string
+}
+
+// ChangePasswordInput replaces the password. Accounts without one set their first password with
+// a Confirmation code instead of CurrentPassword.
+type ChangePasswordInput struct {
+	UserID          int64
+	CurrentPassword string
+	Confirmation    string
+	NewPassword     string
+}
+
+// LogoutInput identifies the session to end. RefreshToken is optional; without it only the
+// presented access token is revoked.
+type LogoutInput struct {
+	UserID        int64
+	AccessJTI     string
+	AccessExpires time.Time
+	RefreshToken  string
+}
+
+// LoginOutput is either a session (RegisterOutput) or, for accounts with 2FA, a challenge to
+// complete with VerifyLogin.
+type LoginOutput struct {
+	*RegisterOutput
+	MFARequired        bool   `json:"mfa_required,omitempty"`
+	ChallengeToken     string `json:"challenge_token,omitempty"`
+	ChallengeExpiresIn int64  `json:"challenge_expires_in,omitempty"` // seconds
+}
+
+// VerifyLoginInput is the second login step: the challenge from Login plus a TOTP or recovery
+// code.
+type VerifyLoginInput struct {
+	ChallengeToken string
+	Code           string
+	IP             string
+	UserAgent      string
+}
+
+type TOTPSetup struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"` // render as a QR code for authenticator apps
+}
+
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled"`
+	Pending                bool `json:"pending"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// OAuthStart is where to send the browser to sign in with a provider. State is the value the
+// provider echoes back; the handler pins it to the browser so a callback cannot be replayed in
+// someone else's.
+type OAuthStart struct {
+	AuthorizationURL string        `json:"authorization_url"`
+	State            string        `json:"-"`
+	ExpiresIn        time.Duration `json:"-"`
+}
+
+// OAuthCallbackInput is what the provider redirected back with.
+type OAuthCallbackInput struct {
+	Provider string
+	Code     string
+	State    string
+
+	// IP and UserAgent identify the client for the login audit (sign-in only).
+	IP        string
+	UserAgent string
+}
+
+// LinkIdentityInput completes linking a provider to the signed-in user.
+type LinkIdentityInput struct {
+	UserID   int64
+	Provider string
+	Code     string
+	State    string
 }
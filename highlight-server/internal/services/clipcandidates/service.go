@@ -6,23 +6,37 @@ import (
 	"sort"
 
 	"highlightiq-server/internal/integrations/clipper"
+	"highlightiq-server/internal/lifecycle"
 	candidatesrepo "highlightiq-server/internal/repos/clipcandidates"
 	recordingsrepo "highlightiq-server/internal/repos/recordings"
+	usagesvc "highlightiq-server/internal/services/usage"
+	webhookssvc "highlightiq-server/internal/services/webhooks"
+	workspacessvc "highlightiq-server/internal/services/workspaces"
+	"highlightiq-server/internal/storage"
 )
 
 var ErrNotFound = errors.New("clipcandidates: recording not found")
+var ErrCandidateNotFound = errors.New("clipcandidates: candidate not found")
 
 type Service struct {
 	recordings *recordingsrepo.Repo
 	candidates *candidatesrepo.Repo
 	clipper    *clipper.Client
+	files      *storage.Cache
+	usage      *usagesvc.Service
+	access     *workspacessvc.Service
+	hooks      *webhookssvc.Service
 }
 
-func New(recordings *recordingsrepo.Repo, candidates *candidatesrepo.Repo, clipperClient *clipper.Client) *Service {
+func New(recordings *recordingsrepo.Repo, candidates *candidatesrepo.Repo, clipperClient *clipper.Client, files *storage.Cache, usage *usagesvc.Service, access *workspacessvc.Service, hooks *webhookssvc.Service) *Service {
 	return &Service{
 		recordings: recordings,
 		candidates: candidates,
 		clipper:    clipperClient,
+		files:      files,
+		usage:      usage,
+		access:     access,
+		hooks:      hooks,
 	}
 }
 
@@ -53,6 +67,8 @@ type DetectInput struct {
 	// CooldownSeconds is now exposed above
 }
 
+// DetectAndStore runs kill detection on a recording and stores the candidates found. The
+// recording is processing while detection runs, and ready or failed after.
 func (s *Service) DetectAndStore(ctx context.Context, userID int64, in DetectInput) (int64, error) {
 	if in.RecordingUUID == "" {
 		return 0, ErrNotFound
@@ -62,6 +78,9 @@ func (s *Service) DetectAndStore(ctx context.Context, userID int64, in DetectInp
 	if err != nil {
 		return 0, ErrNotFound
 	}
+	if err := s.access.RequireEditor(ctx, userID, rec.WorkspaceID, ErrNotFound); err != nil {
+		return 0, err
+	}
 
 	// ---- defaults (match python defaults) ----
 	if in.MaxClipSeconds <= 0 {
@@ -110,8 +129,48 @@ func (s *Service) DetectAndStore(ctx context.Context, userID int64, in DetectInp
 	if in.CooldownSeconds <= 0 {
 		in.CooldownSeconds = 1.2
 	}
+
+	if s.usage != nil {
+		if err := s.usage.CheckDetection(ctx, userID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := lifecycle.Recording.Check(rec.Status, "processing"); err != nil {
+		return 0, err
+	}
+	if err := s.recordings.SetStatus(ctx, rec.ID, rec.Status, "processing", &userID); err != nil {
+		return 0, err
+	}
+
+	created, err := s.detect(ctx, userID, rec, in)
+	if err != nil {
+		// The request's context may be what ended detection.
+		_ = s.recordings.SetStatus(context.WithoutCancel(ctx), rec.ID, "processing", "failed", nil)
+		return 0, err
+	}
+	if err := s.recordings.SetStatus(ctx, rec.ID, "processing", "ready", nil); err != nil {
+		return 0, err
+	}
+
+	s.hooks.Emit(ctx, rec.WorkspaceID, webhookssvc.EventDetectionCompleted, webhookssvc.DetectionData{
+		RecordingUUID: rec.UUID,
+		Candidates:    created,
+	})
+	return created, nil
+}
+
+// detect runs the clipper on the recording, bills the minutes scanned and stores the best
+// candidates, returning how many were stored.
+func (s *Service) detect(ctx context.Context, userID int64, rec recordingsrepo.Recording, in DetectInput) (int64, error) {
+	// The clipper reads from disk, so make sure there is a local copy of the recording.
+	path, err := s.files.LocalPath(ctx, rec.StoragePath)
+	if err != nil {
+		return 0, err
+	}
+
 	resp, err := s.clipper.DetectKills(ctx, clipper.DetectKillsRequest{
-		Path:               rec.StoragePath,
+		Path:               path,
 		MaxClipSeconds:     in.MaxClipSeconds,
 		PreRollSeconds:     in.PreRollSeconds,
 		PostRollSeconds:    in.PostRollSeconds,
@@ -127,6 +186,13 @@ func (s *Service) DetectAndStore(ctx context.Context, userID int64, in DetectInp
 		return 0, err
 	}
 
+	// Detection minutes are billed on the length of video scanned.
+	if s.usage != nil {
+		if err := s.usage.RecordDetection(ctx, userID, int64(resp.VideoEndSeconds+0.5)); err != nil {
+			return 0, err
+		}
+	}
+
 	// Sort by score desc, then start asc
 	sort.Slice(resp.Candidates, func(i, j int) bool {
 		if resp.Candidates[i].Score == resp.Candidates[j].Score {
@@ -178,14 +244,48 @@ func (s *Service) ListByRecordingUUID(ctx context.Context, userID int64, recordi
 	return s.candidates.ListByRecordingID(ctx, rec.ID)
 }
 
-func (s *Service) UpdateStatus(ctx context.Context, id int64, status string) error {
-	return s.candidates.UpdateStatus(ctx, id, status)
+func (s *Service) UpdateStatus(ctx context.Context, userID int64, id int64, status string) error {
+	c, err := s.requireEditor(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if err := lifecycle.Candidate.Check(c.Status, status); err != nil {
+		return err
+	}
+	if err := s.candidates.SetStatus(ctx, id, c.Status, status, &userID); err != nil {
+		return err
+	}
+
+	if status == "approved" && c.Status != "approved" {
+		c.Status = status
+		s.hooks.Emit(ctx, c.WorkspaceID, webhookssvc.EventCandidateApproved, webhookssvc.NewCandidateData(c))
+	}
+	return nil
 }
 
-func (s *Service) Delete(ctx context.Context, id int64) error {
+func (s *Service) Delete(ctx context.Context, userID int64, id int64) error {
+	if _, err := s.requireEditor(ctx, userID, id); err != nil {
+		return err
+	}
 	return s.candidates.Delete(ctx, id)
 }
 
+// requireEditor checks that the candidate's recording is in a workspace the user can edit, and
+// returns the candidate.
+func (s *Service) requireEditor(ctx context.Context, userID int64, id int64) (candidatesrepo.Candidate, error) {
+	c, err := s.candidates.GetByIDForUser(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, candidatesrepo.ErrNotFound) {
+			return candidatesrepo.Candidate{}, ErrCandidateNotFound
+		}
+		return candidatesrepo.Candidate{}, err
+	}
+	if err := s.access.RequireEditor(ctx, userID, c.WorkspaceID, ErrCandidateNotFound); err != nil {
+		return candidatesrepo.Candidate{}, err
+	}
+	return c, nil
+}
+
 func abs(x int) int {
 	if x < 0 {
 		return -x
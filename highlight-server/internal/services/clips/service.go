@@ -9,66 +9,63 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"runtime"
 	"strings"
 
+	"highlightiq-server/internal/integrations/ffmpeg"
+	"highlightiq-server/internal/lifecycle"
 	clipsrepo "highlightiq-server/internal/repos/clips"
 	recordingsrepo "highlightiq-server/internal/repos/recordings"
+	tagsrepo "highlightiq-server/internal/repos/tags"
+	usagerepo "highlightiq-server/internal/repos/usage"
+	usagesvc "highlightiq-server/internal/services/usage"
+	webhookssvc "highlightiq-server/internal/services/webhooks"
+	workspacessvc "highlightiq-server/internal/services/workspaces"
+	"highlightiq-server/internal/signedurl"
+	"highlightiq-server/internal/storage"
 )
 
 var ErrNotFound = errors.New("clips: not found")
 var ErrBadInput = errors.New("clips: bad input")
 var ErrNotReady = errors.New("clips: not ready")
+var ErrRecordingDeleted = errors.New("clips: recording is in the trash")
+var ErrLinkInvalid = errors.New("clips: invalid link signature")
+var ErrLinkExpired = errors.New("clips: link expired")
+
+// A clip is published exactly while one of its publications is live.
+var ErrNoLivePublications = fmt.Errorf("%w: the clip has no live publications", lifecycle.ErrIllegalTransition)
+var ErrLivePublications = fmt.Errorf("%w: the clip has live publications", lifecycle.ErrIllegalTransition)
 
 type Service struct {
 	clipsRepo      *clipsrepo.Repo
 	recordingsRepo *recordingsrepo.Repo
-	clipsDir       string
+	tagsRepo       *tagsrepo.Repo
+	recordingFiles *storage.Cache
+	clipFiles      *storage.Cache
+	workDir        string
 	ffmpegPath     string
-	notifier       PublishNotifier
-	clipsBaseURL   string
+	urls           *signedurl.Signer
+	usage          *usagesvc.Service
+	access         *workspacessvc.Service
+	hooks          *webhookssvc.Service
 }
 
-func New(clipsRepo *clipsrepo.Repo, recordingsRepo *recordingsrepo.Repo, clipsDir string, clipsBaseURL string, notifier PublishNotifier) *Service {
+// New wires the clips service. recordingFiles provides local copies of source recordings for
+// ffmpeg, clipFiles is where exports are stored, and workDir holds ffmpeg output until upload.
+// hooks announces finished exports.
+func New(clipsRepo *clipsrepo.Repo, recordingsRepo *recordingsrepo.Repo, tagsRepo *tagsrepo.Repo, recordingFiles *storage.Cache, clipFiles *storage.Cache, workDir string, urls *signedurl.Signer, usage *usagesvc.Service, access *workspacessvc.Service, hooks *webhookssvc.Service) *Service {
 	return &Service{
 		clipsRepo:      clipsRepo,
 		recordingsRepo: recordingsRepo,
-		clipsDir:       clipsDir,
-		ffmpegPath:     resolveFFmpegPath(),
-		notifier:       notifier,
-		clipsBaseURL:   clipsBaseURL,
-	}
-}
-
-func resolveFFmpegPath() string {
-	// 1) Allow explicit override (recommended on Windows)
-	// Can be either:
-	//   - full path to ffmpeg.exe
-	//   - directory that contains ffmpeg.exe
-	if v := strings.TrimSpace(os.Getenv("FFMPEG_PATH")); v != "" {
-		// If it's a directory, append ffmpeg(.exe)
-		if st, err := os.Stat(v); err == nil && st.IsDir() {
-			if runtime.GOOS == "windows" {
-				return filepath.Join(v, "ffmpeg.exe")
-			}
-			return filepath.Join(v, "ffmpeg")
-		}
-		return v
-	}
-
-	// 2) Try PATH lookup
-	// On Windows, try both "ffmpeg" and "ffmpeg.exe"
-	if p, err := exec.LookPath("ffmpeg"); err == nil {
-		return p
-	}
-	if runtime.GOOS == "windows" {
-		if p, err := exec.LookPath("ffmpeg.exe"); err == nil {
-			return p
-		}
+		tagsRepo:       tagsRepo,
+		recordingFiles: recordingFiles,
+		clipFiles:      clipFiles,
+		workDir:        workDir,
+		ffmpegPath:     ffmpeg.ResolvePath(),
+		urls:           urls,
+		usage:          usage,
+		access:         access,
+		hooks:          hooks,
 	}
-
-	// 3) Fallback (will error at runtime with a clear message)
-	return "ffmpeg"
 }
 
 type CreateInput struct {
@@ -91,9 +88,14 @@ func (s *Service) Create(ctx context.Context, userID int64, in CreateInput) (cli
 	if err != nil {
 		return clipsrepo.Clip{}, ErrNotFound
 	}
+	if err := s.access.RequireEditor(ctx, userID, rec.WorkspaceID, ErrNotFound); err != nil {
+		return clipsrepo.Clip{}, err
+	}
 
+	// Clips live in the workspace of their recording.
 	return s.clipsRepo.Create(ctx, clipsrepo.CreateParams{
 		UserID:      userID,
+		WorkspaceID: rec.WorkspaceID,
 		RecordingID: rec.ID,
 		CandidateID: in.CandidateID,
 		Title:       in.Title,
@@ -113,10 +115,14 @@ func (s *Service) Get(ctx context.Context, userID int64, id int64) (clipsrepo.Cl
 		}
 		return clipsrepo.Clip{}, err
 	}
+	if err := s.attachTags(ctx, []*clipsrepo.Clip{&c}); err != nil {
+		return clipsrepo.Clip{}, err
+	}
 	return c, nil
 }
 
-func (s *Service) List(ctx context.Context, userID int64, recordingUUID *string) ([]clipsrepo.Clip, error) {
+// List returns clips across the user's workspaces, or only workspaceID when it is not 0.
+func (s *Service) List(ctx context.Context, userID int64, workspaceID int64, recordingUUID *string, tag *string) ([]clipsrepo.Clip, error) {
 	var recordingID *int64
 	if recordingUUID != nil && *recordingUUID != "" {
 		rec, err := s.recordingsRepo.GetByUUIDForUser(ctx, userID, *recordingUUID, 0)
@@ -126,7 +132,43 @@ func (s *Service) List(ctx context.Context, userID int64, recordingUUID *string)
 		recordingID = &rec.ID
 	}
 
-	return s.clipsRepo.ListByUser(ctx, userID, recordingID)
+	items, err := s.clipsRepo.ListByUser(ctx, userID, workspaceID, recordingID, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	ptrs := make([]*clipsrepo.Clip, 0, len(items))
+	for i := range items {
+		ptrs = append(ptrs, &items[i])
+	}
+	if err := s.attachTags(ctx, ptrs); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// attachTags loads tag names for the given clips in a single query.
+func (s *Service) attachTags(ctx context.Context, items []*clipsrepo.Clip) error {
+	if s.tagsRepo == nil || len(items) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(items))
+	for _, c := range items {
+		ids = append(ids, c.ID)
+	}
+
+	names, err := s.tagsRepo.NamesForClips(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, c := range items {
+		c.Tags = names[c.ID]
+		if c.Tags == nil {
+			c.Tags = []string{}
+		}
+	}
+	return nil
 }
 
 type UpdateInput struct {
@@ -141,13 +183,31 @@ func (s *Service) Update(ctx context.Context, userID int64, id int64, in UpdateI
 	if in.StartMS != nil && in.EndMS != nil && *in.EndMS <= *in.StartMS {
 		return clipsrepo.Clip{}, ErrBadInput
 	}
+	if err := s.requireEditor(ctx, userID, id); err != nil {
+		return clipsrepo.Clip{}, err
+	}
+
+	if in.Status != nil {
+		cur, err := s.clipsRepo.GetByIDForUser(ctx, userID, id)
+		if err != nil {
+			if errors.Is(err, clipsrepo.ErrNotFound) {
+				return clipsrepo.Clip{}, ErrNotFound
+			}
+			return clipsrepo.Clip{}, err
+		}
+		if err := s.checkStatus(ctx, cur, *in.Status); err != nil {
+			return clipsrepo.Clip{}, err
+		}
+		if err := s.clipsRepo.SetStatus(ctx, id, cur.Status, *in.Status, &userID); err != nil {
+			return clipsrepo.Clip{}, err
+		}
+	}
 
 	c, err := s.clipsRepo.UpdateByIDForUser(ctx, userID, id, clipsrepo.UpdateParams{
 		Title:   in.Title,
 		Caption: in.Caption,
 		StartMS: in.StartMS,
 		EndMS:   in.EndMS,
-		Status:  in.Status,
 	})
 	if err != nil {
 		if errors.Is(err, clipsrepo.ErrNotFound) {
@@ -158,17 +218,100 @@ func (s *Service) Update(ctx context.Context, userID int64, id int64, in UpdateI
 	return c, nil
 }
 
+// checkStatus checks that the clip may go to status: the change must be in lifecycle.Clip, and
+// published must match whether the clip has live publications.
+func (s *Service) checkStatus(ctx context.Context, c clipsrepo.Clip, status string) error {
+	if err := lifecycle.Clip.Check(c.Status, status); err != nil {
+		return err
+	}
+	if c.Status == status || (status != "published" && c.Status != "published") {
+		return nil
+	}
+
+	live, err := s.clipsRepo.CountLivePublications(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+	if status == "published" && live == 0 {
+		return ErrNoLivePublications
+	}
+	if c.Status == "published" && live > 0 {
+		return ErrLivePublications
+	}
+	return nil
+}
+
+// Delete moves the clip to the trash. The exported file stays in storage until the trash is purged.
 func (s *Service) Delete(ctx context.Context, userID int64, id int64) error {
-	err := s.clipsRepo.DeleteByIDForUser(ctx, userID, id)
+	if err := s.requireEditor(ctx, userID, id); err != nil {
+		return err
+	}
+	held, err := s.holding(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = s.clipsRepo.SoftDeleteByIDForUser(ctx, userID, id)
 	if err != nil {
 		if errors.Is(err, clipsrepo.ErrNotFound) {
 			return ErrNotFound
 		}
 		return err
 	}
+	if s.usage != nil {
+		if err := s.usage.Release(ctx, held); err != nil {
+			log.Printf("clips: releasing usage of clip %d failed: %v", id, err)
+		}
+	}
 	return nil
 }
 
+// Restore takes a clip out of the trash. The clip's recording must not be in the trash itself,
+// and its export must fit in the owner's quota again.
+func (s *Service) Restore(ctx context.Context, userID int64, id int64) (clipsrepo.Clip, error) {
+	if err := s.requireEditor(ctx, userID, id); err != nil {
+		return clipsrepo.Clip{}, err
+	}
+	c, err := s.clipsRepo.GetDeletedByIDForUser(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, clipsrepo.ErrNotFound) {
+			return clipsrepo.Clip{}, ErrNotFound
+		}
+		return clipsrepo.Clip{}, err
+	}
+
+	if _, err := s.recordingsRepo.GetStoragePathByIDForUser(ctx, userID, c.RecordingID); err != nil {
+		if errors.Is(err, recordingsrepo.ErrNotFound) {
+			return clipsrepo.Clip{}, ErrRecordingDeleted
+		}
+		return clipsrepo.Clip{}, err
+	}
+
+	held, err := s.holding(ctx, id)
+	if err != nil {
+		return clipsrepo.Clip{}, err
+	}
+	if s.usage != nil {
+		if err := s.usage.Charge(ctx, held); err != nil {
+			return clipsrepo.Clip{}, err
+		}
+	}
+
+	restored, err := s.clipsRepo.RestoreByIDForUser(ctx, userID, id)
+	if err != nil {
+		if s.usage != nil {
+			_ = s.usage.Release(ctx, held)
+		}
+		if errors.Is(err, clipsrepo.ErrNotFound) {
+			return clipsrepo.Clip{}, ErrNotFound
+		}
+		return clipsrepo.Clip{}, err
+	}
+	if err := s.attachTags(ctx, []*clipsrepo.Clip{&restored}); err != nil {
+		return clipsrepo.Clip{}, err
+	}
+	return restored, nil
+}
+
 func (s *Service) GetExport(ctx context.Context, userID int64, id int64) (string, string, error) {
 	c, err := s.clipsRepo.GetByIDForUser(ctx, userID, id)
 	if err != nil {
@@ -193,7 +336,7 @@ func (s *Service) Export(ctx context.Context, userID int64, id int64) (clipsrepo
 	// Fail early with a clearer error if ffmpeg isn't resolvable.
 	if s.ffmpegPath == "ffmpeg" || s.ffmpegPath == "ffmpeg.exe" {
 		// try one more time at runtime
-		s.ffmpegPath = resolveFFmpegPath()
+		s.ffmpegPath = ffmpeg.ResolvePath()
 	}
 	if strings.EqualFold(filepath.Base(s.ffmpegPath), "ffmpeg") || strings.EqualFold(filepath.Base(s.ffmpegPath), "ffmpeg.exe") {
 		if _, err := exec.LookPath(s.ffmpegPath); err != nil && !filepath.IsAbs(s.ffmpegPath) {
@@ -208,20 +351,34 @@ func (s *Service) Export(ctx context.Context, userID int64, id int64) (clipsrepo
 		}
 		return clipsrepo.Clip{}, err
 	}
+	if err := s.access.RequireEditor(ctx, userID, c.WorkspaceID, ErrNotFound); err != nil {
+		return clipsrepo.Clip{}, err
+	}
 
-	inputPath, err := s.recordingsRepo.GetStoragePathByIDForUser(ctx, userID, c.RecordingID)
+	recordingKey, err := s.recordingsRepo.GetStoragePathByIDForUser(ctx, userID, c.RecordingID)
 	if err != nil {
 		return clipsrepo.Clip{}, ErrNotFound
 	}
-	if _, err := os.Stat(inputPath); err != nil {
-		return clipsrepo.Clip{}, fmt.Errorf("recording file not found at %q: %w", inputPath, err)
+
+	// Exports count against the clip's owner, whoever runs them, like the clip itself.
+	if s.usage != nil {
+		if err := s.usage.CheckExport(ctx, c.UserID); err != nil {
+			return clipsrepo.Clip{}, err
+		}
 	}
 
-	if err := os.MkdirAll(s.clipsDir, 0755); err != nil {
+	inputPath, err := s.recordingFiles.LocalPath(ctx, recordingKey)
+	if err != nil {
+		return clipsrepo.Clip{}, fmt.Errorf("recording file %q not available: %w", recordingKey, err)
+	}
+
+	if err := os.MkdirAll(s.workDir, 0755); err != nil {
 		return clipsrepo.Clip{}, err
 	}
 
-	outPath := filepath.Join(s.clipsDir, fmt.Sprintf("clip_%d.mp4", c.ID))
+	key := fmt.Sprintf("clip_%d.mp4", c.ID)
+	outPath := filepath.Join(s.workDir, fmt.Sprintf(".export_clip_%d.mp4", c.ID))
+	defer os.Remove(outPath)
 
 	startSec := float64(c.StartMS) / 1000.0
 	durSec := float64(c.EndMS-c.StartMS) / 1000.0
@@ -250,10 +407,10 @@ func (s *Service) Export(ctx context.Context, userID int64, id int64) (clipsrepo
 
 	out, runErr := cmd.CombinedOutput()
 	if runErr != nil {
-		failed := "failed"
-		_, _ = s.clipsRepo.UpdateByIDForUser(ctx, userID, id, clipsrepo.UpdateParams{
-			Status: &failed,
-		})
+		// A clip that was exported before keeps its last export and status.
+		if c.Status == "draft" {
+			_ = s.clipsRepo.SetStatus(ctx, id, c.Status, "failed", &userID)
+		}
 
 		msg := strings.TrimSpace(string(out))
 		if msg == "" {
@@ -262,33 +419,116 @@ func (s *Service) Export(ctx context.Context, userID int64, id int64) (clipsrepo
 		return clipsrepo.Clip{}, fmt.Errorf("ffmpeg failed: %s", msg)
 	}
 
-	ready := "ready"
+	// Charge only the size difference when an existing export is replaced.
+	st, err := os.Stat(outPath)
+	if err != nil {
+		return clipsrepo.Clip{}, err
+	}
+	prevBytes, err := s.clipsRepo.GetExportBytes(ctx, c.ID)
+	if err != nil {
+		return clipsrepo.Clip{}, err
+	}
+	delta := st.Size() - prevBytes
+	if s.usage != nil {
+		if err := s.usage.ChargeExport(ctx, c.UserID, delta); err != nil {
+			return clipsrepo.Clip{}, err
+		}
+	}
+
+	if err := storage.PutFile(ctx, s.clipFiles.Store(), key, outPath, "video/mp4"); err != nil {
+		if s.usage != nil {
+			_ = s.usage.ChargeExport(ctx, c.UserID, -delta)
+		}
+		return clipsrepo.Clip{}, err
+	}
+	s.clipFiles.Evict(key)
+
+	if err := s.clipsRepo.SetExportBytes(ctx, c.ID, st.Size()); err != nil {
+		// The usage stays in step with the recorded size, which is what purges give back.
+		if s.usage != nil {
+			_ = s.usage.ChargeExport(ctx, c.UserID, -delta)
+		}
+		return clipsrepo.Clip{}, err
+	}
+
+	// Exports written before keys were introduced live under a different name.
+	if c.ExportPath != nil && *c.ExportPath != "" && *c.ExportPath != key {
+		_ = s.clipFiles.Store().Delete(ctx, *c.ExportPath)
+	}
+
+	// Re-exporting a ready or published clip leaves its status alone.
+	if c.Status == "draft" || c.Status == "failed" {
+		if err := s.clipsRepo.SetStatus(ctx, id, c.Status, "ready", &userID); err != nil {
+			return clipsrepo.Clip{}, err
+		}
+	}
 	updated, err := s.clipsRepo.UpdateByIDForUser(ctx, userID, id, clipsrepo.UpdateParams{
-		Status:     &ready,
-		ExportPath: &outPath,
+		ExportPath: &key,
 	})
 	if err != nil {
 		return clipsrepo.Clip{}, err
 	}
 
-	if s.notifier != nil {
-		clipURL := s.buildClipURL(updated.ExportPath)
-		if err := s.notifier.NotifyClipExported(ctx, updated, clipURL); err != nil {
-			log.Printf("n8n notify failed for clip %d: %v", updated.ID, err)
-		}
+	if err := s.attachTags(ctx, []*clipsrepo.Clip{&updated}); err != nil {
+		return clipsrepo.Clip{}, err
 	}
 
+	s.hooks.Emit(ctx, updated.WorkspaceID, webhookssvc.EventClipExported, webhookssvc.NewClipData(updated))
 	return updated, nil
 }
 
-func (s *Service) buildClipURL(exportPath *string) string {
-	if exportPath == nil || *exportPath == "" {
+// holding is what the clip counts against its owner's quota outside the trash.
+func (s *Service) holding(ctx context.Context, id int64) (usagerepo.Holding, error) {
+	if s.usage == nil {
+		return usagerepo.Holding{}, nil
+	}
+	return s.usage.ClipHolding(ctx, id)
+}
+
+// requireEditor turns a viewer's attempt to change a clip into workspaces.ErrForbidden instead
+// of a not-found from the edit-scoped query.
+func (s *Service) requireEditor(ctx context.Context, userID int64, id int64) error {
+	wsID, err := s.clipsRepo.GetWorkspaceIDByIDForUser(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, clipsrepo.ErrNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return s.access.RequireEditor(ctx, userID, wsID, ErrNotFound)
+}
+
+// PublicURL returns a signed, expiring download link for an exported clip, or "" when
+// public links are not configured.
+func (s *Service) PublicURL(id int64) string {
+	if s.urls == nil {
 		return ""
 	}
-	if s.clipsBaseURL == "" {
-		return *exportPath
+	return s.urls.URL(publicPath(id))
+}
+
+// GetPublicExport resolves a signed public link to the clip's export key and file name.
+func (s *Service) GetPublicExport(ctx context.Context, id int64, exp string, sig string) (string, string, error) {
+	if s.urls == nil {
+		return "", "", ErrNotFound
+	}
+	if err := s.urls.Verify(publicPath(id), exp, sig); err != nil {
+		if errors.Is(err, signedurl.ErrExpired) {
+			return "", "", ErrLinkExpired
+		}
+		return "", "", ErrLinkInvalid
 	}
 
-	base := strings.TrimRight(s.clipsBaseURL, "/")
-	return base + "/" + filepath.Base(*exportPath)
+	key, err := s.clipsRepo.GetExportPathByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, clipsrepo.ErrNotFound) {
+			return "", "", ErrNotFound
+		}
+		return "", "", err
+	}
+	return key, filepath.Base(key), nil
+}
+
+func publicPath(id int64) string {
+	return fmt.Sprintf("/public/clips/%d", id)
 }
@@ -0,0 +1,42 @@
+package collections
+
+import (
+	collectionsrepo "highlightiq-server/internal/repos/collections"
+)
+
+type PlaylistItem struct {
+	ClipID         int64  `json:"clip_id"`
+	Position       int    `json:"position"`
+	YoutubeVideoID string `json:"youtube_video_id"`
+}
+
+// PlaylistEvent is the payload of outbox.TopicPlaylistPublish: create (or refresh) the YouTube
+// playlist for a collection. Clips without an uploaded video are left out.
+type PlaylistEvent struct {
+	CollectionID int64          `json:"collection_id"`
+	Title        string         `json:"title"`
+	Description  *string        `json:"description,omitempty"`
+	PlaylistID   *string        `json:"youtube_playlist_id,omitempty"`
+	Items        []PlaylistItem `json:"items"`
+}
+
+func newPlaylistEvent(col collectionsrepo.Collection) PlaylistEvent {
+	ev := PlaylistEvent{
+		CollectionID: col.ID,
+		Title:        col.Title,
+		Description:  col.Description,
+		PlaylistID:   col.YoutubePlaylistID,
+		Items:        make([]PlaylistItem, 0, len(col.Items)),
+	}
+	for _, it := range col.Items {
+		if it.YoutubeVideoID == nil {
+			continue
+		}
+		ev.Items = append(ev.Items, PlaylistItem{
+			ClipID:         it.ClipID,
+			Position:       it.Position,
+			YoutubeVideoID: *it.YoutubeVideoID,
+		})
+	}
+	return ev
+}
@@ -0,0 +1,341 @@
+package collections
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"highlightiq-server/internal/integrations/ffmpeg"
+	clipsrepo "highlightiq-server/internal/repos/clips"
+	collectionsrepo "highlightiq-server/internal/repos/collections"
+	outboxsvc "highlightiq-server/internal/services/outbox"
+	"highlightiq-server/internal/storage"
+)
+
+var (
+	ErrNotFound  = errors.New("collections: not found")
+	ErrNotReady  = errors.New("collections: not ready")
+	ErrDuplicate = errors.New("collections: clip already in collection")
+	ErrBadInput  = errors.New("collections: bad input")
+)
+
+// Service manages collections. Unlike recordings and clips, collections stay personal: they
+// belong to the user who created them, like tags, and are never shared with a workspace. A
+// collection may gather clips from any workspace its owner can read; clips from a workspace the
+// owner has left drop out of it.
+type Service struct {
+	repo       *collectionsrepo.Repo
+	clips      *clipsrepo.Repo
+	files      *storage.Cache
+	workDir    string
+	ffmpegPath string
+	events     *outboxsvc.Service
+}
+
+// New wires the collections service. Compilations are built from, and stored next to, the
+// exported clips in files; workDir holds ffmpeg scratch files. Playlists are published through
+// events' outbox.
+func New(repo *collectionsrepo.Repo, clips *clipsrepo.Repo, files *storage.Cache, workDir string, events *outboxsvc.Service) *Service {
+	return &Service{
+		repo:       repo,
+		clips:      clips,
+		files:      files,
+		workDir:    workDir,
+		ffmpegPath: ffmpeg.ResolvePath(),
+		events:     events,
+	}
+}
+
+type CreateInput struct {
+	Title       string
+	Description *string
+}
+
+type UpdateInput struct {
+	Title       *string
+	Description *string
+}
+
+func (s *Service) Create(ctx context.Context, userID int64, in CreateInput) (collectionsrepo.Collection, error) {
+	return s.repo.Create(ctx, collectionsrepo.CreateParams{
+		UserID:      userID,
+		Title:       in.Title,
+		Description: in.Description,
+	})
+}
+
+func (s *Service) List(ctx context.Context, userID int64) ([]collectionsrepo.Collection, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *Service) Get(ctx context.Context, userID int64, id int64) (collectionsrepo.Collection, error) {
+	col, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return collectionsrepo.Collection{}, err
+	}
+
+	items, err := s.repo.ListItems(ctx, col.ID)
+	if err != nil {
+		return collectionsrepo.Collection{}, err
+	}
+	col.Items = items
+	return col, nil
+}
+
+func (s *Service) Update(ctx context.Context, userID int64, id int64, in UpdateInput) (collectionsrepo.Collection, error) {
+	col, err := s.repo.UpdateByIDForUser(ctx, userID, id, collectionsrepo.UpdateParams{
+		Title:       in.Title,
+		Description: in.Description,
+	})
+	if err != nil {
+		if errors.Is(err, collectionsrepo.ErrNotFound) {
+			return collectionsrepo.Collection{}, ErrNotFound
+		}
+		return collectionsrepo.Collection{}, err
+	}
+	return col, nil
+}
+
+func (s *Service) Delete(ctx context.Context, userID int64, id int64) error {
+	col, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteByIDForUser(ctx, userID, id); err != nil {
+		if errors.Is(err, collectionsrepo.ErrNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	if col.ExportPath != nil && *col.ExportPath != "" {
+		_ = s.files.Store().Delete(ctx, *col.ExportPath)
+	}
+	return nil
+}
+
+func (s *Service) AddClip(ctx context.Context, userID int64, id int64, clipID int64) (collectionsrepo.Collection, error) {
+	if _, err := s.getOwned(ctx, userID, id); err != nil {
+		return collectionsrepo.Collection{}, err
+	}
+	if _, err := s.clips.GetByIDForUser(ctx, userID, clipID); err != nil {
+		if errors.Is(err, clipsrepo.ErrNotFound) {
+			return collectionsrepo.Collection{}, ErrNotFound
+		}
+		return collectionsrepo.Collection{}, err
+	}
+
+	if err := s.repo.AddClip(ctx, id, clipID); err != nil {
+		if errors.Is(err, collectionsrepo.ErrDuplicate) {
+			return collectionsrepo.Collection{}, ErrDuplicate
+		}
+		return collectionsrepo.Collection{}, err
+	}
+	return s.Get(ctx, userID, id)
+}
+
+func (s *Service) RemoveClip(ctx context.Context, userID int64, id int64, clipID int64) error {
+	if _, err := s.getOwned(ctx, userID, id); err != nil {
+		return err
+	}
+
+	err := s.repo.RemoveClip(ctx, id, clipID)
+	if errors.Is(err, collectionsrepo.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Reorder takes the complete, ordered list of clip ids in the collection.
+func (s *Service) Reorder(ctx context.Context, userID int64, id int64, clipIDs []int64) (collectionsrepo.Collection, error) {
+	if _, err := s.getOwned(ctx, userID, id); err != nil {
+		return collectionsrepo.Collection{}, err
+	}
+
+	items, err := s.repo.ListItems(ctx, id)
+	if err != nil {
+		return collectionsrepo.Collection{}, err
+	}
+	if len(items) != len(clipIDs) {
+		return collectionsrepo.Collection{}, ErrBadInput
+	}
+
+	seen := make(map[int64]struct{}, len(clipIDs))
+	for _, clipID := range clipIDs {
+		if _, ok := seen[clipID]; ok {
+			return collectionsrepo.Collection{}, ErrBadInput
+		}
+		seen[clipID] = struct{}{}
+	}
+
+	if err := s.repo.Reorder(ctx, id, clipIDs); err != nil {
+		if errors.Is(err, collectionsrepo.ErrNotFound) {
+			return collectionsrepo.Collection{}, ErrBadInput
+		}
+		return collectionsrepo.Collection{}, err
+	}
+	return s.Get(ctx, userID, id)
+}
+
+// Export concatenates the exported clips of a collection, in order, into a single compilation mp4.
+// Every clip must already be exported. Compilations do not count against the export quota (see
+// usage.Service.ChargeExport).
+func (s *Service) Export(ctx context.Context, userID int64, id int64) (collectionsrepo.Collection, error) {
+	if _, err := s.getOwned(ctx, userID, id); err != nil {
+		return collectionsrepo.Collection{}, err
+	}
+
+	items, err := s.repo.ListItems(ctx, id)
+	if err != nil {
+		return collectionsrepo.Collection{}, err
+	}
+	if len(items) == 0 {
+		return collectionsrepo.Collection{}, ErrNotReady
+	}
+
+	var list strings.Builder
+	for _, it := range items {
+		if it.ExportPath == nil || *it.ExportPath == "" {
+			return collectionsrepo.Collection{}, ErrNotReady
+		}
+		local, err := s.files.LocalPath(ctx, *it.ExportPath)
+		if err != nil {
+			return collectionsrepo.Collection{}, fmt.Errorf("clip %d file %q not available: %w", it.ClipID, *it.ExportPath, err)
+		}
+		abs, err := filepath.Abs(local)
+		if err != nil {
+			return collectionsrepo.Collection{}, err
+		}
+		// concat demuxer syntax: single quotes escaped as '\''
+		list.WriteString("file '" + strings.ReplaceAll(abs, "'", `'\''`) + "'\n")
+	}
+
+	if err := os.MkdirAll(s.workDir, 0755); err != nil {
+		return collectionsrepo.Collection{}, err
+	}
+
+	listFile, err := os.CreateTemp(s.workDir, fmt.Sprintf("collection_%d_*.txt", id))
+	if err != nil {
+		return collectionsrepo.Collection{}, err
+	}
+	defer os.Remove(listFile.Name())
+
+	if _, err := listFile.WriteString(list.String()); err != nil {
+		_ = listFile.Close()
+		return collectionsrepo.Collection{}, err
+	}
+	if err := listFile.Close(); err != nil {
+		return collectionsrepo.Collection{}, err
+	}
+
+	key := fmt.Sprintf("collection_%d.mp4", id)
+	outPath := filepath.Join(s.workDir, fmt.Sprintf(".export_collection_%d.mp4", id))
+	defer os.Remove(outPath)
+
+	// Re-encode so clips cut from different recordings can be joined safely.
+	cmd := exec.CommandContext(ctx, s.ffmpegPath,
+		"-hide_banner",
+		"-loglevel", "error",
+		"-y",
+		"-f", "concat",
+		"-safe", "0",
+		"-i", listFile.Name(),
+		"-c:v", "libx264",
+		"-preset", "veryfast",
+		"-crf", "23",
+		"-c:a", "aac",
+		"-b:a", "128k",
+		outPath,
+	)
+	cmd.Env = os.Environ()
+
+	out, runErr := cmd.CombinedOutput()
+	if runErr != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			msg = runErr.Error()
+		}
+		return collectionsrepo.Collection{}, fmt.Errorf("ffmpeg failed: %s", msg)
+	}
+
+	if err := storage.PutFile(ctx, s.files.Store(), key, outPath, "video/mp4"); err != nil {
+		return collectionsrepo.Collection{}, err
+	}
+
+	if _, err := s.repo.UpdateByIDForUser(ctx, userID, id, collectionsrepo.UpdateParams{
+		ExportPath: &key,
+	}); err != nil {
+		return collectionsrepo.Collection{}, err
+	}
+	return s.Get(ctx, userID, id)
+}
+
+func (s *Service) GetExport(ctx context.Context, userID int64, id int64) (string, string, error) {
+	col, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return "", "", err
+	}
+
+	if col.ExportPath == nil || *col.ExportPath == "" {
+		return "", "", ErrNotReady
+	}
+
+	return *col.ExportPath, filepath.Base(*col.ExportPath), nil
+}
+
+// Publish queues the collection for the playlist automation. Only clips that already have an
+// uploaded YouTube video are included; at least one is required.
+func (s *Service) Publish(ctx context.Context, userID int64, id int64) (collectionsrepo.Collection, error) {
+	col, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return collectionsrepo.Collection{}, err
+	}
+
+	published := 0
+	for _, it := range col.Items {
+		if it.YoutubeVideoID != nil {
+			published++
+		}
+	}
+	if published == 0 {
+		return collectionsrepo.Collection{}, ErrNotReady
+	}
+
+	if !s.events.Handles(outboxsvc.TopicPlaylistPublish) {
+		return collectionsrepo.Collection{}, errors.New("collections: playlist publishing is not configured")
+	}
+	if err := s.events.Enqueue(ctx, outboxsvc.TopicPlaylistPublish, newPlaylistEvent(col)); err != nil {
+		return collectionsrepo.Collection{}, err
+	}
+	return col, nil
+}
+
+// SetPlaylistInternal records the YouTube playlist created for a collection (called back by n8n).
+func (s *Service) SetPlaylistInternal(ctx context.Context, id int64, playlistID string) (collectionsrepo.Collection, error) {
+	col, err := s.repo.UpdateByID(ctx, id, collectionsrepo.UpdateParams{
+		YoutubePlaylistID: &playlistID,
+	})
+	if err != nil {
+		if errors.Is(err, collectionsrepo.ErrNotFound) {
+			return collectionsrepo.Collection{}, ErrNotFound
+		}
+		return collectionsrepo.Collection{}, err
+	}
+	return col, nil
+}
+
+func (s *Service) getOwned(ctx context.Context, userID int64, id int64) (collectionsrepo.Collection, error) {
+	col, err := s.repo.GetByIDForUser(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, collectionsrepo.ErrNotFound) {
+			return collectionsrepo.Collection{}, ErrNotFound
+		}
+		return collectionsrepo.Collection{}, err
+	}
+	return col, nil
+}
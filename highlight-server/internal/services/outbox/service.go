@@ -0,0 +1,246 @@
+// Package outbox delivers the events other services write to outbox_events: each topic has a
+// Sender (an n8n webhook, the native uploader), failed deliveries are retried with exponential
+// backoff and, once they run out of attempts or fail permanently, dead-lettered until replayed.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"unicode/utf8"
+
+	outboxrepo "highlightiq-server/internal/repos/outbox"
+)
+
+var ErrNotFound = errors.New("outbox: not found")
+var ErrNotDead = errors.New("outbox: event is not dead")
+
+// TopicPlaylistPublish carries collections.PlaylistEvent.
+const TopicPlaylistPublish = "collection.playlist"
+
+// TopicWebhookDelivery carries webhooks.Queued: one delivery to a user's webhook endpoint.
+const TopicWebhookDelivery = "webhook.delivery"
+
+// PublishTopic is the topic carrying publications.PublishEvent for a platform, such as
+// "youtube.publish".
+func PublishTopic(platform string) string {
+	return platform + ".publish"
+}
+
+// Sender delivers one topic's events. Errors that report Temporary() == false dead-letter the
+// event straight away; any other error is retried.
+type Sender interface {
+	Send(ctx context.Context, payload json.RawMessage) error
+}
+
+// Repo is the outbox repo the dispatcher works against.
+type Repo interface {
+	Enqueue(ctx context.Context, topic string, payload any) error
+	GetByID(ctx context.Context, id int64) (outboxrepo.Event, error)
+	ListDue(ctx context.Context, now time.Time, limit int) ([]outboxrepo.Event, error)
+	ListByStatus(ctx context.Context, status string, limit int) ([]outboxrepo.Event, error)
+	Lease(ctx context.Context, id int64, now time.Time, until time.Time) (bool, error)
+	MarkDelivered(ctx context.Context, id int64) error
+	Retry(ctx context.Context, id int64, next time.Time, lastError string) error
+	Bury(ctx context.Context, id int64, lastError string) error
+	Replay(ctx context.Context, id int64) (bool, error)
+}
+
+type Service struct {
+	repo    Repo
+	senders map[string]Sender
+
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	// lease is how long an event being delivered is hidden from other dispatchers.
+	lease time.Duration
+	// wake nudges Run to deliver right away instead of on its next tick.
+	wake chan struct{}
+}
+
+// New builds the dispatcher. Topics without a sender (nil entries included) are not handled:
+// their events are dead-lettered.
+func New(repo Repo, senders map[string]Sender) *Service {
+	s := &Service{
+		repo:        repo,
+		senders:     make(map[string]Sender, len(senders)),
+		maxAttempts: 8,
+		baseDelay:   30 * time.Second,
+		maxDelay:    time.Hour,
+		lease:       5 * time.Minute,
+		wake:        make(chan struct{}, 1),
+	}
+	for topic, sender := range senders {
+		if sender != nil {
+			s.senders[topic] = sender
+		}
+	}
+	return s
+}
+
+// Handle sets the sender of topic; a nil sender leaves the topic unhandled. It is for senders
+// that need services built on top of the dispatcher, and must be called before Run.
+func (s *Service) Handle(topic string, sender Sender) {
+	if sender == nil {
+		delete(s.senders, topic)
+		return
+	}
+	s.senders[topic] = sender
+}
+
+// Handles reports whether events on topic are delivered anywhere.
+func (s *Service) Handles(topic string) bool {
+	return s != nil && s.senders[topic] != nil
+}
+
+// Enqueue writes an event that does not accompany a change; events that do are written by the
+// repo making the change (see outboxrepo.Insert) followed by Wake.
+func (s *Service) Enqueue(ctx context.Context, topic string, payload any) error {
+	if err := s.repo.Enqueue(ctx, topic, payload); err != nil {
+		return err
+	}
+	s.Wake()
+	return nil
+}
+
+// Wake asks Run to look for due events now.
+func (s *Service) Wake() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run delivers due events every interval, and whenever it is woken, until ctx is cancelled.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		s.deliverDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		case <-s.wake:
+		}
+	}
+}
+
+func (s *Service) deliverDue(ctx context.Context) {
+	now := time.Now()
+	due, err := s.repo.ListDue(ctx, now, 50)
+	if err != nil {
+		log.Printf("outbox: listing due events failed: %v", err)
+		return
+	}
+
+	for _, e := range due {
+		leased, err := s.repo.Lease(ctx, e.ID, now, now.Add(s.lease))
+		if err != nil {
+			log.Printf("outbox: leasing event %d failed: %v", e.ID, err)
+			continue
+		}
+		if !leased {
+			continue
+		}
+		s.deliver(ctx, e)
+	}
+}
+
+func (s *Service) deliver(ctx context.Context, e outboxrepo.Event) {
+	sender := s.senders[e.Topic]
+	if sender == nil {
+		s.bury(ctx, e, fmt.Errorf("no sender for topic %q", e.Topic))
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	err := sender.Send(sendCtx, e.Payload)
+	cancel()
+
+	if err == nil {
+		if err := s.repo.MarkDelivered(ctx, e.ID); err != nil {
+			log.Printf("outbox: marking event %d delivered failed: %v", e.ID, err)
+		}
+		return
+	}
+
+	attempts := e.Attempts + 1
+	if permanent(err) || attempts >= s.maxAttempts {
+		s.bury(ctx, e, err)
+		return
+	}
+
+	log.Printf("outbox: delivering %s event %d failed (attempt %d): %v", e.Topic, e.ID, attempts, err)
+	if err := s.repo.Retry(ctx, e.ID, time.Now().Add(s.backoff(attempts)), errorMessage(err)); err != nil {
+		log.Printf("outbox: scheduling retry of event %d failed: %v", e.ID, err)
+	}
+}
+
+func (s *Service) bury(ctx context.Context, e outboxrepo.Event, cause error) {
+	log.Printf("outbox: %s event %d is dead: %v", e.Topic, e.ID, cause)
+	if err := s.repo.Bury(ctx, e.ID, errorMessage(cause)); err != nil {
+		log.Printf("outbox: dead-lettering event %d failed: %v", e.ID, err)
+	}
+}
+
+// backoff is the wait before the next attempt after the given number of failed ones.
+func (s *Service) backoff(attempts int) time.Duration {
+	d := s.baseDelay
+	for i := 1; i < attempts && d < s.maxDelay; i++ {
+		d *= 2
+	}
+	if d > s.maxDelay {
+		d = s.maxDelay
+	}
+	return d
+}
+
+// List returns events in a status (dead by default) for operators.
+func (s *Service) List(ctx context.Context, status string) ([]outboxrepo.Event, error) {
+	if status == "" {
+		status = outboxrepo.StatusDead
+	}
+	return s.repo.ListByStatus(ctx, status, 200)
+}
+
+// Replay gives a dead event a fresh set of attempts and delivers it right away.
+func (s *Service) Replay(ctx context.Context, id int64) (outboxrepo.Event, error) {
+	ok, err := s.repo.Replay(ctx, id)
+	if err != nil {
+		return outboxrepo.Event{}, err
+	}
+
+	e, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, outboxrepo.ErrNotFound) {
+			return outboxrepo.Event{}, ErrNotFound
+		}
+		return outboxrepo.Event{}, err
+	}
+	if !ok {
+		return outboxrepo.Event{}, ErrNotDead
+	}
+
+	s.Wake()
+	return e, nil
+}
+
+func permanent(err error) bool {
+	var t interface{ Temporary() bool }
+	return errors.As(err, &t) && !t.Temporary()
+}
+
+func errorMessage(err error) string {
+	msg := err.Error()
+	if utf8.RuneCountInString(msg) > 500 {
+		msg = string([]rune(msg)[:500])
+	}
+	return msg
+}
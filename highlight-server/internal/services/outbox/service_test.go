@@ -0,0 +1,184 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	outboxrepo "highlightiq-server/internal/repos/outbox"
+)
+
+// fakeRepo keeps events in memory. Lease is atomic, like the UPDATE it stands in for.
+type fakeRepo struct {
+	Repo
+
+	mu        sync.Mutex
+	events    []outboxrepo.Event
+	leased    map[int64]time.Time
+	delivered []int64
+	retries   map[int64]time.Time
+	buried    map[int64]string
+}
+
+func newFakeRepo(events ...outboxrepo.Event) *fakeRepo {
+	return &fakeRepo{
+		events:  events,
+		leased:  map[int64]time.Time{},
+		retries: map[int64]time.Time{},
+		buried:  map[int64]string{},
+	}
+}
+
+func (r *fakeRepo) ListDue(ctx context.Context, now time.Time, limit int) ([]outboxrepo.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]outboxrepo.Event(nil), r.events...), nil
+}
+
+func (r *fakeRepo) Lease(ctx context.Context, id int64, now time.Time, until time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.leased[id].After(now) {
+		return false, nil
+	}
+	r.leased[id] = until
+	return true, nil
+}
+
+func (r *fakeRepo) MarkDelivered(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delivered = append(r.delivered, id)
+	return nil
+}
+
+func (r *fakeRepo) Retry(ctx context.Context, id int64, next time.Time, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.retries[id] = next
+	return nil
+}
+
+func (r *fakeRepo) Bury(ctx context.Context, id int64, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buried[id] = lastError
+	return nil
+}
+
+type senderFunc func(ctx context.Context, payload json.RawMessage) error
+
+func (f senderFunc) Send(ctx context.Context, payload json.RawMessage) error {
+	return f(ctx, payload)
+}
+
+// refused is an error its sender reports as permanent.
+type refused struct{}
+
+func (refused) Error() string   { return "refused" }
+func (refused) Temporary() bool { return false }
+
+func TestBackoff(t *testing.T) {
+	s := New(newFakeRepo(), nil)
+
+	for _, tc := range []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{100, time.Hour},
+	} {
+		if got := s.backoff(tc.attempts); got != tc.want {
+			t.Errorf("backoff(%d) = %s, want %s", tc.attempts, got, tc.want)
+		}
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	temporary := errors.New("connection reset")
+
+	for _, tc := range []struct {
+		name     string
+		topic    string
+		attempts int
+		err      error
+		want     string // delivered, retry or buried
+	}{
+		{"success", "t", 0, nil, "delivered"},
+		{"temporary failure", "t", 0, temporary, "retry"},
+		{"last attempt", "t", 7, temporary, "buried"},
+		{"permanent failure", "t", 0, refused{}, "buried"},
+		{"wrapped permanent failure", "t", 0, errors.Join(errors.New("post"), refused{}), "buried"},
+		{"no sender", "unknown", 0, nil, "buried"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newFakeRepo()
+			s := New(repo, map[string]Sender{
+				"t": senderFunc(func(ctx context.Context, payload json.RawMessage) error { return tc.err }),
+			})
+
+			before := time.Now()
+			s.deliver(context.Background(), outboxrepo.Event{ID: 1, Topic: tc.topic, Attempts: tc.attempts})
+
+			got := ""
+			switch {
+			case len(repo.delivered) == 1:
+				got = "delivered"
+			case len(repo.retries) == 1:
+				got = "retry"
+			case len(repo.buried) == 1:
+				got = "buried"
+			}
+			if got != tc.want || len(repo.delivered)+len(repo.retries)+len(repo.buried) != 1 {
+				t.Fatalf("expected %s, got delivered=%v retries=%v buried=%v", tc.want, repo.delivered, repo.retries, repo.buried)
+			}
+
+			if tc.want == "retry" {
+				next := repo.retries[1]
+				if wait := next.Sub(before); wait < s.baseDelay || wait > s.baseDelay+time.Second {
+					t.Fatalf("expected a retry in %s, got %s", s.baseDelay, wait)
+				}
+			}
+		})
+	}
+}
+
+func TestDeliverDueLeasesEachEventOnce(t *testing.T) {
+	var events []outboxrepo.Event
+	for i := range 20 {
+		events = append(events, outboxrepo.Event{ID: int64(i + 1), Topic: "t"})
+	}
+	repo := newFakeRepo(events...)
+
+	var mu sync.Mutex
+	sends := 0
+	sender := senderFunc(func(ctx context.Context, payload json.RawMessage) error {
+		mu.Lock()
+		sends++
+		mu.Unlock()
+		return nil
+	})
+
+	// Several dispatchers, as with several API instances, share the table.
+	var wg sync.WaitGroup
+	for range 4 {
+		s := New(repo, map[string]Sender{"t": sender})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.deliverDue(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	if sends != len(events) || len(repo.delivered) != len(events) {
+		t.Fatalf("expected %d sends, got %d (%d marked delivered)", len(events), sends, len(repo.delivered))
+	}
+}
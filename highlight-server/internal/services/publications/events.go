@@ -0,0 +1,57 @@
+package publications
+
+import (
+	"encoding/json"
+	"time"
+
+	clipsrepo "highlightiq-server/internal/repos/clips"
+	pubrepo "highlightiq-server/internal/repos/publications"
+)
+
+// ClipURLs makes the signed download links the publisher fetches exports from.
+type ClipURLs interface {
+	PublicURL(id int64) string
+}
+
+// PublishEvent is the payload of outbox.PublishTopic(platform): post a clip for a due publish
+// request. The publisher reports back by updating the publication (for n8n flows, POST
+// /internal/publications with the publish_id).
+type PublishEvent struct {
+	PublishID   int64           `json:"publish_id"`
+	Platform    string          `json:"platform"`
+	ClipID      int64           `json:"clip_id"`
+	ClipURL     string          `json:"clip_url"`
+	Title       string          `json:"title"`
+	Description *string         `json:"description,omitempty"`
+	Ratio       string          `json:"ratio,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Privacy     string          `json:"privacy"`
+	ChannelID   *string         `json:"channel_id,omitempty"`
+	Metadata    json.RawMessage `json:"metadata,omitempty"`
+	PublishAt   *time.Time      `json:"publish_at,omitempty"`
+}
+
+func newPublishEvent(pub pubrepo.Publication, clip clipsrepo.Clip, clipURL string) PublishEvent {
+	ev := PublishEvent{
+		PublishID:   pub.ID,
+		Platform:    pub.Platform,
+		ClipID:      clip.ID,
+		ClipURL:     clipURL,
+		Title:       clip.Title,
+		Description: pub.Description,
+		Tags:        pub.Tags,
+		Privacy:     "private",
+		ChannelID:   pub.ChannelID,
+		PublishAt:   pub.PublishAt,
+	}
+	if pub.Title != nil {
+		ev.Title = *pub.Title
+	}
+	if pub.Privacy != nil {
+		ev.Privacy = *pub.Privacy
+	}
+	if pub.Metadata != nil {
+		ev.Metadata = json.RawMessage(*pub.Metadata)
+	}
+	return ev
+}
@@ -0,0 +1,195 @@
+package publications
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	clipsrepo "highlightiq-server/internal/repos/clips"
+	pubrepo "highlightiq-server/internal/repos/publications"
+)
+
+var ErrInvalidRange = errors.New("publications: invalid metrics range")
+
+const (
+	// DefaultMetricsDays is how far back a metrics range goes when no start is given.
+	DefaultMetricsDays = 30
+	// MaxMetricsDays bounds a metrics range, since every day in it is returned.
+	MaxMetricsDays = 366
+	// RawSnapshotRetention is how long every sync's snapshot is kept before only the last one of
+	// each day remains.
+	RawSnapshotRetention = 30 * 24 * time.Hour
+)
+
+// DailyMetrics is the total at the end of a UTC day, and how much it grew that day.
+type DailyMetrics struct {
+	Date          string `json:"date"` // YYYY-MM-DD
+	Views         int    `json:"views"`
+	Likes         int    `json:"likes"`
+	Comments      int    `json:"comments"`
+	Shares        int    `json:"shares"`
+	ViewsDelta    int    `json:"views_delta"`
+	LikesDelta    int    `json:"likes_delta"`
+	CommentsDelta int    `json:"comments_delta"`
+	SharesDelta   int    `json:"shares_delta"`
+}
+
+// PublicationMetrics is one publication's history: every snapshot in the range and the
+// day-by-day view of it.
+type PublicationMetrics struct {
+	PublicationID int64              `json:"publication_id"`
+	From          time.Time          `json:"from"`
+	To            time.Time          `json:"to"`
+	Snapshots     []pubrepo.Snapshot `json:"snapshots"`
+	Daily         []DailyMetrics     `json:"daily"`
+}
+
+// MetricsRollup sums the daily metrics of several publications.
+type MetricsRollup struct {
+	Publications int            `json:"publications"` // how many have metrics by the end of the range
+	From         time.Time      `json:"from"`
+	To           time.Time      `json:"to"`
+	Daily        []DailyMetrics `json:"daily"`
+}
+
+// MetricsRange resolves an optional [from, to) range: to defaults to now and from to
+// DefaultMetricsDays before it. Both are truncated to UTC days, and to includes its whole day.
+func MetricsRange(from *time.Time, to *time.Time, now time.Time) (time.Time, time.Time, error) {
+	end := startOfDay(now).AddDate(0, 0, 1)
+	if to != nil {
+		end = startOfDay(*to).AddDate(0, 0, 1)
+	}
+	start := end.AddDate(0, 0, -DefaultMetricsDays)
+	if from != nil {
+		start = startOfDay(*from)
+	}
+
+	if !start.Before(end) || end.Sub(start) > MaxMetricsDays*24*time.Hour {
+		return time.Time{}, time.Time{}, ErrInvalidRange
+	}
+	return start, end, nil
+}
+
+// Metrics returns a publication's snapshot history in [from, to).
+func (s *Service) Metrics(ctx context.Context, userID int64, id int64, from time.Time, to time.Time) (PublicationMetrics, error) {
+	if _, err := s.repo.GetByIDForUser(ctx, userID, id); err != nil {
+		if errors.Is(err, pubrepo.ErrNotFound) {
+			return PublicationMetrics{}, ErrNotFound
+		}
+		return PublicationMetrics{}, err
+	}
+
+	series, err := s.repo.SnapshotsByPublication(ctx, id, from, to)
+	if err != nil {
+		return PublicationMetrics{}, err
+	}
+
+	snapshots := series.Snapshots
+	if snapshots == nil {
+		snapshots = []pubrepo.Snapshot{}
+	}
+	daily, _ := dailyMetrics(series, from, to)
+	return PublicationMetrics{PublicationID: id, From: from, To: to, Snapshots: snapshots, Daily: daily}, nil
+}
+
+// ClipMetrics sums the metrics of a clip's publications per day.
+func (s *Service) ClipMetrics(ctx context.Context, userID int64, clipID int64, from time.Time, to time.Time) (MetricsRollup, error) {
+	if _, err := s.clips.GetByIDForUser(ctx, userID, clipID); err != nil {
+		if errors.Is(err, clipsrepo.ErrNotFound) {
+			return MetricsRollup{}, ErrNotFound
+		}
+		return MetricsRollup{}, err
+	}
+
+	series, err := s.repo.SnapshotsByClipForUser(ctx, userID, clipID, from, to)
+	if err != nil {
+		return MetricsRollup{}, err
+	}
+	return rollup(series, from, to), nil
+}
+
+// UserMetrics sums the metrics of every publication the user can see per day.
+func (s *Service) UserMetrics(ctx context.Context, userID int64, from time.Time, to time.Time) (MetricsRollup, error) {
+	series, err := s.repo.SnapshotsForUser(ctx, userID, from, to)
+	if err != nil {
+		return MetricsRollup{}, err
+	}
+	return rollup(series, from, to), nil
+}
+
+// RunMetricsRetention downsamples snapshots older than RawSnapshotRetention every interval until
+// ctx is cancelled.
+func (s *Service) RunMetricsRetention(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		n, err := s.repo.DownsampleSnapshots(ctx, startOfDay(time.Now().Add(-RawSnapshotRetention)))
+		if err != nil {
+			log.Printf("metrics retention: downsampling failed: %v", err)
+		} else if n > 0 {
+			log.Printf("metrics retention: downsampled %d snapshots", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func rollup(series pubrepo.SnapshotSeries, from time.Time, to time.Time) MetricsRollup {
+	daily, publications := dailyMetrics(series, from, to)
+	return MetricsRollup{Publications: publications, From: from, To: to, Daily: daily}
+}
+
+// dailyMetrics walks the series one UTC day at a time from from to to, summing each
+// publication's latest numbers at the end of every day. A publication without a snapshot on a
+// day keeps the numbers of its last one. It also returns how many publications were counted.
+func dailyMetrics(series pubrepo.SnapshotSeries, from time.Time, to time.Time) ([]DailyMetrics, int) {
+	latest := make(map[int64]pubrepo.Snapshot, len(series.Before))
+	for _, snap := range series.Before {
+		latest[snap.PublicationID] = snap
+	}
+
+	prev := total(latest)
+	out := make([]DailyMetrics, 0, int(to.Sub(from).Hours()/24)+1)
+	next := 0
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		end := day.AddDate(0, 0, 1)
+		for next < len(series.Snapshots) && series.Snapshots[next].CapturedAt.Before(end) {
+			snap := series.Snapshots[next]
+			latest[snap.PublicationID] = snap
+			next++
+		}
+
+		cur := total(latest)
+		cur.Date = day.Format("2006-01-02")
+		cur.ViewsDelta = cur.Views - prev.Views
+		cur.LikesDelta = cur.Likes - prev.Likes
+		cur.CommentsDelta = cur.Comments - prev.Comments
+		cur.SharesDelta = cur.Shares - prev.Shares
+		out = append(out, cur)
+		prev = cur
+	}
+
+	return out, len(latest)
+}
+
+func total(latest map[int64]pubrepo.Snapshot) DailyMetrics {
+	var d DailyMetrics
+	for _, snap := range latest {
+		d.Views += snap.Views
+		d.Likes += snap.Likes
+		d.Comments += snap.Comments
+		d.Shares += snap.Shares
+	}
+	return d
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,156 @@
+package publications
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	pubrepo "highlightiq-server/internal/repos/publications"
+)
+
+var metricsFrom = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+// at is a snapshot of publication id, captured hour hours into day (0 is metricsFrom).
+func at(id int64, day int, hour int, views int, likes int) pubrepo.Snapshot {
+	return pubrepo.Snapshot{
+		PublicationID: id,
+		Granularity:   pubrepo.GranularityRaw,
+		Views:         views,
+		Likes:         likes,
+		CapturedAt:    metricsFrom.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour),
+	}
+}
+
+func daily(s pubrepo.Snapshot) pubrepo.Snapshot {
+	s.Granularity = pubrepo.GranularityDaily
+	return s
+}
+
+// viewsAndDeltas flattens the views columns of days for comparison.
+func viewsAndDeltas(days []DailyMetrics) [][2]int {
+	out := make([][2]int, len(days))
+	for i, d := range days {
+		out[i] = [2]int{d.Views, d.ViewsDelta}
+	}
+	return out
+}
+
+func TestDailyMetrics(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		series       pubrepo.SnapshotSeries
+		days         int
+		want         [][2]int // views, views delta per day
+		publications int
+	}{
+		{
+			name:   "no snapshots",
+			days:   2,
+			want:   [][2]int{{0, 0}, {0, 0}},
+			series: pubrepo.SnapshotSeries{},
+		},
+		{
+			name: "a day without snapshots carries the total forward",
+			days: 4,
+			series: pubrepo.SnapshotSeries{Snapshots: []pubrepo.Snapshot{
+				at(1, 0, 10, 100, 1),
+				at(1, 2, 10, 150, 2),
+			}},
+			want:         [][2]int{{100, 100}, {100, 0}, {150, 50}, {150, 0}},
+			publications: 1,
+		},
+		{
+			name: "the baseline before the range",
+			days: 2,
+			series: pubrepo.SnapshotSeries{
+				Before:    []pubrepo.Snapshot{at(1, -3, 0, 80, 0)},
+				Snapshots: []pubrepo.Snapshot{at(1, 1, 5, 90, 0)},
+			},
+			want:         [][2]int{{80, 0}, {90, 10}},
+			publications: 1,
+		},
+		{
+			name: "the day's last snapshot wins",
+			days: 1,
+			series: pubrepo.SnapshotSeries{Snapshots: []pubrepo.Snapshot{
+				at(1, 0, 1, 10, 0),
+				at(1, 0, 12, 30, 0),
+				at(1, 0, 23, 35, 0),
+			}},
+			want:         [][2]int{{35, 35}},
+			publications: 1,
+		},
+		{
+			name: "publications are summed",
+			days: 3,
+			series: pubrepo.SnapshotSeries{
+				Before: []pubrepo.Snapshot{at(1, -1, 0, 100, 0)},
+				Snapshots: []pubrepo.Snapshot{
+					at(2, 0, 8, 20, 0),
+					at(1, 1, 8, 110, 0),
+					at(3, 2, 8, 5, 0),
+					at(2, 2, 9, 40, 0),
+				},
+			},
+			want:         [][2]int{{120, 20}, {130, 10}, {155, 25}},
+			publications: 3,
+		},
+		{
+			name: "downsampled snapshots count like raw ones",
+			days: 3,
+			series: pubrepo.SnapshotSeries{
+				Before: []pubrepo.Snapshot{daily(at(1, -1, 23, 50, 0))},
+				Snapshots: []pubrepo.Snapshot{
+					daily(at(1, 0, 23, 60, 0)),
+					daily(at(1, 1, 22, 75, 0)),
+					at(1, 2, 3, 80, 0),
+				},
+			},
+			want:         [][2]int{{60, 10}, {75, 15}, {80, 5}},
+			publications: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			to := metricsFrom.AddDate(0, 0, tc.days)
+			days, publications := dailyMetrics(tc.series, metricsFrom, to)
+
+			if got := viewsAndDeltas(days); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected views %v, got %v", tc.want, got)
+			}
+			if publications != tc.publications {
+				t.Fatalf("expected %d publications, got %d", tc.publications, publications)
+			}
+			for i, d := range days {
+				if want := metricsFrom.AddDate(0, 0, i).Format("2006-01-02"); d.Date != want {
+					t.Fatalf("expected day %d to be %s, got %s", i, want, d.Date)
+				}
+			}
+		})
+	}
+}
+
+func TestRollup(t *testing.T) {
+	to := metricsFrom.AddDate(0, 0, 2)
+	series := pubrepo.SnapshotSeries{
+		Before: []pubrepo.Snapshot{at(1, -1, 0, 10, 1)},
+		Snapshots: []pubrepo.Snapshot{
+			at(2, 0, 6, 5, 2),
+			at(1, 1, 6, 25, 4),
+		},
+	}
+
+	got := rollup(series, metricsFrom, to)
+
+	want := MetricsRollup{
+		Publications: 2,
+		From:         metricsFrom,
+		To:           to,
+		Daily: []DailyMetrics{
+			{Date: "2026-03-01", Views: 15, Likes: 3, ViewsDelta: 5, LikesDelta: 2},
+			{Date: "2026-03-02", Views: 30, Likes: 6, ViewsDelta: 15, LikesDelta: 3},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
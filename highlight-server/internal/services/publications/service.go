@@ -0,0 +1,555 @@
+package publications
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"highlightiq-server/internal/lifecycle"
+	clipsrepo "highlightiq-server/internal/repos/clips"
+	pubrepo "highlightiq-server/internal/repos/publications"
+	tagsrepo "highlightiq-server/internal/repos/tags"
+	outboxsvc "highlightiq-server/internal/services/outbox"
+	webhookssvc "highlightiq-server/internal/services/webhooks"
+	workspacessvc "highlightiq-server/internal/services/workspaces"
+)
+
+var ErrNotFound = errors.New("publications: not found")
+var ErrNotExported = errors.New("publications: clip has not been exported")
+var ErrNotConfigured = errors.New("publications: publishing is not configured")
+
+type Service struct {
+	clips  *clipsrepo.Repo
+	tags   *tagsrepo.Repo
+	repo   *pubrepo.Repo
+	access *workspacessvc.Service
+	events *outboxsvc.Service
+	hooks  *webhookssvc.Service
+	urls   ClipURLs
+
+	// wake nudges Run to dispatch right away instead of on its next tick.
+	wake chan struct{}
+}
+
+// New wires the service. Due publish requests are handed to the publisher through events'
+// outbox; when no publisher handles them, publish requests are refused. Every change to a
+// publication is announced to the workspace's webhooks through hooks. A clip's status follows its
+// publications: the repo publishes or unpublishes the clip in the same transaction as the
+// publication change that calls for it (see clipsrepo.SyncStatus).
+func New(clips *clipsrepo.Repo, tags *tagsrepo.Repo, repo *pubrepo.Repo, access *workspacessvc.Service, events *outboxsvc.Service, hooks *webhookssvc.Service, urls ClipURLs) *Service {
+	return &Service{
+		clips:  clips,
+		tags:   tags,
+		repo:   repo,
+		access: access,
+		events: events,
+		hooks:  hooks,
+		urls:   urls,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Publish queues a request to publish an exported clip to a platform (YouTube unless in.Platform
+// says otherwise), now or at in.PublishAt. The scheduler (Run) hands it to the platform's
+// publisher once it is due.
+func (s *Service) Publish(ctx context.Context, userID int64, clipID int64, in PublishInput) (pubrepo.Publication, error) {
+	clip, err := s.clips.GetByIDForUser(ctx, userID, clipID)
+	if err != nil {
+		if errors.Is(err, clipsrepo.ErrNotFound) {
+			return pubrepo.Publication{}, ErrNotFound
+		}
+		return pubrepo.Publication{}, err
+	}
+	if err := s.access.RequireEditor(ctx, userID, clip.WorkspaceID, ErrNotFound); err != nil {
+		return pubrepo.Publication{}, err
+	}
+	// A published clip is still exported, and may go out to more platforms.
+	if (clip.Status != "ready" && clip.Status != "published") || clip.ExportPath == nil || *clip.ExportPath == "" {
+		return pubrepo.Publication{}, ErrNotExported
+	}
+	platform := in.Platform
+	if platform == "" {
+		platform = pubrepo.PlatformYouTube
+	}
+	if !s.events.Handles(outboxsvc.PublishTopic(platform)) {
+		return pubrepo.Publication{}, ErrNotConfigured
+	}
+
+	p := pubrepo.RequestParams{
+		ClipID:      clip.ID,
+		WorkspaceID: clip.WorkspaceID,
+		Platform:    platform,
+		RequestedBy: userID,
+		Metadata:    in.Metadata,
+		Title:       clip.Title,
+		Tags:        in.Tags,
+		Privacy:     "private",
+		PublishAt:   in.PublishAt,
+	}
+	if clip.Caption != nil {
+		p.Description = *clip.Caption
+	}
+	if in.Title != nil {
+		p.Title = *in.Title
+	}
+	if in.Description != nil {
+		p.Description = *in.Description
+	}
+	if in.Tags == nil {
+		names, err := s.tags.NamesForClips(ctx, []int64{clip.ID})
+		if err != nil {
+			return pubrepo.Publication{}, err
+		}
+		p.Tags = names[clip.ID]
+	}
+	if in.Privacy != nil {
+		p.Privacy = *in.Privacy
+	}
+	if in.ChannelID != nil {
+		p.ChannelID = *in.ChannelID
+	}
+
+	created, err := s.repo.CreateRequest(ctx, p)
+	if err != nil {
+		return pubrepo.Publication{}, err
+	}
+	s.announce(ctx, webhookssvc.EventPublishUpdated, created)
+
+	if created.PublishAt == nil || !created.PublishAt.After(time.Now()) {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+	return created, nil
+}
+
+// Run dispatches due publish requests every interval, and whenever a request that is due
+// immediately comes in, until ctx is cancelled.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		s.dispatchDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		case <-s.wake:
+		}
+	}
+}
+
+func (s *Service) dispatchDue(ctx context.Context) {
+	due, err := s.repo.ListDue(ctx, time.Now(), 50)
+	if err != nil {
+		log.Printf("publish scheduler: listing due publishes failed: %v", err)
+		return
+	}
+
+	for _, pub := range due {
+		if err := s.dispatch(ctx, pub); err != nil {
+			log.Printf("publish scheduler: dispatching publish %d failed: %v", pub.ID, err)
+		}
+	}
+}
+
+// dispatch claims a due request and writes its outbox event in one go. Requests that can no
+// longer be published are claimed and failed instead.
+func (s *Service) dispatch(ctx context.Context, pub pubrepo.Publication) error {
+	clip, err := s.clips.GetByID(ctx, pub.ClipID)
+	if err != nil && !errors.Is(err, clipsrepo.ErrNotFound) {
+		return err
+	}
+
+	var cause error
+	switch {
+	case err != nil:
+		cause = errors.New("the clip was deleted")
+	case clip.ExportPath == nil || *clip.ExportPath == "":
+		cause = ErrNotExported
+	case !s.events.Handles(outboxsvc.PublishTopic(pub.Platform)):
+		cause = ErrNotConfigured
+	}
+	if cause != nil {
+		claimed, err := s.repo.Claim(ctx, pub.ID)
+		if err != nil || !claimed {
+			return err
+		}
+		s.fail(ctx, pub.ID, cause)
+		return nil
+	}
+
+	claimed, err := s.repo.ClaimWithEvent(ctx, pub.ID, outboxsvc.PublishTopic(pub.Platform), newPublishEvent(pub, clip, s.urls.PublicURL(clip.ID)))
+	if err != nil {
+		return err
+	}
+	if claimed {
+		s.events.Wake()
+	}
+	return nil
+}
+
+func (s *Service) fail(ctx context.Context, id int64, cause error) {
+	status := "failed"
+	message := cause.Error()
+	switch {
+	case errors.Is(cause, ErrNotConfigured):
+		message = "publishing is not configured"
+	case errors.Is(cause, ErrNotExported):
+		message = "the clip has no export"
+	}
+	if r := []rune(message); len(r) > 500 {
+		message = string(r[:500])
+	}
+
+	failed, err := s.repo.UpdateByID(ctx, id, pubrepo.UpdateParams{
+		Status:       &status,
+		ErrorMessage: &message,
+	})
+	if err != nil {
+		log.Printf("publish scheduler: recording failure of publish %d failed: %v", id, err)
+		return
+	}
+	s.announce(ctx, webhookssvc.EventPublishUpdated, failed)
+}
+
+func (s *Service) Create(ctx context.Context, userID int64, clipID int64, in CreateInput) (pubrepo.Publication, error) {
+	clip, err := s.clips.GetByIDForUser(ctx, userID, clipID)
+	if err != nil {
+		if errors.Is(err, clipsrepo.ErrNotFound) {
+			return pubrepo.Publication{}, ErrNotFound
+		}
+		return pubrepo.Publication{}, err
+	}
+	if err := s.access.RequireEditor(ctx, userID, clip.WorkspaceID, ErrNotFound); err != nil {
+		return pubrepo.Publication{}, err
+	}
+
+	created, err := s.repo.Create(ctx, pubrepo.CreateParams{
+		ClipID:       clipID,
+		WorkspaceID:  clip.WorkspaceID,
+		Platform:     in.Platform,
+		ExternalID:   in.ExternalID,
+		URL:          in.URL,
+		Status:       in.Status,
+		Metadata:     in.Metadata,
+		PublishedAt:  in.PublishedAt,
+		LastSyncedAt: in.LastSyncedAt,
+		Views:        in.Views,
+		Likes:        in.Likes,
+		Comments:     in.Comments,
+		Shares:       in.Shares,
+		Analytics:    in.Analytics,
+		ActorID:      &userID,
+	})
+	if err != nil {
+		return pubrepo.Publication{}, err
+	}
+
+	s.announce(ctx, webhookssvc.EventPublishUpdated, created)
+	return created, nil
+}
+
+func (s *Service) CreateInternal(ctx context.Context, clipID int64, in CreateInput) (pubrepo.Publication, error) {
+	clip, err := s.clips.GetByID(ctx, clipID)
+	if err != nil {
+		if errors.Is(err, clipsrepo.ErrNotFound) {
+			return pubrepo.Publication{}, ErrNotFound
+		}
+		return pubrepo.Publication{}, err
+	}
+
+	if in.PublishID != nil {
+		return s.completeRequest(ctx, clipID, *in.PublishID, in)
+	}
+
+	created, err := s.repo.Create(ctx, pubrepo.CreateParams{
+		ClipID:       clipID,
+		WorkspaceID:  clip.WorkspaceID,
+		Platform:     in.Platform,
+		ExternalID:   in.ExternalID,
+		URL:          in.URL,
+		Status:       in.Status,
+		Metadata:     in.Metadata,
+		PublishedAt:  in.PublishedAt,
+		LastSyncedAt: in.LastSyncedAt,
+		Views:        in.Views,
+		Likes:        in.Likes,
+		Comments:     in.Comments,
+		Shares:       in.Shares,
+		Analytics:    in.Analytics,
+	})
+	if err != nil {
+		return pubrepo.Publication{}, err
+	}
+
+	s.announce(ctx, webhookssvc.EventPublishUpdated, created)
+	return created, nil
+}
+
+// completeRequest records the outcome n8n reports for a publish request it was handed.
+func (s *Service) completeRequest(ctx context.Context, clipID int64, publishID int64, in CreateInput) (pubrepo.Publication, error) {
+	current, err := s.repo.GetByID(ctx, publishID)
+	if err != nil {
+		if errors.Is(err, pubrepo.ErrNotFound) {
+			return pubrepo.Publication{}, ErrNotFound
+		}
+		return pubrepo.Publication{}, err
+	}
+	if current.ClipID != clipID || current.Platform != in.Platform {
+		return pubrepo.Publication{}, ErrNotFound
+	}
+
+	status := in.Status
+	if status == "" {
+		status = "uploaded"
+	}
+	if err := lifecycle.Publication.Check(current.Status, status); err != nil {
+		return pubrepo.Publication{}, err
+	}
+	cleared := ""
+
+	updated, err := s.repo.UpdateByID(ctx, publishID, pubrepo.UpdateParams{
+		ExternalID:   &in.ExternalID,
+		URL:          &in.URL,
+		Status:       &status,
+		PublishedAt:  in.PublishedAt,
+		LastSyncedAt: in.LastSyncedAt,
+		Views:        &in.Views,
+		Likes:        &in.Likes,
+		Comments:     &in.Comments,
+		Shares:       &in.Shares,
+		Analytics:    in.Analytics,
+		ErrorMessage: &cleared,
+	})
+	if err != nil {
+		return pubrepo.Publication{}, err
+	}
+
+	s.announce(ctx, webhookssvc.EventPublishUpdated, updated)
+	return updated, nil
+}
+
+func (s *Service) ListByClip(ctx context.Context, userID int64, clipID int64) ([]pubrepo.Publication, error) {
+	if _, err := s.clips.GetByIDForUser(ctx, userID, clipID); err != nil {
+		if errors.Is(err, clipsrepo.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return s.repo.ListByClipIDForUser(ctx, userID, clipID)
+}
+
+// ListForSync returns the posts a metrics sync run should look at, and the cursor of the next
+// page, which is 0 on the last one.
+func (s *Service) ListForSync(ctx context.Context, q SyncQuery) ([]SyncItem, int64, error) {
+	now := time.Now()
+	newSince := now.Add(-NewPublicationAge)
+
+	f := pubrepo.SyncFilter{
+		Platform:         q.Platform,
+		Statuses:         q.Statuses,
+		LastSyncedBefore: q.LastSyncedBefore,
+		AfterID:          q.Cursor,
+		Limit:            q.Limit,
+	}
+	if q.DueOnly {
+		f.Due = &pubrepo.SyncDue{
+			NewSince:        newSince,
+			NewSyncedBefore: now.Add(-NewSyncInterval),
+			OldSyncedBefore: now.Add(-OldSyncInterval),
+		}
+	}
+
+	pubs, err := s.repo.ListForSync(ctx, f)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	out := make([]SyncItem, 0, len(pubs))
+	for _, pub := range pubs {
+		item := SyncItem{Publication: pub, Priority: PriorityLow, Interval: OldSyncInterval}
+		if !livedAt(pub).Before(newSince) {
+			item.Priority = PriorityHigh
+			item.Interval = NewSyncInterval
+		}
+		item.Due = pub.LastSyncedAt == nil || !pub.LastSyncedAt.After(now.Add(-item.Interval))
+		out = append(out, item)
+	}
+
+	var next int64
+	if q.Limit > 0 && len(pubs) == q.Limit {
+		next = pubs[len(pubs)-1].ID
+	}
+	return out, next, nil
+}
+
+// livedAt is when a post went live, or when we learned about it if the platform did not say.
+func livedAt(pub pubrepo.Publication) time.Time {
+	if pub.PublishedAt != nil {
+		return *pub.PublishedAt
+	}
+	return pub.CreatedAt
+}
+
+func (s *Service) GetByExternalID(ctx context.Context, platform string, externalID string) (pubrepo.Publication, error) {
+	pub, err := s.repo.GetByExternalID(ctx, platform, externalID)
+	if err != nil {
+		if errors.Is(err, pubrepo.ErrNotFound) {
+			return pubrepo.Publication{}, ErrNotFound
+		}
+		return pubrepo.Publication{}, err
+	}
+
+	return pub, nil
+}
+
+// MarkDeleted records that the post was taken down on the platform.
+func (s *Service) MarkDeleted(ctx context.Context, platform string, externalID string, lastSyncedAt *time.Time) (pubrepo.Publication, error) {
+	status := "deleted"
+	updated, err := s.repo.UpdateByExternalID(ctx, platform, externalID, pubrepo.UpdateParams{
+		Status:       &status,
+		LastSyncedAt: lastSyncedAt,
+	})
+	if err != nil {
+		if errors.Is(err, pubrepo.ErrNotFound) {
+			return pubrepo.Publication{}, ErrNotFound
+		}
+		return pubrepo.Publication{}, err
+	}
+
+	s.announce(ctx, webhookssvc.EventPublishDeleted, updated)
+	return updated, nil
+}
+
+func (s *Service) Update(ctx context.Context, userID int64, id int64, in UpdateInput) (pubrepo.Publication, error) {
+	current, err := s.repo.GetByIDForUser(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, pubrepo.ErrNotFound) {
+			return pubrepo.Publication{}, ErrNotFound
+		}
+		return pubrepo.Publication{}, err
+	}
+	clip, err := s.clips.GetByID(ctx, current.ClipID)
+	if err != nil {
+		if errors.Is(err, clipsrepo.ErrNotFound) {
+			return pubrepo.Publication{}, ErrNotFound
+		}
+		return pubrepo.Publication{}, err
+	}
+	if err := s.access.RequireEditor(ctx, userID, clip.WorkspaceID, ErrNotFound); err != nil {
+		return pubrepo.Publication{}, err
+	}
+	if in.Status != nil {
+		if err := lifecycle.Publication.Check(current.Status, *in.Status); err != nil {
+			return pubrepo.Publication{}, err
+		}
+	}
+
+	updated, err := s.repo.UpdateByIDForUser(ctx, userID, id, pubrepo.UpdateParams{
+		URL:          in.URL,
+		Status:       in.Status,
+		PublishedAt:  in.PublishedAt,
+		LastSyncedAt: in.LastSyncedAt,
+		Views:        in.Views,
+		Likes:        in.Likes,
+		Comments:     in.Comments,
+		Shares:       in.Shares,
+		Analytics:    in.Analytics,
+		ActorID:      &userID,
+	})
+	if err != nil {
+		if errors.Is(err, pubrepo.ErrNotFound) {
+			return pubrepo.Publication{}, ErrNotFound
+		}
+		return pubrepo.Publication{}, err
+	}
+
+	s.announce(ctx, webhookssvc.EventPublishUpdated, updated)
+	return updated, nil
+}
+
+func (s *Service) UpdateByExternalID(ctx context.Context, platform string, externalID string, in UpdateInput) (pubrepo.Publication, error) {
+	updated, err := s.repo.UpdateByExternalID(ctx, platform, externalID, pubrepo.UpdateParams{
+		URL:          in.URL,
+		Status:       in.Status,
+		PublishedAt:  in.PublishedAt,
+		LastSyncedAt: in.LastSyncedAt,
+		Views:        in.Views,
+		Likes:        in.Likes,
+		Comments:     in.Comments,
+		Shares:       in.Shares,
+		Analytics:    in.Analytics,
+	})
+	if err != nil {
+		if errors.Is(err, pubrepo.ErrNotFound) {
+			return pubrepo.Publication{}, ErrNotFound
+		}
+		return pubrepo.Publication{}, err
+	}
+
+	s.announce(ctx, webhookssvc.EventPublishUpdated, updated)
+	return updated, nil
+}
+
+// ApplyBatch applies a sync batch in one transaction: either every item is recorded or, on a
+// database error, none is. Items naming no publication are reported as BatchNotFound, and items
+// whose status change lifecycle.Publication does not allow as BatchConflict.
+func (s *Service) ApplyBatch(ctx context.Context, items []BatchItem) ([]BatchResult, error) {
+	deleted := "deleted"
+	updates := make([]pubrepo.ExternalUpdate, len(items))
+	for i, it := range items {
+		p := pubrepo.UpdateParams{
+			URL:          it.Update.URL,
+			Status:       it.Update.Status,
+			PublishedAt:  it.Update.PublishedAt,
+			LastSyncedAt: it.Update.LastSyncedAt,
+			Views:        it.Update.Views,
+			Likes:        it.Update.Likes,
+			Comments:     it.Update.Comments,
+			Shares:       it.Update.Shares,
+			Analytics:    it.Update.Analytics,
+		}
+		if it.Deleted {
+			p.Status = &deleted
+		}
+		updates[i] = pubrepo.ExternalUpdate{Platform: it.Platform, ExternalID: it.ExternalID, Params: p}
+	}
+
+	results, err := s.repo.UpdateBatchByExternalID(ctx, updates)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]BatchResult, len(items))
+	for i, res := range results {
+		pub := res.Publication
+		switch {
+		case pub.ID == 0:
+			out[i] = BatchResult{Result: BatchNotFound}
+			continue
+		case res.Illegal:
+			out[i] = BatchResult{Result: BatchConflict, Publication: pub}
+			continue
+		}
+		out[i] = BatchResult{Result: BatchUpdated, Publication: pub}
+
+		event := webhookssvc.EventPublishUpdated
+		if items[i].Deleted {
+			event = webhookssvc.EventPublishDeleted
+		}
+		s.announce(ctx, event, pub)
+	}
+
+	return out, nil
+}
+
+// announce tells the publication's workspace webhooks about a change to it.
+func (s *Service) announce(ctx context.Context, eventType string, pub pubrepo.Publication) {
+	s.hooks.Emit(ctx, pub.WorkspaceID, eventType, webhookssvc.NewPublicationData(pub))
+}
@@ -0,0 +1,103 @@
+package publications
+
+import (
+	"time"
+
+	pubrepo "highlightiq-server/internal/repos/publications"
+)
+
+type CreateInput struct {
+	PublishID    *int64 // internal callbacks only: the queued publish request to complete
+	Platform     string
+	ExternalID   string
+	URL          string
+	Status       string
+	Metadata     *string
+	PublishedAt  *time.Time
+	LastSyncedAt *time.Time
+	Views        int
+	Likes        int
+	Comments     int
+	Shares       int
+	Analytics    *string
+}
+
+type UpdateInput struct {
+	URL          *string
+	Status       *string
+	PublishedAt  *time.Time
+	LastSyncedAt *time.Time
+	Views        *int
+	Likes        *int
+	Comments     *int
+	Shares       *int
+	Analytics    *string
+}
+
+// PublishInput overrides the post's metadata, which otherwise comes from the clip. An empty
+// Platform means YouTube; a nil PublishAt publishes as soon as possible.
+type PublishInput struct {
+	Platform    string
+	Title       *string
+	Description *string
+	Tags        []string
+	Privacy     *string
+	ChannelID   *string
+	Metadata    *string
+	PublishAt   *time.Time
+}
+
+// BatchItem is one entry of a metrics sync batch: new numbers for a post and, when Deleted, the
+// news that it was taken down.
+type BatchItem struct {
+	Platform   string
+	ExternalID string
+	Update     UpdateInput
+	Deleted    bool
+}
+
+// Outcomes of a batch item. Items that fail validation are BatchInvalid and never reach the
+// service.
+const (
+	BatchUpdated  = "updated"
+	BatchNotFound = "not_found"
+	BatchInvalid  = "invalid"
+	BatchConflict = "conflict" // the status change is not allowed; nothing was applied
+)
+
+type BatchResult struct {
+	Result      string
+	Publication pubrepo.Publication // set when Result is BatchUpdated or BatchConflict
+}
+
+// Metrics sync schedule: posts are synced hourly while new and daily after that.
+const (
+	NewPublicationAge = 7 * 24 * time.Hour
+	NewSyncInterval   = time.Hour
+	OldSyncInterval   = 24 * time.Hour
+)
+
+// Sync priorities.
+const (
+	PriorityHigh = "high" // new posts, synced every NewSyncInterval
+	PriorityLow  = "low"  // older posts, synced every OldSyncInterval
+)
+
+// SyncQuery filters the posts a metrics sync run lists. Cursor is the value returned with the
+// previous page; a Limit of 0 lists everything.
+type SyncQuery struct {
+	Platform         string
+	Statuses         []string
+	LastSyncedBefore *time.Time
+	DueOnly          bool
+	Cursor           int64
+	Limit            int
+}
+
+// SyncItem is a post along with when it should be synced.
+type SyncItem struct {
+	pubrepo.Publication
+	Priority string
+	Interval time.Duration
+	Due      bool
+}
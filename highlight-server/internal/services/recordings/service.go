@@ -1,8 +1,10 @@
 package recordings
 
 import (
+	"bytes"
 	"context"
-	"os"
+	"log"
+	"mime"
 	"path/filepath"
 	"strings"
 	"time"
@@ -10,86 +12,225 @@ import (
 	"github.com/google/uuid"
 
 	recRepo "highlightiq-server/internal/repos/recordings"
+	tagsrepo "highlightiq-server/internal/repos/tags"
+	usagerepo "highlightiq-server/internal/repos/usage"
+	usagesvc "highlightiq-server/internal/services/usage"
+	webhookssvc "highlightiq-server/internal/services/webhooks"
+	workspacessvc "highlightiq-server/internal/services/workspaces"
+	"highlightiq-server/internal/storage"
 )
 
 type Service struct {
 	repo     *recRepo.Repo
-	baseDir  string
+	tags     *tagsrepo.Repo
+	store    storage.BlobStore
+	usage    *usagesvc.Service
+	access   *workspacessvc.Service
+	hooks    *webhookssvc.Service
 	maxBytes int64
 }
 
-func New(repo *recRepo.Repo, baseDir string) *Service {
-	if baseDir == "" {
-		baseDir = "./storage/recordings"
-	}
+func New(repo *recRepo.Repo, tags *tagsrepo.Repo, store storage.BlobStore, usage *usagesvc.Service, access *workspacessvc.Service, hooks *webhookssvc.Service) *Service {
 	return &Service{
 		repo:     repo,
-		baseDir:  baseDir,
+		tags:     tags,
+		store:    store,
+		usage:    usage,
+		access:   access,
+		hooks:    hooks,
 		maxBytes: 1_000_000_000, // 1GB
 	}
 }
 
-func (s *Service) Create(ctx context.Context, userID int64, title string, originalName string, fileBytes []byte) (recRepo.Recording, error) {
+// Create uploads a recording into workspaceID, or the user's personal workspace when it is 0.
+// Storage quota is charged to the uploading user.
+func (s *Service) Create(ctx context.Context, userID int64, workspaceID int64, title string, originalName string, fileBytes []byte) (recRepo.Recording, error) {
+	workspaceID, err := s.access.TargetForCreate(ctx, userID, workspaceID)
+	if err != nil {
+		return recRepo.Recording{}, err
+	}
+
 	recUUID := uuid.NewString()
 
 	if title == "" {
 		title = filenameNoExt(originalName)
 	}
 
-	if err := os.MkdirAll(s.baseDir, 0o755); err != nil {
-		return recRepo.Recording{}, err
+	size := int64(len(fileBytes))
+
+	// Reserve quota up front; every failure below gives it back.
+	if s.usage != nil {
+		if err := s.usage.ReserveRecording(ctx, userID, size); err != nil {
+			return recRepo.Recording{}, err
+		}
+	}
+	release := func() {
+		if s.usage != nil {
+			_ = s.usage.ReleaseRecording(ctx, userID, size)
+		}
 	}
 
-	fileName := recUUID + "_" + sanitizeFileName(originalName)
-	fullPath := filepath.Join(s.baseDir, fileName)
+	// storage_path holds the object key; the store decides where the bytes live.
+	key := recUUID + "_" + sanitizeFileName(originalName)
+	contentType := mime.TypeByExtension(filepath.Ext(key))
 
-	if err := os.WriteFile(fullPath, fileBytes, 0o644); err != nil {
+	if err := s.store.Put(ctx, key, bytes.NewReader(fileBytes), size, contentType); err != nil {
+		release()
 		return recRepo.Recording{}, err
 	}
 
 	rec, err := s.repo.Create(ctx, recRepo.CreateParams{
 		UUID:            recUUID,
 		UserID:          userID,
+		WorkspaceID:     workspaceID,
 		Title:           title,
 		OriginalName:    originalName,
-		StoragePath:     fullPath,
+		StoragePath:     key,
+		SizeBytes:       size,
 		DurationSeconds: 0,
 		Status:          "uploaded",
 	})
 	if err != nil {
 		// If DB insert fails, clean up the saved file
-		_ = os.Remove(fullPath)
+		_ = s.store.Delete(ctx, key)
+		release()
 		return recRepo.Recording{}, err
 	}
 
+	s.hooks.Emit(ctx, rec.WorkspaceID, webhookssvc.EventRecordingUploaded, webhookssvc.NewRecordingData(rec))
 	return rec, nil
 }
 
-func (s *Service) List(ctx context.Context, userID int64) ([]recRepo.Recording, error) {
-	return s.repo.ListByUser(ctx, userID)
+func (s *Service) List(ctx context.Context, userID int64, workspaceID int64, tag string) ([]recRepo.Recording, error) {
+	var tagPtr *string
+	if tag != "" {
+		tagPtr = &tag
+	}
+
+	recs, err := s.repo.ListByUser(ctx, userID, workspaceID, tagPtr)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachTags(ctx, recs); err != nil {
+		return nil, err
+	}
+	return recs, nil
 }
 
 func (s *Service) Get(ctx context.Context, userID int64, recUUID string) (recRepo.Recording, error) {
-	return s.repo.GetByUUIDForUser(ctx, userID, recUUID, 0)
+	rec, err := s.repo.GetByUUIDForUser(ctx, userID, recUUID, 0)
+	if err != nil {
+		return recRepo.Recording{}, err
+	}
+
+	recs := []recRepo.Recording{rec}
+	if err := s.attachTags(ctx, recs); err != nil {
+		return recRepo.Recording{}, err
+	}
+	return recs[0], nil
+}
+
+// attachTags loads tag names for the given recordings in a single query.
+func (s *Service) attachTags(ctx context.Context, recs []recRepo.Recording) error {
+	if s.tags == nil || len(recs) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(recs))
+	for _, rec := range recs {
+		ids = append(ids, rec.ID)
+	}
+
+	names, err := s.tags.NamesForRecordings(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range recs {
+		recs[i].Tags = names[recs[i].ID]
+		if recs[i].Tags == nil {
+			recs[i].Tags = []string{}
+		}
+	}
+	return nil
 }
 
 func (s *Service) UpdateTitle(ctx context.Context, userID int64, recUUID string, title string) error {
 	if title == "" {
 		return recRepo.ErrNotFound // will be mapped to 422 by handler; keep it simple
 	}
+	if err := s.requireEditor(ctx, userID, recUUID); err != nil {
+		return err
+	}
 	return s.repo.UpdateTitleByUUIDForUser(ctx, userID, recUUID, title)
 }
 
+// Delete moves the recording to the trash. The file stays in storage until the trash is purged,
+// but it and the exports of the clips cut from it stop counting against their owners' quotas.
 func (s *Service) Delete(ctx context.Context, userID int64, recUUID string) error {
-	path, err := s.repo.DeleteByUUIDForUser(ctx, userID, recUUID)
+	if err := s.requireEditor(ctx, userID, recUUID); err != nil {
+		return err
+	}
+	held, err := s.holding(ctx, userID, recUUID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SoftDeleteByUUIDForUser(ctx, userID, recUUID); err != nil {
+		return err
+	}
+	if s.usage != nil {
+		if err := s.usage.Release(ctx, held...); err != nil {
+			log.Printf("recordings: releasing usage of %s failed: %v", recUUID, err)
+		}
+	}
+	return nil
+}
+
+// Restore takes a recording out of the trash. It fails with usage.ErrQuotaExceeded when the
+// uploader, or the owner of one of its clips, no longer has room for it.
+func (s *Service) Restore(ctx context.Context, userID int64, recUUID string) error {
+	if err := s.requireEditor(ctx, userID, recUUID); err != nil {
+		return err
+	}
+	held, err := s.holding(ctx, userID, recUUID)
 	if err != nil {
 		return err
 	}
-	// Best-effort file delete (if it fails, DB row is already gone)
-	_ = os.Remove(path)
+	if s.usage != nil {
+		if err := s.usage.Charge(ctx, held...); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.RestoreByUUIDForUser(ctx, userID, recUUID); err != nil {
+		if s.usage != nil {
+			_ = s.usage.Release(ctx, held...)
+		}
+		return err
+	}
 	return nil
 }
 
+// holding is what the recording counts against its owners' quotas outside the trash.
+func (s *Service) holding(ctx context.Context, userID int64, recUUID string) ([]usagerepo.Holding, error) {
+	if s.usage == nil {
+		return nil, nil
+	}
+	id, err := s.repo.GetIDByUUIDForUser(ctx, userID, recUUID)
+	if err != nil {
+		return nil, err
+	}
+	return s.usage.RecordingHolding(ctx, id)
+}
+
+// requireEditor turns a viewer's attempt to change a recording into ErrForbidden instead of a
+// not-found from the edit-scoped query.
+func (s *Service) requireEditor(ctx context.Context, userID int64, recUUID string) error {
+	wsID, err := s.repo.GetWorkspaceIDByUUIDForUser(ctx, userID, recUUID)
+	if err != nil {
+		return err
+	}
+	return s.access.RequireEditor(ctx, userID, wsID, recRepo.ErrNotFound)
+}
+
 func sanitizeFileName(name string) string {
 	name = filepath.Base(name)
 	name = strings.ReplaceAll(name, " ", "_")
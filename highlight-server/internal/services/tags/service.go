@@ -0,0 +1,98 @@
+package tags
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	clipsrepo "highlightiq-server/internal/repos/clips"
+	recordingsrepo "highlightiq-server/internal/repos/recordings"
+	tagsrepo "highlightiq-server/internal/repos/tags"
+	workspacessvc "highlightiq-server/internal/services/workspaces"
+)
+
+var ErrNotFound = errors.New("tags: not found")
+
+type Service struct {
+	tags       *tagsrepo.Repo
+	clips      *clipsrepo.Repo
+	recordings *recordingsrepo.Repo
+	access     *workspacessvc.Service
+}
+
+func New(tags *tagsrepo.Repo, clips *clipsrepo.Repo, recordings *recordingsrepo.Repo, access *workspacessvc.Service) *Service {
+	return &Service{
+		tags:       tags,
+		clips:      clips,
+		recordings: recordings,
+		access:     access,
+	}
+}
+
+func (s *Service) List(ctx context.Context, userID int64) ([]tagsrepo.Tag, error) {
+	return s.tags.ListByUser(ctx, userID)
+}
+
+func (s *Service) SetRecordingTags(ctx context.Context, userID int64, recUUID string, names []string) ([]string, error) {
+	rec, err := s.recordings.GetByUUIDForUser(ctx, userID, recUUID, 0)
+	if err != nil {
+		if errors.Is(err, recordingsrepo.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if err := s.access.RequireEditor(ctx, userID, rec.WorkspaceID, ErrNotFound); err != nil {
+		return nil, err
+	}
+
+	clean := Normalize(names)
+	if err := s.tags.SetForRecording(ctx, userID, rec.ID, clean); err != nil {
+		return nil, err
+	}
+	return clean, nil
+}
+
+func (s *Service) SetClipTags(ctx context.Context, userID int64, clipID int64, names []string) ([]string, error) {
+	clip, err := s.clips.GetByIDForUser(ctx, userID, clipID)
+	if err != nil {
+		if errors.Is(err, clipsrepo.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if err := s.access.RequireEditor(ctx, userID, clip.WorkspaceID, ErrNotFound); err != nil {
+		return nil, err
+	}
+
+	clean := Normalize(names)
+	if err := s.tags.SetForClip(ctx, userID, clipID, clean); err != nil {
+		return nil, err
+	}
+	return clean, nil
+}
+
+func (s *Service) Delete(ctx context.Context, userID int64, name string) error {
+	err := s.tags.DeleteByNameForUser(ctx, userID, strings.ToLower(strings.TrimSpace(name)))
+	if errors.Is(err, tagsrepo.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Normalize trims and lowercases tag names, dropping empties and duplicates while keeping order.
+func Normalize(names []string) []string {
+	out := make([]string, 0, len(names))
+	seen := make(map[string]struct{}, len(names))
+	for _, n := range names {
+		n = strings.ToLower(strings.TrimSpace(n))
+		if n == "" {
+			continue
+		}
+		if _, ok := seen[n]; ok {
+			continue
+		}
+		seen[n] = struct{}{}
+		out = append(out, n)
+	}
+	return out
+}
@@ -0,0 +1,165 @@
+package trash
+
+import (
+	"context"
+	"log"
+	"time"
+
+	clipsrepo "highlightiq-server/internal/repos/clips"
+	recordingsrepo "highlightiq-server/internal/repos/recordings"
+	"highlightiq-server/internal/storage"
+)
+
+// RecordingsRepo is the part of the recordings repo the trash needs.
+type RecordingsRepo interface {
+	ListDeletedByUser(ctx context.Context, userID int64) ([]recordingsrepo.Recording, error)
+	ListDeletedBefore(ctx context.Context, userID int64, cutoff time.Time) ([]recordingsrepo.Recording, error)
+	HardDeleteByID(ctx context.Context, id int64) error
+}
+
+// ClipsRepo is the part of the clips repo the trash needs.
+type ClipsRepo interface {
+	ListDeletedByUser(ctx context.Context, userID int64) ([]clipsrepo.Clip, error)
+	ListDeletedBefore(ctx context.Context, userID int64, cutoff time.Time) ([]clipsrepo.Clip, error)
+	ListExportPathsByRecordingID(ctx context.Context, recordingID int64) ([]string, error)
+	HardDeleteByID(ctx context.Context, id int64) error
+}
+
+// Service lists, empties and purges the trash. Items gave their storage quota back when they were
+// trashed, so purging them leaves usage alone.
+type Service struct {
+	recordings RecordingsRepo
+	clips      ClipsRepo
+	recFiles   *storage.Cache
+	clipFiles  *storage.Cache
+	retention  time.Duration
+}
+
+func New(recordings RecordingsRepo, clips ClipsRepo, recFiles *storage.Cache, clipFiles *storage.Cache, retentionDays int) *Service {
+	if retentionDays <= 0 {
+		retentionDays = 30
+	}
+	return &Service{
+		recordings: recordings,
+		clips:      clips,
+		recFiles:   recFiles,
+		clipFiles:  clipFiles,
+		retention:  time.Duration(retentionDays) * 24 * time.Hour,
+	}
+}
+
+type Trash struct {
+	Recordings    []recordingsrepo.Recording `json:"recordings"`
+	Clips         []clipsrepo.Clip           `json:"clips"`
+	RetentionDays int                        `json:"retention_days"`
+}
+
+type PurgeResult struct {
+	Recordings int `json:"recordings"`
+	Clips      int `json:"clips"`
+}
+
+func (s *Service) List(ctx context.Context, userID int64) (Trash, error) {
+	recs, err := s.recordings.ListDeletedByUser(ctx, userID)
+	if err != nil {
+		return Trash{}, err
+	}
+	clips, err := s.clips.ListDeletedByUser(ctx, userID)
+	if err != nil {
+		return Trash{}, err
+	}
+
+	if recs == nil {
+		recs = []recordingsrepo.Recording{}
+	}
+	if clips == nil {
+		clips = []clipsrepo.Clip{}
+	}
+
+	return Trash{
+		Recordings:    recs,
+		Clips:         clips,
+		RetentionDays: int(s.retention / (24 * time.Hour)),
+	}, nil
+}
+
+// Empty permanently removes everything in the user's trash right away.
+func (s *Service) Empty(ctx context.Context, userID int64) (PurgeResult, error) {
+	return s.purge(ctx, userID, time.Now().UTC().Add(time.Minute))
+}
+
+// Purge permanently removes trashed items older than the retention period, for every user.
+func (s *Service) Purge(ctx context.Context) (PurgeResult, error) {
+	return s.purge(ctx, 0, time.Now().UTC().Add(-s.retention))
+}
+
+func (s *Service) purge(ctx context.Context, userID int64, cutoff time.Time) (PurgeResult, error) {
+	var res PurgeResult
+
+	// Clips first: a trashed clip under a trashed recording would otherwise vanish through the
+	// cascade without its export file being removed.
+	clips, err := s.clips.ListDeletedBefore(ctx, userID, cutoff)
+	if err != nil {
+		return res, err
+	}
+	for _, c := range clips {
+		if err := s.clips.HardDeleteByID(ctx, c.ID); err != nil {
+			return res, err
+		}
+		if c.ExportPath != nil && *c.ExportPath != "" {
+			// Best-effort file delete (if it fails, DB row is already gone)
+			removeFile(ctx, s.clipFiles, *c.ExportPath)
+		}
+		res.Clips++
+	}
+
+	recs, err := s.recordings.ListDeletedBefore(ctx, userID, cutoff)
+	if err != nil {
+		return res, err
+	}
+	for _, rec := range recs {
+		exports, err := s.clips.ListExportPathsByRecordingID(ctx, rec.ID)
+		if err != nil {
+			return res, err
+		}
+		if err := s.recordings.HardDeleteByID(ctx, rec.ID); err != nil {
+			return res, err
+		}
+		removeFile(ctx, s.recFiles, rec.StoragePath)
+		for _, p := range exports {
+			removeFile(ctx, s.clipFiles, p)
+		}
+		res.Recordings++
+	}
+
+	return res, nil
+}
+
+// removeFile deletes an object and any cached copy. Failures are only logged.
+func removeFile(ctx context.Context, files *storage.Cache, key string) {
+	if err := files.Store().Delete(ctx, key); err != nil {
+		log.Printf("trash: delete %q failed: %v", key, err)
+	}
+	files.Evict(key)
+}
+
+// Run purges expired trash every interval until ctx is cancelled.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		res, err := s.Purge(ctx)
+		if err != nil {
+			log.Printf("trash purge failed: %v", err)
+		} else if res.Recordings > 0 || res.Clips > 0 {
+			log.Printf("trash purge removed %d recordings and %d clips", res.Recordings, res.Clips)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
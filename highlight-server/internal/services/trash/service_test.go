@@ -0,0 +1,251 @@
+package trash
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	clipsrepo "highlightiq-server/internal/repos/clips"
+	recordingsrepo "highlightiq-server/internal/repos/recordings"
+	"highlightiq-server/internal/storage"
+)
+
+// fakeTrash stands in for the recordings and clips repos. Like the SQL it replaces, a purge by a
+// user matches their own items and everything in workspaces they own (ownerOf).
+type fakeTrash struct {
+	mu         sync.Mutex
+	recordings []recordingsrepo.Recording
+	clips      []clipsrepo.Clip
+	ownerOf    map[int64]int64
+	calls      []string
+	cutoffs    []time.Time
+}
+
+func (f *fakeTrash) purgeable(userID, owner, workspaceID int64, deletedAt *time.Time, cutoff time.Time) bool {
+	if deletedAt == nil || !deletedAt.Before(cutoff) {
+		return false
+	}
+	return userID == 0 || owner == userID || f.ownerOf[workspaceID] == userID
+}
+
+type fakeRecordings struct{ *fakeTrash }
+
+func (f fakeRecordings) ListDeletedByUser(ctx context.Context, userID int64) ([]recordingsrepo.Recording, error) {
+	return nil, nil
+}
+
+func (f fakeRecordings) ListDeletedBefore(ctx context.Context, userID int64, cutoff time.Time) ([]recordingsrepo.Recording, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, "recordings")
+	f.cutoffs = append(f.cutoffs, cutoff)
+
+	var out []recordingsrepo.Recording
+	for _, r := range f.recordings {
+		if f.purgeable(userID, r.UserID, r.WorkspaceID, r.DeletedAt, cutoff) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (f fakeRecordings) HardDeleteByID(ctx context.Context, id int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, r := range f.recordings {
+		if r.ID == id {
+			f.recordings = append(f.recordings[:i], f.recordings[i+1:]...)
+			break
+		}
+	}
+	// Mirror the ON DELETE CASCADE from clips to recordings.
+	kept := f.clips[:0]
+	for _, c := range f.clips {
+		if c.RecordingID != id {
+			kept = append(kept, c)
+		}
+	}
+	f.clips = kept
+	return nil
+}
+
+type fakeClips struct{ *fakeTrash }
+
+func (f fakeClips) ListDeletedByUser(ctx context.Context, userID int64) ([]clipsrepo.Clip, error) {
+	return nil, nil
+}
+
+func (f fakeClips) ListDeletedBefore(ctx context.Context, userID int64, cutoff time.Time) ([]clipsrepo.Clip, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, "clips")
+	f.cutoffs = append(f.cutoffs, cutoff)
+
+	var out []clipsrepo.Clip
+	for _, c := range f.clips {
+		if f.purgeable(userID, c.UserID, c.WorkspaceID, c.DeletedAt, cutoff) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (f fakeClips) ListExportPathsByRecordingID(ctx context.Context, recordingID int64) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []string
+	for _, c := range f.clips {
+		if c.RecordingID == recordingID && c.ExportPath != nil {
+			out = append(out, *c.ExportPath)
+		}
+	}
+	return out, nil
+}
+
+func (f fakeClips) HardDeleteByID(ctx context.Context, id int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, c := range f.clips {
+		if c.ID == id {
+			f.clips = append(f.clips[:i], f.clips[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func newTestService(t *testing.T, f *fakeTrash, files ...string) (*Service, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cache := storage.NewCache(storage.NewLocal(dir), "", 0)
+	return New(fakeRecordings{f}, fakeClips{f}, cache, cache, 30), dir
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestEmptyPurgesOnlyTheUsersItems(t *testing.T) {
+	yesterday := time.Now().UTC().Add(-24 * time.Hour)
+	f := &fakeTrash{
+		// User 1 owns workspace 10 and edits workspace 20, which user 3 owns.
+		ownerOf: map[int64]int64{10: 1, 20: 3},
+		recordings: []recordingsrepo.Recording{
+			{ID: 1, UserID: 2, WorkspaceID: 10, StoragePath: "rec1.mp4", DeletedAt: &yesterday},
+			{ID: 2, UserID: 2, WorkspaceID: 20, StoragePath: "rec2.mp4", DeletedAt: &yesterday},
+		},
+		clips: []clipsrepo.Clip{
+			{ID: 1, UserID: 1, WorkspaceID: 20, RecordingID: 9, ExportPath: ptr("clip1.mp4"), DeletedAt: &yesterday},
+			{ID: 2, UserID: 2, WorkspaceID: 20, RecordingID: 9, ExportPath: ptr("clip2.mp4"), DeletedAt: &yesterday},
+			{ID: 3, UserID: 1, WorkspaceID: 10, RecordingID: 9},
+		},
+	}
+	s, dir := newTestService(t, f, "rec1.mp4", "rec2.mp4", "clip1.mp4", "clip2.mp4")
+
+	res, err := s.Empty(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != (PurgeResult{Recordings: 1, Clips: 1}) {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if !reflect.DeepEqual(f.calls, []string{"clips", "recordings"}) {
+		t.Fatalf("expected clips to be purged before recordings, got %v", f.calls)
+	}
+
+	// The teammate's clip in a workspace user 1 only edits stays, as does the live clip.
+	if len(f.clips) != 2 || f.clips[0].ID != 2 || f.clips[1].ID != 3 {
+		t.Fatalf("unexpected remaining clips %+v", f.clips)
+	}
+	if len(f.recordings) != 1 || f.recordings[0].ID != 2 {
+		t.Fatalf("unexpected remaining recordings %+v", f.recordings)
+	}
+
+	for name, want := range map[string]bool{"rec1.mp4": false, "clip1.mp4": false, "rec2.mp4": true, "clip2.mp4": true} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if got := err == nil; got != want {
+			t.Errorf("%s: expected exists=%v, got %v", name, want, got)
+		}
+	}
+}
+
+func TestPurgeHonoursRetention(t *testing.T) {
+	old := time.Now().UTC().Add(-31 * 24 * time.Hour)
+	recent := time.Now().UTC().Add(-29 * 24 * time.Hour)
+	f := &fakeTrash{
+		recordings: []recordingsrepo.Recording{
+			{ID: 1, UserID: 1, WorkspaceID: 10, StoragePath: "old.mp4", DeletedAt: &old},
+			{ID: 2, UserID: 2, WorkspaceID: 20, StoragePath: "recent.mp4", DeletedAt: &recent},
+		},
+		clips: []clipsrepo.Clip{
+			{ID: 1, UserID: 1, RecordingID: 1, ExportPath: ptr("cascade.mp4")},
+		},
+	}
+	s, dir := newTestService(t, f, "old.mp4", "recent.mp4", "cascade.mp4")
+
+	res, err := s.Purge(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != (PurgeResult{Recordings: 1}) {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if len(f.recordings) != 1 || f.recordings[0].ID != 2 {
+		t.Fatalf("unexpected remaining recordings %+v", f.recordings)
+	}
+	// Exports of live clips under a purged recording go with it.
+	if _, err := os.Stat(filepath.Join(dir, "cascade.mp4")); !os.IsNotExist(err) {
+		t.Fatalf("expected cascade.mp4 to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "recent.mp4")); err != nil {
+		t.Fatalf("expected recent.mp4 to be kept, got %v", err)
+	}
+}
+
+func TestRunPurgesUntilCancelled(t *testing.T) {
+	f := &fakeTrash{}
+	s, _ := newTestService(t, f)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx, 5*time.Millisecond)
+		close(done)
+	}()
+
+	deadline := time.After(time.Second)
+	for {
+		f.mu.Lock()
+		n := len(f.cutoffs)
+		f.mu.Unlock()
+		if n >= 4 { // two ticks, each listing clips and recordings
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("expected repeated purges, got %d list calls", n)
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop after cancel")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	want := time.Now().UTC().Add(-30 * 24 * time.Hour)
+	if d := want.Sub(f.cutoffs[0]); d < 0 || d > time.Minute {
+		t.Fatalf("expected cutoff near %v, got %v", want, f.cutoffs[0])
+	}
+}
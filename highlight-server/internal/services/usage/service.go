@@ -0,0 +1,231 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	usagerepo "highlightiq-server/internal/repos/usage"
+)
+
+// ErrQuotaExceeded is wrapped with the name of the exhausted quota.
+var ErrQuotaExceeded = errors.New("usage: quota exceeded")
+
+// Limits are the default quotas applied to every user without an override. 0 means unlimited.
+type Limits struct {
+	RecordingBytes   int64
+	ExportBytes      int64
+	DetectionMinutes int64
+}
+
+type Service struct {
+	repo     *usagerepo.Repo
+	defaults Limits
+}
+
+func New(repo *usagerepo.Repo, defaults Limits) *Service {
+	return &Service{repo: repo, defaults: defaults}
+}
+
+type Meter struct {
+	Used  int64 `json:"used"`
+	Limit int64 `json:"limit"` // 0 = unlimited
+}
+
+type Report struct {
+	Month            string `json:"month"`
+	RecordingBytes   Meter  `json:"recording_bytes"`
+	ExportBytes      Meter  `json:"export_bytes"`
+	DetectionMinutes Meter  `json:"detection_minutes"`
+}
+
+func (s *Service) Get(ctx context.Context, userID int64) (Report, error) {
+	month := currentMonth()
+
+	u, err := s.repo.Get(ctx, userID, month)
+	if err != nil {
+		return Report{}, err
+	}
+	lim, err := s.limits(ctx, userID)
+	if err != nil {
+		return Report{}, err
+	}
+
+	return Report{
+		Month:            month,
+		RecordingBytes:   Meter{Used: u.RecordingBytes, Limit: lim.RecordingBytes},
+		ExportBytes:      Meter{Used: u.ExportBytes, Limit: lim.ExportBytes},
+		DetectionMinutes: Meter{Used: (u.DetectionSeconds + 59) / 60, Limit: lim.DetectionMinutes},
+	}, nil
+}
+
+// ReserveRecording counts n bytes of a new upload against the user's quota.
+func (s *Service) ReserveRecording(ctx context.Context, userID int64, n int64) error {
+	lim, err := s.limits(ctx, userID)
+	if err != nil {
+		return err
+	}
+	ok, err := s.repo.AddRecordingBytes(ctx, userID, n, lim.RecordingBytes)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: recording storage", ErrQuotaExceeded)
+	}
+	return nil
+}
+
+// ReleaseRecording undoes a reservation whose upload did not go through.
+func (s *Service) ReleaseRecording(ctx context.Context, userID int64, n int64) error {
+	_, err := s.repo.AddRecordingBytes(ctx, userID, -n, 0)
+	return err
+}
+
+// CheckExport fails when the user has no export space left at all, so an export can be
+// refused before ffmpeg runs.
+func (s *Service) CheckExport(ctx context.Context, userID int64) error {
+	lim, err := s.limits(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if lim.ExportBytes <= 0 {
+		return nil
+	}
+	u, err := s.repo.Get(ctx, userID, currentMonth())
+	if err != nil {
+		return err
+	}
+	if u.ExportBytes >= lim.ExportBytes {
+		return fmt.Errorf("%w: export storage", ErrQuotaExceeded)
+	}
+	return nil
+}
+
+// ChargeExport applies the size change of a (re-)export. delta may be negative. Collection
+// compilations are exempt: they are rebuilt from clip exports that are already counted, and a
+// collection only ever keeps its latest one.
+func (s *Service) ChargeExport(ctx context.Context, userID int64, delta int64) error {
+	lim, err := s.limits(ctx, userID)
+	if err != nil {
+		return err
+	}
+	ok, err := s.repo.AddExportBytes(ctx, userID, delta, lim.ExportBytes)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: export storage", ErrQuotaExceeded)
+	}
+	return nil
+}
+
+// CheckDetection fails once this month's detection minutes are used up.
+func (s *Service) CheckDetection(ctx context.Context, userID int64) error {
+	lim, err := s.limits(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if lim.DetectionMinutes <= 0 {
+		return nil
+	}
+	u, err := s.repo.Get(ctx, userID, currentMonth())
+	if err != nil {
+		return err
+	}
+	if u.DetectionSeconds >= lim.DetectionMinutes*60 {
+		return fmt.Errorf("%w: detection minutes", ErrQuotaExceeded)
+	}
+	return nil
+}
+
+// RecordDetection adds the scanned video length to this month's detection usage.
+func (s *Service) RecordDetection(ctx context.Context, userID int64, seconds int64) error {
+	if seconds <= 0 {
+		return nil
+	}
+	return s.repo.AddDetectionSeconds(ctx, userID, currentMonth(), seconds)
+}
+
+// ClipHolding returns what a clip counts against its owner's quota while it is not in the trash.
+func (s *Service) ClipHolding(ctx context.Context, clipID int64) (usagerepo.Holding, error) {
+	return s.repo.ClipHolding(ctx, clipID)
+}
+
+// RecordingHolding returns what a recording, with the clips cut from it, counts against the
+// quotas of its uploader and of the clips' owners while it is not in the trash.
+func (s *Service) RecordingHolding(ctx context.Context, recordingID int64) ([]usagerepo.Holding, error) {
+	return s.repo.RecordingHolding(ctx, recordingID)
+}
+
+// Charge counts items coming back out of the trash. It fails, charging nothing, when that would
+// go over any of the quotas involved.
+func (s *Service) Charge(ctx context.Context, held ...usagerepo.Holding) error {
+	for i, h := range held {
+		if err := s.charge(ctx, h); err != nil {
+			_ = s.Release(ctx, held[:i]...)
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) charge(ctx context.Context, h usagerepo.Holding) error {
+	lim, err := s.limits(ctx, h.UserID)
+	if err != nil {
+		return err
+	}
+	ok, err := s.repo.AddRecordingBytes(ctx, h.UserID, h.RecordingBytes, lim.RecordingBytes)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: recording storage", ErrQuotaExceeded)
+	}
+	ok, err = s.repo.AddExportBytes(ctx, h.UserID, h.ExportBytes, lim.ExportBytes)
+	if err == nil && !ok {
+		err = fmt.Errorf("%w: export storage", ErrQuotaExceeded)
+	}
+	if err != nil {
+		_, _ = s.repo.AddRecordingBytes(ctx, h.UserID, -h.RecordingBytes, 0)
+		return err
+	}
+	return nil
+}
+
+// Release gives back what items held when they go to the trash. Usage only counts items outside
+// the trash, so purging them later gives back nothing more.
+func (s *Service) Release(ctx context.Context, held ...usagerepo.Holding) error {
+	for _, h := range held {
+		if _, err := s.repo.AddRecordingBytes(ctx, h.UserID, -h.RecordingBytes, 0); err != nil {
+			return err
+		}
+		if _, err := s.repo.AddExportBytes(ctx, h.UserID, -h.ExportBytes, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) limits(ctx context.Context, userID int64) (Limits, error) {
+	q, err := s.repo.GetQuota(ctx, userID)
+	if err != nil {
+		return Limits{}, err
+	}
+
+	lim := s.defaults
+	if q.RecordingBytes != nil {
+		lim.RecordingBytes = *q.RecordingBytes
+	}
+	if q.ExportBytes != nil {
+		lim.ExportBytes = *q.ExportBytes
+	}
+	if q.DetectionMinutes != nil {
+		lim.DetectionMinutes = *q.DetectionMinutes
+	}
+	return lim, nil
+}
+
+func currentMonth() string {
+	return time.Now().UTC().Format("2006-01")
+}
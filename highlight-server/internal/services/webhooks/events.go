@@ -0,0 +1,161 @@
+package webhooks
+
+import (
+	"time"
+
+	candidatesrepo "highlightiq-server/internal/repos/clipcandidates"
+	clipsrepo "highlightiq-server/internal/repos/clips"
+	pubrepo "highlightiq-server/internal/repos/publications"
+	recordingsrepo "highlightiq-server/internal/repos/recordings"
+)
+
+// Event types endpoints can subscribe to. EventPing is only sent on request, to every endpoint.
+const (
+	EventRecordingUploaded  = "recording.uploaded"
+	EventDetectionCompleted = "detection.completed"
+	EventCandidateApproved  = "candidate.approved"
+	EventClipExported       = "clip.exported"
+	EventPublishUpdated     = "publish.updated"
+	EventPublishDeleted     = "publish.deleted"
+	EventPing               = "ping"
+)
+
+var EventTypes = []string{
+	EventRecordingUploaded,
+	EventDetectionCompleted,
+	EventCandidateApproved,
+	EventClipExported,
+	EventPublishUpdated,
+	EventPublishDeleted,
+}
+
+// PayloadVersion is bumped whenever the shape of an event's data changes in a way receivers
+// could trip over; additions do not count.
+const PayloadVersion = 1
+
+// Event is the body of every delivery. ID is shared by the deliveries of one event to several
+// endpoints, so receivers can de-duplicate retries.
+type Event struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	Version     int       `json:"version"`
+	WorkspaceID int64     `json:"workspace_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	Data        any       `json:"data"`
+}
+
+// The data of each event type. These are the public contract, kept apart from the repo models
+// so internal changes do not leak into payloads.
+
+type RecordingData struct {
+	UUID            string    `json:"uuid"`
+	Title           string    `json:"title"`
+	OriginalName    string    `json:"original_filename"`
+	Status          string    `json:"status"`
+	DurationSeconds int       `json:"duration_seconds"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func NewRecordingData(rec recordingsrepo.Recording) RecordingData {
+	return RecordingData{
+		UUID:            rec.UUID,
+		Title:           rec.Title,
+		OriginalName:    rec.OriginalName,
+		Status:          rec.Status,
+		DurationSeconds: rec.DurationSeconds,
+		CreatedAt:       rec.CreatedAt,
+	}
+}
+
+type DetectionData struct {
+	RecordingUUID string `json:"recording_uuid"`
+	Candidates    int64  `json:"candidates"`
+}
+
+type CandidateData struct {
+	ID          int64   `json:"id"`
+	RecordingID int64   `json:"recording_id"`
+	StartMS     int     `json:"start_ms"`
+	EndMS       int     `json:"end_ms"`
+	Score       float64 `json:"score"`
+	Status      string  `json:"status"`
+}
+
+func NewCandidateData(c candidatesrepo.Candidate) CandidateData {
+	return CandidateData{
+		ID:          c.ID,
+		RecordingID: c.RecordingID,
+		StartMS:     c.StartMS,
+		EndMS:       c.EndMS,
+		Score:       c.Score,
+		Status:      c.Status,
+	}
+}
+
+type ClipData struct {
+	ID              int64    `json:"id"`
+	RecordingID     int64    `json:"recording_id"`
+	CandidateID     *int64   `json:"candidate_id,omitempty"`
+	Title           string   `json:"title"`
+	Caption         *string  `json:"caption,omitempty"`
+	StartMS         int      `json:"start_ms"`
+	EndMS           int      `json:"end_ms"`
+	DurationSeconds int      `json:"duration_seconds"`
+	Status          string   `json:"status"`
+	Tags            []string `json:"tags,omitempty"`
+}
+
+func NewClipData(c clipsrepo.Clip) ClipData {
+	return ClipData{
+		ID:              c.ID,
+		RecordingID:     c.RecordingID,
+		CandidateID:     c.CandidateID,
+		Title:           c.Title,
+		Caption:         c.Caption,
+		StartMS:         c.StartMS,
+		EndMS:           c.EndMS,
+		DurationSeconds: c.DurationSeconds,
+		Status:          c.Status,
+		Tags:            c.Tags,
+	}
+}
+
+type PublicationData struct {
+	ID           int64      `json:"id"`
+	ClipID       int64      `json:"clip_id"`
+	Platform     string     `json:"platform"`
+	Status       string     `json:"status"`
+	ExternalID   string     `json:"external_id,omitempty"`
+	URL          string     `json:"url,omitempty"`
+	ErrorMessage *string    `json:"error_message,omitempty"`
+	PublishAt    *time.Time `json:"publish_at,omitempty"`
+	PublishedAt  *time.Time `json:"published_at,omitempty"`
+	Views        int        `json:"views"`
+	Likes        int        `json:"likes"`
+	Comments     int        `json:"comments"`
+	Shares       int        `json:"shares"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func NewPublicationData(p pubrepo.Publication) PublicationData {
+	return PublicationData{
+		ID:           p.ID,
+		ClipID:       p.ClipID,
+		Platform:     p.Platform,
+		Status:       p.Status,
+		ExternalID:   p.ExternalID,
+		URL:          p.URL,
+		ErrorMessage: p.ErrorMessage,
+		PublishAt:    p.PublishAt,
+		PublishedAt:  p.PublishedAt,
+		Views:        p.Views,
+		Likes:        p.Likes,
+		Comments:     p.Comments,
+		Shares:       p.Shares,
+		UpdatedAt:    p.UpdatedAt,
+	}
+}
+
+type PingData struct {
+	EndpointID int64 `json:"endpoint_id"`
+}
@@ -0,0 +1,76 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"highlightiq-server/internal/integrations/webhook"
+	webhooksrepo "highlightiq-server/internal/repos/webhooks"
+	"highlightiq-server/internal/secretbox"
+)
+
+// Sender makes the deliveries Emit queues. It implements outbox.Sender for
+// outbox.TopicWebhookDelivery; the outbox retries what it reports as failed.
+type Sender struct {
+	repo    *webhooksrepo.Repo
+	secrets *secretbox.Box
+	client  *webhook.Client
+}
+
+func NewSender(repo *webhooksrepo.Repo, secrets *secretbox.Box, client *webhook.Client) *Sender {
+	return &Sender{repo: repo, secrets: secrets, client: client}
+}
+
+func (s *Sender) Send(ctx context.Context, payload json.RawMessage) error {
+	var q webhooksrepo.Queued
+	if err := json.Unmarshal(payload, &q); err != nil {
+		return err
+	}
+
+	d, err := s.repo.GetDelivery(ctx, q.DeliveryID)
+	if err != nil {
+		if errors.Is(err, webhooksrepo.ErrNotFound) {
+			// The endpoint was deleted, and its log with it.
+			return nil
+		}
+		return err
+	}
+	if d.Status == webhooksrepo.StatusDelivered {
+		return nil
+	}
+
+	e, err := s.repo.GetByID(ctx, d.EndpointID)
+	if err != nil {
+		if errors.Is(err, webhooksrepo.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !e.Active && d.EventType != EventPing {
+		return s.repo.RecordAttempt(ctx, d.ID, webhooksrepo.Attempt{Error: "endpoint is disabled"})
+	}
+
+	secret, err := s.secrets.Open(e.Secret)
+	if err != nil {
+		return err
+	}
+
+	res, sendErr := s.client.Post(ctx, webhook.Request{
+		URL:        e.URL,
+		Secret:     secret,
+		EventType:  d.EventType,
+		DeliveryID: d.ID,
+		Body:       d.Payload,
+	})
+
+	// res.Body is empty unless the endpoint answered 2xx.
+	a := webhooksrepo.Attempt{ResponseStatus: res.StatusCode, ResponseBody: res.Body}
+	if sendErr != nil {
+		a.Error = sendErr.Error()
+	}
+	if err := s.repo.RecordAttempt(ctx, d.ID, a); err != nil {
+		return err
+	}
+	return sendErr
+}
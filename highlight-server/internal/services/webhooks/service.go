@@ -0,0 +1,243 @@
+// Package webhooks lets users register endpoints that are told about events in a workspace
+// (uploads, detections, exports, publishes). Each event is logged as one delivery per subscribed
+// endpoint and handed to the outbox, which retries failures; see Sender.
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"highlightiq-server/internal/integrations/webhook"
+	webhooksrepo "highlightiq-server/internal/repos/webhooks"
+	"highlightiq-server/internal/secretbox"
+	outboxsvc "highlightiq-server/internal/services/outbox"
+	workspacessvc "highlightiq-server/internal/services/workspaces"
+)
+
+var (
+	ErrNotFound   = errors.New("webhooks: not found")
+	ErrInvalidURL = errors.New("webhooks: invalid url")
+)
+
+// SecretPrefix starts every signing secret, so leaked ones are recognisable.
+const SecretPrefix = "whsec_"
+
+// deliveryLogLimit caps how many deliveries an endpoint's log shows.
+const deliveryLogLimit = 100
+
+type Service struct {
+	repo    *webhooksrepo.Repo
+	events  *outboxsvc.Service
+	secrets *secretbox.Box
+	access  *workspacessvc.Service
+	client  *webhook.Client
+}
+
+// New wires the webhooks service. client is the one the Sender delivers with; endpoint URLs are
+// checked against its rules when they are saved.
+func New(repo *webhooksrepo.Repo, events *outboxsvc.Service, secrets *secretbox.Box, access *workspacessvc.Service, client *webhook.Client) *Service {
+	return &Service{repo: repo, events: events, secrets: secrets, access: access, client: client}
+}
+
+// Created is returned once, on creation; Secret is never shown again.
+type Created struct {
+	webhooksrepo.Endpoint
+	Secret string `json:"secret"`
+}
+
+// Create registers an endpoint in workspaceID, or the user's personal workspace when it is 0.
+func (s *Service) Create(ctx context.Context, userID int64, workspaceID int64, in CreateInput) (Created, error) {
+	if err := s.checkURL(ctx, in.URL); err != nil {
+		return Created{}, err
+	}
+	workspaceID, err := s.access.TargetForCreate(ctx, userID, workspaceID)
+	if err != nil {
+		if errors.Is(err, workspacessvc.ErrNotFound) {
+			return Created{}, ErrNotFound
+		}
+		return Created{}, err
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return Created{}, err
+	}
+	sealed, err := s.secrets.Seal(secret)
+	if err != nil {
+		return Created{}, err
+	}
+
+	e, err := s.repo.Create(ctx, webhooksrepo.CreateParams{
+		WorkspaceID: workspaceID,
+		CreatedBy:   userID,
+		URL:         in.URL,
+		Description: strings.TrimSpace(in.Description),
+		Events:      dedupe(in.Events),
+		Secret:      sealed,
+	})
+	if err != nil {
+		return Created{}, err
+	}
+
+	return Created{Endpoint: e, Secret: secret}, nil
+}
+
+// List returns the endpoints of workspaces the user can edit, or of workspaceID when it is not 0.
+func (s *Service) List(ctx context.Context, userID int64, workspaceID int64) ([]webhooksrepo.Endpoint, error) {
+	return s.repo.ListForUser(ctx, userID, workspaceID)
+}
+
+func (s *Service) Get(ctx context.Context, userID int64, id int64) (webhooksrepo.Endpoint, error) {
+	e, err := s.repo.GetByIDForUser(ctx, userID, id)
+	if errors.Is(err, webhooksrepo.ErrNotFound) {
+		return webhooksrepo.Endpoint{}, ErrNotFound
+	}
+	return e, err
+}
+
+func (s *Service) Update(ctx context.Context, userID int64, id int64, in UpdateInput) (webhooksrepo.Endpoint, error) {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return webhooksrepo.Endpoint{}, err
+	}
+	if in.URL != nil {
+		if err := s.checkURL(ctx, *in.URL); err != nil {
+			return webhooksrepo.Endpoint{}, err
+		}
+	}
+
+	p := webhooksrepo.UpdateParams{URL: in.URL, Active: in.Active}
+	if in.Description != nil {
+		d := strings.TrimSpace(*in.Description)
+		p.Description = &d
+	}
+	if in.Events != nil {
+		p.Events = dedupe(in.Events)
+	}
+	return s.repo.Update(ctx, id, p)
+}
+
+func (s *Service) Delete(ctx context.Context, userID int64, id int64) error {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return err
+	}
+	err := s.repo.Delete(ctx, id)
+	if errors.Is(err, webhooksrepo.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Deliveries returns the endpoint's delivery log, newest first.
+func (s *Service) Deliveries(ctx context.Context, userID int64, id int64) ([]webhooksrepo.Delivery, error) {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, id, deliveryLogLimit)
+}
+
+// Ping queues a ping event to the endpoint, whether or not it is active, so its owner can
+// check that it is reachable and verifies signatures.
+func (s *Service) Ping(ctx context.Context, userID int64, id int64) (webhooksrepo.Delivery, error) {
+	e, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return webhooksrepo.Delivery{}, err
+	}
+
+	ids, err := s.queue(ctx, []webhooksrepo.Endpoint{e}, e.WorkspaceID, EventPing, PingData{EndpointID: e.ID})
+	if err != nil {
+		return webhooksrepo.Delivery{}, err
+	}
+	return s.repo.GetDelivery(ctx, ids[0])
+}
+
+// Emit announces an event to the workspace's subscribed endpoints. It is called after the change
+// it describes is saved, and only logs failures: a webhook must not undo the change.
+func (s *Service) Emit(ctx context.Context, workspaceID int64, eventType string, data any) {
+	if s == nil {
+		return
+	}
+
+	endpoints, err := s.repo.ListSubscribed(ctx, workspaceID, eventType)
+	if err != nil {
+		log.Printf("webhooks: listing endpoints for %s in workspace %d failed: %v", eventType, workspaceID, err)
+		return
+	}
+	if len(endpoints) == 0 {
+		return
+	}
+
+	if _, err := s.queue(ctx, endpoints, workspaceID, eventType, data); err != nil {
+		log.Printf("webhooks: queueing %s for workspace %d failed: %v", eventType, workspaceID, err)
+	}
+}
+
+func (s *Service) queue(ctx context.Context, endpoints []webhooksrepo.Endpoint, workspaceID int64, eventType string, data any) ([]int64, error) {
+	ev := Event{
+		ID:          uuid.NewString(),
+		Type:        eventType,
+		Version:     PayloadVersion,
+		WorkspaceID: workspaceID,
+		CreatedAt:   time.Now().UTC(),
+		Data:        data,
+	}
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]webhooksrepo.NewDelivery, 0, len(endpoints))
+	for _, e := range endpoints {
+		deliveries = append(deliveries, webhooksrepo.NewDelivery{
+			EndpointID: e.ID,
+			EventID:    ev.ID,
+			EventType:  ev.Type,
+			Payload:    body,
+		})
+	}
+
+	ids, err := s.repo.Queue(ctx, outboxsvc.TopicWebhookDelivery, deliveries)
+	if err != nil {
+		return nil, err
+	}
+	s.events.Wake()
+	return ids, nil
+}
+
+// checkURL accepts https URLs whose host resolves to public addresses only; see
+// webhook.Client.CheckURL.
+func (s *Service) checkURL(ctx context.Context, raw string) error {
+	if err := s.client.CheckURL(ctx, raw); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	return nil
+}
+
+func dedupe(events []string) []string {
+	out := make([]string, 0, len(events))
+	seen := make(map[string]struct{}, len(events))
+	for _, e := range events {
+		if _, ok := seen[e]; ok {
+			continue
+		}
+		seen[e] = struct{}{}
+		out = append(out, e)
+	}
+	return out
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return SecretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,15 @@
+package webhooks
+
+type CreateInput struct {
+	URL         string
+	Description string
+	Events      []string
+}
+
+// UpdateInput changes the fields that are set; Events replaces the subscriptions.
+type UpdateInput struct {
+	URL         *string
+	Description *string
+	Events      []string
+	Active      *bool
+}
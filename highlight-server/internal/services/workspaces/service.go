@@ -0,0 +1,302 @@
+package workspaces
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"highlightiq-server/internal/repos/users"
+	wsrepo "highlightiq-server/internal/repos/workspaces"
+)
+
+var (
+	ErrNotFound          = errors.New("workspaces: not found")
+	ErrForbidden         = errors.New("workspaces: forbidden")
+	ErrBadInput          = errors.New("workspaces: bad input")
+	ErrInvitationInvalid = errors.New("workspaces: invitation invalid or expired")
+)
+
+const invitationTTL = 7 * 24 * time.Hour
+
+type Service struct {
+	repo  *wsrepo.Repo
+	users *users.Repo
+}
+
+func New(repo *wsrepo.Repo, usersRepo *users.Repo) *Service {
+	return &Service{repo: repo, users: usersRepo}
+}
+
+// Default returns the user's personal workspace, creating it on first use.
+func (s *Service) Default(ctx context.Context, userID int64) (wsrepo.Workspace, error) {
+	w, err := s.repo.GetPersonal(ctx, userID)
+	if !errors.Is(err, wsrepo.ErrNotFound) {
+		return w, err
+	}
+
+	w, err = s.repo.Create(ctx, "Personal", userID, true)
+	if errors.Is(err, wsrepo.ErrDuplicate) {
+		// A concurrent request created it first.
+		return s.repo.GetPersonal(ctx, userID)
+	}
+	return w, err
+}
+
+// TargetForCreate picks the workspace new content goes into: the given one, which the user
+// must be able to edit, or their personal workspace when workspaceID is 0.
+func (s *Service) TargetForCreate(ctx context.Context, userID int64, workspaceID int64) (int64, error) {
+	if workspaceID == 0 {
+		w, err := s.Default(ctx, userID)
+		if err != nil {
+			return 0, err
+		}
+		return w.ID, nil
+	}
+
+	if err := s.RequireEditor(ctx, userID, workspaceID, ErrNotFound); err != nil {
+		return 0, err
+	}
+	return workspaceID, nil
+}
+
+// RequireEditor checks that the user may change content of the workspace. Non-members get
+// notFound, so callers can answer as if the resource did not exist; viewers get ErrForbidden.
+func (s *Service) RequireEditor(ctx context.Context, userID int64, workspaceID int64, notFound error) error {
+	role, err := s.repo.Role(ctx, workspaceID, userID)
+	if errors.Is(err, wsrepo.ErrNotFound) {
+		return notFound
+	}
+	if err != nil {
+		return err
+	}
+	if !wsrepo.CanEdit(role) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func (s *Service) Create(ctx context.Context, userID int64, name string) (wsrepo.Workspace, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return wsrepo.Workspace{}, ErrBadInput
+	}
+	return s.repo.Create(ctx, name, userID, false)
+}
+
+func (s *Service) List(ctx context.Context, userID int64) ([]wsrepo.Workspace, error) {
+	// Make sure the personal workspace shows up even before anything was uploaded.
+	if _, err := s.Default(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListForUser(ctx, userID)
+}
+
+func (s *Service) Get(ctx context.Context, userID int64, id int64) (wsrepo.Workspace, error) {
+	w, err := s.repo.GetForUser(ctx, userID, id)
+	if errors.Is(err, wsrepo.ErrNotFound) {
+		return wsrepo.Workspace{}, ErrNotFound
+	}
+	return w, err
+}
+
+func (s *Service) Rename(ctx context.Context, userID int64, id int64, name string) (wsrepo.Workspace, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return wsrepo.Workspace{}, ErrBadInput
+	}
+	if _, err := s.requireOwner(ctx, userID, id); err != nil {
+		return wsrepo.Workspace{}, err
+	}
+	if err := s.repo.Rename(ctx, id, name); err != nil {
+		if errors.Is(err, wsrepo.ErrNotFound) {
+			return wsrepo.Workspace{}, ErrNotFound
+		}
+		return wsrepo.Workspace{}, err
+	}
+	return s.Get(ctx, userID, id)
+}
+
+func (s *Service) Members(ctx context.Context, userID int64, id int64) ([]wsrepo.Member, error) {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListMembers(ctx, id)
+}
+
+// UpdateMemberRole lets the owner switch a member between editor and viewer.
+func (s *Service) UpdateMemberRole(ctx context.Context, userID int64, id int64, memberUUID string, role string) error {
+	if role != wsrepo.RoleEditor && role != wsrepo.RoleViewer {
+		return ErrBadInput
+	}
+	if _, err := s.requireOwner(ctx, userID, id); err != nil {
+		return err
+	}
+
+	memberID, cur, err := s.member(ctx, id, memberUUID)
+	if err != nil {
+		return err
+	}
+	if cur == wsrepo.RoleOwner {
+		return ErrBadInput
+	}
+	return s.repo.SetMemberRole(ctx, id, memberID, role)
+}
+
+// RemoveMember removes someone from the workspace. The owner may remove anyone but themselves;
+// other members may only remove themselves (leave).
+func (s *Service) RemoveMember(ctx context.Context, userID int64, id int64, memberUUID string) error {
+	w, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	memberID, cur, err := s.member(ctx, id, memberUUID)
+	if err != nil {
+		return err
+	}
+	if cur == wsrepo.RoleOwner {
+		return ErrBadInput
+	}
+	if memberID != userID && w.Role != wsrepo.RoleOwner {
+		return ErrForbidden
+	}
+
+	if err := s.repo.RemoveMember(ctx, id, memberID); err != nil {
+		if errors.Is(err, wsrepo.ErrNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// InvitationCreated carries the one-time token to hand to the invitee.
+type InvitationCreated struct {
+	wsrepo.Invitation
+	Token string `json:"token"`
+}
+
+func (s *Service) Invite(ctx context.Context, userID int64, id int64, email string, role string) (InvitationCreated, error) {
+	email = wsrepo.NormalizeEmail(email)
+	if email == "" || (role != wsrepo.RoleEditor && role != wsrepo.RoleViewer) {
+		return InvitationCreated{}, ErrBadInput
+	}
+	w, err := s.requireOwner(ctx, userID, id)
+	if err != nil {
+		return InvitationCreated{}, err
+	}
+	if w.Personal {
+		// Personal workspaces stay private; share through a team workspace instead.
+		return InvitationCreated{}, ErrBadInput
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return InvitationCreated{}, err
+	}
+
+	inv, err := s.repo.CreateInvitation(ctx, wsrepo.CreateInvitationParams{
+		WorkspaceID: id,
+		Email:       email,
+		Role:        role,
+		TokenHash:   hashToken(token),
+		InvitedBy:   userID,
+		ExpiresAt:   time.Now().Add(invitationTTL),
+	})
+	if err != nil {
+		return InvitationCreated{}, err
+	}
+	return InvitationCreated{Invitation: inv, Token: token}, nil
+}
+
+func (s *Service) Invitations(ctx context.Context, userID int64, id int64) ([]wsrepo.Invitation, error) {
+	if _, err := s.requireOwner(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListPendingInvitations(ctx, id)
+}
+
+func (s *Service) RevokeInvitation(ctx context.Context, userID int64, id int64, invitationID int64) error {
+	if _, err := s.requireOwner(ctx, userID, id); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteInvitation(ctx, id, invitationID); err != nil {
+		if errors.Is(err, wsrepo.ErrNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// AcceptInvitation joins the user to the invitation's workspace. The invitation must have been
+// sent to the user's email address.
+func (s *Service) AcceptInvitation(ctx context.Context, userID int64, email string, token string) (wsrepo.Workspace, error) {
+	inv, err := s.repo.GetPendingInvitationByHash(ctx, hashToken(strings.TrimSpace(token)))
+	if errors.Is(err, wsrepo.ErrNotFound) {
+		return wsrepo.Workspace{}, ErrInvitationInvalid
+	}
+	if err != nil {
+		return wsrepo.Workspace{}, err
+	}
+	if inv.Email != wsrepo.NormalizeEmail(email) {
+		return wsrepo.Workspace{}, ErrInvitationInvalid
+	}
+
+	if err := s.repo.AcceptInvitation(ctx, inv, userID); err != nil {
+		if errors.Is(err, wsrepo.ErrNotFound) {
+			return wsrepo.Workspace{}, ErrInvitationInvalid
+		}
+		return wsrepo.Workspace{}, err
+	}
+	return s.Get(ctx, userID, inv.WorkspaceID)
+}
+
+func (s *Service) requireOwner(ctx context.Context, userID int64, id int64) (wsrepo.Workspace, error) {
+	w, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return wsrepo.Workspace{}, err
+	}
+	if w.Role != wsrepo.RoleOwner {
+		return wsrepo.Workspace{}, ErrForbidden
+	}
+	return w, nil
+}
+
+// member resolves a member's public uuid to their user id and role in the workspace.
+func (s *Service) member(ctx context.Context, id int64, memberUUID string) (int64, string, error) {
+	u, err := s.users.GetByUUID(ctx, memberUUID)
+	if errors.Is(err, users.ErrNotFound) {
+		return 0, "", ErrNotFound
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	role, err := s.repo.Role(ctx, id, u.ID)
+	if errors.Is(err, wsrepo.ErrNotFound) {
+		return 0, "", ErrNotFound
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	return u.ID, role, nil
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,419 @@
+// Package youtubepublisher uploads exported clips straight to the requester's YouTube channel. It
+// stands in for the n8n flow as the outbox sender for publish events: the upload runs in the
+// background until the publications row is uploaded or failed.
+//
+// Uploads take far longer than an outbox lease, so the outbox only guarantees the hand-off: its
+// event is delivered once the upload has started. From then on the publications row is the
+// record. Every outcome, including an upload cut off by a restart (see Run), ends with the row
+// failed or uploaded, and a failed publish is retried by the user requesting it again.
+package youtubepublisher
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"highlightiq-server/internal/integrations/oidc"
+	"highlightiq-server/internal/integrations/youtube"
+	clipsrepo "highlightiq-server/internal/repos/clips"
+	"highlightiq-server/internal/repos/identities"
+	pubrepo "highlightiq-server/internal/repos/publications"
+	"highlightiq-server/internal/repos/youtubeaccounts"
+	"highlightiq-server/internal/secretbox"
+	pubsvc "highlightiq-server/internal/services/publications"
+	webhookssvc "highlightiq-server/internal/services/webhooks"
+	"highlightiq-server/internal/storage"
+)
+
+var (
+	ErrNotConnected  = errors.New("youtubepublisher: no channel connected")
+	ErrInvalidState  = errors.New("youtubepublisher: invalid or expired state")
+	ErrConnectFailed = errors.New("youtubepublisher: connecting the channel failed")
+)
+
+// stateProvider tags this flow's authorization requests in oauth_states.
+const stateProvider = "youtube"
+
+type Service struct {
+	accounts  *youtubeaccounts.Repo
+	publishes *pubrepo.Repo
+	clips     *clipsrepo.Repo
+	states    *identities.Repo
+	api       *youtube.Client
+	secrets   *secretbox.Box
+	clipFiles *storage.Cache
+	hooks     *webhookssvc.Service
+	privacy   string
+
+	stateTTL      time.Duration
+	uploadTimeout time.Duration
+	// slots bounds how many uploads run at once.
+	slots chan struct{}
+}
+
+// New builds the publisher. privacy is the privacyStatus videos get when the request does not
+// name one (private, unlisted or public). hooks hears about every outcome it records.
+func New(accounts *youtubeaccounts.Repo, publishes *pubrepo.Repo, clips *clipsrepo.Repo, states *identities.Repo, api *youtube.Client, secrets *secretbox.Box, clipFiles *storage.Cache, hooks *webhookssvc.Service, privacy string) *Service {
+	return &Service{
+		accounts:      accounts,
+		publishes:     publishes,
+		clips:         clips,
+		states:        states,
+		api:           api,
+		secrets:       secrets,
+		clipFiles:     clipFiles,
+		hooks:         hooks,
+		privacy:       privacy,
+		stateTTL:      10 * time.Minute,
+		uploadTimeout: 2 * time.Hour,
+		slots:         make(chan struct{}, 2),
+	}
+}
+
+// Status reports the user's connected channel, if any.
+func (s *Service) Status(ctx context.Context, userID int64) (Connection, error) {
+	a, err := s.accounts.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, youtubeaccounts.ErrNotFound) {
+			return Connection{}, nil
+		}
+		return Connection{}, err
+	}
+	return toConnection(a), nil
+}
+
+// StartConnect returns Google's consent page for upload access.
+func (s *Service) StartConnect(ctx context.Context, userID int64) (ConnectStart, error) {
+	state, verifier, challenge, err := newAuthorization()
+	if err != nil {
+		return ConnectStart{}, err
+	}
+
+	if err := s.states.CreateState(ctx, identities.CreateStateParams{
+		StateHash:    hashState(state),
+		Provider:     stateProvider,
+		CodeVerifier: verifier,
+		LinkUserID:   &userID,
+		ExpiresAt:    time.Now().Add(s.stateTTL),
+	}); err != nil {
+		return ConnectStart{}, err
+	}
+
+	return ConnectStart{AuthorizationURL: s.api.AuthCodeURL(state, challenge)}, nil
+}
+
+// CompleteConnect stores the channel's tokens. Connecting again replaces the earlier channel.
+func (s *Service) CompleteConnect(ctx context.Context, userID int64, code string, state string) (Connection, error) {
+	st, err := s.states.ConsumeState(ctx, hashState(state))
+	if err != nil {
+		if errors.Is(err, identities.ErrNotFound) {
+			return Connection{}, ErrInvalidState
+		}
+		return Connection{}, err
+	}
+	if st.Provider != stateProvider || st.LinkUserID == nil || *st.LinkUserID != userID {
+		return Connection{}, ErrInvalidState
+	}
+
+	tok, err := s.api.Exchange(ctx, code, st.CodeVerifier)
+	if err != nil {
+		log.Printf("youtube: code exchange for user %d failed: %v", userID, err)
+		return Connection{}, ErrConnectFailed
+	}
+	ch, err := s.api.Channel(ctx, tok.AccessToken)
+	if err != nil {
+		log.Printf("youtube: channel lookup for user %d failed: %v", userID, err)
+		return Connection{}, ErrConnectFailed
+	}
+
+	refreshEnc, err := s.secrets.Seal(tok.RefreshToken)
+	if err != nil {
+		return Connection{}, err
+	}
+	accessEnc, err := s.secrets.Seal(tok.AccessToken)
+	if err != nil {
+		return Connection{}, err
+	}
+
+	if err := s.accounts.Save(ctx, youtubeaccounts.SaveParams{
+		UserID:          userID,
+		ChannelID:       ch.ID,
+		ChannelTitle:    ch.Title,
+		RefreshTokenEnc: refreshEnc,
+		AccessTokenEnc:  accessEnc,
+		AccessExpiresAt: tok.Expiry,
+	}); err != nil {
+		return Connection{}, err
+	}
+
+	return s.Status(ctx, userID)
+}
+
+// Disconnect forgets the user's channel. Uploads already running finish.
+func (s *Service) Disconnect(ctx context.Context, userID int64) error {
+	if err := s.accounts.Delete(ctx, userID); err != nil {
+		if errors.Is(err, youtubeaccounts.ErrNotFound) {
+			return ErrNotConnected
+		}
+		return err
+	}
+	return nil
+}
+
+// Send implements outbox.Sender for publications.PublishEvent. It checks that the requester
+// still has the target channel connected and uploads to it in the background. Requests that
+// cannot be uploaded are failed rather than retried. A nil error means the upload started, not
+// that it succeeded; errors before that point (reading the rows) are retried by the outbox.
+func (s *Service) Send(ctx context.Context, payload json.RawMessage) error {
+	var ev pubsvc.PublishEvent
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		return err
+	}
+
+	pub, err := s.publishes.GetByID(ctx, ev.PublishID)
+	if err != nil {
+		if errors.Is(err, pubrepo.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if pub.Platform != pubrepo.PlatformYouTube || pub.Status != "queued" {
+		return nil
+	}
+
+	clip, err := s.clips.GetByID(ctx, pub.ClipID)
+	if err != nil {
+		if errors.Is(err, clipsrepo.ErrNotFound) {
+			s.finish(pub.ID, failed("the clip was deleted"))
+			return nil
+		}
+		return err
+	}
+	if clip.ExportPath == nil || *clip.ExportPath == "" {
+		s.finish(pub.ID, failed("the clip has no export"))
+		return nil
+	}
+
+	userID := clip.UserID
+	if pub.RequestedBy != nil {
+		userID = *pub.RequestedBy
+	}
+
+	a, err := s.accounts.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, youtubeaccounts.ErrNotFound) {
+			s.finish(pub.ID, failed(failureMessage(ErrNotConnected)))
+			return nil
+		}
+		return err
+	}
+	if pub.ChannelID != nil && *pub.ChannelID != a.ChannelID {
+		s.finish(pub.ID, failed(fmt.Sprintf("channel %s is not connected", *pub.ChannelID)))
+		return nil
+	}
+
+	go s.run(userID, pub, clip)
+	return nil
+}
+
+// run uploads one dispatched publish and records the outcome. It outlives the delivery.
+func (s *Service) run(userID int64, pub pubrepo.Publication, clip clipsrepo.Clip) {
+	s.slots <- struct{}{}
+	defer func() { <-s.slots }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.uploadTimeout)
+	defer cancel()
+
+	videoID, err := s.upload(ctx, userID, pub, clip)
+	if err != nil {
+		log.Printf("youtube: upload of clip %d (publish %d) failed: %v", clip.ID, pub.ID, err)
+		s.finish(pub.ID, failed(failureMessage(err)))
+		return
+	}
+
+	now := time.Now().UTC()
+	s.finish(pub.ID, pubrepo.UpdateParams{
+		ExternalID:   &videoID,
+		URL:          strPtr("https://www.youtube.com/watch?v=" + videoID),
+		Status:       strPtr("uploaded"),
+		PublishedAt:  &now,
+		ErrorMessage: strPtr(""),
+	})
+}
+
+func (s *Service) finish(publishID int64, p pubrepo.UpdateParams) {
+	// A fresh context: the upload's may be what ran out.
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	pub, err := s.publishes.UpdateByID(ctx, publishID, p)
+	if err != nil {
+		log.Printf("youtube: recording publish %d failed: %v", publishID, err)
+		return
+	}
+	s.hooks.Emit(ctx, pub.WorkspaceID, webhookssvc.EventPublishUpdated, webhookssvc.NewPublicationData(pub))
+}
+
+func (s *Service) upload(ctx context.Context, userID int64, pub pubrepo.Publication, clip clipsrepo.Clip) (string, error) {
+	token, err := s.accessToken(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := s.clipFiles.LocalPath(ctx, *clip.ExportPath)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	video := youtube.Video{
+		Title:         clip.Title,
+		Tags:          pub.Tags,
+		PrivacyStatus: s.privacy,
+	}
+	if pub.Title != nil {
+		video.Title = *pub.Title
+	}
+	if pub.Description != nil {
+		video.Description = *pub.Description
+	}
+	if pub.Privacy != nil {
+		video.PrivacyStatus = *pub.Privacy
+	}
+	video.Title = truncate(video.Title, 100)
+	video.Description = truncate(video.Description, 5000)
+
+	return s.api.Upload(ctx, token, video, f, st.Size(), "video/mp4")
+}
+
+// accessToken returns a usable access token for the user's channel, refreshing it when it is
+// about to expire.
+func (s *Service) accessToken(ctx context.Context, userID int64) (string, error) {
+	a, err := s.accounts.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, youtubeaccounts.ErrNotFound) {
+			return "", ErrNotConnected
+		}
+		return "", err
+	}
+
+	if a.AccessTokenEnc != nil && a.AccessExpiresAt != nil && time.Until(*a.AccessExpiresAt) > time.Minute {
+		return s.secrets.Open(*a.AccessTokenEnc)
+	}
+
+	refresh, err := s.secrets.Open(a.RefreshTokenEnc)
+	if err != nil {
+		return "", err
+	}
+	tok, err := s.api.Refresh(ctx, refresh)
+	if err != nil {
+		return "", err
+	}
+
+	enc, err := s.secrets.Seal(tok.AccessToken)
+	if err != nil {
+		return "", err
+	}
+	if err := s.accounts.UpdateAccessToken(ctx, userID, enc, tok.Expiry); err != nil {
+		return "", err
+	}
+	return tok.AccessToken, nil
+}
+
+// Run fails publishes whose upload can no longer finish, such as those cut off by a restart,
+// every interval until ctx is cancelled.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		n, err := s.publishes.FailStale(ctx, pubrepo.PlatformYouTube, time.Now().Add(-s.uploadTimeout-time.Minute), "upload interrupted")
+		if err != nil {
+			log.Printf("youtube: failing stale publishes failed: %v", err)
+		} else if n > 0 {
+			log.Printf("youtube: marked %d interrupted uploads as failed", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// failureMessage is what the user sees on the failed publish.
+func failureMessage(err error) string {
+	switch {
+	case errors.Is(err, ErrNotConnected):
+		return "no YouTube channel connected"
+	case errors.Is(err, youtube.ErrRevoked):
+		return "YouTube access was revoked; connect the channel again"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "upload timed out"
+	}
+	return truncate(err.Error(), 500)
+}
+
+func newAuthorization() (state string, verifier string, challenge string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	verifier, challenge, err = oidc.NewPKCE()
+	if err != nil {
+		return "", "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), verifier, challenge, nil
+}
+
+// hashState is what oauth_states keeps instead of the state itself.
+func hashState(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncate(s string, max int) string {
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
+
+func failed(message string) pubrepo.UpdateParams {
+	return pubrepo.UpdateParams{
+		Status:       strPtr("failed"),
+		ErrorMessage: strPtr(message),
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func toConnection(a youtubeaccounts.Account) Connection {
+	return Connection{
+		Connected:    true,
+		ChannelID:    a.ChannelID,
+		ChannelTitle: a.ChannelTitle,
+		ConnectedAt:  &a.CreatedAt,
+	}
+}
@@ -0,0 +1,15 @@
+package youtubepublisher
+
+import "time"
+
+// Connection is the user's link to a YouTube channel.
+type Connection struct {
+	Connected    bool       `json:"connected"`
+	ChannelID    string     `json:"channel_id,omitempty"`
+	ChannelTitle string     `json:"channel_title,omitempty"`
+	ConnectedAt  *time.Time `json:"connected_at,omitempty"`
+}
+
+type ConnectStart struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache gives tools that need a real file (ffmpeg, the clipper) a local copy of an object.
+// Objects in a FileStore are used in place; remote objects are downloaded once and reused.
+// Downloads of different keys run in parallel; callers asking for a key that is already being
+// downloaded wait for that download instead of starting another.
+type Cache struct {
+	store    BlobStore
+	dir      string
+	maxBytes int64
+
+	// mu guards fetches and the cache directory bookkeeping (hits, eviction, pruning). It is
+	// never held while bytes are transferred.
+	mu      sync.Mutex
+	fetches map[string]*fetch
+}
+
+// fetch is a download in progress. done is closed once path or err is set.
+type fetch struct {
+	done chan struct{}
+	path string
+	err  error
+}
+
+// fetchPrefix names the temporary files of downloads in progress, which pruning leaves alone.
+const fetchPrefix = ".fetch-"
+
+func NewCache(store BlobStore, dir string, maxBytes int64) *Cache {
+	return &Cache{
+		store:    store,
+		dir:      dir,
+		maxBytes: maxBytes,
+		fetches:  make(map[string]*fetch),
+	}
+}
+
+// Store returns the store the cache reads from.
+func (c *Cache) Store() BlobStore {
+	return c.store
+}
+
+// LocalPath returns a local file holding the object's bytes.
+func (c *Cache) LocalPath(ctx context.Context, key string) (string, error) {
+	if fs, ok := c.store.(FileStore); ok {
+		p := fs.LocalPath(key)
+		if _, err := os.Stat(p); err != nil {
+			if os.IsNotExist(err) {
+				return "", ErrNotFound
+			}
+			return "", err
+		}
+		return p, nil
+	}
+
+	path := c.cachePath(key)
+
+	c.mu.Lock()
+	if f, ok := c.fetches[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.path, f.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	if _, err := os.Stat(path); err == nil {
+		now := time.Now()
+		_ = os.Chtimes(path, now, now)
+		c.mu.Unlock()
+		return path, nil
+	}
+	f := &fetch{done: make(chan struct{})}
+	c.fetches[key] = f
+	c.mu.Unlock()
+
+	// Waiters share this download, so it is cut short only if this caller gives up.
+	f.err = c.download(ctx, key, path)
+	if f.err == nil {
+		f.path = path
+	}
+
+	c.mu.Lock()
+	delete(c.fetches, key)
+	if f.err == nil {
+		c.prune(path)
+	}
+	c.mu.Unlock()
+	close(f.done)
+
+	return f.path, f.err
+}
+
+// download copies the object into a temporary file and renames it into place, so a reader never
+// sees a partial file under path.
+func (c *Cache) download(ctx context.Context, key string, path string) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+
+	body, _, err := c.store.Open(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	tmp, err := os.CreateTemp(c.dir, fetchPrefix+"*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (c *Cache) cachePath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+filepath.Ext(key))
+}
+
+// Evict drops a cached copy, e.g. after the object was replaced or deleted.
+func (c *Cache) Evict(key string) {
+	if _, ok := c.store.(FileStore); ok {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_ = os.Remove(c.cachePath(key))
+}
+
+// prune removes least recently used files until the cache fits in maxBytes. keep and downloads
+// in progress are never removed. c.mu must be held.
+func (c *Cache) prune(keep string) {
+	if c.maxBytes <= 0 {
+		return
+	}
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+
+	type file struct {
+		path string
+		size int64
+		mod  time.Time
+	}
+	var files []file
+	var total int64
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), fetchPrefix) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, file{path: filepath.Join(c.dir, e.Name()), size: info.Size(), mod: info.ModTime()})
+		total += info.Size()
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].mod.Before(files[j].mod) })
+	for _, f := range files {
+		if total <= c.maxBytes {
+			return
+		}
+		if f.path == keep {
+			continue
+		}
+		if err := os.Remove(f.path); err == nil {
+			total -= f.size
+		}
+	}
+}
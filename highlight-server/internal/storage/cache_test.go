@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gatedStore is a remote store whose downloads of "slow.mp4" block until release is closed.
+type gatedStore struct {
+	BlobStore
+	release chan struct{}
+
+	mu    sync.Mutex
+	opens map[string]int
+}
+
+func (g *gatedStore) Open(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error) {
+	g.mu.Lock()
+	g.opens[key]++
+	g.mu.Unlock()
+
+	if key == "slow.mp4" {
+		select {
+		case <-g.release:
+		case <-ctx.Done():
+			return nil, ObjectInfo{}, ctx.Err()
+		}
+	}
+	return io.NopCloser(strings.NewReader("bytes of " + key)), ObjectInfo{}, nil
+}
+
+func TestCacheDownloadsEachKeyOnce(t *testing.T) {
+	store := &gatedStore{release: make(chan struct{}), opens: map[string]int{}}
+	c := NewCache(store, t.TempDir(), 0)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	paths := make([]string, 5)
+	for i := range paths {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p, err := c.LocalPath(ctx, "slow.mp4")
+			if err != nil {
+				t.Errorf("LocalPath: %v", err)
+			}
+			paths[i] = p
+		}(i)
+	}
+
+	// Another key is not held up by the slow download.
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.LocalPath(ctx, "fast.mp4")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("LocalPath fast.mp4: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("fast.mp4 waited for slow.mp4")
+	}
+
+	close(store.release)
+	wg.Wait()
+
+	if store.opens["slow.mp4"] != 1 {
+		t.Fatalf("expected one download of slow.mp4, got %d", store.opens["slow.mp4"])
+	}
+	for _, p := range paths {
+		if p != paths[0] {
+			t.Fatalf("expected every caller to get the same file, got %v", paths)
+		}
+	}
+	b, err := os.ReadFile(paths[0])
+	if err != nil || string(b) != "bytes of slow.mp4" {
+		t.Fatalf("unexpected cached file %q: %v", b, err)
+	}
+
+	// Later calls are served from the cache.
+	if _, err := c.LocalPath(ctx, "slow.mp4"); err != nil {
+		t.Fatal(err)
+	}
+	if store.opens["slow.mp4"] != 1 {
+		t.Fatalf("expected a cache hit, got %d downloads", store.opens["slow.mp4"])
+	}
+}
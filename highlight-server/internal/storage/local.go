@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LocalStore keeps objects as files under a root directory.
+type LocalStore struct {
+	root string
+}
+
+func NewLocal(root string) *LocalStore {
+	return &LocalStore{root: root}
+}
+
+// LocalPath maps a key to its file. Migration 000029 turned the absolute paths of rows written
+// before keys were introduced into keys; an absolute path that still shows up (say, a row
+// restored from an old backup) is used as-is.
+func (s *LocalStore) LocalPath(key string) string {
+	if filepath.IsAbs(key) || filepath.VolumeName(key) != "" {
+		return key
+	}
+	return filepath.Join(s.root, filepath.FromSlash(cleanKey(key)))
+}
+
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	full := s.LocalPath(key)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(full), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), full)
+}
+
+func (s *LocalStore) Open(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error) {
+	f, err := os.Open(s.LocalPath(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ObjectInfo{}, ErrNotFound
+		}
+		return nil, ObjectInfo{}, err
+	}
+
+	st, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, ObjectInfo{}, err
+	}
+
+	return f, ObjectInfo{
+		Size:        st.Size(),
+		ModTime:     st.ModTime(),
+		ContentType: mime.TypeByExtension(filepath.Ext(key)),
+	}, nil
+}
+
+func (s *LocalStore) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	st, err := os.Stat(s.LocalPath(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ObjectInfo{}, ErrNotFound
+		}
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{
+		Size:        st.Size(),
+		ModTime:     st.ModTime(),
+		ContentType: mime.TypeByExtension(filepath.Ext(key)),
+	}, nil
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	err := os.Remove(s.LocalPath(key))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStore) PresignGet(ctx context.Context, key string, ttl time.Duration, downloadName string) (string, error) {
+	return "", ErrPresignUnsupported
+}
+
+// PutFile stores a local file under key. On a LocalStore the file is moved into place
+// when possible; otherwise it is streamed and the source is left for the caller to remove.
+func PutFile(ctx context.Context, store BlobStore, key string, path string, contentType string) error {
+	if fs, ok := store.(FileStore); ok {
+		dst := fs.LocalPath(key)
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		if err := os.Rename(path, dst); err == nil {
+			return nil
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	st, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := store.Put(ctx, key, f, st.Size(), contentType); err != nil {
+		return fmt.Errorf("store %q: %w", key, err)
+	}
+	return nil
+}
+
+func cleanKey(key string) string {
+	key = strings.ReplaceAll(key, "\\", "/")
+	key = strings.TrimLeft(key, "/")
+	// Drop any parent-dir segments so keys cannot escape the root.
+	parts := strings.Split(key, "/")
+	out := parts[:0]
+	for _, p := range parts {
+		if p == "" || p == "." || p == ".." {
+			continue
+		}
+		out = append(out, p)
+	}
+	return strings.Join(out, "/")
+}
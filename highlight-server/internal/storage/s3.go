@@ -0,0 +1,345 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3Config configures an S3-compatible bucket (AWS S3, MinIO, R2, ...).
+type S3Config struct {
+	Endpoint  string // e.g. https://s3.eu-west-1.amazonaws.com or http://127.0.0.1:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle addresses objects as endpoint/bucket/key instead of bucket.endpoint/key.
+	// MinIO and most self-hosted servers need this.
+	PathStyle bool
+	// Prefix is prepended to every key, so several stores can share one bucket.
+	Prefix string
+	// Presign enables PresignGet; when false downloads are streamed through the server.
+	Presign bool
+}
+
+// S3Store talks to an S3-compatible API using SigV4-signed requests.
+type S3Store struct {
+	cfg      S3Config
+	endpoint *url.URL
+	http     *http.Client
+	now      func() time.Time
+}
+
+func NewS3(cfg S3Config) (*S3Store, error) {
+	u, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("storage: invalid s3 endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("storage: s3 bucket is required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+
+	return &S3Store{
+		cfg:      cfg,
+		endpoint: u,
+		http: &http.Client{
+			// Large uploads/downloads are bounded by the request context instead.
+			Timeout: 0,
+		},
+		now: time.Now,
+	}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req)
+
+	res, err := s.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("s3 put: %w", err)
+	}
+	defer res.Body.Close()
+
+	return checkResponse(res)
+}
+
+func (s *S3Store) Open(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error) {
+	body, _, h, err := s.OpenRange(ctx, key, "")
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	return body, infoFromHeader(h), nil
+}
+
+// OpenRange fetches an object, passing an HTTP Range header through when set. It returns the
+// backend status (200 or 206) and headers so they can be relayed to a client.
+func (s *S3Store) OpenRange(ctx context.Context, key string, rangeHeader string) (io.ReadCloser, int, http.Header, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if rangeHeader != "" {
+		req.Header.Set("Range", rangeHeader)
+	}
+	s.sign(req)
+
+	res, err := s.http.Do(req)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("s3 get: %w", err)
+	}
+	if err := checkResponse(res); err != nil {
+		res.Body.Close()
+		return nil, 0, nil, err
+	}
+
+	return res.Body, res.StatusCode, res.Header, nil
+}
+
+func (s *S3Store) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	req, err := s.newRequest(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	s.sign(req)
+
+	res, err := s.http.Do(req)
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("s3 head: %w", err)
+	}
+	defer res.Body.Close()
+
+	if err := checkResponse(res); err != nil {
+		return ObjectInfo{}, err
+	}
+	return infoFromHeader(res.Header), nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	s.sign(req)
+
+	res, err := s.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("s3 delete: %w", err)
+	}
+	defer res.Body.Close()
+
+	err = checkResponse(res)
+	if err == ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+func (s *S3Store) PresignGet(ctx context.Context, key string, ttl time.Duration, downloadName string) (string, error) {
+	if !s.cfg.Presign {
+		return "", ErrPresignUnsupported
+	}
+	if ttl <= 0 || ttl > 7*24*time.Hour {
+		ttl = 15 * time.Minute
+	}
+
+	u := s.objectURL(key)
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := s.scope(now)
+
+	q := url.Values{}
+	q.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	q.Set("X-Amz-Credential", s.cfg.AccessKey+"/"+scope)
+	q.Set("X-Amz-Date", amzDate)
+	q.Set("X-Amz-Expires", strconv.Itoa(int(ttl.Seconds())))
+	q.Set("X-Amz-SignedHeaders", "host")
+	if downloadName != "" {
+		q.Set("response-content-disposition", `attachment; filename="`+downloadName+`"`)
+	}
+
+	canonical := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		canonicalQuery(q),
+		"host:" + u.Host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+
+	q.Set("X-Amz-Signature", s.signature(now, amzDate, scope, canonical))
+	u.RawQuery = canonicalQuery(q)
+	return u.String(), nil
+}
+
+func (s *S3Store) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	u := s.objectURL(key)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func (s *S3Store) objectURL(key string) *url.URL {
+	objectKey := cleanKey(strings.TrimRight(s.cfg.Prefix, "/") + "/" + key)
+
+	u := *s.endpoint
+	if s.cfg.PathStyle {
+		u.Path = "/" + s.cfg.Bucket + "/" + objectKey
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+		u.Path = "/" + objectKey
+	}
+	u.RawPath = uriEncode(u.Path, false)
+	return &u
+}
+
+// sign adds SigV4 headers to req. Payloads are sent unsigned so large bodies can stream.
+func (s *S3Store) sign(req *http.Request) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := s.scope(now)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": "UNSIGNED-PAYLOAD",
+		"x-amz-date":           amzDate,
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "" {
+		headers["content-type"] = ct
+	}
+	if rg := req.Header.Get("Range"); rg != "" {
+		headers["range"] = rg
+	}
+
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonHeaders strings.Builder
+	for _, k := range names {
+		canonHeaders.WriteString(k + ":" + strings.TrimSpace(headers[k]) + "\n")
+	}
+	signed := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonHeaders.String(),
+		signed,
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+
+	sig := s.signature(now, amzDate, scope, canonical)
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKey, scope, signed, sig,
+	))
+}
+
+func (s *S3Store) scope(t time.Time) string {
+	return t.Format("20060102") + "/" + s.cfg.Region + "/s3/aws4_request"
+}
+
+func (s *S3Store) signature(t time.Time, amzDate, scope, canonical string) string {
+	sum := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+
+	k := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), t.Format("20060102"))
+	k = hmacSHA256(k, s.cfg.Region)
+	k = hmacSHA256(k, "s3")
+	k = hmacSHA256(k, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(k, toSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(data))
+	return m.Sum(nil)
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		vals := append([]string(nil), q[k]...)
+		sort.Strings(vals)
+		for _, v := range vals {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode implements the SigV4 flavour of percent-encoding.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func checkResponse(res *http.Response) error {
+	if res.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if res.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 8<<10))
+		if len(b) > 0 {
+			return fmt.Errorf("s3 returned status %d: %s", res.StatusCode, string(b))
+		}
+		return fmt.Errorf("s3 returned status %d", res.StatusCode)
+	}
+	return nil
+}
+
+func infoFromHeader(h http.Header) ObjectInfo {
+	info := ObjectInfo{ContentType: h.Get("Content-Type")}
+	if n, err := strconv.ParseInt(h.Get("Content-Length"), 10, 64); err == nil {
+		info.Size = n
+	}
+	if t, err := http.ParseTime(h.Get("Last-Modified")); err == nil {
+		info.ModTime = t
+	}
+	return info
+}
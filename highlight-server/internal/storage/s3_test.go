@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 is a minimal in-memory stand-in for a path-style S3 server such as MinIO.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		b, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = b
+	case http.MethodGet, http.MethodHead:
+		b, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(b))
+	case http.MethodDelete:
+		if _, ok := f.objects[r.URL.Path]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newTestS3(t *testing.T) (*S3Store, *fakeS3) {
+	t.Helper()
+
+	fake := &fakeS3{objects: map[string][]byte{}}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	s, err := NewS3(S3Config{
+		Endpoint:  srv.URL,
+		Bucket:    "media",
+		AccessKey: "key",
+		SecretKey: "secret",
+		PathStyle: true,
+		Prefix:    "clips",
+		Presign:   true,
+	})
+	if err != nil {
+		t.Fatalf("NewS3: %v", err)
+	}
+	return s, fake
+}
+
+func TestS3RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s, fake := newTestS3(t)
+
+	data := []byte("not really an mp4")
+	if err := s.Put(ctx, "clip_1.mp4", bytes.NewReader(data), int64(len(data)), "video/mp4"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, ok := fake.objects["/media/clips/clip_1.mp4"]; !ok {
+		t.Fatalf("object not stored under bucket/prefix, have %v", fake.objects)
+	}
+
+	info, err := s.Stat(ctx, "clip_1.mp4")
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Size != int64(len(data)) {
+		t.Fatalf("expected size %d, got %d", len(data), info.Size)
+	}
+
+	body, _, err := s.Open(ctx, "clip_1.mp4")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	got, _ := io.ReadAll(body)
+	body.Close()
+	if !bytes.Equal(got, data) {
+		t.Fatalf("expected %q, got %q", data, got)
+	}
+
+	if err := s.Delete(ctx, "clip_1.mp4"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Stat(ctx, "clip_1.mp4"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestS3StreamRange(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestS3(t)
+
+	data := []byte("0123456789")
+	if err := s.Put(ctx, "clip_2.mp4", bytes.NewReader(data), int64(len(data)), "video/mp4"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/clips/2/download", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	rr := httptest.NewRecorder()
+
+	if err := Stream(rr, req, s, "clip_2.mp4", "clip_2.mp4"); err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if rr.Code != http.StatusPartialContent {
+		t.Fatalf("expected 206, got %d", rr.Code)
+	}
+	if rr.Body.String() != "2345" {
+		t.Fatalf("expected range body, got %q", rr.Body.String())
+	}
+	if rr.Header().Get("Content-Range") != "bytes 2-5/10" {
+		t.Fatalf("unexpected Content-Range %q", rr.Header().Get("Content-Range"))
+	}
+}
+
+func TestS3PresignGet(t *testing.T) {
+	s, _ := newTestS3(t)
+
+	u, err := s.PresignGet(context.Background(), "clip_3.mp4", time.Minute, "clip_3.mp4")
+	if err != nil {
+		t.Fatalf("PresignGet: %v", err)
+	}
+	for _, want := range []string{"/media/clips/clip_3.mp4?", "X-Amz-Expires=60", "X-Amz-Signature="} {
+		if !strings.Contains(u, want) {
+			t.Fatalf("expected %q in presigned url %q", want, u)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// PresignTTL is how long redirect URLs handed out by Serve stay valid.
+const PresignTTL = 15 * time.Minute
+
+// Serve sends an object to the client as an attachment. Stores that can presign redirect the
+// client to the backend; everything else is streamed through the server with Range support.
+func Serve(w http.ResponseWriter, r *http.Request, store BlobStore, key string, name string) error {
+	if u, err := store.PresignGet(r.Context(), key, PresignTTL, name); err == nil {
+		http.Redirect(w, r, u, http.StatusFound)
+		return nil
+	} else if !errors.Is(err, ErrPresignUnsupported) {
+		return err
+	}
+
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+name+"\"")
+	return Stream(w, r, store, key, name)
+}
+
+// Stream writes an object to w, honouring Range requests.
+func Stream(w http.ResponseWriter, r *http.Request, store BlobStore, key string, name string) error {
+	// Remote stores can serve byte ranges themselves; pass the header through.
+	if s3, ok := store.(*S3Store); ok {
+		body, status, h, err := s3.OpenRange(r.Context(), key, r.Header.Get("Range"))
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+
+		for _, k := range []string{"Content-Type", "Content-Length", "Content-Range", "Last-Modified", "ETag"} {
+			if v := h.Get(k); v != "" {
+				w.Header().Set(k, v)
+			}
+		}
+		w.Header().Set("Accept-Ranges", "bytes")
+		w.WriteHeader(status)
+		_, _ = io.Copy(w, body)
+		return nil
+	}
+
+	body, info, err := store.Open(r.Context(), key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if rs, ok := body.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, name, info.ModTime, rs)
+		return nil
+	}
+
+	if info.ContentType != "" {
+		w.Header().Set("Content-Type", info.ContentType)
+	}
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, body)
+	return nil
+}
@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrNotFound           = errors.New("storage: object not found")
+	ErrPresignUnsupported = errors.New("storage: presigned urls not supported")
+)
+
+// ObjectInfo describes a stored object.
+type ObjectInfo struct {
+	Size        int64
+	ModTime     time.Time
+	ContentType string
+}
+
+// BlobStore stores recordings and exports by key. Keys are relative, slash-separated names;
+// the DB stores keys, never backend-specific paths.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns the object body. Local stores return an io.ReadSeeker so callers can serve ranges.
+	Open(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error)
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	// PresignGet returns a time-limited URL the client can download from directly,
+	// or ErrPresignUnsupported.
+	PresignGet(ctx context.Context, key string, ttl time.Duration, downloadName string) (string, error)
+}
+
+// FileStore is implemented by stores that keep objects on the local filesystem,
+// so tools like ffmpeg can read them in place.
+type FileStore interface {
+	LocalPath(key string) string
+}
@@ -0,0 +1,51 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B, SHA1 vectors (truncated to 6 digits).
+func TestCodeRFC6238(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	cases := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tc := range cases {
+		got, err := Code(secret, Step(time.Unix(tc.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code: %v", err)
+		}
+		if got != tc.want {
+			t.Fatalf("at %d: expected %s, got %s", tc.unix, tc.want, got)
+		}
+	}
+}
+
+func TestValidateAcceptsAdjacentStep(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+
+	prev, _ := Code(secret, Step(now)-1)
+	if step, ok := Validate(secret, prev, now); !ok || step != Step(now)-1 {
+		t.Fatalf("expected previous step code to validate, got ok=%v step=%d", ok, step)
+	}
+
+	old, _ := Code(secret, Step(now)-3)
+	if _, ok := Validate(secret, old, now); ok {
+		t.Fatalf("expected code from three steps ago to be rejected")
+	}
+}
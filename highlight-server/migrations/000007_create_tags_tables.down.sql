@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS recording_tags;
+DROP TABLE IF EXISTS clip_tags;
+DROP TABLE IF EXISTS tags;
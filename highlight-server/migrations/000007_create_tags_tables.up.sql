@@ -0,0 +1,48 @@
+CREATE TABLE tags (
+  id INT NOT NULL AUTO_INCREMENT,
+  user_id INT NOT NULL,
+
+  name VARCHAR(40) NOT NULL,
+
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (id),
+
+  UNIQUE KEY uq_tags_user_name (user_id, name),
+
+  CONSTRAINT fk_tags_user
+    FOREIGN KEY (user_id) REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE clip_tags (
+  clip_id INT NOT NULL,
+  tag_id INT NOT NULL,
+
+  PRIMARY KEY (clip_id, tag_id),
+  KEY idx_clip_tags_tag_id (tag_id),
+
+  CONSTRAINT fk_clip_tags_clip
+    FOREIGN KEY (clip_id) REFERENCES clips(id)
+    ON DELETE CASCADE,
+
+  CONSTRAINT fk_clip_tags_tag
+    FOREIGN KEY (tag_id) REFERENCES tags(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE recording_tags (
+  recording_id INT NOT NULL,
+  tag_id INT NOT NULL,
+
+  PRIMARY KEY (recording_id, tag_id),
+  KEY idx_recording_tags_tag_id (tag_id),
+
+  CONSTRAINT fk_recording_tags_recording
+    FOREIGN KEY (recording_id) REFERENCES recordings(id)
+    ON DELETE CASCADE,
+
+  CONSTRAINT fk_recording_tags_tag
+    FOREIGN KEY (tag_id) REFERENCES tags(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
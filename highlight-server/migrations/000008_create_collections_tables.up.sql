@@ -0,0 +1,42 @@
+CREATE TABLE collections (
+  id INT NOT NULL AUTO_INCREMENT,
+  user_id INT NOT NULL,
+
+  title VARCHAR(120) NOT NULL,
+  description TEXT NULL,
+
+  export_path VARCHAR(255) NULL,
+  youtube_playlist_id VARCHAR(64) NULL,
+
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (id),
+
+  KEY idx_collections_user_id (user_id),
+
+  CONSTRAINT fk_collections_user
+    FOREIGN KEY (user_id) REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE collection_clips (
+  collection_id INT NOT NULL,
+  clip_id INT NOT NULL,
+
+  position INT NOT NULL DEFAULT 0,
+
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (collection_id, clip_id),
+  KEY idx_collection_clips_clip_id (clip_id),
+  KEY idx_collection_clips_position (collection_id, position),
+
+  CONSTRAINT fk_collection_clips_collection
+    FOREIGN KEY (collection_id) REFERENCES collections(id)
+    ON DELETE CASCADE,
+
+  CONSTRAINT fk_collection_clips_clip
+    FOREIGN KEY (clip_id) REFERENCES clips(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,10 @@
+DELETE FROM clips WHERE deleted_at IS NOT NULL;
+DELETE FROM recordings WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE clips
+  DROP KEY idx_clips_deleted_at,
+  DROP COLUMN deleted_at;
+
+ALTER TABLE recordings
+  DROP KEY idx_recordings_deleted_at,
+  DROP COLUMN deleted_at;
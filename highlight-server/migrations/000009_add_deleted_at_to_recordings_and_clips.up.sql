@@ -0,0 +1,7 @@
+ALTER TABLE recordings
+  ADD COLUMN deleted_at DATETIME NULL AFTER status,
+  ADD KEY idx_recordings_deleted_at (deleted_at);
+
+ALTER TABLE clips
+  ADD COLUMN deleted_at DATETIME NULL AFTER export_path,
+  ADD KEY idx_clips_deleted_at (deleted_at);
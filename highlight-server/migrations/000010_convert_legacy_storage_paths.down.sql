@@ -0,0 +1,2 @@
+-- The original directories are not recorded; keys keep working with the local store.
+SELECT 1;
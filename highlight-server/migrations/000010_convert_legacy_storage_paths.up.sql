@@ -0,0 +1,15 @@
+-- Rows written before object keys held absolute (or working-directory relative) file paths such
+-- as /data/recordings/<uuid>_<name>.mp4 or D:\recordings\clip_7.mp4. Those files were always
+-- written straight into the recordings or clips directory, which is the root of the local store,
+-- so the key is the file name. For an S3 backend, upload the files under that same name.
+UPDATE recordings
+SET storage_path = SUBSTRING_INDEX(SUBSTRING_INDEX(storage_path, '\\', -1), '/', -1)
+WHERE LOCATE('/', storage_path) > 0 OR LOCATE('\\', storage_path) > 0;
+
+UPDATE clips
+SET export_path = SUBSTRING_INDEX(SUBSTRING_INDEX(export_path, '\\', -1), '/', -1)
+WHERE LOCATE('/', export_path) > 0 OR LOCATE('\\', export_path) > 0;
+
+UPDATE collections
+SET export_path = SUBSTRING_INDEX(SUBSTRING_INDEX(export_path, '\\', -1), '/', -1)
+WHERE LOCATE('/', export_path) > 0 OR LOCATE('\\', export_path) > 0;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS user_quotas;
+DROP TABLE IF EXISTS detection_usage;
+DROP TABLE IF EXISTS user_usage;
+
+ALTER TABLE clips
+  DROP COLUMN export_bytes;
+
+ALTER TABLE recordings
+  DROP COLUMN size_bytes;
@@ -0,0 +1,54 @@
+-- Sizes are recorded per row so usage can be given back when files are purged.
+-- Rows that existed before this migration start at 0 and do not count towards usage.
+-- Usage only counts items outside the trash: trashing gives the bytes back, restoring charges them.
+ALTER TABLE recordings
+  ADD COLUMN size_bytes BIGINT NOT NULL DEFAULT 0 AFTER duration_seconds;
+
+ALTER TABLE clips
+  ADD COLUMN export_bytes BIGINT NOT NULL DEFAULT 0 AFTER export_path;
+
+CREATE TABLE user_usage (
+  user_id INT NOT NULL,
+
+  recording_bytes BIGINT NOT NULL DEFAULT 0,
+  export_bytes BIGINT NOT NULL DEFAULT 0,
+
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (user_id),
+
+  CONSTRAINT fk_user_usage_user
+    FOREIGN KEY (user_id) REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE detection_usage (
+  user_id INT NOT NULL,
+  month CHAR(7) NOT NULL, -- YYYY-MM (UTC)
+
+  seconds INT NOT NULL DEFAULT 0,
+
+  PRIMARY KEY (user_id, month),
+
+  CONSTRAINT fk_detection_usage_user
+    FOREIGN KEY (user_id) REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Per-user overrides. NULL columns (or no row) fall back to the configured defaults; 0 means unlimited.
+CREATE TABLE user_quotas (
+  user_id INT NOT NULL,
+
+  recording_bytes BIGINT NULL,
+  export_bytes BIGINT NULL,
+  detection_minutes INT NULL,
+
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (user_id),
+
+  CONSTRAINT fk_user_quotas_user
+    FOREIGN KEY (user_id) REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,47 @@
+-- One row per issued refresh token. Rotation creates a new row in the same family; presenting
+-- an already rotated token revokes the whole family.
+CREATE TABLE refresh_tokens (
+  id INT NOT NULL AUTO_INCREMENT,
+
+  user_id INT NOT NULL,
+  family_id CHAR(36) NOT NULL,
+
+  token_hash CHAR(64) NOT NULL, -- sha256 hex of the opaque token
+
+  access_jti CHAR(36) NOT NULL, -- access token issued alongside this refresh token
+  access_expires_at DATETIME NOT NULL,
+
+  expires_at DATETIME NOT NULL,
+  rotated_at DATETIME NULL,
+  revoked_at DATETIME NULL,
+
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (id),
+
+  UNIQUE KEY uq_refresh_tokens_hash (token_hash),
+  KEY idx_refresh_tokens_user_id (user_id),
+  KEY idx_refresh_tokens_family_id (family_id),
+  KEY idx_refresh_tokens_expires_at (expires_at),
+
+  CONSTRAINT fk_refresh_tokens_user
+    FOREIGN KEY (user_id) REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Access tokens (by jti) that must be rejected before they expire.
+CREATE TABLE revoked_tokens (
+  jti CHAR(36) NOT NULL,
+
+  user_id INT NOT NULL,
+  expires_at DATETIME NOT NULL,
+
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (jti),
+  KEY idx_revoked_tokens_expires_at (expires_at),
+
+  CONSTRAINT fk_revoked_tokens_user
+    FOREIGN KEY (user_id) REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
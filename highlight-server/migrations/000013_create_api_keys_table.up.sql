@@ -0,0 +1,25 @@
+CREATE TABLE api_keys (
+  id INT NOT NULL AUTO_INCREMENT,
+
+  user_id INT NOT NULL,
+
+  name VARCHAR(60) NOT NULL,
+  prefix VARCHAR(16) NOT NULL, -- shown to the user to tell keys apart
+  key_hash CHAR(64) NOT NULL, -- sha256 hex of the full key
+
+  scopes VARCHAR(500) NOT NULL, -- comma separated, e.g. recordings:write,clips:read
+
+  last_used_at DATETIME NULL,
+  revoked_at DATETIME NULL,
+
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (id),
+
+  UNIQUE KEY uq_api_keys_hash (key_hash),
+  KEY idx_api_keys_user_id (user_id),
+
+  CONSTRAINT fk_api_keys_user
+    FOREIGN KEY (user_id) REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
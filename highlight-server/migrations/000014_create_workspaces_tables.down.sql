@@ -0,0 +1,15 @@
+ALTER TABLE youtube_publishes
+  DROP FOREIGN KEY fk_youtube_workspace,
+  DROP COLUMN workspace_id;
+
+ALTER TABLE clips
+  DROP FOREIGN KEY fk_clips_workspace,
+  DROP COLUMN workspace_id;
+
+ALTER TABLE recordings
+  DROP FOREIGN KEY fk_recordings_workspace,
+  DROP COLUMN workspace_id;
+
+DROP TABLE IF EXISTS workspace_invitations;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
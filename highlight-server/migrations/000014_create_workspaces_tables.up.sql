@@ -0,0 +1,121 @@
+CREATE TABLE workspaces (
+  id INT NOT NULL AUTO_INCREMENT,
+
+  uuid CHAR(36) NOT NULL,
+  name VARCHAR(80) NOT NULL,
+
+  owner_user_id INT NOT NULL,
+  -- 1 for the user's personal workspace, NULL otherwise; the unique key allows one per owner
+  personal TINYINT(1) NULL,
+
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (id),
+
+  UNIQUE KEY uq_workspaces_uuid (uuid),
+  UNIQUE KEY uq_workspaces_personal (owner_user_id, personal),
+
+  CONSTRAINT fk_workspaces_owner
+    FOREIGN KEY (owner_user_id) REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE workspace_members (
+  workspace_id INT NOT NULL,
+  user_id INT NOT NULL,
+
+  role ENUM('owner','editor','viewer') NOT NULL,
+
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (workspace_id, user_id),
+  KEY idx_workspace_members_user (user_id),
+
+  CONSTRAINT fk_workspace_members_workspace
+    FOREIGN KEY (workspace_id) REFERENCES workspaces(id)
+    ON DELETE CASCADE,
+
+  CONSTRAINT fk_workspace_members_user
+    FOREIGN KEY (user_id) REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE workspace_invitations (
+  id INT NOT NULL AUTO_INCREMENT,
+
+  workspace_id INT NOT NULL,
+  email VARCHAR(255) NOT NULL,
+  role ENUM('editor','viewer') NOT NULL,
+
+  token_hash CHAR(64) NOT NULL, -- sha256 hex of the invitation token
+  invited_by INT NOT NULL,
+
+  expires_at DATETIME NOT NULL,
+  accepted_at DATETIME NULL,
+
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (id),
+
+  UNIQUE KEY uq_workspace_invitations_hash (token_hash),
+  KEY idx_workspace_invitations_workspace (workspace_id),
+
+  CONSTRAINT fk_workspace_invitations_workspace
+    FOREIGN KEY (workspace_id) REFERENCES workspaces(id)
+    ON DELETE CASCADE,
+
+  CONSTRAINT fk_workspace_invitations_inviter
+    FOREIGN KEY (invited_by) REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Give every existing user a personal workspace and move their content into it.
+-- user_id stays on recordings and clips as the uploader/creator.
+INSERT INTO workspaces (uuid, name, owner_user_id, personal)
+SELECT UUID(), 'Personal', id, 1 FROM users;
+
+INSERT INTO workspace_members (workspace_id, user_id, role)
+SELECT id, owner_user_id, 'owner' FROM workspaces WHERE personal = 1;
+
+ALTER TABLE recordings
+  ADD COLUMN workspace_id INT NULL AFTER user_id;
+
+UPDATE recordings r
+JOIN workspaces w ON w.owner_user_id = r.user_id AND w.personal = 1
+SET r.workspace_id = w.id;
+
+ALTER TABLE recordings
+  MODIFY COLUMN workspace_id INT NOT NULL,
+  ADD KEY idx_recordings_workspace_id (workspace_id),
+  ADD CONSTRAINT fk_recordings_workspace
+    FOREIGN KEY (workspace_id) REFERENCES workspaces(id)
+    ON DELETE CASCADE;
+
+ALTER TABLE clips
+  ADD COLUMN workspace_id INT NULL AFTER user_id;
+
+UPDATE clips c
+JOIN recordings r ON r.id = c.recording_id
+SET c.workspace_id = r.workspace_id;
+
+ALTER TABLE clips
+  MODIFY COLUMN workspace_id INT NOT NULL,
+  ADD KEY idx_clips_workspace_id (workspace_id),
+  ADD CONSTRAINT fk_clips_workspace
+    FOREIGN KEY (workspace_id) REFERENCES workspaces(id)
+    ON DELETE CASCADE;
+
+ALTER TABLE youtube_publishes
+  ADD COLUMN workspace_id INT NULL AFTER clip_id;
+
+UPDATE youtube_publishes yp
+JOIN clips c ON c.id = yp.clip_id
+SET yp.workspace_id = c.workspace_id;
+
+ALTER TABLE youtube_publishes
+  MODIFY COLUMN workspace_id INT NOT NULL,
+  ADD KEY idx_youtube_workspace_id (workspace_id),
+  ADD CONSTRAINT fk_youtube_workspace
+    FOREIGN KEY (workspace_id) REFERENCES workspaces(id)
+    ON DELETE CASCADE;
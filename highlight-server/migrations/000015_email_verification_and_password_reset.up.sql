@@ -0,0 +1,28 @@
+ALTER TABLE users
+  ADD COLUMN email_verified_at DATETIME NULL AFTER password_hash;
+
+-- Accounts created before verification existed keep working.
+UPDATE users SET email_verified_at = created_at;
+
+CREATE TABLE user_tokens (
+  id INT NOT NULL AUTO_INCREMENT,
+
+  user_id INT NOT NULL,
+  purpose ENUM('verify_email','password_reset','confirm') NOT NULL, -- confirm: mailed code for passwordless accounts
+  token_hash CHAR(64) NOT NULL, -- sha256 hex of the token sent by email
+
+  expires_at DATETIME NOT NULL,
+  used_at DATETIME NULL,
+
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (id),
+
+  UNIQUE KEY uq_user_tokens_hash (token_hash),
+  KEY idx_user_tokens_user_purpose (user_id, purpose),
+  KEY idx_user_tokens_expires_at (expires_at),
+
+  CONSTRAINT fk_user_tokens_user
+    FOREIGN KEY (user_id) REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
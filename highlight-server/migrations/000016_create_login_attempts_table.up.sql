@@ -0,0 +1,23 @@
+CREATE TABLE login_attempts (
+  id BIGINT NOT NULL AUTO_INCREMENT,
+
+  email VARCHAR(120) NOT NULL, -- as typed (lowercased), whether or not an account exists
+  ip VARCHAR(45) NOT NULL,
+  user_id INT NULL, -- set when the email belongs to an account
+
+  outcome ENUM('success','invalid_credentials','throttled') NOT NULL,
+  user_agent VARCHAR(255) NOT NULL DEFAULT '',
+
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (id),
+
+  KEY idx_login_attempts_email_created (email, created_at),
+  KEY idx_login_attempts_ip_created (ip, created_at),
+  KEY idx_login_attempts_user_id (user_id),
+  KEY idx_login_attempts_created_at (created_at),
+
+  CONSTRAINT fk_login_attempts_user
+    FOREIGN KEY (user_id) REFERENCES users(id)
+    ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,10 @@
+DELETE FROM login_attempts WHERE outcome = 'invalid_code';
+ALTER TABLE login_attempts
+  MODIFY outcome ENUM('success','invalid_credentials','throttled') NOT NULL;
+
+DELETE FROM user_tokens WHERE purpose = 'login_challenge';
+ALTER TABLE user_tokens
+  MODIFY purpose ENUM('verify_email','password_reset','confirm') NOT NULL;
+
+DROP TABLE IF EXISTS user_recovery_codes;
+DROP TABLE IF EXISTS user_totp;
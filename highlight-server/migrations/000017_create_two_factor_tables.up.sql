@@ -0,0 +1,42 @@
+CREATE TABLE user_totp (
+  user_id INT NOT NULL,
+
+  secret_enc VARCHAR(255) NOT NULL, -- AES-GCM sealed base32 secret
+  confirmed_at DATETIME NULL, -- 2FA is only enforced once the user proved their app works
+  last_used_step BIGINT NOT NULL DEFAULT 0, -- a code is accepted once; older steps are refused
+
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (user_id),
+
+  CONSTRAINT fk_user_totp_user
+    FOREIGN KEY (user_id) REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE user_recovery_codes (
+  id INT NOT NULL AUTO_INCREMENT,
+
+  user_id INT NOT NULL,
+  code_hash CHAR(64) NOT NULL, -- sha256 hex of the normalised code
+  used_at DATETIME NULL,
+
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (id),
+
+  UNIQUE KEY uq_user_recovery_codes (user_id, code_hash),
+
+  CONSTRAINT fk_user_recovery_codes_user
+    FOREIGN KEY (user_id) REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- The first login step hands out a short-lived challenge token instead of a session.
+ALTER TABLE user_tokens
+  MODIFY purpose ENUM('verify_email','password_reset','confirm','login_challenge') NOT NULL;
+
+-- Wrong second-factor codes count towards login throttling like wrong passwords.
+ALTER TABLE login_attempts
+  MODIFY outcome ENUM('success','invalid_credentials','invalid_code','throttled') NOT NULL;
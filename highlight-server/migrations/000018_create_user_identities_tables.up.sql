@@ -0,0 +1,44 @@
+-- Accounts at external identity providers (Google, Discord, Twitch, generic OIDC) linked to users.
+-- Users created through a provider have an empty password_hash until they set a password.
+CREATE TABLE user_identities (
+  id INT NOT NULL AUTO_INCREMENT,
+
+  user_id INT NOT NULL,
+  provider VARCHAR(32) NOT NULL,
+  subject VARCHAR(255) NOT NULL, -- the provider's stable user id
+  email VARCHAR(255) NULL, -- as reported by the provider when linked, for display
+
+  last_login_at DATETIME NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (id),
+
+  UNIQUE KEY uq_user_identities_subject (provider, subject),
+  UNIQUE KEY uq_user_identities_user (user_id, provider),
+
+  CONSTRAINT fk_user_identities_user
+    FOREIGN KEY (user_id) REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- One row per authorization request in flight; the state comes back with the code and is used once.
+CREATE TABLE oauth_states (
+  id INT NOT NULL AUTO_INCREMENT,
+
+  state_hash CHAR(64) NOT NULL, -- sha256 hex of the state parameter
+  provider VARCHAR(32) NOT NULL,
+  code_verifier VARCHAR(128) NOT NULL, -- PKCE verifier, sent with the code exchange
+  link_user_id INT NULL, -- set when a signed-in user links a provider instead of signing in
+
+  expires_at DATETIME NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (id),
+
+  UNIQUE KEY uq_oauth_states_hash (state_hash),
+  KEY idx_oauth_states_expires (expires_at),
+
+  CONSTRAINT fk_oauth_states_user
+    FOREIGN KEY (link_user_id) REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS youtube_accounts;
+
+DELETE FROM youtube_publishes WHERE youtube_video_id IS NULL;
+ALTER TABLE youtube_publishes
+  DROP COLUMN error_message,
+  MODIFY youtube_url VARCHAR(255) NOT NULL,
+  MODIFY youtube_video_id VARCHAR(32) NOT NULL;
@@ -0,0 +1,27 @@
+-- The native publisher creates a row before uploading, so the video id and url are unknown
+-- while it is queued, and keeps the reason when an upload fails.
+ALTER TABLE youtube_publishes
+  MODIFY youtube_video_id VARCHAR(32) NULL,
+  MODIFY youtube_url VARCHAR(255) NULL,
+  ADD COLUMN error_message VARCHAR(500) NULL AFTER status;
+
+-- A user's connected YouTube channel. Tokens are AES-GCM sealed.
+CREATE TABLE youtube_accounts (
+  user_id INT NOT NULL,
+
+  channel_id VARCHAR(64) NOT NULL,
+  channel_title VARCHAR(255) NOT NULL,
+
+  refresh_token_enc TEXT NOT NULL,
+  access_token_enc TEXT NULL,
+  access_expires_at DATETIME NULL,
+
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (user_id),
+
+  CONSTRAINT fk_youtube_accounts_user
+    FOREIGN KEY (user_id) REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,11 @@
+ALTER TABLE youtube_publishes
+  DROP FOREIGN KEY fk_youtube_publishes_requested_by,
+  DROP KEY idx_youtube_publishes_due,
+  DROP COLUMN dispatched_at,
+  DROP COLUMN requested_by,
+  DROP COLUMN publish_at,
+  DROP COLUMN channel_id,
+  DROP COLUMN privacy,
+  DROP COLUMN tags,
+  DROP COLUMN description,
+  DROP COLUMN title;
@@ -0,0 +1,18 @@
+-- Publishing is now an explicit request: the video's metadata and an optional schedule live on
+-- the row, and the scheduler hands due rows to the publisher, stamping dispatched_at.
+ALTER TABLE youtube_publishes
+  ADD COLUMN title VARCHAR(100) NULL AFTER error_message,
+  ADD COLUMN description TEXT NULL AFTER title,
+  ADD COLUMN tags JSON NULL AFTER description,
+  ADD COLUMN privacy ENUM('public','unlisted','private') NULL AFTER tags,
+  ADD COLUMN channel_id VARCHAR(64) NULL AFTER privacy,
+  ADD COLUMN publish_at DATETIME NULL AFTER channel_id,
+  ADD COLUMN requested_by INT NULL AFTER publish_at,
+  ADD COLUMN dispatched_at DATETIME NULL AFTER requested_by,
+  ADD KEY idx_youtube_publishes_due (status, dispatched_at, publish_at),
+  ADD CONSTRAINT fk_youtube_publishes_requested_by
+    FOREIGN KEY (requested_by) REFERENCES users(id)
+    ON DELETE SET NULL;
+
+-- Rows from before this migration were handed off when they were created.
+UPDATE youtube_publishes SET dispatched_at = created_at;
@@ -0,0 +1,20 @@
+-- Outgoing webhook events, written in the same transaction as the change they announce and
+-- delivered by the outbox dispatcher. Events that keep failing end up dead until replayed.
+CREATE TABLE outbox_events (
+  id BIGINT NOT NULL AUTO_INCREMENT,
+
+  topic VARCHAR(64) NOT NULL,
+  payload JSON NOT NULL,
+
+  status ENUM('pending','delivered','dead') NOT NULL DEFAULT 'pending',
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at DATETIME NOT NULL,
+  last_error VARCHAR(500) NULL,
+  delivered_at DATETIME NULL,
+
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (id),
+  KEY idx_outbox_events_due (status, next_attempt_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
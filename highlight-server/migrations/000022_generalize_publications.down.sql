@@ -0,0 +1,13 @@
+DELETE FROM publications WHERE platform <> 'youtube';
+
+ALTER TABLE publications
+  DROP INDEX idx_publications_platform,
+  DROP INDEX uq_publications_platform_external_id,
+  DROP COLUMN shares,
+  DROP COLUMN metadata,
+  CHANGE COLUMN url youtube_url VARCHAR(255) NULL,
+  CHANGE COLUMN external_id youtube_video_id VARCHAR(32) NULL,
+  DROP COLUMN platform,
+  ADD UNIQUE KEY uq_youtube_video_id (youtube_video_id);
+
+RENAME TABLE publications TO youtube_publishes;
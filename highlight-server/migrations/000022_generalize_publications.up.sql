@@ -0,0 +1,16 @@
+-- Publishing is no longer YouTube-only: youtube_publishes becomes publications, one row per clip
+-- and platform. Existing rows keep their ids and become YouTube publications.
+RENAME TABLE youtube_publishes TO publications;
+
+ALTER TABLE publications
+  ADD COLUMN platform ENUM('youtube','tiktok','instagram','x','twitch') NOT NULL DEFAULT 'youtube' AFTER workspace_id,
+  CHANGE COLUMN youtube_video_id external_id VARCHAR(64) NULL,
+  CHANGE COLUMN youtube_url url VARCHAR(500) NULL,
+  ADD COLUMN metadata JSON NULL AFTER channel_id,
+  ADD COLUMN shares INT NOT NULL DEFAULT 0 AFTER comments,
+  DROP INDEX uq_youtube_video_id,
+  ADD UNIQUE KEY uq_publications_platform_external_id (platform, external_id),
+  ADD KEY idx_publications_platform (platform);
+
+ALTER TABLE publications
+  ALTER COLUMN platform DROP DEFAULT;
@@ -0,0 +1,57 @@
+-- Webhook endpoints users register to hear about their workspace's events, and the log of
+-- every delivery to them. Deliveries are retried through outbox_events.
+CREATE TABLE webhook_endpoints (
+  id INT NOT NULL AUTO_INCREMENT,
+
+  workspace_id INT NOT NULL,
+  created_by INT NULL,
+
+  url VARCHAR(500) NOT NULL,
+  description VARCHAR(255) NULL,
+  events JSON NOT NULL, -- subscribed event types, e.g. ["clip.exported","publish.updated"]
+  secret VARCHAR(255) NOT NULL, -- signing secret, sealed with secretbox
+  active TINYINT(1) NOT NULL DEFAULT 1,
+
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (id),
+
+  KEY idx_webhook_endpoints_workspace (workspace_id, active),
+
+  CONSTRAINT fk_webhook_endpoints_workspace
+    FOREIGN KEY (workspace_id) REFERENCES workspaces(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_webhook_endpoints_created_by
+    FOREIGN KEY (created_by) REFERENCES users(id)
+    ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE webhook_deliveries (
+  id BIGINT NOT NULL AUTO_INCREMENT,
+
+  endpoint_id INT NOT NULL,
+
+  event_id CHAR(36) NOT NULL, -- shared by every endpoint's delivery of the same event
+  event_type VARCHAR(64) NOT NULL,
+  payload JSON NOT NULL,
+
+  status ENUM('pending','delivered','failed') NOT NULL DEFAULT 'pending',
+  attempts INT NOT NULL DEFAULT 0,
+  response_status INT NULL,
+  response_body VARCHAR(1000) NULL, -- kept for 2xx responses only
+  last_error VARCHAR(500) NULL,
+  last_attempt_at DATETIME NULL,
+  delivered_at DATETIME NULL,
+
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (id),
+
+  KEY idx_webhook_deliveries_endpoint (endpoint_id, created_at),
+
+  CONSTRAINT fk_webhook_deliveries_endpoint
+    FOREIGN KEY (endpoint_id) REFERENCES webhook_endpoints(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
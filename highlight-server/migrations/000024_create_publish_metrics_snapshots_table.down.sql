@@ -0,0 +1 @@
+DROP TABLE IF EXISTS publish_metrics_snapshots;
@@ -0,0 +1,32 @@
+-- Every metrics sync of a publication, so growth can be charted. Raw snapshots older than 30 days
+-- are downsampled to the last one of each day.
+CREATE TABLE publish_metrics_snapshots (
+  id BIGINT NOT NULL AUTO_INCREMENT,
+
+  publication_id INT NOT NULL,
+
+  granularity ENUM('raw','daily') NOT NULL DEFAULT 'raw',
+
+  views INT NOT NULL DEFAULT 0,
+  likes INT NOT NULL DEFAULT 0,
+  comments INT NOT NULL DEFAULT 0,
+  shares INT NOT NULL DEFAULT 0,
+
+  captured_at DATETIME NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (id),
+
+  KEY idx_publish_metrics_publication (publication_id, captured_at),
+  KEY idx_publish_metrics_granularity (granularity, captured_at),
+
+  CONSTRAINT fk_publish_metrics_publication
+    FOREIGN KEY (publication_id) REFERENCES publications(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Start each synced publication's history with the numbers it has now.
+INSERT INTO publish_metrics_snapshots (publication_id, views, likes, comments, shares, captured_at)
+SELECT id, views, likes, comments, shares, last_synced_at
+FROM publications
+WHERE last_synced_at IS NOT NULL;
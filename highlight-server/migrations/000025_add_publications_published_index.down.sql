@@ -0,0 +1,2 @@
+ALTER TABLE publications
+  DROP KEY idx_publications_workspace_published;
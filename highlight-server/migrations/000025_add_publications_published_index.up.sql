@@ -0,0 +1,3 @@
+-- Analytics read a workspace's publications by when they went live.
+ALTER TABLE publications
+  ADD KEY idx_publications_workspace_published (workspace_id, published_at);
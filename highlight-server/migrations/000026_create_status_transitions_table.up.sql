@@ -0,0 +1,23 @@
+-- Every status change of a recording, clip candidate, clip or publication. entity_id points at
+-- the entity's own table; rows outlive the entity so its history can still be audited.
+CREATE TABLE status_transitions (
+  id BIGINT NOT NULL AUTO_INCREMENT,
+
+  entity ENUM('recording','candidate','clip','publication') NOT NULL,
+  entity_id INT NOT NULL,
+
+  from_status VARCHAR(32) NOT NULL,
+  to_status VARCHAR(32) NOT NULL,
+
+  actor_user_id INT NULL, -- NULL for changes made by the system
+
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (id),
+
+  KEY idx_status_transitions_entity (entity, entity_id, created_at),
+
+  CONSTRAINT fk_status_transitions_actor
+    FOREIGN KEY (actor_user_id) REFERENCES users(id)
+    ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,2 @@
+-- Published clips were ready before the status was kept in sync. Their history stays.
+UPDATE clips SET status = 'ready' WHERE status = 'published';
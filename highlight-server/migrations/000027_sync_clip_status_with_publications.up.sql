@@ -0,0 +1,12 @@
+-- Clips are published exactly while one of their publications is live. Nothing set the status
+-- before, so bring ready clips with a live publication in line, recording the change.
+INSERT INTO status_transitions (entity, entity_id, from_status, to_status)
+SELECT 'clip', c.id, c.status, 'published'
+FROM clips c
+WHERE c.status = 'ready'
+  AND EXISTS (SELECT 1 FROM publications p WHERE p.clip_id = c.id AND p.status = 'uploaded');
+
+UPDATE clips c
+SET c.status = 'published'
+WHERE c.status = 'ready'
+  AND EXISTS (SELECT 1 FROM publications p WHERE p.clip_id = c.id AND p.status = 'uploaded');